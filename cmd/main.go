@@ -2,23 +2,32 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // todo time zones must load on hosts without a zoneinfo database
 
 	"github.com/a-berahman/todo-list/config"
 	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
 	"github.com/a-berahman/todo-list/internal/handlers"
+	"github.com/a-berahman/todo-list/internal/handlers/auth"
+	"github.com/a-berahman/todo-list/internal/handlers/todo"
 	"github.com/a-berahman/todo-list/internal/infra/db"
 	"github.com/a-berahman/todo-list/internal/infra/queue"
 	"github.com/a-berahman/todo-list/internal/infra/storage"
+	"github.com/a-berahman/todo-list/internal/ports/inbound"
 	"github.com/go-playground/validator"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/viper"
@@ -33,20 +42,120 @@ func main() {
 		os.Exit(1)
 	}
 
-	dbConn := initDB(conf.DBURL)
-	defer dbConn.Close(context.Background())
+	dbPool := initDB(conf.DBURL)
+	defer dbPool.Close()
 
-	e := setupEcho(logger)
+	typeLimits, err := conf.UploadConf.TypeLimits()
+	if err != nil {
+		logger.Error("failed to load upload limits", "error", err)
+		os.Exit(1)
+	}
 
+	store := db.NewStore(dbPool)
 	todoService := application.NewTodoService(
-		db.New(dbConn),
-		storage.NewS3FileStorage(conf.AWSConf.S3Conf.Region, conf.AWSConf.S3Conf.Bucket, conf.AWSConf.Endpoint, conf.AWSConf.S3Conf.DisableSSL, conf.AWSConf.S3Conf.ForcePathStyle),
+		store,
+		storage.NewS3FileStorage(conf.AWSConf.S3Conf.Region, conf.AWSConf.S3Conf.Bucket, conf.AWSConf.Endpoint, conf.AWSConf.S3Conf.DisableSSL, conf.AWSConf.S3Conf.ForcePathStyle, conf.AWSConf.S3Conf.PresignExpiry),
 		queue.NewSQSPublisher(conf.AWSConf.SQSConf.Region, conf.AWSConf.SQSConf.QueueURL, conf.AWSConf.Endpoint, conf.AWSConf.SQSConf.DisableSSL),
+		application.UploadLimits{
+			MaxSize:      conf.UploadConf.MaxSize,
+			MaxFiles:     conf.UploadConf.MaxFiles,
+			AllowedTypes: conf.UploadConf.AllowedTypes,
+			TypeMaxSizes: typeLimits,
+		},
+		domain.CompletionPolicy(conf.SubtaskConf.CompletionPolicy),
 		logger,
 	)
 
-	h := handlers.NewHandler(todoService, logger)
-	e.POST("api/v1/upload", h.TodoHandler.CreateTodo)
+	if len(os.Args) > 1 && os.Args[1] == reconcileFilesCommand {
+		// the files of every workspace are reconciled, so the command isn't limited to one by row-level security
+		if err := runReconcileFiles(db.WithAllWorkspaces(context.Background()), os.Args[2:], todoService, os.Stdout, logger); err != nil {
+			logger.Error("failed to reconcile files", "error", err)
+			dbPool.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	jwtKey, err := conf.AuthConf.VerificationKey()
+	if err != nil {
+		logger.Error("failed to load JWT verification key", "error", err)
+		os.Exit(1)
+	}
+	verifier, err := auth.NewJWTVerifier(conf.AuthConf.JWTAlgorithm, jwtKey, conf.AuthConf.JWTIssuer, conf.AuthConf.JWTAudience)
+	if err != nil {
+		logger.Error("failed to set up JWT verification", "error", err)
+		os.Exit(1)
+	}
+
+	apiKeyService := application.NewAPIKeyService(store, logger)
+	workspaceService := application.NewWorkspaceService(store, logger)
+	e := setupEcho(logger, verifier, application.NewUserService(store, logger), apiKeyService, workspaceService)
+
+	h := handlers.NewHandler(todoService, apiKeyService, workspaceService, logger)
+	uploadLimit := bodyLimit(uploadBodyLimit(conf.UploadConf.MaxSize, conf.UploadConf.MaxFiles))
+	// requests made with an API key need the scope of the route, users signed in with a token pass
+	read := auth.RequireScope(domain.ScopeTodosRead)
+	write := auth.RequireScope(domain.ScopeTodosWrite)
+	attach := auth.RequireScope(domain.ScopeAttachmentsWrite)
+	e.POST("api/v1/upload", h.TodoHandler.CreateTodo, write, uploadLimit)
+	e.POST("api/v1/todos", h.TodoHandler.CreateTodo, write, uploadLimit)
+	e.GET("api/v1/todos", h.TodoHandler.ListTodos, read)
+	e.GET("api/v1/todos/graph", h.TodoHandler.GetDependencyGraph, read)
+	e.GET("api/v1/todos/:id", h.TodoHandler.GetTodo, read)
+	e.PUT("api/v1/todos/:id", h.TodoHandler.UpdateTodo, write)
+	e.PATCH("api/v1/todos/:id", h.TodoHandler.PatchTodo, write)
+	e.PUT("api/v1/todos/:id/status", h.TodoHandler.TransitionTodo, write)
+	e.POST("api/v1/todos/:id/move", h.TodoHandler.MoveTodo, write)
+	e.DELETE("api/v1/todos/:id", h.TodoHandler.DeleteTodo, write)
+	e.POST("api/v1/todos/:id/restore", h.TodoHandler.RestoreTodo, write)
+	e.POST("api/v1/todos/:id/attachments", h.TodoHandler.AddAttachments, attach, uploadLimit)
+	e.GET("api/v1/todos/:id/attachments", h.TodoHandler.ListAttachments, read)
+	e.GET("api/v1/todos/:id/attachments/:fileId", h.TodoHandler.GetAttachment, read)
+	e.DELETE("api/v1/todos/:id/attachments/:fileId", h.TodoHandler.RemoveAttachment, attach)
+	e.POST("api/v1/todos/:id/checklist", h.TodoHandler.AddChecklistItem, write)
+	e.PATCH("api/v1/todos/:id/checklist/:itemId", h.TodoHandler.UpdateChecklistItem, write)
+	e.DELETE("api/v1/todos/:id/checklist/:itemId", h.TodoHandler.RemoveChecklistItem, write)
+	e.POST("api/v1/todos/:id/dependencies", h.TodoHandler.AddDependency, write)
+	e.DELETE("api/v1/todos/:id/dependencies/:blockerId", h.TodoHandler.RemoveDependency, write)
+	e.POST("api/v1/todos/:id/comments", h.TodoHandler.AddComment, write)
+	e.GET("api/v1/todos/:id/comments", h.TodoHandler.ListComments, read)
+	e.POST("api/v1/todos/:id/shares", h.TodoHandler.ShareTodo, write)
+	e.GET("api/v1/todos/:id/shares", h.TodoHandler.ListShares, read)
+	e.DELETE("api/v1/todos/:id/shares/:shareId", h.TodoHandler.RevokeShare, write)
+	e.POST("api/v1/tags", h.TodoHandler.CreateTag, write)
+	e.GET("api/v1/tags", h.TodoHandler.ListTags, read)
+	e.GET("api/v1/tags/:id", h.TodoHandler.GetTag, read)
+	e.PUT("api/v1/tags/:id", h.TodoHandler.UpdateTag, write)
+	e.DELETE("api/v1/tags/:id", h.TodoHandler.DeleteTag, write)
+	e.POST("api/v1/lists", h.TodoHandler.CreateList, write)
+	e.GET("api/v1/lists", h.TodoHandler.ListLists, read)
+	e.GET("api/v1/lists/:id", h.TodoHandler.GetList, read)
+	e.PATCH("api/v1/lists/:id", h.TodoHandler.UpdateList, write)
+	e.DELETE("api/v1/lists/:id", h.TodoHandler.DeleteList, write)
+	e.POST("api/v1/lists/:id/shares", h.TodoHandler.ShareList, write)
+	e.GET("api/v1/lists/:id/shares", h.TodoHandler.ListListShares, read)
+	e.DELETE("api/v1/lists/:id/shares/:shareId", h.TodoHandler.RevokeListShare, write)
+
+	// the handlers check the role of the user in the workspace of the path, not the one the request is made in
+	user := auth.RequireUser()
+	e.POST("api/v1/workspaces", h.WorkspaceHandler.CreateWorkspace, user)
+	e.GET("api/v1/workspaces", h.WorkspaceHandler.ListWorkspaces, user)
+	e.GET("api/v1/workspaces/:id/members", h.WorkspaceHandler.ListWorkspaceMembers, user)
+	e.PUT("api/v1/workspaces/:id/members/:userId", h.WorkspaceHandler.SetWorkspaceMember, user)
+	e.DELETE("api/v1/workspaces/:id/members/:userId", h.WorkspaceHandler.RemoveWorkspaceMember, user)
+
+	admin := auth.RequireAdmin(conf.AuthConf.AdminSubjects)
+	e.POST("api/v1/admin/api-keys", h.APIKeyHandler.MintAPIKey, admin)
+	e.GET("api/v1/admin/api-keys", h.APIKeyHandler.ListAPIKeys, admin)
+	e.DELETE("api/v1/admin/api-keys/:id", h.APIKeyHandler.RevokeAPIKey, admin)
+	e.POST("api/v1/admin/api-keys/:id/rotate", h.APIKeyHandler.RotateAPIKey, admin)
+
+	// the workers aren't made on behalf of a user, they work on the todos of every workspace
+	workersCtx, stopWorkers := context.WithCancel(db.WithAllWorkspaces(context.Background()))
+	defer stopWorkers()
+
+	go application.NewTrashPurger(todoService, conf.TrashConf.Retention, conf.TrashConf.PurgeInterval, logger).Run(workersCtx)
+	go application.NewOutboxRelay(todoService, conf.OutboxConf.RelayInterval, conf.OutboxConf.Retention, logger).Run(workersCtx)
 
 	go func() {
 		if err := e.Start(conf.Port); err != nil {
@@ -58,6 +167,8 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopWorkers()
+
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -70,14 +181,18 @@ func main() {
 	logger.Info("server shutdown successfully")
 }
 
-func setupEcho(logger *slog.Logger) *echo.Echo {
+func setupEcho(logger *slog.Logger, verifier *auth.JWTVerifier, userService inbound.UserService, apiKeyService inbound.APIKeyService, workspaceService inbound.WorkspaceService) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = todo.NewHTTPErrorHandler(logger)
 
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(requestTimer(logger))
+	e.Use(auth.Authenticate(verifier, userService, apiKeyService, logger))
+	e.Use(auth.ResolveWorkspace(workspaceService))
 
 	v := validator.New()
+	v.RegisterTagNameFunc(requestFieldName)
 	v.RegisterValidation("datetime", func(fl validator.FieldLevel) bool {
 		_, err := time.Parse(fl.Param(), fl.Field().String())
 		return err == nil
@@ -87,20 +202,28 @@ func setupEcho(logger *slog.Logger) *echo.Echo {
 	return e
 }
 
-func initDB(dbURL string) *pgx.Conn {
+// initDB opens a connection pool, a single connection can't be shared by concurrent requests and background workers.
+// Every connection is limited to the workspace of the request using it, see db.ApplyWorkspace.
+func initDB(dbURL string) *pgxpool.Pool {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := pgx.Connect(ctx, dbURL)
+	poolConf, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		log.Fatalf("failed to parse database URL: %v", err)
+	}
+	poolConf.BeforeAcquire = db.ApplyWorkspace
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConf)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
-	if err := conn.Ping(ctx); err != nil {
+	if err := pool.Ping(ctx); err != nil {
 		log.Fatalf("failed to ping database: %v", err)
 	}
 
-	return conn
+	return pool
 }
 
 func loadConfig() (*config.Config, error) {
@@ -142,6 +265,40 @@ func requestTimer(logger *slog.Logger) echo.MiddlewareFunc {
 
 }
 
+// maxFormOverhead leaves room for the form fields and multipart boundaries next to the largest accepted files.
+const maxFormOverhead = 1 << 20
+
+// uploadBodyLimit is the largest body accepted by the upload endpoints. JSON bodies inline the files base64 encoded,
+// so the limit leaves room for the encoded size rather than the raw one.
+func uploadBodyLimit(maxSize int64, maxFiles int) int64 {
+	return int64(base64.StdEncoding.EncodedLen(int(maxSize)))*int64(maxFiles) + maxFormOverhead
+}
+
+// bodyLimit stops reading request bodies after limit bytes, so oversized uploads are rejected while they stream in.
+func bodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge)
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// requestFieldName reports validation failures under the name a field has in the request rather than its Go name.
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"form", "query", "param", "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 type CustomValidator struct {
 	Validator *validator.Validate
 }
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/a-berahman/todo-list/internal/handlers/auth"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -41,16 +45,25 @@ func TestCustomValidator(t *testing.T) {
 		{
 			name: "valid datetime",
 			input: struct {
-				Date string `validate:"datetime=2006-01-02T15:04:05Z"`
+				Date string `validate:"datetime=2006-01-02T15:04:05Z07:00"`
 			}{
 				Date: "2024-01-01T12:00:00Z",
 			},
 			expectedError: false,
 		},
+		{
+			name: "valid datetime with an offset",
+			input: struct {
+				Date string `validate:"datetime=2006-01-02T15:04:05Z07:00"`
+			}{
+				Date: "2024-01-01T12:00:00+02:00",
+			},
+			expectedError: false,
+		},
 		{
 			name: "invalid datetime",
 			input: struct {
-				Date string `validate:"datetime=2006-01-02T15:04:05Z"`
+				Date string `validate:"datetime=2006-01-02T15:04:05Z07:00"`
 			}{
 				Date: "invalid-date",
 			},
@@ -78,6 +91,38 @@ func TestCustomValidator(t *testing.T) {
 	}
 }
 
+func TestRequestFieldName(t *testing.T) {
+	e := setupEcho(slog.Default(), nil, nil, nil, nil)
+
+	err := e.Validator.Validate(&schemas.PatchTodoRequest{ID: "not-a-uuid", Description: new(string), DueDate: new(string)})
+
+	var validationErrs validator.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+	fields := make([]string, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, fe.Field())
+	}
+	assert.Equal(t, []string{"id", "dueDate"}, fields)
+}
+
+func TestSetupEchoRequiresToken(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.AlgorithmHS256, []byte("0123456789abcdef0123456789abcdef"), "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	e := setupEcho(slog.Default(), verifier, nil, nil, nil)
+	e.GET("/api/v1/todos", func(c echo.Context) error {
+		return c.String(http.StatusOK, "success")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+}
+
 func TestRequestTimer(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -123,6 +168,58 @@ func TestRequestTimer(t *testing.T) {
 	}
 }
 
+func TestUploadBodyLimit(t *testing.T) {
+	// 3 raw bytes take 4 base64 encoded
+	assert.Equal(t, int64(2*4+maxFormOverhead), uploadBodyLimit(3, 2))
+}
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{
+			name:           "body within the limit",
+			body:           "12345678",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "declared length over the limit",
+			body:           "123456789",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "streamed body over the limit",
+			body:           "123456789",
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.POST("/upload", func(c echo.Context) error {
+				if _, err := io.ReadAll(c.Request().Body); err != nil {
+					return echo.NewHTTPError(http.StatusRequestEntityTooLarge)
+				}
+				return c.String(http.StatusOK, "success")
+			}, bodyLimit(8))
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
 func TestGetProjectRoot(t *testing.T) {
 	root := getProjectRoot()
 	assert.NotEmpty(t, root)
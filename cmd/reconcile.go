@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+)
+
+const reconcileFilesCommand = "reconcile-files"
+
+// fileReconciler is the part of the todo service used by the reconcile-files command.
+type fileReconciler interface {
+	ReconcileFiles(ctx context.Context, modifiedBefore time.Time, remove bool) (application.FileReconciliationReport, error)
+}
+
+// runReconcileFiles reports stored files that no todo references and removes them when -delete is given.
+func runReconcileFiles(ctx context.Context, args []string, reconciler fileReconciler, out io.Writer, logger *slog.Logger) error {
+	flags := flag.NewFlagSet(reconcileFilesCommand, flag.ContinueOnError)
+	flags.SetOutput(out)
+	remove := flags.Bool("delete", false, "delete orphaned files instead of only reporting them")
+	minAge := flags.Duration("min-age", time.Hour, "ignore files modified more recently than this, their todo may still be being saved")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := reconciler.ReconcileFiles(ctx, time.Now().Add(-*minAge), *remove)
+	for _, key := range report.Orphans {
+		fmt.Fprintln(out, key)
+	}
+	logger.Info("file reconciliation finished",
+		"scanned", report.Scanned,
+		"skipped", report.Skipped,
+		"orphans", len(report.Orphans),
+		"removed", report.Removed,
+	)
+	return err
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubReconciler struct {
+	report         application.FileReconciliationReport
+	err            error
+	modifiedBefore time.Time
+	remove         bool
+}
+
+func (s *stubReconciler) ReconcileFiles(_ context.Context, modifiedBefore time.Time, remove bool) (application.FileReconciliationReport, error) {
+	s.modifiedBefore = modifiedBefore
+	s.remove = remove
+	return s.report, s.err
+}
+
+func TestRunReconcileFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		err            error
+		expectedRemove bool
+		expectedMinAge time.Duration
+		expectedError  bool
+	}{
+		{
+			name:           "report only by default",
+			expectedMinAge: time.Hour,
+		},
+		{
+			name:           "delete with custom min age",
+			args:           []string{"-delete", "-min-age", "24h"},
+			expectedRemove: true,
+			expectedMinAge: 24 * time.Hour,
+		},
+		{
+			name:          "unknown flag",
+			args:          []string{"-force"},
+			expectedError: true,
+		},
+		{
+			name:           "reconciliation fails",
+			err:            errors.New("s3 error"),
+			expectedMinAge: time.Hour,
+			expectedError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := &stubReconciler{
+				report: application.FileReconciliationReport{Scanned: 2, Orphans: []string{"todos/1/a"}},
+				err:    tt.err,
+			}
+			var out bytes.Buffer
+
+			start := time.Now()
+			err := runReconcileFiles(context.Background(), tt.args, reconciler, &out, slog.Default())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.expectedMinAge == 0 {
+				return
+			}
+			assert.Equal(t, tt.expectedRemove, reconciler.remove)
+			assert.WithinDuration(t, start.Add(-tt.expectedMinAge), reconciler.modifiedBefore, time.Second)
+			assert.Equal(t, "todos/1/a\n", out.String())
+		})
+	}
+}
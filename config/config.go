@@ -3,15 +3,24 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Port    string    `mapstructure:"SERVER_PORT"`
-	DBURL   string    `mapstructure:"DATABASE_URL"`
-	AWSConf AWSConfig `mapstructure:",squash"`
+	Port        string        `mapstructure:"SERVER_PORT"`
+	DBURL       string        `mapstructure:"DATABASE_URL"`
+	AWSConf     AWSConfig     `mapstructure:",squash"`
+	TrashConf   TrashConfig   `mapstructure:",squash"`
+	OutboxConf  OutboxConfig  `mapstructure:",squash"`
+	UploadConf  UploadConfig  `mapstructure:",squash"`
+	SubtaskConf SubtaskConfig `mapstructure:",squash"`
+	AuthConf    AuthConfig    `mapstructure:",squash"`
 }
 
 type AWSConfig struct {
@@ -27,10 +36,74 @@ type SQSConfig struct {
 }
 
 type S3Config struct {
-	Bucket         string `mapstructure:"AWS_S3_BUCKET"`
-	Region         string `mapstructure:"AWS_S3_REGION"`
-	DisableSSL     bool   `mapstructure:"AWS_S3_DISABLE_SSL"`
-	ForcePathStyle bool   `mapstructure:"AWS_S3_FORCE_PATH_STYLE"`
+	Bucket         string        `mapstructure:"AWS_S3_BUCKET"`
+	Region         string        `mapstructure:"AWS_S3_REGION"`
+	DisableSSL     bool          `mapstructure:"AWS_S3_DISABLE_SSL"`
+	ForcePathStyle bool          `mapstructure:"AWS_S3_FORCE_PATH_STYLE"`
+	PresignExpiry  time.Duration `mapstructure:"AWS_S3_PRESIGN_EXPIRY"` // How long attachment download links stay valid
+}
+
+type TrashConfig struct {
+	Retention     time.Duration `mapstructure:"TRASH_RETENTION"`      // How long deleted todos stay restorable
+	PurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"` // How often the trash is purged
+}
+
+type OutboxConfig struct {
+	RelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"` // How often pending events are published
+	Retention     time.Duration `mapstructure:"OUTBOX_RETENTION"`      // How long sent events are kept
+}
+
+type UploadConfig struct {
+	MaxSize      int64    `mapstructure:"UPLOAD_MAX_SIZE"`       // Largest accepted file in bytes
+	MaxFiles     int      `mapstructure:"UPLOAD_MAX_FILES"`      // Most files accepted in a single request
+	AllowedTypes []string `mapstructure:"UPLOAD_ALLOWED_TYPES"`  // Content types accepted, detected from the file content
+	TypeMaxSizes string   `mapstructure:"UPLOAD_TYPE_MAX_SIZES"` // Lower limits per content type, e.g. image/png=10485760,text/plain=1048576
+}
+
+type SubtaskConfig struct {
+	CompletionPolicy string `mapstructure:"SUBTASK_COMPLETION_POLICY"` // block or cascade, what completing a todo with unfinished subtasks does
+}
+
+type AuthConfig struct {
+	JWTAlgorithm     string   `mapstructure:"JWT_ALGORITHM"`       // HS256 or RS256, the only algorithm tokens are accepted with
+	JWTSecret        string   `mapstructure:"JWT_SECRET"`          // Shared secret of HS256 tokens, at least 32 bytes
+	JWTPublicKeyFile string   `mapstructure:"JWT_PUBLIC_KEY_FILE"` // PEM file with the public key of RS256 tokens
+	JWTIssuer        string   `mapstructure:"JWT_ISSUER"`          // Required iss claim, not checked when empty
+	JWTAudience      string   `mapstructure:"JWT_AUDIENCE"`        // Required aud claim, not checked when empty
+	AdminSubjects    []string `mapstructure:"ADMIN_SUBJECTS"`      // sub claims of the users who manage API keys
+}
+
+// minJWTSecretLength is the shortest HS256 secret accepted, RFC 7518 asks for a key as long as the hash output.
+const minJWTSecretLength = 32
+
+// TypeLimits parses TypeMaxSizes into the largest accepted size in bytes per content type.
+func (u UploadConfig) TypeLimits() (map[string]int64, error) {
+	limits := make(map[string]int64)
+	for _, entry := range strings.Split(u.TypeMaxSizes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		contentType, size, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("missing size for %q", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid size for %q", entry)
+		}
+		limits[strings.TrimSpace(contentType)] = limit
+	}
+	return limits, nil
+}
+
+// VerificationKey returns the key tokens are verified with, the secret for HS256 and the content of the public key
+// file for RS256.
+func (a AuthConfig) VerificationKey() ([]byte, error) {
+	if a.JWTAlgorithm == "RS256" {
+		return os.ReadFile(a.JWTPublicKeyFile)
+	}
+	return []byte(a.JWTSecret), nil
 }
 
 // NewConfig initializes and returns a Config struct
@@ -78,6 +151,18 @@ func setDefaults() {
 	viper.SetDefault("BREAKER_FAILURES_THRESHOLD", 3)
 	viper.SetDefault("BACKOFF_MAX_INTERVAL", 5*time.Second)
 	viper.SetDefault("BACKOFF_MAX_ELAPSED_TIME", 25*time.Second)
+	viper.SetDefault("TRASH_RETENTION", 30*24*time.Hour)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", time.Hour)
+	viper.SetDefault("OUTBOX_RELAY_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_RETENTION", 7*24*time.Hour)
+	viper.SetDefault("UPLOAD_MAX_SIZE", 25<<20)
+	viper.SetDefault("UPLOAD_MAX_FILES", 10)
+	viper.SetDefault("UPLOAD_ALLOWED_TYPES", []string{"text/plain", "image/png", "image/jpeg", "application/pdf"})
+	viper.SetDefault("UPLOAD_TYPE_MAX_SIZES", "")
+	viper.SetDefault("AWS_S3_PRESIGN_EXPIRY", 15*time.Minute)
+	viper.SetDefault("SUBTASK_COMPLETION_POLICY", "block")
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("ADMIN_SUBJECTS", []string{})
 }
 
 func (c *Config) Validate() error {
@@ -86,9 +171,86 @@ func (c *Config) Validate() error {
 		return ErrMissingConfig("SERVER_PORT")
 	}
 
+	if c.TrashConf.Retention <= 0 {
+		slog.Error("TRASH_RETENTION must be positive")
+		return ErrInvalidConfig("TRASH_RETENTION")
+	}
+
+	if c.TrashConf.PurgeInterval <= 0 {
+		slog.Error("TRASH_PURGE_INTERVAL must be positive")
+		return ErrInvalidConfig("TRASH_PURGE_INTERVAL")
+	}
+
+	if c.OutboxConf.RelayInterval <= 0 {
+		slog.Error("OUTBOX_RELAY_INTERVAL must be positive")
+		return ErrInvalidConfig("OUTBOX_RELAY_INTERVAL")
+	}
+
+	if c.OutboxConf.Retention <= 0 {
+		slog.Error("OUTBOX_RETENTION must be positive")
+		return ErrInvalidConfig("OUTBOX_RETENTION")
+	}
+
+	if c.AWSConf.S3Conf.PresignExpiry <= 0 {
+		slog.Error("AWS_S3_PRESIGN_EXPIRY must be positive")
+		return ErrInvalidConfig("AWS_S3_PRESIGN_EXPIRY")
+	}
+
+	if c.UploadConf.MaxSize <= 0 {
+		slog.Error("UPLOAD_MAX_SIZE must be positive")
+		return ErrInvalidConfig("UPLOAD_MAX_SIZE")
+	}
+
+	if c.UploadConf.MaxFiles <= 0 {
+		slog.Error("UPLOAD_MAX_FILES must be positive")
+		return ErrInvalidConfig("UPLOAD_MAX_FILES")
+	}
+
+	if len(c.UploadConf.AllowedTypes) == 0 {
+		slog.Error("UPLOAD_ALLOWED_TYPES must not be empty")
+		return ErrInvalidConfig("UPLOAD_ALLOWED_TYPES")
+	}
+
+	typeLimits, err := c.UploadConf.TypeLimits()
+	if err != nil {
+		slog.Error("UPLOAD_TYPE_MAX_SIZES is malformed", "error", err)
+		return ErrInvalidConfig("UPLOAD_TYPE_MAX_SIZES")
+	}
+	for contentType := range typeLimits {
+		if !slices.Contains(c.UploadConf.AllowedTypes, contentType) {
+			slog.Error("UPLOAD_TYPE_MAX_SIZES limits a type that isn't allowed", "contentType", contentType)
+			return ErrInvalidConfig("UPLOAD_TYPE_MAX_SIZES")
+		}
+	}
+
+	if !slices.Contains([]string{"block", "cascade"}, c.SubtaskConf.CompletionPolicy) {
+		slog.Error("SUBTASK_COMPLETION_POLICY must be block or cascade")
+		return ErrInvalidConfig("SUBTASK_COMPLETION_POLICY")
+	}
+
+	switch c.AuthConf.JWTAlgorithm {
+	case "HS256":
+		if len(c.AuthConf.JWTSecret) < minJWTSecretLength {
+			slog.Error("JWT_SECRET must be at least 32 bytes for HS256")
+			return ErrInvalidConfig("JWT_SECRET")
+		}
+	case "RS256":
+		if c.AuthConf.JWTPublicKeyFile == "" {
+			slog.Error("JWT_PUBLIC_KEY_FILE is not set")
+			return ErrMissingConfig("JWT_PUBLIC_KEY_FILE")
+		}
+	default:
+		slog.Error("JWT_ALGORITHM must be HS256 or RS256")
+		return ErrInvalidConfig("JWT_ALGORITHM")
+	}
+
 	return nil
 }
 
 func ErrMissingConfig(key string) error {
 	return fmt.Errorf("missing required configuration: %s", key)
 }
+
+func ErrInvalidConfig(key string) error {
+	return fmt.Errorf("invalid configuration value: %s", key)
+}
@@ -0,0 +1,221 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// apiKeyMarker starts every key, so leaked keys are easy to spot in logs and by secret scanners.
+	apiKeyMarker = "tdl_"
+	// apiKeySecretBytes is the entropy of a key, enough for an unsalted SHA-256 hash to be safe to store.
+	apiKeySecretBytes = 32
+	// apiKeyPrefixLength is how much of a key is kept readable to tell keys apart.
+	apiKeyPrefixLength = len(apiKeyMarker) + 8
+)
+
+var (
+	errAPIKeyName   = domain.NewValidationError("name", "name is required")
+	errAPIKeyScopes = domain.NewValidationError("scopes", "at least one of todos:read, todos:write and attachments:write is required")
+)
+
+// APIKeyService mints the API keys services authenticate with and checks the keys of requests.
+type APIKeyService struct {
+	apiKeyRepository outbound.DBRepository
+	logger           *slog.Logger
+}
+
+func NewAPIKeyService(apiKeyRepository outbound.DBRepository, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{apiKeyRepository: apiKeyRepository, logger: logger}
+}
+
+// MintAPIKey creates a key owned by the user of the request. The key itself is only returned here, afterwards
+// just its prefix can be seen.
+func (s *APIKeyService) MintAPIKey(ctx context.Context, name string, scopes []domain.Scope) (domain.APIKey, string, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.APIKey{}, "", errAPIKeyName
+	}
+	if len(scopes) == 0 {
+		return domain.APIKey{}, "", errAPIKeyScopes
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return domain.APIKey{}, "", errAPIKeyScopes
+		}
+	}
+
+	var key domain.APIKey
+	var secret string
+	err = s.apiKeyRepository.ExecTx(ctx, func(q db.Querier) error {
+		key, secret, err = createAPIKey(ctx, q, owner, name, scopes)
+		return err
+	})
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	return key, secret, nil
+}
+
+// ListAPIKeys returns every key, the revoked ones included, newest first.
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.apiKeyRepository.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list api keys from repository: %w", err))
+	}
+	keys := make([]domain.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toDomainAPIKey(row))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops the key from authenticating requests. Revoked keys can't be used again.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	_, err := revokeAPIKey(ctx, s.apiKeyRepository, id)
+	return err
+}
+
+// RotateAPIKey revokes the key and mints a new one with the same owner, name and scopes in its place.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id string) (domain.APIKey, string, error) {
+	var key domain.APIKey
+	var secret string
+	err := s.apiKeyRepository.ExecTx(ctx, func(q db.Querier) error {
+		revoked, err := revokeAPIKey(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		key, secret, err = createAPIKey(ctx, q, revoked.OwnerID, revoked.Name, toDomainScopes(revoked.Scopes))
+		return err
+	})
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	return key, secret, nil
+}
+
+// AuthenticateAPIKey returns the unrevoked key matching secret and records that it was used.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, secret string) (domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyMarker) {
+		return domain.APIKey{}, domain.ErrInvalidAPIKey
+	}
+	row, err := s.apiKeyRepository.GetActiveAPIKeyByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrInvalidAPIKey
+		}
+		return domain.APIKey{}, databaseError(fmt.Errorf("failed to get api key from repository: %w", err))
+	}
+
+	// a failed update only costs the last-used time, it doesn't fail the request
+	err = s.apiKeyRepository.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		ID:         row.ID,
+		LastUsedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		s.logger.Warn("failed to record api key use", "error", err, "apiKeyId", uuid.UUID(row.ID.Bytes).String())
+	}
+	return toDomainAPIKey(row), nil
+}
+
+func createAPIKey(ctx context.Context, q db.Querier, owner pgtype.UUID, name string, scopes []domain.Scope) (domain.APIKey, string, error) {
+	random := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(random); err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := apiKeyMarker + base64.RawURLEncoding.EncodeToString(random)
+
+	params := db.CreateAPIKeyParams{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		OwnerID:   owner,
+		Name:      name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		KeyHash:   hashAPIKey(secret),
+		Scopes:    make([]string, 0, len(scopes)),
+		CreatedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	}
+	for _, scope := range scopes {
+		params.Scopes = append(params.Scopes, string(scope))
+	}
+	if err := q.CreateAPIKey(ctx, params); err != nil {
+		return domain.APIKey{}, "", databaseError(fmt.Errorf("failed to save api key to repository: %w", err))
+	}
+
+	return toDomainAPIKey(db.ApiKey{
+		ID:        params.ID,
+		OwnerID:   params.OwnerID,
+		Name:      params.Name,
+		Prefix:    params.Prefix,
+		Scopes:    params.Scopes,
+		CreatedAt: params.CreatedAt,
+	}), secret, nil
+}
+
+func revokeAPIKey(ctx context.Context, q db.Querier, id string) (db.ApiKey, error) {
+	keyUUID, err := uuid.Parse(id)
+	if err != nil {
+		return db.ApiKey{}, domain.ErrAPIKeyNotFound
+	}
+	row, err := q.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		ID:        pgtype.UUID{Bytes: keyUUID, Valid: true},
+		RevokedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) { // revoked keys are gone as far as clients are concerned
+			return db.ApiKey{}, domain.ErrAPIKeyNotFound
+		}
+		return db.ApiKey{}, databaseError(fmt.Errorf("failed to revoke api key in repository: %w", err))
+	}
+	return row, nil
+}
+
+func hashAPIKey(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+func toDomainAPIKey(row db.ApiKey) domain.APIKey {
+	key := domain.APIKey{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		OwnerID:   uuid.UUID(row.OwnerID.Bytes).String(),
+		Name:      row.Name,
+		Prefix:    row.Prefix,
+		Scopes:    toDomainScopes(row.Scopes),
+		CreatedAt: row.CreatedAt.Time.UTC(),
+	}
+	if row.LastUsedAt.Valid {
+		lastUsedAt := row.LastUsedAt.Time.UTC()
+		key.LastUsedAt = &lastUsedAt
+	}
+	if row.RevokedAt.Valid {
+		revokedAt := row.RevokedAt.Time.UTC()
+		key.RevokedAt = &revokedAt
+	}
+	return key
+}
+
+func toDomainScopes(scopes []string) []domain.Scope {
+	domainScopes := make([]domain.Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		domainScopes = append(domainScopes, domain.Scope(scope))
+	}
+	return domainScopes
+}
@@ -0,0 +1,260 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMintAPIKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		keyName       string
+		scopes        []domain.Scope
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:    "mints the key",
+			keyName: " release bot ",
+			scopes:  []domain.Scope{domain.ScopeTodosRead, domain.ScopeTodosWrite},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(params db.CreateAPIKeyParams) bool {
+					return params.OwnerID == testOwnerID && params.Name == "release bot" && len(params.KeyHash) == 32 &&
+						strings.HasPrefix(params.Prefix, apiKeyMarker) && len(params.Scopes) == 2
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "without name",
+			keyName:       " ",
+			scopes:        []domain.Scope{domain.ScopeTodosRead},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:          "without scopes",
+			keyName:       "release bot",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:          "unknown scope",
+			keyName:       "release bot",
+			scopes:        []domain.Scope{"todos:admin"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:    "database error",
+			keyName: "release bot",
+			scopes:  []domain.Scope{domain.ScopeTodosRead},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateAPIKey", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: "failed to save api key to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewAPIKeyService(mockDB, slog.Default())
+			key, secret, err := service.MintAPIKey(ownerContext(), tt.keyName, tt.scopes)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(secret, key.Prefix))
+				assert.Equal(t, "release bot", key.Name)
+				assert.Equal(t, tt.scopes, key.Scopes)
+				assert.Equal(t, uuid.UUID(testOwnerID.Bytes).String(), key.OwnerID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMintAPIKeyUnauthenticated(t *testing.T) {
+	service := NewAPIKeyService(new(MockDBRepository), slog.Default())
+	_, _, err := service.MintAPIKey(context.Background(), "release bot", []domain.Scope{domain.ScopeTodosRead})
+
+	assert.ErrorIs(t, err, domain.ErrUnauthenticatedRequest)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	secret := apiKeyMarker + "c2VjcmV0LXRoYXQtaXMtbG9uZy1lbm91Z2gtZm9yLWEta2V5"
+	row := db.ApiKey{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		OwnerID: testOwnerID,
+		Name:    "release bot",
+		Scopes:  []string{"todos:read"},
+	}
+
+	tests := []struct {
+		name          string
+		secret        string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:   "valid key",
+			secret: secret,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetActiveAPIKeyByHash", mock.Anything, hashAPIKey(secret)).Return(row, nil)
+				mockDB.On("TouchAPIKey", mock.Anything, mock.MatchedBy(func(params db.TouchAPIKeyParams) bool {
+					return params.ID == row.ID && params.LastUsedAt.Valid
+				})).Return(nil)
+			},
+		},
+		{
+			name:   "recording the use fails",
+			secret: secret,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetActiveAPIKeyByHash", mock.Anything, mock.Anything).Return(row, nil)
+				mockDB.On("TouchAPIKey", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+		},
+		{
+			name:   "unknown or revoked key",
+			secret: secret,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetActiveAPIKeyByHash", mock.Anything, mock.Anything).Return(db.ApiKey{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrInvalidAPIKey,
+		},
+		{
+			name:          "not an api key",
+			secret:        "eyJhbGciOiJIUzI1NiJ9",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrInvalidAPIKey,
+		},
+		{
+			name:   "database error",
+			secret: secret,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetActiveAPIKeyByHash", mock.Anything, mock.Anything).Return(db.ApiKey{}, errors.New("db error"))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewAPIKeyService(mockDB, slog.Default())
+			key, err := service.AuthenticateAPIKey(context.Background(), tt.secret)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uuid.UUID(row.ID.Bytes).String(), key.ID)
+				assert.Equal(t, []domain.Scope{domain.ScopeTodosRead}, key.Scopes)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	keyUUID := uuid.New()
+	revoked := db.ApiKey{
+		ID:      pgtype.UUID{Bytes: keyUUID, Valid: true},
+		OwnerID: testOwnerID,
+		Name:    "release bot",
+		Scopes:  []string{"todos:read", "attachments:write"},
+	}
+
+	tests := []struct {
+		name          string
+		id            string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "replaces the key",
+			id:   keyUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("RevokeAPIKey", mock.Anything, mock.MatchedBy(func(params db.RevokeAPIKeyParams) bool {
+					return params.ID == revoked.ID && params.RevokedAt.Valid
+				})).Return(revoked, nil)
+				mockDB.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(params db.CreateAPIKeyParams) bool {
+					return params.ID != revoked.ID && params.OwnerID == revoked.OwnerID && params.Name == revoked.Name &&
+						assert.ObjectsAreEqual(revoked.Scopes, params.Scopes)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "key not found or revoked",
+			id:   keyUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("RevokeAPIKey", mock.Anything, mock.Anything).Return(db.ApiKey{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrAPIKeyNotFound,
+		},
+		{
+			name:          "invalid ID",
+			id:            "not-a-uuid",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewAPIKeyService(mockDB, slog.Default())
+			key, secret, err := service.RotateAPIKey(ownerContext(), tt.id)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, keyUUID.String(), key.ID)
+				assert.True(t, strings.HasPrefix(secret, apiKeyMarker))
+				assert.Equal(t, []domain.Scope{domain.ScopeTodosRead, domain.ScopeAttachmentsWrite}, key.Scopes)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTodoWithFilesRequiresAttachmentScope(t *testing.T) {
+	ctx := domain.ContextWithPrincipal(context.Background(), domain.Principal{
+		UserID:      uuid.UUID(testOwnerID.Bytes).String(),
+		APIKeyID:    uuid.New().String(),
+		Scopes:      []domain.Scope{domain.ScopeTodosWrite},
+		WorkspaceID: uuid.UUID(testWorkspaceID.Bytes).String(),
+	})
+
+	service := NewTodoService(new(MockDBRepository), nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+	_, err := service.CreateTodo(ctx, domain.TodoItem{ID: uuid.New().String(), Description: "Ship release"}, []domain.FileUpload{{Filename: "notes.txt"}})
+
+	assert.ErrorIs(t, err, domain.ErrInsufficientScope)
+}
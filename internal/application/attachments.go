@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AddAttachments stores the files and attaches them to an existing todo. The files of a shared todo are kept with
+// those of its workspace.
+func (s *TodoService) AddAttachments(ctx context.Context, todoID string, files []domain.FileUpload) ([]domain.Attachment, error) {
+	todo, workspace, err := s.findTodo(ctx, todoID, domain.ShareLevelEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.uploadFiles(ctx, uuid.UUID(workspace.Bytes).String(), uuid.UUID(todo.ID.Bytes).String(), files)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.execTx(ctx, func(q db.Querier) error {
+		return s.saveAttachments(ctx, q, attachments, true)
+	})
+	if err != nil {
+		s.compensateUploads(ctx, attachments)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// ListAttachments returns the files attached to the todo, oldest first.
+func (s *TodoService) ListAttachments(ctx context.Context, todoID string) ([]domain.Attachment, error) {
+	todo, err := s.GetTodo(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	return todo.Attachments, nil
+}
+
+// RemoveAttachment detaches the file from the todo and deletes it from the storage, unless the attachments of
+// other occurrences of a recurring todo still refer to it.
+func (s *TodoService) RemoveAttachment(ctx context.Context, todoID, attachmentID string) error {
+	attachmentUUID, err := uuid.Parse(attachmentID)
+	if err != nil {
+		return domain.ErrAttachmentNotFound
+	}
+
+	todo, err := s.getTodo(ctx, todoID, domain.ShareLevelEdit)
+	if err != nil {
+		return err
+	}
+
+	var removed domain.Attachment
+	var shared bool
+	err = s.execTx(ctx, func(q db.Querier) error {
+		row, err := q.DeleteAttachment(ctx, db.DeleteAttachmentParams{
+			TodoID: pgtype.UUID{Bytes: uuid.MustParse(todo.ID), Valid: true},
+			ID:     pgtype.UUID{Bytes: attachmentUUID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrAttachmentNotFound
+			}
+			return databaseError(fmt.Errorf("failed to delete attachment in repository: %w", err))
+		}
+		removed = toDomainAttachment(row)
+
+		referenced, err := q.ListReferencedStorageKeys(ctx, []string{removed.StorageKey})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to look up attachment references in repository: %w", err))
+		}
+		shared = len(referenced) > 0
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoAttachmentRemoved, domain.TodoItemAttachmentEvent{
+			Type:         domain.EventTypeTodoAttachmentRemoved,
+			ID:           todo.ID,
+			AttachmentID: removed.ID,
+			Filename:     removed.Filename,
+			ContentType:  removed.ContentType,
+			Size:         removed.Size,
+			ChangedAt:    time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
+
+	// the row is gone already, a file left behind is picked up by ReconcileFiles
+	if err := s.fileStorage.Delete(ctx, removed.StorageKey); err != nil {
+		s.logger.Error("failed to delete removed attachment", "error", err, "key", removed.StorageKey)
+	}
+	return nil
+}
+
+// OpenAttachment opens the file attached to the todo for streaming.
+func (s *TodoService) OpenAttachment(ctx context.Context, todoID, attachmentID string) (*domain.FileDownload, error) {
+	attachment, err := s.getAttachment(ctx, todoID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.fileStorage.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, outbound.ErrFileNotFound) {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		return nil, domain.Unavailable(dependencyFileStorage, fmt.Errorf("failed to open attachment: %w", err))
+	}
+
+	return &domain.FileDownload{
+		Filename:    attachment.Filename,
+		Content:     content.Body,
+		ContentType: attachment.ContentType,
+		Size:        content.Size,
+	}, nil
+}
+
+// AttachmentURL returns a time-limited link to download the file attached to the todo.
+func (s *TodoService) AttachmentURL(ctx context.Context, todoID, attachmentID string) (string, error) {
+	attachment, err := s.getAttachment(ctx, todoID, attachmentID)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := s.fileStorage.PresignDownload(ctx, attachment.StorageKey)
+	if err != nil {
+		return "", domain.Unavailable(dependencyFileStorage, fmt.Errorf("failed to presign attachment download: %w", err))
+	}
+	return url, nil
+}
+
+// getAttachment looks up the attachment, making sure it belongs to a todo that isn't deleted.
+func (s *TodoService) getAttachment(ctx context.Context, todoID, attachmentID string) (domain.Attachment, error) {
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelRead)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	attachmentUUID, err := uuid.Parse(attachmentID)
+	if err != nil {
+		return domain.Attachment{}, domain.ErrAttachmentNotFound
+	}
+
+	row, err := s.todoRepository.GetAttachment(ctx, db.GetAttachmentParams{
+		TodoID: id,
+		ID:     pgtype.UUID{Bytes: attachmentUUID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Attachment{}, domain.ErrAttachmentNotFound
+		}
+		return domain.Attachment{}, databaseError(fmt.Errorf("failed to get attachment from repository: %w", err))
+	}
+	return toDomainAttachment(row), nil
+}
+
+// saveAttachments records uploaded files as part of the caller's transaction. With publish set an
+// attachment_added event is queued for each of them, a new todo announces its files in the created event instead.
+func (s *TodoService) saveAttachments(ctx context.Context, q db.Querier, attachments []domain.Attachment, publish bool) error {
+	for _, attachment := range attachments {
+		err := q.CreateAttachment(ctx, db.CreateAttachmentParams{
+			ID:          pgtype.UUID{Bytes: uuid.MustParse(attachment.ID), Valid: true},
+			TodoID:      pgtype.UUID{Bytes: uuid.MustParse(attachment.TodoID), Valid: true},
+			StorageKey:  attachment.StorageKey,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Checksum:    attachment.Checksum,
+			UploadedAt:  pgtype.Timestamp{Time: attachment.UploadedAt, Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to save attachment to repository: %w", err))
+		}
+
+		if !publish {
+			continue
+		}
+		err = s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoAttachmentAdded, domain.TodoItemAttachmentEvent{
+			Type:         domain.EventTypeTodoAttachmentAdded,
+			ID:           attachment.TodoID,
+			AttachmentID: attachment.ID,
+			Filename:     attachment.Filename,
+			ContentType:  attachment.ContentType,
+			Size:         attachment.Size,
+			ChangedAt:    attachment.UploadedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachmentKey is the storage key of an uploaded file, the attachment ID is its last segment. Attachments carried
+// over to the next occurrence of a recurring todo keep the key of the file they refer to.
+func attachmentKey(workspaceID, todoID, attachmentID string) string {
+	return fileKeyPrefix(workspaceID, todoID) + attachmentID
+}
+
+func toDomainAttachment(row db.Attachment) domain.Attachment {
+	return domain.Attachment{
+		ID:          uuid.UUID(row.ID.Bytes).String(),
+		TodoID:      uuid.UUID(row.TodoID.Bytes).String(),
+		StorageKey:  row.StorageKey,
+		Filename:    row.Filename,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		Checksum:    row.Checksum,
+		UploadedAt:  row.UploadedAt.Time,
+	}
+}
@@ -0,0 +1,372 @@
+package application
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddAttachments(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	stored := db.TodoItem{ID: pgID, Description: "Test todo"}
+	fileData := []byte("test file content")
+
+	tests := []struct {
+		name          string
+		fileData      [][]byte
+		setupMocks    func(*MockDBRepository, *MockFileStorage)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:     "attaches the files",
+			fileData: [][]byte{fileData},
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				fs.On("Upload", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, testFileKeyPrefix(todoUUID.String()))
+				}), fileData, "text/plain").Return("file-key", nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.MatchedBy(func(params db.CreateAttachmentParams) bool {
+					return params.TodoID == pgID && params.StorageKey == "file-key" && params.Filename == "file-0.txt"
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoAttachmentAdded && strings.Contains(string(params.Payload), `"filename":"file-0.txt"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "todo not found",
+			fileData: [][]byte{fileData},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+				expectNotShared(mockDB)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name:     "database error removes the uploaded files",
+			fileData: [][]byte{fileData},
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("file-key", nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.Anything).Return(errors.New("db error"))
+				fs.On("Delete", mock.Anything, "file-key").Return(nil)
+			},
+			expectedError: "failed to save attachment to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			attachments, err := service.AddAttachments(ownerContext(), todoUUID.String(), newFileUploads(tt.fileData...))
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Len(t, attachments, len(tt.fileData))
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveAttachment(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	attachmentUUID := uuid.New()
+	stored := db.TodoItem{ID: pgID, Description: "Test todo"}
+	row := db.Attachment{
+		ID:         pgtype.UUID{Bytes: attachmentUUID, Valid: true},
+		TodoID:     pgID,
+		StorageKey: testFileKeyPrefix(todoUUID.String()) + attachmentUUID.String(),
+		Filename:   "notes.txt",
+	}
+
+	tests := []struct {
+		name          string
+		attachmentID  string
+		setupMocks    func(*MockDBRepository, *MockFileStorage)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:         "removes the attachment and its file",
+			attachmentID: attachmentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{row}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("DeleteAttachment", mock.Anything, db.DeleteAttachmentParams{TodoID: pgID, ID: row.ID}).Return(row, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, []string{row.StorageKey}).Return([]string{}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoAttachmentRemoved
+				})).Return(nil)
+				fs.On("Delete", mock.Anything, row.StorageKey).Return(nil)
+			},
+		},
+		{
+			name:         "keeps a file shared with another occurrence",
+			attachmentID: attachmentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{row}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("DeleteAttachment", mock.Anything, mock.Anything).Return(row, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, []string{row.StorageKey}).Return([]string{row.StorageKey}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:         "storage failure is only logged",
+			attachmentID: attachmentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{row}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("DeleteAttachment", mock.Anything, mock.Anything).Return(row, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, mock.Anything).Return([]string{}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+				fs.On("Delete", mock.Anything, row.StorageKey).Return(errors.New("s3 error"))
+			},
+		},
+		{
+			name:         "attachment of another todo",
+			attachmentID: uuid.New().String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{row}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("DeleteAttachment", mock.Anything, mock.Anything).Return(db.Attachment{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrAttachmentNotFound,
+		},
+		{
+			name:          "invalid attachment ID",
+			attachmentID:  "not-a-uuid",
+			setupMocks:    func(_ *MockDBRepository, _ *MockFileStorage) {},
+			expectedErrIs: domain.ErrAttachmentNotFound,
+		},
+		{
+			name:         "database error",
+			attachmentID: attachmentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{row}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("DeleteAttachment", mock.Anything, mock.Anything).Return(db.Attachment{}, errors.New("db error"))
+			},
+			expectedError: "failed to delete attachment in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.RemoveAttachment(ownerContext(), todoUUID.String(), tt.attachmentID)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOpenAttachment(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	attachmentUUID := uuid.New()
+	attachmentParams := db.GetAttachmentParams{TodoID: pgID, ID: pgtype.UUID{Bytes: attachmentUUID, Valid: true}}
+	fileKey := testFileKeyPrefix(todoUUID.String()) + attachmentUUID.String()
+	stored := db.TodoItem{ID: pgID}
+	row := db.Attachment{
+		ID:          attachmentParams.ID,
+		TodoID:      pgID,
+		StorageKey:  fileKey,
+		Filename:    "notes.txt",
+		ContentType: "text/plain",
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockDBRepository, *MockFileStorage)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "opens the attached file",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("GetAttachment", mock.Anything, attachmentParams).Return(row, nil)
+				fs.On("Open", mock.Anything, fileKey).Return(&outbound.FileContent{
+					Body:        io.NopCloser(strings.NewReader("content")),
+					ContentType: "binary/octet-stream",
+					Size:        7,
+				}, nil)
+			},
+		},
+		{
+			name: "attachment of another todo",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("GetAttachment", mock.Anything, attachmentParams).Return(db.Attachment{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrAttachmentNotFound,
+		},
+		{
+			name: "todo not found",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+				expectNotShared(mockDB)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name: "file missing from the storage",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("GetAttachment", mock.Anything, attachmentParams).Return(row, nil)
+				fs.On("Open", mock.Anything, fileKey).Return(nil, outbound.ErrFileNotFound)
+			},
+			expectedErrIs: domain.ErrAttachmentNotFound,
+		},
+		{
+			name: "storage error",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("GetAttachment", mock.Anything, attachmentParams).Return(row, nil)
+				fs.On("Open", mock.Anything, fileKey).Return(nil, errors.New("s3 error"))
+			},
+			expectedError: "failed to open attachment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			file, err := service.OpenAttachment(ownerContext(), todoUUID.String(), attachmentUUID.String())
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, "notes.txt", file.Filename)
+				assert.Equal(t, "text/plain", file.ContentType)
+				assert.Equal(t, int64(7), file.Size)
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentURL(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	attachmentUUID := uuid.New()
+	fileKey := testFileKeyPrefix(todoUUID.String()) + attachmentUUID.String()
+	row := db.Attachment{ID: pgtype.UUID{Bytes: attachmentUUID, Valid: true}, TodoID: pgID, StorageKey: fileKey}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockDBRepository, *MockFileStorage)
+		expectedURL   string
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "presigns the attached file",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetAttachment", mock.Anything, mock.Anything).Return(row, nil)
+				fs.On("PresignDownload", mock.Anything, fileKey).Return("https://example.com/signed", nil)
+			},
+			expectedURL: "https://example.com/signed",
+		},
+		{
+			name: "todo without the attachment",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("GetAttachment", mock.Anything, mock.Anything).Return(db.Attachment{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrAttachmentNotFound,
+		},
+		{
+			name: "presign error",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("GetAttachment", mock.Anything, mock.Anything).Return(row, nil)
+				fs.On("PresignDownload", mock.Anything, fileKey).Return("", errors.New("s3 error"))
+			},
+			expectedError: "failed to presign attachment download",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			url, err := service.AttachmentURL(ownerContext(), todoUUID.String(), attachmentUUID.String())
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedURL, url)
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
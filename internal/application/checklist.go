@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AddChecklistItem adds an unchecked item to the end of the todo's checklist.
+func (s *TodoService) AddChecklistItem(ctx context.Context, todoID string, item domain.ChecklistItem) (domain.ChecklistItem, error) {
+	if err := item.Validate(); err != nil {
+		return domain.ChecklistItem{}, fmt.Errorf("checklist item validation failed: %w", err)
+	}
+
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelEdit)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	now := time.Now().UTC()
+	item.ID = uuid.New().String()
+	item.TodoID = uuid.UUID(id.Bytes).String()
+	item.Done = false
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	err = s.execTx(ctx, func(q db.Querier) error {
+		count, err := q.CountChecklistItems(ctx, id)
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to count checklist items in repository: %w", err))
+		}
+		if count >= domain.MaxChecklistItems {
+			return domain.ErrChecklistFull
+		}
+
+		err = q.CreateChecklistItem(ctx, db.CreateChecklistItemParams{
+			ID:        pgtype.UUID{Bytes: uuid.MustParse(item.ID), Valid: true},
+			TodoID:    id,
+			Text:      item.Text,
+			CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to save checklist item to repository: %w", err))
+		}
+		return s.enqueueChecklistEvent(ctx, q, domain.EventTypeTodoChecklistItemAdded, item)
+	})
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	return item, nil
+}
+
+// UpdateChecklistItem changes the text of an item of the todo's checklist or ticks it off.
+func (s *TodoService) UpdateChecklistItem(ctx context.Context, todoID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error) {
+	itemUUID, err := uuid.Parse(itemID)
+	if err != nil {
+		return domain.ChecklistItem{}, domain.ErrChecklistItemNotFound
+	}
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelEdit)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	var updated domain.ChecklistItem
+	err = s.execTx(ctx, func(q db.Querier) error {
+		row, err := q.GetChecklistItem(ctx, db.GetChecklistItemParams{TodoID: id, ID: pgtype.UUID{Bytes: itemUUID, Valid: true}})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrChecklistItemNotFound
+			}
+			return databaseError(fmt.Errorf("failed to get checklist item from repository: %w", err))
+		}
+
+		item := toDomainChecklistItem(row)
+		if err := item.ApplyPatch(patch); err != nil {
+			return fmt.Errorf("checklist item validation failed: %w", err)
+		}
+
+		row, err = q.UpdateChecklistItem(ctx, db.UpdateChecklistItemParams{
+			TodoID:    id,
+			ID:        row.ID,
+			Text:      item.Text,
+			Done:      item.Done,
+			UpdatedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to update checklist item in repository: %w", err))
+		}
+		updated = toDomainChecklistItem(row)
+		return s.enqueueChecklistEvent(ctx, q, domain.EventTypeTodoChecklistItemUpdated, updated)
+	})
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	return updated, nil
+}
+
+// RemoveChecklistItem removes the item from the todo's checklist.
+func (s *TodoService) RemoveChecklistItem(ctx context.Context, todoID, itemID string) error {
+	itemUUID, err := uuid.Parse(itemID)
+	if err != nil {
+		return domain.ErrChecklistItemNotFound
+	}
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelEdit)
+	if err != nil {
+		return err
+	}
+
+	return s.execTx(ctx, func(q db.Querier) error {
+		deleted, err := q.DeleteChecklistItem(ctx, db.DeleteChecklistItemParams{TodoID: id, ID: pgtype.UUID{Bytes: itemUUID, Valid: true}})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to delete checklist item in repository: %w", err))
+		}
+		if deleted == 0 {
+			return domain.ErrChecklistItemNotFound
+		}
+
+		return s.enqueueChecklistEvent(ctx, q, domain.EventTypeTodoChecklistItemRemoved, domain.ChecklistItem{
+			ID:        itemID,
+			TodoID:    uuid.UUID(id.Bytes).String(),
+			UpdatedAt: time.Now().UTC(),
+		})
+	})
+}
+
+// existingTodoID parses the ID of a todo the request may do what needs the required share level with, making sure the
+// todo isn't deleted.
+func (s *TodoService) existingTodoID(ctx context.Context, todoID string, required domain.ShareLevel) (pgtype.UUID, error) {
+	item, _, err := s.findTodo(ctx, todoID, required)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return item.ID, nil
+}
+
+// enqueueChecklistEvent queues the event of a change to the checklist item as part of the caller's transaction.
+func (s *TodoService) enqueueChecklistEvent(ctx context.Context, q db.Querier, eventType string, item domain.ChecklistItem) error {
+	return s.enqueueTodoEvent(ctx, q, eventType, domain.TodoItemChecklistEvent{
+		Type:      eventType,
+		ID:        item.TodoID,
+		ItemID:    item.ID,
+		Text:      item.Text,
+		Done:      item.Done,
+		ChangedAt: item.UpdatedAt,
+	})
+}
+
+func toDomainChecklistItem(row db.ChecklistItem) domain.ChecklistItem {
+	return domain.ChecklistItem{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		TodoID:    uuid.UUID(row.TodoID.Bytes).String(),
+		Text:      row.Text,
+		Done:      row.Done,
+		CreatedAt: row.CreatedAt.Time.UTC(),
+		UpdatedAt: row.UpdatedAt.Time.UTC(),
+	}
+}
@@ -0,0 +1,211 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddChecklistItem(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+
+	tests := []struct {
+		name          string
+		text          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "adds the item",
+			text: " Book the van ",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("CountChecklistItems", mock.Anything, pgID).Return(int64(2), nil)
+				mockDB.On("CreateChecklistItem", mock.Anything, mock.MatchedBy(func(params db.CreateChecklistItemParams) bool {
+					return params.TodoID == pgID && params.Text == "Book the van" && params.ID.Valid
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoChecklistItemAdded
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "empty text",
+			text:          "  ",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name: "todo not found",
+			text: "Book the van",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+				expectNotShared(mockDB)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name: "checklist full",
+			text: "Book the van",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("CountChecklistItems", mock.Anything, pgID).Return(int64(domain.MaxChecklistItems), nil)
+			},
+			expectedErrIs: domain.ErrChecklistFull,
+		},
+		{
+			name: "database error",
+			text: "Book the van",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("CountChecklistItems", mock.Anything, pgID).Return(int64(0), nil)
+				mockDB.On("CreateChecklistItem", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: "failed to save checklist item to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			item, err := service.AddChecklistItem(ownerContext(), todoUUID.String(), domain.ChecklistItem{Text: tt.text})
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, item.ID)
+				assert.Equal(t, todoUUID.String(), item.TodoID)
+				assert.Equal(t, "Book the van", item.Text)
+				assert.False(t, item.Done)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateChecklistItem(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	itemUUID := uuid.New()
+	itemID := pgtype.UUID{Bytes: itemUUID, Valid: true}
+	stored := db.ChecklistItem{ID: itemID, TodoID: pgID, Text: "Book the van"}
+	done := true
+
+	tests := []struct {
+		name          string
+		itemID        string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:   "ticks the item off",
+			itemID: itemUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("GetChecklistItem", mock.Anything, db.GetChecklistItemParams{TodoID: pgID, ID: itemID}).Return(stored, nil)
+				mockDB.On("UpdateChecklistItem", mock.Anything, mock.MatchedBy(func(params db.UpdateChecklistItemParams) bool {
+					return params.ID == itemID && params.Text == "Book the van" && params.Done
+				})).Return(db.ChecklistItem{ID: itemID, TodoID: pgID, Text: "Book the van", Done: true}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoChecklistItemUpdated
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "invalid item ID",
+			itemID:        "not-a-uuid",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrChecklistItemNotFound,
+		},
+		{
+			name:   "item of another todo",
+			itemID: itemUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("GetChecklistItem", mock.Anything, mock.Anything).Return(db.ChecklistItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrChecklistItemNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			item, err := service.UpdateChecklistItem(ownerContext(), todoUUID.String(), tt.itemID, domain.ChecklistItemPatch{Done: &done})
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, item.Done)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveChecklistItem(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	itemUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		deleted       int64
+		setupEvent    bool
+		expectedErrIs error
+	}{
+		{name: "removes the item", deleted: 1, setupEvent: true},
+		{name: "item not found", deleted: 0, expectedErrIs: domain.ErrChecklistItemNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			mockDB.On("DeleteChecklistItem", mock.Anything, db.DeleteChecklistItemParams{
+				TodoID: pgID,
+				ID:     pgtype.UUID{Bytes: itemUUID, Valid: true},
+			}).Return(tt.deleted, nil)
+			if tt.setupEvent {
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoChecklistItemRemoved
+				})).Return(nil)
+			}
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.RemoveChecklistItem(ownerContext(), todoUUID.String(), itemUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
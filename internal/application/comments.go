@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AddComment leaves a comment on the todo on behalf of the request's user. Besides members of the todo's workspace,
+// users it is shared with for commenting or editing can comment on it.
+func (s *TodoService) AddComment(ctx context.Context, todoID string, comment domain.Comment) (domain.Comment, error) {
+	if err := comment.Validate(); err != nil {
+		return domain.Comment{}, fmt.Errorf("comment validation failed: %w", err)
+	}
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelComment)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	author, err := ownerID(ctx)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment.ID = uuid.New().String()
+	comment.TodoID = uuid.UUID(id.Bytes).String()
+	comment.AuthorID = uuid.UUID(author.Bytes).String()
+	comment.CreatedAt = time.Now().UTC()
+
+	err = s.execTx(ctx, func(q db.Querier) error {
+		err := q.CreateComment(ctx, db.CreateCommentParams{
+			ID:        pgtype.UUID{Bytes: uuid.MustParse(comment.ID), Valid: true},
+			TodoID:    id,
+			AuthorID:  author,
+			Text:      comment.Text,
+			CreatedAt: pgtype.Timestamptz{Time: comment.CreatedAt, Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to save comment to repository: %w", err))
+		}
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoCommentAdded, domain.TodoItemCommentEvent{
+			Type:      domain.EventTypeTodoCommentAdded,
+			ID:        comment.TodoID,
+			CommentID: comment.ID,
+			AuthorID:  comment.AuthorID,
+			Text:      comment.Text,
+			CreatedAt: comment.CreatedAt,
+		})
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return comment, nil
+}
+
+// ListComments returns the comments on the todo, oldest first. Everyone who can read the todo can read them.
+func (s *TodoService) ListComments(ctx context.Context, todoID string) ([]domain.Comment, error) {
+	id, err := s.existingTodoID(ctx, todoID, domain.ShareLevelRead)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.todoRepository.ListComments(ctx, id)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list comments from repository: %w", err))
+	}
+	comments := make([]domain.Comment, 0, len(rows))
+	for _, row := range rows {
+		comments = append(comments, toDomainComment(row))
+	}
+	return comments, nil
+}
+
+func toDomainComment(row db.Comment) domain.Comment {
+	comment := domain.Comment{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		TodoID:    uuid.UUID(row.TodoID.Bytes).String(),
+		Text:      row.Text,
+		CreatedAt: row.CreatedAt.Time.UTC(),
+	}
+	if row.AuthorID.Valid {
+		comment.AuthorID = uuid.UUID(row.AuthorID.Bytes).String()
+	}
+	return comment
+}
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddComment(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	otherWorkspaceID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	setupShare := func(mockDB *MockDBRepository, level string) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+		mockDB.On("GetTodoShareLevels", mock.Anything, db.GetTodoShareLevelsParams{ID: pgID, UserID: testOwnerID}).Return(db.GetTodoShareLevelsRow{
+			WorkspaceID: otherWorkspaceID,
+			Levels:      []string{level},
+		}, nil)
+	}
+	expectComment := func(mockDB *MockDBRepository) {
+		mockDB.On("CreateComment", mock.Anything, mock.MatchedBy(func(params db.CreateCommentParams) bool {
+			return params.TodoID == pgID && params.AuthorID == testOwnerID && params.Text == "Booked the hotel"
+		})).Return(nil)
+		mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+			return params.EventType == domain.EventTypeTodoCommentAdded
+		})).Return(nil)
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "member comments",
+			text: " Booked the hotel ",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				expectComment(mockDB)
+			},
+		},
+		{
+			name: "comment share comments",
+			text: "Booked the hotel",
+			setupMock: func(mockDB *MockDBRepository) {
+				setupShare(mockDB, "comment")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				expectComment(mockDB)
+			},
+		},
+		{
+			name: "read share can't comment",
+			text: "Booked the hotel",
+			setupMock: func(mockDB *MockDBRepository) {
+				setupShare(mockDB, "read")
+			},
+			expectedErrIs: domain.ErrTodoAccessDenied,
+		},
+		{
+			name:          "blank text",
+			text:          "  ",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			comment, err := service.AddComment(ownerContext(), todoUUID.String(), domain.Comment{Text: tt.text})
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Booked the hotel", comment.Text)
+				assert.Equal(t, todoUUID.String(), comment.TodoID)
+				assert.Equal(t, uuid.UUID(testOwnerID.Bytes).String(), comment.AuthorID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
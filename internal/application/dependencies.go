@@ -0,0 +1,209 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxGraphTodos bounds the number of todos a dependency graph is requested for.
+const maxGraphTodos = 100
+
+var (
+	errBlockerNotFound = domain.NewValidationError("blockerId", "the blocking todo was not found")
+	errOwnBlocker      = domain.NewValidationError("blockerId", "a todo can't be blocked by itself")
+	errGraphTodoIDs    = domain.NewValidationError("id", fmt.Sprintf("between 1 and %d valid todo IDs are required", maxGraphTodos))
+)
+
+// AddDependency makes the todo wait for the blocker, it can't be completed before the blocker is done or cancelled.
+// A dependency that would make todos wait for each other fails with domain.ErrDependencyCycle. Adding an existing
+// dependency again changes nothing.
+func (s *TodoService) AddDependency(ctx context.Context, todoID, blockerID string) (domain.TodoDependency, error) {
+	id, err := s.existingTodoID(ctx, todoID, membersOnly)
+	if err != nil {
+		return domain.TodoDependency{}, err
+	}
+	blockerUUID, err := uuid.Parse(blockerID)
+	if err != nil {
+		return domain.TodoDependency{}, errBlockerNotFound
+	}
+	blocker := pgtype.UUID{Bytes: blockerUUID, Valid: true}
+	if blocker == id {
+		return domain.TodoDependency{}, errOwnBlocker
+	}
+
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoDependency{}, err
+	}
+
+	dependency := domain.TodoDependency{TodoID: uuid.UUID(id.Bytes).String(), BlockerID: blockerUUID.String()}
+	now := time.Now().UTC()
+	err = s.execTx(ctx, func(q db.Querier) error {
+		if err := q.LockTodoDependencies(ctx); err != nil {
+			return databaseError(fmt.Errorf("failed to lock todo dependencies: %w", err))
+		}
+		// a todo can only wait for todos of the same workspace
+		if _, err := q.GetTodo(ctx, db.GetTodoParams{ID: blocker, WorkspaceID: workspace}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errBlockerNotFound
+			}
+			return databaseError(fmt.Errorf("failed to get blocking todo from repository: %w", err))
+		}
+
+		// the new dependency closes a cycle when the blocker already waits for the todo
+		cycle, err := q.IsTodoBlockedBy(ctx, db.IsTodoBlockedByParams{TodoID: blocker, BlockerID: id})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to look up todo dependencies in repository: %w", err))
+		}
+		if cycle {
+			return domain.ErrDependencyCycle
+		}
+
+		added, err := q.AddTodoDependency(ctx, db.AddTodoDependencyParams{
+			TodoID:    id,
+			BlockerID: blocker,
+			CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to save todo dependency to repository: %w", err))
+		}
+		if added == 0 {
+			return nil
+		}
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoDependencyAdded, domain.TodoItemDependencyEvent{
+			Type:      domain.EventTypeTodoDependencyAdded,
+			ID:        dependency.TodoID,
+			BlockerID: dependency.BlockerID,
+			ChangedAt: now,
+		})
+	})
+	if err != nil {
+		return domain.TodoDependency{}, err
+	}
+	return dependency, nil
+}
+
+// RemoveDependency stops the todo from waiting for the blocker.
+func (s *TodoService) RemoveDependency(ctx context.Context, todoID, blockerID string) error {
+	id, err := s.existingTodoID(ctx, todoID, membersOnly)
+	if err != nil {
+		return err
+	}
+	blockerUUID, err := uuid.Parse(blockerID)
+	if err != nil {
+		return domain.ErrTodoDependencyNotFound
+	}
+
+	return s.execTx(ctx, func(q db.Querier) error {
+		removed, err := q.RemoveTodoDependency(ctx, db.RemoveTodoDependencyParams{
+			TodoID:    id,
+			BlockerID: pgtype.UUID{Bytes: blockerUUID, Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to delete todo dependency in repository: %w", err))
+		}
+		if removed == 0 {
+			return domain.ErrTodoDependencyNotFound
+		}
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoDependencyRemoved, domain.TodoItemDependencyEvent{
+			Type:      domain.EventTypeTodoDependencyRemoved,
+			ID:        uuid.UUID(id.Bytes).String(),
+			BlockerID: blockerUUID.String(),
+			ChangedAt: time.Now().UTC(),
+		})
+	})
+}
+
+// GetDependencyGraph returns the todos together with their blockers at every depth, the dependencies between all of
+// them and the order in which they can be completed. Trashed blockers are left out.
+func (s *TodoService) GetDependencyGraph(ctx context.Context, todoIDs []string) (domain.DependencyGraph, []string, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.DependencyGraph{}, nil, err
+	}
+	if len(todoIDs) == 0 || len(todoIDs) > maxGraphTodos {
+		return domain.DependencyGraph{}, nil, errGraphTodoIDs
+	}
+	requested := make(map[[16]byte]bool, len(todoIDs))
+	ids := make([]pgtype.UUID, 0, len(todoIDs))
+	for _, todoID := range todoIDs {
+		todoUUID, err := uuid.Parse(todoID)
+		if err != nil {
+			return domain.DependencyGraph{}, nil, errGraphTodoIDs
+		}
+		if !requested[todoUUID] {
+			requested[todoUUID] = true
+			ids = append(ids, pgtype.UUID{Bytes: todoUUID, Valid: true})
+		}
+	}
+
+	rows, err := s.todoRepository.ListDependencyGraph(ctx, ids)
+	if err != nil {
+		return domain.DependencyGraph{}, nil, databaseError(fmt.Errorf("failed to list todo dependencies from repository: %w", err))
+	}
+	var graph domain.DependencyGraph
+	nodes := ids
+	for _, row := range rows {
+		graph.Dependencies = append(graph.Dependencies, domain.TodoDependency{
+			TodoID:    uuid.UUID(row.TodoID.Bytes).String(),
+			BlockerID: uuid.UUID(row.BlockerID.Bytes).String(),
+		})
+		if !requested[row.BlockerID.Bytes] {
+			requested[row.BlockerID.Bytes] = true
+			nodes = append(nodes, row.BlockerID)
+		}
+	}
+
+	items, err := s.todoRepository.ListTodosByIDs(ctx, db.ListTodosByIDsParams{Ids: nodes, WorkspaceID: workspace})
+	if err != nil {
+		return domain.DependencyGraph{}, nil, databaseError(fmt.Errorf("failed to list todos from repository: %w", err))
+	}
+	if len(items) < len(nodes) { // one of the requested todos doesn't exist, is in the trash or belongs to another workspace
+		return domain.DependencyGraph{}, nil, domain.ErrTodoNotFound
+	}
+	attachmentRows, err := s.todoRepository.ListAttachmentsByTodoIDs(ctx, nodes)
+	if err != nil {
+		return domain.DependencyGraph{}, nil, databaseError(fmt.Errorf("failed to list attachments from repository: %w", err))
+	}
+	attachments := make(map[[16]byte][]domain.Attachment, len(items))
+	for _, row := range attachmentRows {
+		attachments[row.TodoID.Bytes] = append(attachments[row.TodoID.Bytes], toDomainAttachment(row))
+	}
+	tags, err := listTodoTags(ctx, s.todoRepository, nodes)
+	if err != nil {
+		return domain.DependencyGraph{}, nil, err
+	}
+	for _, item := range items {
+		todo := toDomainTodo(item)
+		todo.Attachments = attachments[item.ID.Bytes]
+		todo.Tags = tags[item.ID.Bytes]
+		graph.Todos = append(graph.Todos, todo)
+	}
+
+	order, err := graph.TopologicalOrder()
+	if err != nil {
+		return domain.DependencyGraph{}, nil, err
+	}
+	return graph, order, nil
+}
+
+// checkBlockers makes sure none of the todo's blockers is still open before it is completed.
+func checkBlockers(ctx context.Context, q db.Querier, todoID pgtype.UUID) error {
+	blockers, err := q.ListOpenBlockerIDs(ctx, todoID)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to list blocking todos from repository: %w", err))
+	}
+	if len(blockers) > 0 {
+		return fmt.Errorf("%w: todo %s waits for %d open todos", domain.ErrOpenBlockers, uuid.UUID(todoID.Bytes), len(blockers))
+	}
+	return nil
+}
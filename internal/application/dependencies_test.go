@@ -0,0 +1,253 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddDependency(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	blockerUUID := uuid.New()
+	blockerID := pgtype.UUID{Bytes: blockerUUID, Valid: true}
+
+	setupTodos := func(mockDB *MockDBRepository) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+		mockDB.On("LockTodoDependencies", mock.Anything).Return(nil)
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: blockerID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: blockerID}, nil)
+	}
+
+	tests := []struct {
+		name          string
+		blockerID     string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:      "adds the dependency",
+			blockerID: blockerUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodos(mockDB)
+				mockDB.On("IsTodoBlockedBy", mock.Anything, db.IsTodoBlockedByParams{TodoID: blockerID, BlockerID: pgID}).Return(false, nil)
+				mockDB.On("AddTodoDependency", mock.Anything, mock.MatchedBy(func(params db.AddTodoDependencyParams) bool {
+					return params.TodoID == pgID && params.BlockerID == blockerID
+				})).Return(int64(1), nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoDependencyAdded
+				})).Return(nil)
+			},
+		},
+		{
+			name:      "added before",
+			blockerID: blockerUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodos(mockDB)
+				mockDB.On("IsTodoBlockedBy", mock.Anything, mock.Anything).Return(false, nil)
+				mockDB.On("AddTodoDependency", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+		},
+		{
+			name:      "blocked by itself",
+			blockerID: todoUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			},
+			expectedErrIs: errOwnBlocker,
+		},
+		{
+			name:      "blocker not found",
+			blockerID: blockerUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("LockTodoDependencies", mock.Anything).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: blockerID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: errBlockerNotFound,
+		},
+		{
+			name:      "closes a cycle",
+			blockerID: blockerUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodos(mockDB)
+				mockDB.On("IsTodoBlockedBy", mock.Anything, mock.Anything).Return(true, nil)
+			},
+			expectedErrIs: domain.ErrDependencyCycle,
+		},
+		{
+			name:      "database error",
+			blockerID: blockerUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodos(mockDB)
+				mockDB.On("IsTodoBlockedBy", mock.Anything, mock.Anything).Return(false, errors.New("db error"))
+			},
+			expectedError: "failed to look up todo dependencies in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			dependency, err := service.AddDependency(ownerContext(), todoUUID.String(), tt.blockerID)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, domain.TodoDependency{TodoID: todoUUID.String(), BlockerID: blockerUUID.String()}, dependency)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	blockerUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		removed       int64
+		expectedErrIs error
+	}{
+		{name: "removes the dependency", removed: 1},
+		{name: "dependency not found", removed: 0, expectedErrIs: domain.ErrTodoDependencyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			mockDB.On("RemoveTodoDependency", mock.Anything, db.RemoveTodoDependencyParams{
+				TodoID:    pgID,
+				BlockerID: pgtype.UUID{Bytes: blockerUUID, Valid: true},
+			}).Return(tt.removed, nil)
+			if tt.expectedErrIs == nil {
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoDependencyRemoved
+				})).Return(nil)
+			}
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.RemoveDependency(ownerContext(), todoUUID.String(), blockerUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDependencyGraph(t *testing.T) {
+	moveID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	packID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	boxesID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	id := func(pgID pgtype.UUID) string { return uuid.UUID(pgID.Bytes).String() }
+
+	tests := []struct {
+		name          string
+		todoIDs       []string
+		setupMock     func(*MockDBRepository)
+		expectedOrder []string
+		expectedErrIs error
+	}{
+		{
+			name:    "blockers come first",
+			todoIDs: []string{id(moveID), id(moveID)},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListDependencyGraph", mock.Anything, []pgtype.UUID{moveID}).Return([]db.ListDependencyGraphRow{
+					{TodoID: moveID, BlockerID: packID},
+					{TodoID: packID, BlockerID: boxesID},
+				}, nil)
+				mockDB.On("ListTodosByIDs", mock.Anything, db.ListTodosByIDsParams{Ids: []pgtype.UUID{moveID, packID, boxesID}, WorkspaceID: testWorkspaceID}).Return([]db.TodoItem{
+					{ID: moveID, Description: "Move house"},
+					{ID: packID, Description: "Pack"},
+					{ID: boxesID, Description: "Buy boxes"},
+				}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+			},
+			expectedOrder: []string{id(boxesID), id(packID), id(moveID)},
+		},
+		{
+			name:    "todo not found",
+			todoIDs: []string{id(moveID)},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListDependencyGraph", mock.Anything, mock.Anything).Return([]db.ListDependencyGraphRow{}, nil)
+				mockDB.On("ListTodosByIDs", mock.Anything, mock.Anything).Return([]db.TodoItem{}, nil)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name:          "invalid todo ID",
+			todoIDs:       []string{"not-a-uuid"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:          "without todos",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			graph, order, err := service.GetDependencyGraph(ownerContext(), tt.todoIDs)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, graph.Todos, 3)
+				assert.Len(t, graph.Dependencies, 2)
+				assert.Equal(t, tt.expectedOrder, order)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompleteBlockedTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID, Status: string(domain.TodoStatusOpen), Version: 1}, nil)
+	mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+	mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+	mockDB.On("ListOpenBlockerIDs", mock.Anything, pgID).Return([]pgtype.UUID{{Bytes: uuid.New(), Valid: true}}, nil)
+
+	service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyCascade, slog.Default())
+	_, err := service.TransitionTodo(ownerContext(), todoUUID.String(), domain.TodoStatusDone, 0)
+
+	assert.ErrorIs(t, err, domain.ErrOpenBlockers)
+	mockDB.AssertExpectations(t)
+}
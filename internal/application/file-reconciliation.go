@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+)
+
+const reconcileBatchSize = 1000
+
+// FileReconciliationReport is the outcome of a ReconcileFiles run.
+type FileReconciliationReport struct {
+	Scanned int
+	Skipped int
+	Orphans []string
+	Removed int
+}
+
+// ReconcileFiles looks for stored todo files that no attachment references, for example uploads left behind by a crash
+// between the upload and the database insert. Files modified after modifiedBefore are skipped because their todo may
+// still be being saved. Orphans are only reported unless remove is true.
+func (s *TodoService) ReconcileFiles(ctx context.Context, modifiedBefore time.Time, remove bool) (FileReconciliationReport, error) {
+	var report FileReconciliationReport
+
+	var files []outbound.StoredFile
+	for _, prefix := range []string{workspaceFilesPrefix, legacyTodoFilesPrefix} {
+		stored, err := s.fileStorage.List(ctx, prefix)
+		if err != nil {
+			return report, fmt.Errorf("failed to list stored files: %w", err)
+		}
+		files = append(files, stored...)
+	}
+
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		report.Scanned++
+		if file.LastModified.After(modifiedBefore) {
+			report.Skipped++
+			continue
+		}
+		keys = append(keys, file.Key)
+	}
+
+	for start := 0; start < len(keys); start += reconcileBatchSize {
+		batch := keys[start:min(start+reconcileBatchSize, len(keys))]
+
+		referenced, err := s.todoRepository.ListReferencedStorageKeys(ctx, batch)
+		if err != nil {
+			return report, fmt.Errorf("failed to list referenced files: %w", err)
+		}
+		referencedSet := make(map[string]struct{}, len(referenced))
+		for _, key := range referenced {
+			referencedSet[key] = struct{}{}
+		}
+
+		for _, key := range batch {
+			if _, ok := referencedSet[key]; !ok {
+				report.Orphans = append(report.Orphans, key)
+			}
+		}
+	}
+
+	if !remove {
+		return report, nil
+	}
+
+	for _, key := range report.Orphans {
+		if err := s.fileStorage.Delete(ctx, key); err != nil {
+			return report, fmt.Errorf("failed to delete orphaned file %s: %w", key, err)
+		}
+		report.Removed++
+	}
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/google/uuid"
+)
+
+// sniffLength is how much of a file is looked at to detect its content type, as much as http.DetectContentType considers.
+const sniffLength = 512
+
+// UploadLimits restricts the files that can be attached to todos.
+type UploadLimits struct {
+	MaxSize      int64            // Largest accepted file in bytes
+	MaxFiles     int              // Most files accepted in a single request
+	AllowedTypes []string         // Content types accepted, detected from the file content
+	TypeMaxSizes map[string]int64 // Lower size limits for some of the allowed types
+}
+
+// maxSize is the largest accepted size for files of the content type.
+func (l UploadLimits) maxSize(contentType string) int64 {
+	if limit, ok := l.TypeMaxSizes[contentType]; ok && limit < l.MaxSize {
+		return limit
+	}
+	return l.MaxSize
+}
+
+// uploadFiles stores every file for the todo of the workspace. When one of them fails the files already stored are
+// removed again.
+func (s *TodoService) uploadFiles(ctx context.Context, workspaceID, todoID string, files []domain.FileUpload) ([]domain.Attachment, error) {
+	if len(files) > s.uploadLimits.MaxFiles {
+		return nil, fmt.Errorf("%w: %d files, the limit is %d", domain.ErrTooManyFiles, len(files), s.uploadLimits.MaxFiles)
+	}
+
+	attachments := make([]domain.Attachment, 0, len(files))
+	for i := range files {
+		attachment, err := s.uploadFile(ctx, workspaceID, todoID, &files[i])
+		if err != nil {
+			s.logger.Error("failed to upload file", "error", err, "filename", files[i].Filename)
+			s.compensateUploads(ctx, attachments)
+			return nil, fmt.Errorf("failed to upload file %q: %w", files[i].Filename, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// uploadFile streams the file to the storage under the todo's prefix and describes the stored attachment.
+// The content type is detected from the first bytes of the file, types that aren't allowed fail with
+// domain.ErrUnsupportedFileType. Files larger than the limit for their type fail with domain.ErrFileTooLarge,
+// even when their size isn't known up front.
+func (s *TodoService) uploadFile(ctx context.Context, workspaceID, todoID string, file *domain.FileUpload) (domain.Attachment, error) {
+	if file.Size > s.uploadLimits.MaxSize {
+		return domain.Attachment{}, fmt.Errorf("%w: %d bytes, the limit is %d", domain.ErrFileTooLarge, file.Size, s.uploadLimits.MaxSize)
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return domain.Attachment{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+
+	contentType := detectContentType(head)
+	if !slices.Contains(s.uploadLimits.AllowedTypes, contentType) {
+		return domain.Attachment{}, fmt.Errorf("%w: %s", domain.ErrUnsupportedFileType, contentType)
+	}
+
+	maxSize := s.uploadLimits.maxSize(contentType)
+	if file.Size > maxSize {
+		return domain.Attachment{}, fmt.Errorf("%w: %d bytes, the limit for %s is %d", domain.ErrFileTooLarge, file.Size, contentType, maxSize)
+	}
+
+	attachmentID := uuid.New().String()
+	// the sniffed bytes were consumed from the upload, put them back in front of the rest
+	body := &limitedReader{r: io.MultiReader(bytes.NewReader(head), file.Content), remaining: maxSize}
+	checksum := sha256.New()
+	key, err := s.fileStorage.Upload(ctx, attachmentKey(workspaceID, todoID, attachmentID), io.TeeReader(body, checksum), contentType)
+	if err != nil {
+		// the storage wraps read errors in its own types, so check the reader instead of the error chain
+		if body.exceeded {
+			return domain.Attachment{}, fmt.Errorf("%w: the limit for %s is %d bytes", domain.ErrFileTooLarge, contentType, maxSize)
+		}
+		return domain.Attachment{}, domain.Unavailable(dependencyFileStorage, err)
+	}
+
+	return domain.Attachment{
+		ID:          attachmentID,
+		TodoID:      todoID,
+		StorageKey:  key,
+		Filename:    file.Filename,
+		ContentType: contentType,
+		Size:        maxSize - body.remaining,
+		Checksum:    hex.EncodeToString(checksum.Sum(nil)),
+		UploadedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// detectContentType sniffs the content type from the magic numbers at the start of the file, without parameters
+// such as the charset.
+func detectContentType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// limitedReader fails with domain.ErrFileTooLarge once more than remaining bytes are read,
+// unlike io.LimitReader which silently truncates.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, domain.ErrFileTooLarge
+	}
+	// read one byte past the limit to tell an exact fit apart from a larger file
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		l.exceeded = true
+		return 0, domain.ErrFileTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
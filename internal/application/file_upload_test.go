@@ -0,0 +1,173 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUploadFile(t *testing.T) {
+	limits := UploadLimits{
+		MaxSize:      8,
+		AllowedTypes: []string{"text/plain", "image/png"},
+		TypeMaxSizes: map[string]int64{"text/plain": 6},
+	}
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	tests := []struct {
+		name          string
+		file          *domain.FileUpload
+		setupMocks    func(*MockFileStorage)
+		expectedKey   string
+		expectedSize  int64
+		expectedType  string
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "known size within the limit",
+			file: &domain.FileUpload{Content: bytes.NewReader(png), Size: 8},
+			setupMocks: func(fs *MockFileStorage) {
+				fs.On("Upload", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, fileKeyPrefix("workspace-id", "todo-id"))
+				}), png, "image/png").Return("todos/todo-id/file", nil)
+			},
+			expectedKey:  "todos/todo-id/file",
+			expectedSize: 8,
+			expectedType: "image/png",
+		},
+		{
+			name: "unknown size within the limit",
+			file: &domain.FileUpload{Content: strings.NewReader("1234"), Size: -1},
+			setupMocks: func(fs *MockFileStorage) {
+				fs.On("Upload", mock.Anything, mock.Anything, []byte("1234"), "text/plain").Return("todos/todo-id/file", nil)
+			},
+			expectedKey:  "todos/todo-id/file",
+			expectedSize: 4,
+			expectedType: "text/plain",
+		},
+		{
+			name:          "type that isn't allowed",
+			file:          &domain.FileUpload{Filename: "report.txt", Content: strings.NewReader("%PDF-1.4"), Size: 8},
+			setupMocks:    func(_ *MockFileStorage) {},
+			expectedErrIs: domain.ErrUnsupportedFileType,
+		},
+		{
+			name:          "known size over the limit of its type",
+			file:          &domain.FileUpload{Content: strings.NewReader("1234567"), Size: 7},
+			setupMocks:    func(_ *MockFileStorage) {},
+			expectedErrIs: domain.ErrFileTooLarge,
+		},
+		{
+			name:          "unknown size over the limit of its type",
+			file:          &domain.FileUpload{Content: strings.NewReader("1234567"), Size: -1},
+			setupMocks:    func(_ *MockFileStorage) {},
+			expectedErrIs: domain.ErrFileTooLarge,
+		},
+		{
+			name:          "known size over the limit is rejected before uploading",
+			file:          &domain.FileUpload{Content: strings.NewReader("123456789"), Size: 9},
+			setupMocks:    func(_ *MockFileStorage) {},
+			expectedErrIs: domain.ErrFileTooLarge,
+		},
+		{
+			name:          "unknown size over the limit is stopped while streaming",
+			file:          &domain.FileUpload{Content: strings.NewReader("123456789"), Size: -1},
+			setupMocks:    func(_ *MockFileStorage) {},
+			expectedErrIs: domain.ErrFileTooLarge,
+		},
+		{
+			name: "storage error",
+			file: &domain.FileUpload{Content: strings.NewReader("1234"), Size: 4},
+			setupMocks: func(fs *MockFileStorage) {
+				fs.On("Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("s3 error"))
+			},
+			expectedError: "s3 error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockFS)
+
+			service := NewTodoService(nil, mockFS, nil, limits, domain.CompletionPolicyBlock, slog.Default())
+			attachment, err := service.uploadFile(context.Background(), "workspace-id", "todo-id", tt.file)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedKey, attachment.StorageKey)
+				assert.Equal(t, tt.expectedSize, attachment.Size)
+				assert.Equal(t, "todo-id", attachment.TodoID)
+				assert.Equal(t, tt.expectedType, attachment.ContentType)
+				assert.Len(t, attachment.Checksum, 64)
+			}
+
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		limit         int64
+		expectedError error
+	}{
+		{name: "shorter than the limit", data: []byte("abc"), limit: 4},
+		{name: "exactly the limit", data: []byte("abcd"), limit: 4},
+		{name: "longer than the limit", data: []byte("abcde"), limit: 4, expectedError: domain.ErrFileTooLarge},
+		{name: "empty", data: []byte{}, limit: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &limitedReader{r: bytes.NewReader(tt.data), remaining: tt.limit}
+			got, err := io.ReadAll(reader)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.True(t, reader.exceeded)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, got)
+		})
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     []byte
+		expected string
+	}{
+		{name: "plain text without charset", head: []byte("hello world"), expected: "text/plain"},
+		{name: "png", head: []byte("\x89PNG\r\n\x1a\n\x00\x00"), expected: "image/png"},
+		{name: "jpeg", head: []byte("\xff\xd8\xff\xe0"), expected: "image/jpeg"},
+		{name: "pdf", head: []byte("%PDF-1.7"), expected: "application/pdf"},
+		{name: "windows executable", head: []byte("MZ\x90\x00\x03\x00\x00\x00"), expected: "application/octet-stream"},
+		{name: "empty", head: []byte{}, expected: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detectContentType(tt.head))
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errInvalidDefaultSort = domain.NewValidationError("defaultSort", "default sort must be createdAt, dueDate or priority")
+	errListNotFound       = domain.NewValidationError("listId", "the list was not found")
+	errListArchived       = domain.NewValidationError("listId", "archived lists take no new todos")
+)
+
+// CreateList stores a new list in the request's workspace, its name must not be taken by another list of the
+// workspace. A list without a default sort lists its todos newest first.
+func (s *TodoService) CreateList(ctx context.Context, list domain.List) (domain.List, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.List{}, err
+	}
+	if list.DefaultSort == "" {
+		list.DefaultSort = string(SortByCreatedAt)
+	}
+	list.Normalize()
+	if err := list.Validate(); err != nil {
+		return domain.List{}, fmt.Errorf("list validation failed: %w", err)
+	}
+	if !TodoSort(list.DefaultSort).valid() {
+		return domain.List{}, errInvalidDefaultSort
+	}
+
+	row, err := s.todoRepository.CreateList(ctx, db.CreateListParams{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		WorkspaceID: workspace,
+		Name:        list.Name,
+		Description: list.Description,
+		Colour:      list.Colour,
+		Archived:    list.Archived,
+		DefaultSort: list.DefaultSort,
+		CreatedAt:   pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.List{}, domain.ErrListNameTaken
+		}
+		return domain.List{}, databaseError(fmt.Errorf("failed to save list to repository: %w", err))
+	}
+	return toDomainList(row), nil
+}
+
+// GetList returns the list with the counts of its todos.
+func (s *TodoService) GetList(ctx context.Context, id string) (domain.List, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.List{}, err
+	}
+	listUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.List{}, domain.ErrListNotFound
+	}
+
+	row, err := s.todoRepository.GetList(ctx, db.GetListParams{
+		ID:          pgtype.UUID{Bytes: listUUID, Valid: true},
+		WorkspaceID: workspace,
+		OverdueAt:   pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.List{}, domain.ErrListNotFound
+		}
+		return domain.List{}, databaseError(fmt.Errorf("failed to get list from repository: %w", err))
+	}
+	list := toDomainList(db.List{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		Colour:      row.Colour,
+		Archived:    row.Archived,
+		DefaultSort: row.DefaultSort,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	})
+	list.Counts = domain.ListCounts{Open: int(row.OpenCount), Overdue: int(row.OverdueCount), Done: int(row.DoneCount)}
+	return list, nil
+}
+
+// ListLists returns the lists of the request's workspace ordered by name, each with the counts of its todos.
+// Archived lists are left out unless includeArchived is set.
+func (s *TodoService) ListLists(ctx context.Context, includeArchived bool) ([]domain.List, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.todoRepository.ListLists(ctx, db.ListListsParams{
+		WorkspaceID:     workspace,
+		OverdueAt:       pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		IncludeArchived: includeArchived,
+	})
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list lists from repository: %w", err))
+	}
+	lists := make([]domain.List, 0, len(rows))
+	for _, row := range rows {
+		list := toDomainList(db.List{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: row.Description,
+			Colour:      row.Colour,
+			Archived:    row.Archived,
+			DefaultSort: row.DefaultSort,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		})
+		list.Counts = domain.ListCounts{Open: int(row.OpenCount), Overdue: int(row.OverdueCount), Done: int(row.DoneCount)}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+// UpdateList applies the patch to an existing list. Archiving a list keeps its todos where they are.
+func (s *TodoService) UpdateList(ctx context.Context, id string, patch domain.ListPatch) (domain.List, error) {
+	current, err := s.GetList(ctx, id)
+	if err != nil {
+		return domain.List{}, err
+	}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	list := current
+	if err := list.ApplyPatch(patch); err != nil {
+		return domain.List{}, fmt.Errorf("list validation failed: %w", err)
+	}
+	if !TodoSort(list.DefaultSort).valid() {
+		return domain.List{}, errInvalidDefaultSort
+	}
+
+	row, err := s.todoRepository.UpdateList(ctx, db.UpdateListParams{
+		ID:          pgtype.UUID{Bytes: uuid.MustParse(current.ID), Valid: true},
+		WorkspaceID: workspace,
+		Name:        list.Name,
+		Description: list.Description,
+		Colour:      list.Colour,
+		Archived:    list.Archived,
+		DefaultSort: list.DefaultSort,
+		UpdatedAt:   pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows): // deleted since we read it
+			return domain.List{}, domain.ErrListNotFound
+		case isUniqueViolation(err):
+			return domain.List{}, domain.ErrListNameTaken
+		}
+		return domain.List{}, databaseError(fmt.Errorf("failed to update list in repository: %w", err))
+	}
+	updated := toDomainList(row)
+	updated.Counts = current.Counts
+	return updated, nil
+}
+
+// DeleteList deletes a list, its todos stay without a list.
+func (s *TodoService) DeleteList(ctx context.Context, id string) error {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	listUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrListNotFound
+	}
+
+	deleted, err := s.todoRepository.DeleteList(ctx, db.DeleteListParams{ID: pgtype.UUID{Bytes: listUUID, Valid: true}, WorkspaceID: workspace})
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to delete list in repository: %w", err))
+	}
+	if deleted == 0 {
+		return domain.ErrListNotFound
+	}
+	return nil
+}
+
+// checkList makes sure todos can be moved to the list as part of the caller's transaction, it has to be a list of
+// the request's workspace that isn't archived. It returns the list ID to store, which is empty for no list.
+func checkList(ctx context.Context, q db.Querier, listID string) (pgtype.UUID, error) {
+	if listID == "" {
+		return pgtype.UUID{}, nil
+	}
+	list, state, err := getListState(ctx, q, listID)
+	if err != nil {
+		if errors.Is(err, domain.ErrListNotFound) {
+			return pgtype.UUID{}, errListNotFound
+		}
+		return pgtype.UUID{}, err
+	}
+	if state.Archived {
+		return pgtype.UUID{}, errListArchived
+	}
+	return list, nil
+}
+
+// getListState looks up a list of the request's workspace without counting its todos. q is either the repository
+// or the caller's transaction.
+func getListState(ctx context.Context, q db.Querier, listID string) (pgtype.UUID, db.GetListStateRow, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return pgtype.UUID{}, db.GetListStateRow{}, err
+	}
+	listUUID, err := uuid.Parse(listID)
+	if err != nil {
+		return pgtype.UUID{}, db.GetListStateRow{}, domain.ErrListNotFound
+	}
+	id := pgtype.UUID{Bytes: listUUID, Valid: true}
+
+	state, err := q.GetListState(ctx, db.GetListStateParams{ID: id, WorkspaceID: workspace})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, db.GetListStateRow{}, domain.ErrListNotFound
+		}
+		return pgtype.UUID{}, db.GetListStateRow{}, databaseError(fmt.Errorf("failed to get list from repository: %w", err))
+	}
+	return id, state, nil
+}
+
+func toDomainList(row db.List) domain.List {
+	return domain.List{
+		ID:          uuid.UUID(row.ID.Bytes).String(),
+		Name:        row.Name,
+		Description: row.Description,
+		Colour:      row.Colour,
+		Archived:    row.Archived,
+		DefaultSort: row.DefaultSort,
+		CreatedAt:   row.CreatedAt.Time.UTC(),
+		UpdatedAt:   row.UpdatedAt.Time.UTC(),
+	}
+}
+
+// listUUID converts the list ID of a stored todo, an empty ID is no list.
+func listUUID(listID string) pgtype.UUID {
+	if listID == "" {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: uuid.MustParse(listID), Valid: true}
+}
+
+// listIDString converts the list ID of a stored todo, a todo without a list has an empty one.
+func listIDString(listID pgtype.UUID) string {
+	if !listID.Valid {
+		return ""
+	}
+	return uuid.UUID(listID.Bytes).String()
+}
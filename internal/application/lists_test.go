@@ -0,0 +1,388 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateList(t *testing.T) {
+	tests := []struct {
+		name          string
+		list          domain.List
+		setupMock     func(*MockDBRepository)
+		expectedSort  string
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "sorts by creation without a default sort",
+			list: domain.List{Name: " Home renovation ", Colour: "#FF8800"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateList", mock.Anything, mock.MatchedBy(func(params db.CreateListParams) bool {
+					return params.Name == "Home renovation" && params.Colour == "#ff8800" && params.DefaultSort == "createdAt" &&
+						params.WorkspaceID == testWorkspaceID && params.ID.Valid
+				})).Return(db.List{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "Home renovation", DefaultSort: "createdAt"}, nil)
+			},
+			expectedSort: "createdAt",
+		},
+		{
+			name: "keeps the default sort",
+			list: domain.List{Name: "Errands", DefaultSort: "dueDate"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateList", mock.Anything, mock.MatchedBy(func(params db.CreateListParams) bool {
+					return params.DefaultSort == "dueDate"
+				})).Return(db.List{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "Errands", DefaultSort: "dueDate"}, nil)
+			},
+			expectedSort: "dueDate",
+		},
+		{
+			name:          "unknown default sort",
+			list:          domain.List{Name: "Errands", DefaultSort: "name"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: errInvalidDefaultSort,
+		},
+		{
+			name:          "empty name",
+			list:          domain.List{Name: " "},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name: "name taken",
+			list: domain.List{Name: "Errands"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateList", mock.Anything, mock.Anything).Return(db.List{}, &pgconn.PgError{Code: pgUniqueViolation})
+			},
+			expectedErrIs: domain.ErrListNameTaken,
+		},
+		{
+			name: "database error",
+			list: domain.List{Name: "Errands"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateList", mock.Anything, mock.Anything).Return(db.List{}, errors.New("db error"))
+			},
+			expectedError: "failed to save list to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			list, err := service.CreateList(ownerContext(), tt.list)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, list.ID)
+				assert.Equal(t, tt.expectedSort, list.DefaultSort)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListLists(t *testing.T) {
+	homeID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("ListLists", mock.Anything, mock.MatchedBy(func(params db.ListListsParams) bool {
+		return params.WorkspaceID == testWorkspaceID && params.IncludeArchived && params.OverdueAt.Valid
+	})).Return([]db.ListListsRow{
+		{ID: homeID, Name: "Home", DefaultSort: "priority", OpenCount: 4, OverdueCount: 1, DoneCount: 7},
+	}, nil)
+
+	service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+	lists, err := service.ListLists(ownerContext(), true)
+
+	assert.NoError(t, err)
+	if assert.Len(t, lists, 1) {
+		assert.Equal(t, uuid.UUID(homeID.Bytes).String(), lists[0].ID)
+		assert.Equal(t, "priority", lists[0].DefaultSort)
+		assert.Equal(t, domain.ListCounts{Open: 4, Overdue: 1, Done: 7}, lists[0].Counts)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateList(t *testing.T) {
+	listUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: listUUID, Valid: true}
+	archived := true
+	unknownSort := "name"
+	stored := db.GetListRow{ID: pgID, Name: "Home", Colour: "#00ff00", DefaultSort: "dueDate", OpenCount: 2}
+
+	tests := []struct {
+		name          string
+		patch         domain.ListPatch
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:  "archives the list",
+			patch: domain.ListPatch{Archived: &archived},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetList", mock.Anything, mock.MatchedBy(func(params db.GetListParams) bool {
+					return params.ID == pgID && params.WorkspaceID == testWorkspaceID
+				})).Return(stored, nil)
+				mockDB.On("UpdateList", mock.Anything, mock.MatchedBy(func(params db.UpdateListParams) bool {
+					return params.ID == pgID && params.Archived && params.Name == "Home" && params.Colour == "#00ff00" && params.DefaultSort == "dueDate"
+				})).Return(db.List{ID: pgID, Name: "Home", Archived: true, DefaultSort: "dueDate"}, nil)
+			},
+		},
+		{
+			name:  "unknown default sort",
+			patch: domain.ListPatch{DefaultSort: &unknownSort},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetList", mock.Anything, mock.Anything).Return(stored, nil)
+			},
+			expectedErrIs: errInvalidDefaultSort,
+		},
+		{
+			name:  "list not found",
+			patch: domain.ListPatch{Archived: &archived},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetList", mock.Anything, mock.Anything).Return(db.GetListRow{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrListNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			list, err := service.UpdateList(ownerContext(), listUUID.String(), tt.patch)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, list.Archived)
+				assert.Equal(t, 2, list.Counts.Open)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteList(t *testing.T) {
+	listUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		deleted       int64
+		expectedErrIs error
+	}{
+		{name: "deletes the list", deleted: 1},
+		{name: "list not found", deleted: 0, expectedErrIs: domain.ErrListNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("DeleteList", mock.Anything, db.DeleteListParams{
+				ID:          pgtype.UUID{Bytes: listUUID, Valid: true},
+				WorkspaceID: testWorkspaceID,
+			}).Return(tt.deleted, nil)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.DeleteList(ownerContext(), listUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMoveTodoToList(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	listUUID := uuid.New()
+	listID := pgtype.UUID{Bytes: listUUID, Valid: true}
+	toList := listUUID.String()
+	noList := ""
+
+	stored := db.TodoItem{
+		ID:          pgID,
+		Description: "Paint the kitchen",
+		DueDate:     pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		Version:     3,
+	}
+	moved := stored
+	moved.ListID = listID
+	moved.Version = 4
+	moved.UpdatedAt = pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+
+	setupTodo := func(mockDB *MockDBRepository, item db.TodoItem) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(item, nil)
+		mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+		mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+	}
+
+	tests := []struct {
+		name           string
+		patch          domain.TodoItemPatch
+		setupMock      func(*MockDBRepository)
+		expectedListID string
+		expectedErrIs  error
+	}{
+		{
+			name:  "moves the todo with its subtasks",
+			patch: domain.TodoItemPatch{ListID: &toList},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, stored)
+				mockDB.On("GetListState", mock.Anything, db.GetListStateParams{ID: listID, WorkspaceID: testWorkspaceID}).Return(db.GetListStateRow{DefaultSort: "createdAt"}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.ListID == listID && params.ExpectedVersion == 3
+				})).Return(moved, nil)
+				mockDB.On("MoveSubtasksToList", mock.Anything, db.MoveSubtasksToListParams{ParentID: pgID, ListID: listID, UpdatedAt: moved.UpdatedAt}).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"list_id":"`+toList+`"`)
+				})).Return(nil)
+			},
+			expectedListID: toList,
+		},
+		{
+			name:  "takes the todo out of its list",
+			patch: domain.TodoItemPatch{ListID: &noList},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, moved)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return !params.ListID.Valid
+				})).Return(stored, nil)
+				mockDB.On("MoveSubtasksToList", mock.Anything, mock.MatchedBy(func(params db.MoveSubtasksToListParams) bool {
+					return params.ParentID == pgID && !params.ListID.Valid
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:  "archived list",
+			patch: domain.TodoItemPatch{ListID: &toList},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, stored)
+				mockDB.On("GetListState", mock.Anything, mock.Anything).Return(db.GetListStateRow{Archived: true}, nil)
+			},
+			expectedErrIs: errListArchived,
+		},
+		{
+			name:  "list not found",
+			patch: domain.TodoItemPatch{ListID: &toList},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, stored)
+				mockDB.On("GetListState", mock.Anything, mock.Anything).Return(db.GetListStateRow{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: errListNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.UpdateTodo(ownerContext(), todoUUID.String(), tt.patch, 0)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedListID, todo.ListID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListTodosOfList(t *testing.T) {
+	listUUID := uuid.New()
+	listID := pgtype.UUID{Bytes: listUUID, Valid: true}
+
+	tests := []struct {
+		name          string
+		query         TodoQuery
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:  "sorts in the default order of the list",
+			query: TodoQuery{ListID: listUUID.String()},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetListState", mock.Anything, db.GetListStateParams{ID: listID, WorkspaceID: testWorkspaceID}).Return(db.GetListStateRow{DefaultSort: "dueDate"}, nil)
+				mockDB.On("ListTodosByDueDate", mock.Anything, db.ListTodosByDueDateParams{WorkspaceID: testWorkspaceID, ListID: listID, PageSize: DefaultPageSize + 1}).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+		},
+		{
+			name:  "the sort asked for wins",
+			query: TodoQuery{ListID: listUUID.String(), Sort: SortByPriority},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetListState", mock.Anything, mock.Anything).Return(db.GetListStateRow{DefaultSort: "dueDate"}, nil)
+				mockDB.On("ListTodosByPriority", mock.Anything, mock.MatchedBy(func(params db.ListTodosByPriorityParams) bool {
+					return params.ListID == listID
+				})).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+		},
+		{
+			name:  "list not found",
+			query: TodoQuery{ListID: listUUID.String()},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetListState", mock.Anything, mock.Anything).Return(db.GetListStateRow{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrListNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			_, err := service.ListTodos(ownerContext(), tt.query)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// outboxPruneInterval is how often sent events older than the retention are deleted from the outbox.
+const outboxPruneInterval = time.Hour
+
+// OutboxRelay periodically drains the outbox to the message publisher and prunes the events it sent.
+type OutboxRelay struct {
+	todoService *TodoService
+	interval    time.Duration
+	retention   time.Duration
+	logger      *slog.Logger
+}
+
+func NewOutboxRelay(todoService *TodoService, interval, retention time.Duration, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{todoService: todoService, interval: interval, retention: retention, logger: logger}
+}
+
+// Run relays pending events once immediately and then on every interval until ctx is cancelled. Sent events are
+// pruned right away and then every outboxPruneInterval.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(outboxPruneInterval)
+	defer pruneTicker.Stop()
+
+	r.prune(ctx)
+	for {
+		r.relay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-pruneTicker.C:
+			r.prune(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(ctx context.Context) {
+	sent, err := r.todoService.RelayOutboxEvents(ctx)
+	if err != nil {
+		r.logger.Error("failed to relay outbox events", "error", err, "sent", sent)
+		return
+	}
+	if sent > 0 {
+		r.logger.Debug("relayed outbox events", "sent", sent)
+	}
+}
+
+func (r *OutboxRelay) prune(ctx context.Context) {
+	pruned, err := r.todoService.PruneOutboxEvents(ctx, time.Now().UTC().Add(-r.retention))
+	if err != nil {
+		r.logger.Error("failed to prune outbox events", "error", err, "pruned", pruned)
+		return
+	}
+	if pruned > 0 {
+		r.logger.Info("pruned outbox events", "pruned", pruned)
+	}
+}
@@ -0,0 +1,28 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxRelayRun(t *testing.T) {
+	mockDB := new(MockDBRepository)
+	mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{}, nil).Once()
+	mockDB.On("DeleteSentOutboxEvents", mock.Anything, mock.MatchedBy(func(params db.DeleteSentOutboxEventsParams) bool {
+		return time.Since(params.SentBefore.Time) >= 24*time.Hour
+	})).Return(int64(0), nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	relay := NewOutboxRelay(NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default()), time.Second, 24*time.Hour, slog.Default())
+	relay.Run(ctx)
+
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errBeforeTodoNotFound = domain.NewValidationError("before", "the todo to move before was not found")
+	errAfterTodoNotFound  = domain.NewValidationError("after", "the todo to move after was not found")
+)
+
+// MoveTodo moves the todo right before or right after another todo. Only its rank changes, so in a list sorted
+// by priority it moves among the todos of its own priority. The rank orders the todos of the workspace, so only its
+// members can move them.
+// A non-zero expectedVersion must match the stored version, otherwise domain.ErrTodoVersionConflict is returned.
+func (s *TodoService) MoveTodo(ctx context.Context, id string, position domain.TodoPosition, expectedVersion int32) (domain.TodoItem, error) {
+	if err := position.Validate(id); err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	current, err := s.getTodo(ctx, id, membersOnly)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return domain.TodoItem{}, fmt.Errorf("%w: expected version %d, current version %d", domain.ErrTodoVersionConflict, expectedVersion, current.Version)
+	}
+
+	todoID := pgtype.UUID{Bytes: uuid.MustParse(current.ID), Valid: true}
+	now := time.Now().UTC()
+	var moved domain.TodoItem
+	err = s.execTx(ctx, func(q db.Querier) error {
+		rank, err := rankAt(ctx, q, todoID, position)
+		if err != nil {
+			return err
+		}
+
+		item, err := q.UpdateTodoRank(ctx, db.UpdateTodoRankParams{
+			ID:              todoID,
+			Rank:            rank,
+			UpdatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+			ExpectedVersion: current.Version,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) { // someone else updated the row since we read it
+				return domain.ErrTodoVersionConflict
+			}
+			return databaseError(fmt.Errorf("failed to update todo rank in repository: %w", err))
+		}
+		moved = toDomainTodo(item)
+		moved.Attachments = current.Attachments
+		moved.Tags = current.Tags
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoMoved, domain.TodoItemMoveEvent{
+			Type:    domain.EventTypeTodoMoved,
+			ID:      moved.ID,
+			Rank:    moved.Rank,
+			Version: moved.Version,
+			MovedAt: now,
+		})
+	})
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return moved, nil
+}
+
+// rankAtEnd returns a rank after those of all todos of the request's workspace, trashed ones included so restoring
+// them can't clash. The ranks of the workspace stay locked until the caller's transaction ends.
+func rankAtEnd(ctx context.Context, q db.Querier) (string, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := q.LockTodoRanks(ctx, workspace); err != nil {
+		return "", databaseError(fmt.Errorf("failed to lock todo ranks: %w", err))
+	}
+
+	last, err := q.GetLastTodoRank(ctx, workspace)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", databaseError(fmt.Errorf("failed to get last todo rank from repository: %w", err))
+	}
+
+	rank, err := domain.RankBetween(last, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to rank todo: %w", err)
+	}
+	return rank, nil
+}
+
+// rankAt returns the rank of the position for the todo being moved, which is left out when looking for the
+// neighbour of the other todo. The ranks of the request's workspace stay locked until the caller's transaction ends.
+func rankAt(ctx context.Context, q db.Querier, todoID pgtype.UUID, position domain.TodoPosition) (string, error) {
+	otherID, errNotFound := position.Before, errBeforeTodoNotFound
+	if position.After != "" {
+		otherID, errNotFound = position.After, errAfterTodoNotFound
+	}
+	otherUUID, err := uuid.Parse(otherID)
+	if err != nil {
+		return "", errNotFound
+	}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := q.LockTodoRanks(ctx, workspace); err != nil {
+		return "", databaseError(fmt.Errorf("failed to lock todo ranks: %w", err))
+	}
+
+	other, err := q.GetTodo(ctx, db.GetTodoParams{ID: pgtype.UUID{Bytes: otherUUID, Valid: true}, WorkspaceID: workspace})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errNotFound
+		}
+		return "", databaseError(fmt.Errorf("failed to get todo from repository: %w", err))
+	}
+
+	// the neighbour is empty when the other todo is the first or the last one
+	var before, after string
+	if position.After != "" {
+		before = other.Rank
+		after, err = q.GetNextTodoRank(ctx, db.GetNextTodoRankParams{WorkspaceID: workspace, Rank: other.Rank, ExcludeID: todoID})
+	} else {
+		after = other.Rank
+		before, err = q.GetPreviousTodoRank(ctx, db.GetPreviousTodoRankParams{WorkspaceID: workspace, Rank: other.Rank, ExcludeID: todoID})
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", databaseError(fmt.Errorf("failed to get neighbouring todo rank from repository: %w", err))
+	}
+
+	rank, err := domain.RankBetween(before, after)
+	if err != nil {
+		return "", fmt.Errorf("failed to rank todo: %w", err)
+	}
+	return rank, nil
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMoveTodo(t *testing.T) {
+	todoID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	otherID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	id, other := uuid.UUID(todoID.Bytes).String(), uuid.UUID(otherID.Bytes).String()
+
+	stored := db.TodoItem{ID: todoID, Description: "Test todo", Priority: "P2", Rank: "a5", Version: 3}
+	otherRow := db.TodoItem{ID: otherID, Description: "Other todo", Priority: "P2", Rank: "a1"}
+
+	setupGet := func(mockDB *MockDBRepository) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: todoID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+		mockDB.On("ListAttachments", mock.Anything, todoID).Return([]db.Attachment{}, nil)
+		mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+	}
+	movedRow := func(rank string) db.TodoItem {
+		row := stored
+		row.Rank = rank
+		row.Version = 4
+		return row
+	}
+
+	tests := []struct {
+		name            string
+		position        domain.TodoPosition
+		expectedVersion int32
+		setupMock       func(*MockDBRepository)
+		expectedRank    string
+		expectedErrIs   error
+		expectedError   string
+	}{
+		{
+			name:     "before another todo",
+			position: domain.TodoPosition{Before: other},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupGet(mockDB)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: otherID, WorkspaceID: testWorkspaceID}).Return(otherRow, nil)
+				mockDB.On("GetPreviousTodoRank", mock.Anything, db.GetPreviousTodoRankParams{WorkspaceID: testWorkspaceID, Rank: "a1", ExcludeID: todoID}).Return("a0", nil)
+				mockDB.On("UpdateTodoRank", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoRankParams) bool {
+					return params.ID == todoID && params.Rank == "a0V" && params.ExpectedVersion == 3
+				})).Return(movedRow("a0V"), nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoMoved && strings.Contains(string(params.Payload), `"rank":"a0V"`)
+				})).Return(nil)
+			},
+			expectedRank: "a0V",
+		},
+		{
+			name:            "after the last todo",
+			position:        domain.TodoPosition{After: other},
+			expectedVersion: 3,
+			setupMock: func(mockDB *MockDBRepository) {
+				setupGet(mockDB)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: otherID, WorkspaceID: testWorkspaceID}).Return(otherRow, nil)
+				mockDB.On("GetNextTodoRank", mock.Anything, db.GetNextTodoRankParams{WorkspaceID: testWorkspaceID, Rank: "a1", ExcludeID: todoID}).Return("", pgx.ErrNoRows)
+				mockDB.On("UpdateTodoRank", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoRankParams) bool {
+					return params.Rank == "a2"
+				})).Return(movedRow("a2"), nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedRank: "a2",
+		},
+		{
+			name:          "next to itself",
+			position:      domain.TodoPosition{After: id},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:     "other todo not found",
+			position: domain.TodoPosition{After: other},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupGet(mockDB)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: otherID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: errAfterTodoNotFound,
+		},
+		{
+			name:            "stale version",
+			position:        domain.TodoPosition{Before: other},
+			expectedVersion: 2,
+			setupMock:       setupGet,
+			expectedErrIs:   domain.ErrTodoVersionConflict,
+		},
+		{
+			name:     "concurrent update between read and write",
+			position: domain.TodoPosition{Before: other},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupGet(mockDB)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: otherID, WorkspaceID: testWorkspaceID}).Return(otherRow, nil)
+				mockDB.On("GetPreviousTodoRank", mock.Anything, mock.Anything).Return("a0", nil)
+				mockDB.On("UpdateTodoRank", mock.Anything, mock.Anything).Return(db.TodoItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrTodoVersionConflict,
+		},
+		{
+			name:     "database error",
+			position: domain.TodoPosition{Before: other},
+			setupMock: func(mockDB *MockDBRepository) {
+				setupGet(mockDB)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: otherID, WorkspaceID: testWorkspaceID}).Return(otherRow, nil)
+				mockDB.On("GetPreviousTodoRank", mock.Anything, mock.Anything).Return("", errors.New("db error"))
+			},
+			expectedError: "failed to get neighbouring todo rank from repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			moved, err := service.MoveTodo(ownerContext(), id, tt.position, tt.expectedVersion)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRank, moved.Rank)
+				assert.Equal(t, int32(4), moved.Version)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// createNextOccurrence creates the todo following a completed recurring todo as part of the caller's transaction and
+// queues its created event. The attachments of the occurrence refer to the files of the completed todo rather than
+// copies. A todo that is reopened and completed again keeps the occurrence it already has.
+func (s *TodoService) createNextOccurrence(ctx context.Context, q db.Querier, completed domain.TodoItem, now time.Time) error {
+	next, ok := completed.NextOccurrence()
+	if !ok {
+		return nil
+	}
+
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return err
+	}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	completedID := pgtype.UUID{Bytes: uuid.MustParse(completed.ID), Valid: true}
+	exists, err := q.HasNextOccurrence(ctx, completedID)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to look up next occurrence in repository: %w", err))
+	}
+	if exists {
+		return nil
+	}
+
+	nextUUID := uuid.New()
+	next.ID = nextUUID.String()
+	nextID := pgtype.UUID{Bytes: nextUUID, Valid: true}
+
+	// the occurrence takes the place of the completed todo in the manual order
+	rank, err := rankAt(ctx, q, nextID, domain.TodoPosition{After: completed.ID})
+	if err != nil {
+		return err
+	}
+	err = q.CreateTodo(ctx, db.CreateTodoParams{
+		ID:          nextID,
+		Description: next.Description,
+		DueDate:     pgtype.Timestamptz{Time: next.DueDate, Valid: true},
+		Timezone:    pgtype.Text{String: next.TimeZone, Valid: next.TimeZone != ""},
+		Priority:    string(next.Priority),
+		Rank:        rank,
+		Recurrence:  pgtype.Text{String: next.Recurrence, Valid: next.Recurrence != ""},
+		RecursFrom:  completedID,
+		CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		OwnerID:     owner,
+		WorkspaceID: workspace,
+		ListID:      listUUID(next.ListID),
+	})
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to save next occurrence to repository: %w", err))
+	}
+
+	attachments := make([]domain.Attachment, 0, len(next.Attachments))
+	attachmentIDs := make([]string, 0, len(next.Attachments))
+	for _, attachment := range next.Attachments {
+		attachment.ID = uuid.New().String()
+		attachment.TodoID = next.ID
+		attachments = append(attachments, attachment)
+		attachmentIDs = append(attachmentIDs, attachment.ID)
+	}
+	if err := s.saveAttachments(ctx, q, attachments, false); err != nil {
+		return err
+	}
+	tags, err := saveTodoTags(ctx, q, workspace, nextID, next.Tags, false, now)
+	if err != nil {
+		return err
+	}
+	next.Tags = tags
+
+	return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoCreated, domain.TodoItemCreateEvent{
+		Type:        domain.EventTypeTodoCreated,
+		ID:          next.ID,
+		Description: next.Description,
+		DueDate:     next.DueDate,
+		TimeZone:    next.TimeZone,
+		Attachments: attachmentIDs,
+		Tags:        next.TagNames(),
+		Priority:    string(next.Priority),
+		Recurrence:  next.Recurrence,
+		RecursFrom:  completed.ID,
+		ListID:      next.ListID,
+		Status:      string(domain.TodoStatusOpen),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
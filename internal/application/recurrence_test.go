@@ -0,0 +1,155 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCompleteRecurringTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	workID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	dueDate := time.Date(2030, time.March, 25, 9, 0, 0, 0, time.UTC) // 10:00 on a Monday in Berlin
+	// still 10:00 in Berlin, which switched to summer time in between
+	nextDueDate := time.Date(2030, time.April, 1, 8, 0, 0, 0, time.UTC)
+
+	storedRow := func(recurrence string) db.TodoItem {
+		return db.TodoItem{
+			ID:          pgID,
+			Description: "Weekly report",
+			DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: true},
+			Timezone:    pgtype.Text{String: "Europe/Berlin", Valid: true},
+			Status:      string(domain.TodoStatusInProgress),
+			Priority:    "P1",
+			Rank:        "a5",
+			Recurrence:  pgtype.Text{String: recurrence, Valid: true},
+			Version:     2,
+		}
+	}
+	attachment := db.Attachment{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TodoID:     pgID,
+		StorageKey: "todos/" + todoUUID.String() + "/template",
+		Filename:   "template.docx",
+	}
+
+	setupTransition := func(mockDB *MockDBRepository, recurrence string) {
+		stored := storedRow(recurrence)
+		completed := stored
+		completed.Status = string(domain.TodoStatusDone)
+		completed.CompletedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		completed.Version = 3
+
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+		mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{attachment}, nil)
+		mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{
+			{TodoID: pgID, ID: workID, Name: "work"},
+		}, nil)
+		mockDB.On("ListOpenBlockerIDs", mock.Anything, mock.Anything).Return([]pgtype.UUID{}, nil)
+		mockDB.On("ListSubtasks", mock.Anything, pgID).Return([]db.TodoItem{}, nil)
+		mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ChecklistItem{}, nil)
+		mockDB.On("UpdateTodoStatus", mock.Anything, mock.Anything).Return(completed, nil)
+		mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+			return params.EventType == domain.EventTypeTodoStatusChanged
+		})).Return(nil)
+	}
+
+	tests := []struct {
+		name          string
+		recurrence    string
+		setupMocks    func(*MockDBRepository)
+		expectedError string
+	}{
+		{
+			name:       "creates the next occurrence",
+			recurrence: "FREQ=WEEKLY;COUNT=3",
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTransition(mockDB, "FREQ=WEEKLY;COUNT=3")
+				mockDB.On("HasNextOccurrence", mock.Anything, pgID).Return(false, nil)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetNextTodoRank", mock.Anything, mock.Anything).Return("", pgx.ErrNoRows)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.ID != pgID &&
+						params.Description == "Weekly report" &&
+						params.DueDate.Time.Equal(nextDueDate) &&
+						params.Timezone.String == "Europe/Berlin" &&
+						params.Priority == "P1" &&
+						params.Rank == "a6" &&
+						params.Recurrence.String == "FREQ=WEEKLY;COUNT=2" &&
+						params.RecursFrom == pgID
+				})).Return(nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.MatchedBy(func(params db.CreateAttachmentParams) bool {
+					return params.ID != attachment.ID && params.TodoID != pgID && params.StorageKey == attachment.StorageKey
+				})).Return(nil)
+				mockDB.On("UpsertTag", mock.Anything, mock.MatchedBy(func(params db.UpsertTagParams) bool {
+					return params.Name == "work"
+				})).Return(db.Tag{ID: workID, Name: "work"}, nil)
+				mockDB.On("AddTodoTag", mock.Anything, mock.MatchedBy(func(params db.AddTodoTagParams) bool {
+					return params.TodoID != pgID && params.TagID == workID
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					message := string(params.Payload)
+					return params.EventType == domain.EventTypeTodoCreated &&
+						strings.Contains(message, `"recurs_from":"`+todoUUID.String()+`"`) &&
+						strings.Contains(message, `"tags":["work"]`)
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "occurrence created before",
+			recurrence: "FREQ=DAILY",
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTransition(mockDB, "FREQ=DAILY")
+				mockDB.On("HasNextOccurrence", mock.Anything, pgID).Return(true, nil)
+			},
+		},
+		{
+			name:       "last occurrence",
+			recurrence: "FREQ=DAILY;COUNT=1",
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTransition(mockDB, "FREQ=DAILY;COUNT=1")
+			},
+		},
+		{
+			name:       "database error",
+			recurrence: "FREQ=DAILY",
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTransition(mockDB, "FREQ=DAILY")
+				mockDB.On("HasNextOccurrence", mock.Anything, pgID).Return(false, errors.New("db error"))
+			},
+			expectedError: "failed to look up next occurrence in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMocks(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.TransitionTodo(ownerContext(), todoUUID.String(), domain.TodoStatusDone, 2)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.TodoStatusDone, todo.Status)
+				assert.Equal(t, tt.recurrence, todo.Recurrence)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
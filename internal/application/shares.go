@@ -0,0 +1,277 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// membersOnly is the level required for what no share allows, only members of the todo's workspace may do it.
+const membersOnly domain.ShareLevel = ""
+
+var (
+	errShareUserNotFound      = domain.NewValidationError("userId", "the user to share with was not found")
+	errShareWorkspaceNotFound = domain.NewValidationError("workspaceId", "the workspace to share with was not found")
+	errShareOwnWorkspace      = domain.NewValidationError("workspaceId", "the todo or list already belongs to this workspace")
+)
+
+// ShareTodo gives a user, or every member of a workspace, access to a todo of the request's workspace at the level
+// of the share. Sharing the todo again with the same grantee changes the level of the existing share. Only members
+// of the todo's workspace can share it.
+func (s *TodoService) ShareTodo(ctx context.Context, todoID string, share domain.Share) (domain.Share, error) {
+	if err := share.Validate(); err != nil {
+		return domain.Share{}, err
+	}
+	id, err := s.existingTodoID(ctx, todoID, membersOnly)
+	if err != nil {
+		return domain.Share{}, err
+	}
+	return s.grantShare(ctx, db.UpsertShareParams{TodoID: id}, share)
+}
+
+// ShareList gives a user, or every member of a workspace, access to every todo of a list of the request's workspace
+// at the level of the share, the todos moved to the list later included. Sharing the list again with the same
+// grantee changes the level of the existing share. Only members of the list's workspace can share it.
+func (s *TodoService) ShareList(ctx context.Context, listID string, share domain.Share) (domain.Share, error) {
+	if err := share.Validate(); err != nil {
+		return domain.Share{}, err
+	}
+	id, _, err := getListState(ctx, s.todoRepository, listID)
+	if err != nil {
+		return domain.Share{}, err
+	}
+	return s.grantShare(ctx, db.UpsertShareParams{ListID: id}, share)
+}
+
+// grantShare stores the share of the todo or the list set in params and publishes it.
+func (s *TodoService) grantShare(ctx context.Context, params db.UpsertShareParams, share domain.Share) (domain.Share, error) {
+	grantedBy, err := ownerID(ctx)
+	if err != nil {
+		return domain.Share{}, err
+	}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.Share{}, err
+	}
+
+	params.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	params.Level = string(share.Level)
+	params.GrantedBy = grantedBy
+	params.CreatedAt = pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+	params.OwnerWorkspaceID = workspace
+	granteeErr := errShareUserNotFound
+	if share.UserID != "" {
+		userUUID, err := uuid.Parse(share.UserID)
+		if err != nil {
+			return domain.Share{}, errShareUserNotFound
+		}
+		params.UserID = pgtype.UUID{Bytes: userUUID, Valid: true}
+	} else {
+		workspaceUUID, err := uuid.Parse(share.WorkspaceID)
+		if err != nil {
+			return domain.Share{}, errShareWorkspaceNotFound
+		}
+		if workspaceUUID == workspace.Bytes {
+			return domain.Share{}, errShareOwnWorkspace
+		}
+		params.WorkspaceID = pgtype.UUID{Bytes: workspaceUUID, Valid: true}
+		granteeErr = errShareWorkspaceNotFound
+	}
+
+	var shared domain.Share
+	err = s.execTx(ctx, func(q db.Querier) error {
+		row, err := q.UpsertShare(ctx, params)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+				return granteeErr
+			}
+			return databaseError(fmt.Errorf("failed to save share to repository: %w", err))
+		}
+		shared = toDomainShare(row)
+		return s.enqueueShareEvent(ctx, q, shared, false)
+	})
+	if err != nil {
+		return domain.Share{}, err
+	}
+	return shared, nil
+}
+
+// ListShares returns the users and workspaces the todo is shared with, oldest share first. Shares of its list are
+// listed with the list. Only members of the todo's workspace can see them.
+func (s *TodoService) ListShares(ctx context.Context, todoID string) ([]domain.Share, error) {
+	id, err := s.existingTodoID(ctx, todoID, membersOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.todoRepository.ListShares(ctx, id)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list shares from repository: %w", err))
+	}
+	return toDomainShares(rows), nil
+}
+
+// ListListShares returns the users and workspaces the list is shared with, oldest share first. Only members of the
+// list's workspace can see them.
+func (s *TodoService) ListListShares(ctx context.Context, listID string) ([]domain.Share, error) {
+	id, _, err := getListState(ctx, s.todoRepository, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.todoRepository.ListListShares(ctx, id)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list shares from repository: %w", err))
+	}
+	return toDomainShares(rows), nil
+}
+
+// RevokeShare takes the access the share gave away again. Only members of the todo's workspace can revoke it.
+func (s *TodoService) RevokeShare(ctx context.Context, todoID, shareID string) error {
+	shareUUID, err := uuid.Parse(shareID)
+	if err != nil {
+		return domain.ErrShareNotFound
+	}
+	id, err := s.existingTodoID(ctx, todoID, membersOnly)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeShare(ctx, func(q db.Querier) (db.Share, error) {
+		return q.DeleteShare(ctx, db.DeleteShareParams{TodoID: id, ID: pgtype.UUID{Bytes: shareUUID, Valid: true}})
+	})
+}
+
+// RevokeListShare takes the access the share of a list gave to its todos away again. Only members of the list's
+// workspace can revoke it.
+func (s *TodoService) RevokeListShare(ctx context.Context, listID, shareID string) error {
+	shareUUID, err := uuid.Parse(shareID)
+	if err != nil {
+		return domain.ErrShareNotFound
+	}
+	id, _, err := getListState(ctx, s.todoRepository, listID)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeShare(ctx, func(q db.Querier) (db.Share, error) {
+		return q.DeleteListShare(ctx, db.DeleteListShareParams{ListID: id, ID: pgtype.UUID{Bytes: shareUUID, Valid: true}})
+	})
+}
+
+// revokeShare deletes a share with deleteShare and publishes it in the same transaction.
+func (s *TodoService) revokeShare(ctx context.Context, deleteShare func(q db.Querier) (db.Share, error)) error {
+	return s.execTx(ctx, func(q db.Querier) error {
+		row, err := deleteShare(q)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrShareNotFound
+			}
+			return databaseError(fmt.Errorf("failed to delete share in repository: %w", err))
+		}
+		return s.enqueueShareEvent(ctx, q, toDomainShare(row), true)
+	})
+}
+
+// enqueueShareEvent publishes a granted share, or a revoked one, of a todo or a list as part of the caller's
+// transaction.
+func (s *TodoService) enqueueShareEvent(ctx context.Context, q db.Querier, share domain.Share, revoked bool) error {
+	level, grantedBy, changedAt := string(share.Level), share.GrantedBy, share.UpdatedAt
+	todoEventType, listEventType := domain.EventTypeTodoShared, domain.EventTypeListShared
+	if revoked {
+		level, grantedBy, changedAt = "", "", time.Now().UTC()
+		todoEventType, listEventType = domain.EventTypeTodoUnshared, domain.EventTypeListUnshared
+	}
+
+	if share.ListID != "" {
+		return s.enqueueTodoEvent(ctx, q, listEventType, domain.ListShareEvent{
+			Type:        listEventType,
+			ID:          share.ListID,
+			ShareID:     share.ID,
+			UserID:      share.UserID,
+			WorkspaceID: share.WorkspaceID,
+			Level:       level,
+			GrantedBy:   grantedBy,
+			ChangedAt:   changedAt,
+		})
+	}
+	return s.enqueueTodoEvent(ctx, q, todoEventType, domain.TodoItemShareEvent{
+		Type:        todoEventType,
+		ID:          share.TodoID,
+		ShareID:     share.ID,
+		UserID:      share.UserID,
+		WorkspaceID: share.WorkspaceID,
+		Level:       level,
+		GrantedBy:   grantedBy,
+		ChangedAt:   changedAt,
+	})
+}
+
+// sharedTodoWorkspace returns the workspace of a todo outside the request's workspace, provided it is shared with
+// the user, on its own or with its list, at a level allowing what needs the required level. Todos that aren't shared with the user at all are
+// reported as not found, so they can't be told apart from those that don't exist. No share allows what is left to
+// members only.
+func (s *TodoService) sharedTodoWorkspace(ctx context.Context, id pgtype.UUID, required domain.ShareLevel) (pgtype.UUID, error) {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	row, err := s.todoRepository.GetTodoShareLevels(ctx, db.GetTodoShareLevelsParams{ID: id, UserID: user})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, domain.ErrTodoNotFound
+		}
+		return pgtype.UUID{}, databaseError(fmt.Errorf("failed to look up todo shares in repository: %w", err))
+	}
+	levels := make([]domain.ShareLevel, 0, len(row.Levels))
+	for _, level := range row.Levels {
+		levels = append(levels, domain.ShareLevel(level))
+	}
+	if !domain.MaxShareLevel(levels).Allows(required) {
+		return pgtype.UUID{}, domain.ErrTodoAccessDenied
+	}
+	return row.WorkspaceID, nil
+}
+
+func toDomainShare(row db.Share) domain.Share {
+	share := domain.Share{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		Level:     domain.ShareLevel(row.Level),
+		CreatedAt: row.CreatedAt.Time.UTC(),
+		UpdatedAt: row.UpdatedAt.Time.UTC(),
+	}
+	if row.TodoID.Valid {
+		share.TodoID = uuid.UUID(row.TodoID.Bytes).String()
+	}
+	if row.ListID.Valid {
+		share.ListID = uuid.UUID(row.ListID.Bytes).String()
+	}
+	if row.UserID.Valid {
+		share.UserID = uuid.UUID(row.UserID.Bytes).String()
+	}
+	if row.WorkspaceID.Valid {
+		share.WorkspaceID = uuid.UUID(row.WorkspaceID.Bytes).String()
+	}
+	if row.GrantedBy.Valid {
+		share.GrantedBy = uuid.UUID(row.GrantedBy.Bytes).String()
+	}
+	return share
+}
+
+func toDomainShares(rows []db.Share) []domain.Share {
+	shares := make([]domain.Share, 0, len(rows))
+	for _, row := range rows {
+		shares = append(shares, toDomainShare(row))
+	}
+	return shares
+}
@@ -0,0 +1,455 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	userUUID := uuid.New()
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name          string
+		share         domain.Share
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:  "shares with a user",
+			share: domain.Share{UserID: userUUID.String(), Level: domain.ShareLevelComment},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("UpsertShare", mock.Anything, mock.MatchedBy(func(params db.UpsertShareParams) bool {
+					return params.TodoID == pgID && params.UserID.Bytes == userUUID && !params.WorkspaceID.Valid &&
+						params.Level == "comment" && params.GrantedBy == testOwnerID && params.OwnerWorkspaceID == testWorkspaceID
+				})).Return(db.Share{
+					ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+					TodoID:    pgID,
+					UserID:    pgtype.UUID{Bytes: userUUID, Valid: true},
+					Level:     "comment",
+					GrantedBy: testOwnerID,
+					CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+					UpdatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+				}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoShared && strings.Contains(string(params.Payload), `"user_id":"`+userUUID.String()+`"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "without a grantee",
+			share:         domain.Share{Level: domain.ShareLevelRead},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:  "with the todo's own workspace",
+			share: domain.Share{WorkspaceID: uuid.UUID(testWorkspaceID.Bytes).String(), Level: domain.ShareLevelRead},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			},
+			expectedErrIs: errShareOwnWorkspace,
+		},
+		{
+			name:  "user not found",
+			share: domain.Share{UserID: userUUID.String(), Level: domain.ShareLevelRead},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("UpsertShare", mock.Anything, mock.Anything).Return(db.Share{}, &pgconn.PgError{Code: pgForeignKeyViolation})
+			},
+			expectedErrIs: errShareUserNotFound,
+		},
+		{
+			name:  "todo shared with the user",
+			share: domain.Share{UserID: userUUID.String(), Level: domain.ShareLevelRead},
+			setupMock: func(mockDB *MockDBRepository) {
+				// only members of the todo's workspace can share it
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+				mockDB.On("GetTodoShareLevels", mock.Anything, mock.Anything).Return(db.GetTodoShareLevelsRow{Levels: []string{"edit"}}, nil)
+			},
+			expectedErrIs: domain.ErrTodoAccessDenied,
+		},
+		{
+			name:  "database error",
+			share: domain.Share{UserID: userUUID.String(), Level: domain.ShareLevelRead},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+				mockDB.On("UpsertShare", mock.Anything, mock.Anything).Return(db.Share{}, errors.New("db error"))
+			},
+			expectedError: "failed to save share to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			share, err := service.ShareTodo(ownerContext(), todoUUID.String(), tt.share)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, todoUUID.String(), share.TodoID)
+				assert.Equal(t, userUUID.String(), share.UserID)
+				assert.Equal(t, domain.ShareLevelComment, share.Level)
+				assert.Equal(t, uuid.UUID(testOwnerID.Bytes).String(), share.GrantedBy)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeShare(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	shareUUID := uuid.New()
+	workspaceUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "revokes the share",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteShare", mock.Anything, db.DeleteShareParams{TodoID: pgID, ID: pgtype.UUID{Bytes: shareUUID, Valid: true}}).Return(db.Share{
+					ID:          pgtype.UUID{Bytes: shareUUID, Valid: true},
+					TodoID:      pgID,
+					WorkspaceID: pgtype.UUID{Bytes: workspaceUUID, Valid: true},
+					Level:       "edit",
+				}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoUnshared && strings.Contains(string(params.Payload), `"workspace_id":"`+workspaceUUID.String()+`"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "share not found",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteShare", mock.Anything, mock.Anything).Return(db.Share{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrShareNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID}, nil)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.RevokeShare(ownerContext(), todoUUID.String(), shareUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShareList(t *testing.T) {
+	listUUID := uuid.New()
+	listID := pgtype.UUID{Bytes: listUUID, Valid: true}
+	workspaceUUID := uuid.New()
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "shares the list with a workspace",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetListState", mock.Anything, db.GetListStateParams{ID: listID, WorkspaceID: testWorkspaceID}).Return(db.GetListStateRow{}, nil)
+				mockDB.On("UpsertShare", mock.Anything, mock.MatchedBy(func(params db.UpsertShareParams) bool {
+					return params.ListID == listID && !params.TodoID.Valid && params.WorkspaceID.Bytes == workspaceUUID &&
+						params.Level == "edit" && params.OwnerWorkspaceID == testWorkspaceID
+				})).Return(db.Share{
+					ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+					ListID:      listID,
+					WorkspaceID: pgtype.UUID{Bytes: workspaceUUID, Valid: true},
+					Level:       "edit",
+					GrantedBy:   testOwnerID,
+					CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+					UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+				}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeListShared && strings.Contains(string(params.Payload), `"id":"`+listUUID.String()+`"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "list not found",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetListState", mock.Anything, mock.Anything).Return(db.GetListStateRow{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrListNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			share, err := service.ShareList(ownerContext(), listUUID.String(), domain.Share{WorkspaceID: workspaceUUID.String(), Level: domain.ShareLevelEdit})
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, listUUID.String(), share.ListID)
+				assert.Empty(t, share.TodoID)
+				assert.Equal(t, workspaceUUID.String(), share.WorkspaceID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeListShare(t *testing.T) {
+	listUUID := uuid.New()
+	listID := pgtype.UUID{Bytes: listUUID, Valid: true}
+	shareUUID := uuid.New()
+	userUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "revokes the share",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteListShare", mock.Anything, db.DeleteListShareParams{ListID: listID, ID: pgtype.UUID{Bytes: shareUUID, Valid: true}}).Return(db.Share{
+					ID:     pgtype.UUID{Bytes: shareUUID, Valid: true},
+					ListID: listID,
+					UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+					Level:  "read",
+				}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeListUnshared && strings.Contains(string(params.Payload), `"user_id":"`+userUUID.String()+`"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "share not found",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteListShare", mock.Anything, mock.Anything).Return(db.Share{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrShareNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("GetListState", mock.Anything, db.GetListStateParams{ID: listID, WorkspaceID: testWorkspaceID}).Return(db.GetListStateRow{}, nil)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.RevokeListShare(ownerContext(), listUUID.String(), shareUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSharedTodoAccess(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	otherWorkspaceID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	shared := db.TodoItem{ID: pgID, Description: "Plan the trip", Status: string(domain.TodoStatusOpen), Version: 1}
+
+	setupShare := func(mockDB *MockDBRepository, levels ...string) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+		mockDB.On("GetTodoShareLevels", mock.Anything, db.GetTodoShareLevelsParams{ID: pgID, UserID: testOwnerID}).Return(db.GetTodoShareLevelsRow{
+			WorkspaceID: otherWorkspaceID,
+			Levels:      levels,
+		}, nil)
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockDBRepository, *MockFileStorage)
+		call          func(*TodoService) error
+		expectedErrIs error
+	}{
+		{
+			name: "read share gets the todo",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				setupShare(mockDB, "read")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(shared, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+			},
+			call: func(service *TodoService) error {
+				todo, err := service.GetTodo(ownerContext(), todoUUID.String())
+				assert.Equal(t, "Plan the trip", todo.Description)
+				return err
+			},
+		},
+		{
+			name: "read share gets the todo without its subtasks",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				setupShare(mockDB, "read")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(shared, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ChecklistItem{
+					{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: pgID, Text: "Book flights", Done: true},
+				}, nil)
+			},
+			call: func(service *TodoService) error {
+				todo, err := service.GetTodoTree(ownerContext(), todoUUID.String())
+				assert.Equal(t, "Plan the trip", todo.Description)
+				assert.Len(t, todo.Checklist, 1)
+				assert.Empty(t, todo.Subtasks)
+				return err
+			},
+		},
+		{
+			name: "read share can't edit the todo",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				setupShare(mockDB, "read", "comment")
+			},
+			call: func(service *TodoService) error {
+				_, err := service.AddChecklistItem(ownerContext(), todoUUID.String(), domain.ChecklistItem{Text: "Book flights"})
+				return err
+			},
+			expectedErrIs: domain.ErrTodoAccessDenied,
+		},
+		{
+			name: "edit share stores files with the todo's workspace",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				setupShare(mockDB, "read", "edit")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(shared, nil)
+				fs.On("Upload", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, fileKeyPrefix(uuid.UUID(otherWorkspaceID.Bytes).String(), todoUUID.String()))
+				}), []byte("itinerary"), "text/plain").Return("file-key", nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.Anything).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+			call: func(service *TodoService) error {
+				_, err := service.AddAttachments(ownerContext(), todoUUID.String(), newFileUploads([]byte("itinerary")))
+				return err
+			},
+		},
+		{
+			name: "edit share replaces the todo keeping its parent, list and tags",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				setupShare(mockDB, "edit")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(shared, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ListTagsByTodoIDsRow{
+					{TodoID: pgID, ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "travel"},
+				}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.ID == pgID && params.Description == "Plan the trip to Rome"
+				})).Return(db.TodoItem{ID: pgID, Description: "Plan the trip to Rome", Status: string(domain.TodoStatusOpen), Version: 2}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+			call: func(service *TodoService) error {
+				description, empty, priority := "Plan the trip to Rome", "", domain.DefaultTodoPriority
+				dueDate := time.Now().Add(24 * time.Hour)
+				todo, err := service.UpdateTodo(ownerContext(), todoUUID.String(), domain.TodoItemPatch{
+					Description: &description,
+					DueDate:     &dueDate,
+					TimeZone:    &empty,
+					Tags:        &[]string{"Travel"},
+					Priority:    &priority,
+					Recurrence:  &empty,
+					ParentID:    &empty,
+					ListID:      &empty,
+				}, 0)
+				assert.Equal(t, []string{"travel"}, todo.TagNames())
+				return err
+			},
+		},
+		{
+			name: "edit share can't change the tags",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				setupShare(mockDB, "edit")
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: otherWorkspaceID}).Return(shared, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ListTagsByTodoIDsRow{}, nil)
+			},
+			call: func(service *TodoService) error {
+				_, err := service.UpdateTodo(ownerContext(), todoUUID.String(), domain.TodoItemPatch{Tags: &[]string{"travel"}}, 0)
+				return err
+			},
+			expectedErrIs: domain.ErrTodoAccessDenied,
+		},
+		{
+			name: "edit share can't change the status",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				// status changes reach other todos of the workspace
+				setupShare(mockDB, "edit")
+			},
+			call: func(service *TodoService) error {
+				_, err := service.TransitionTodo(ownerContext(), todoUUID.String(), domain.TodoStatusDone, 0)
+				return err
+			},
+			expectedErrIs: domain.ErrTodoAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := tt.call(service)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errParentNotFound = domain.NewValidationError("parentId", "the parent todo was not found")
+	errParentCycle    = domain.NewValidationError("parentId", "a todo can't be a subtask of its own subtasks")
+	errTodoTooDeep    = domain.NewValidationError("parentId", fmt.Sprintf("subtasks can be nested at most %d levels deep", domain.MaxTodoDepth))
+)
+
+// GetTodoTree returns the todo with its subtasks nested at every depth, the checklists of all of them and how far
+// each of them has progressed. Subtasks aren't shared along with their parent, so the users a todo of another
+// workspace is shared with get it with its checklist but without its subtasks.
+func (s *TodoService) GetTodoTree(ctx context.Context, id string) (domain.TodoItem, error) {
+	item, workspace, err := s.findTodo(ctx, id, domain.ShareLevelRead)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	todo, err := s.withAttachmentsAndTags(ctx, item)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	requestWorkspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	if workspace == requestWorkspace {
+		err = loadSubtree(ctx, s.todoRepository, &todo)
+	} else {
+		todo.Checklist, err = listChecklist(ctx, s.todoRepository, item.ID)
+	}
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	todo.CountProgress()
+	return todo, nil
+}
+
+// listChecklist returns the checklist of a single todo. q is either the repository or the caller's transaction.
+func listChecklist(ctx context.Context, q db.Querier, todoID pgtype.UUID) ([]domain.ChecklistItem, error) {
+	rows, err := q.ListChecklistItemsByTodoIDs(ctx, []pgtype.UUID{todoID})
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list checklist items from repository: %w", err))
+	}
+	var checklist []domain.ChecklistItem
+	for _, row := range rows {
+		checklist = append(checklist, toDomainChecklistItem(row))
+	}
+	return checklist, nil
+}
+
+// completeSubtasks applies the completion policy to the todo being completed as part of the caller's transaction.
+// Under the cascade policy its unfinished subtasks are completed, each with its own status change event and next
+// occurrence, unless one of them waits for an open blocker, and the unchecked items of its checklists are ticked off.
+func (s *TodoService) completeSubtasks(ctx context.Context, q db.Querier, todo domain.TodoItem, now time.Time) error {
+	if err := loadSubtree(ctx, q, &todo); err != nil {
+		return err
+	}
+	subtasks, err := todo.SubtasksToComplete(s.completionPolicy)
+	if err != nil {
+		return err
+	}
+
+	for _, subtask := range subtasks {
+		from := subtask.Status
+		if err := subtask.TransitionTo(domain.TodoStatusDone, now); err != nil {
+			return err
+		}
+		subtaskID := pgtype.UUID{Bytes: uuid.MustParse(subtask.ID), Valid: true}
+		if err := checkBlockers(ctx, q, subtaskID); err != nil {
+			return err
+		}
+		item, err := q.UpdateTodoStatus(ctx, db.UpdateTodoStatusParams{
+			ID:              subtaskID,
+			Status:          string(subtask.Status),
+			CompletedAt:     pgtype.Timestamptz{Time: now, Valid: true},
+			UpdatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+			ExpectedVersion: subtask.Version,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) { // someone else updated the subtask since we read it
+				return domain.ErrTodoVersionConflict
+			}
+			return databaseError(fmt.Errorf("failed to update subtask status in repository: %w", err))
+		}
+		completed := toDomainTodo(item)
+		completed.Attachments = subtask.Attachments
+		completed.Tags = subtask.Tags
+
+		err = s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoStatusChanged, domain.TodoItemLifecycleEvent{
+			Type:        domain.EventTypeTodoStatusChanged,
+			ID:          completed.ID,
+			From:        string(from),
+			To:          string(completed.Status),
+			CompletedAt: &completed.CompletedAt,
+			Version:     completed.Version,
+			ChangedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.createNextOccurrence(ctx, q, completed, now); err != nil {
+			return err
+		}
+	}
+
+	unchecked := uncheckedTodoIDs(todo)
+	if len(unchecked) == 0 {
+		return nil
+	}
+	err = q.CheckChecklistItems(ctx, db.CheckChecklistItemsParams{
+		TodoIds:   unchecked,
+		UpdatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to check checklist items in repository: %w", err))
+	}
+	return nil
+}
+
+// checkParent makes sure the todo can become a subtask of parentID without nesting in a cycle or deeper than
+// domain.MaxTodoDepth, height being the number of levels of the todo and its subtasks. It returns the parent ID to
+// store, which is empty for a top-level todo. The caller's transaction has to hold the hierarchy lock.
+func checkParent(ctx context.Context, q db.Querier, todoID pgtype.UUID, parentID string, height int32) (pgtype.UUID, error) {
+	if parentID == "" {
+		return pgtype.UUID{}, nil
+	}
+	parentUUID, err := uuid.Parse(parentID)
+	if err != nil {
+		return pgtype.UUID{}, errParentNotFound
+	}
+	parent := pgtype.UUID{Bytes: parentUUID, Valid: true}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	if _, err := q.GetTodo(ctx, db.GetTodoParams{ID: parent, WorkspaceID: workspace}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, errParentNotFound
+		}
+		return pgtype.UUID{}, databaseError(fmt.Errorf("failed to get parent todo from repository: %w", err))
+	}
+
+	ancestors, err := q.ListTodoAncestorIDs(ctx, parent)
+	if err != nil {
+		return pgtype.UUID{}, databaseError(fmt.Errorf("failed to list parent todos from repository: %w", err))
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == todoID {
+			return pgtype.UUID{}, errParentCycle
+		}
+	}
+	if len(ancestors)+int(height) > domain.MaxTodoDepth {
+		return pgtype.UUID{}, errTodoTooDeep
+	}
+	return parent, nil
+}
+
+// moveSubtree sets the new parent on the update of a todo, which moves together with its subtasks.
+func moveSubtree(ctx context.Context, q db.Querier, params *db.UpdateTodoParams, parentID string) error {
+	if parentID == "" {
+		params.ParentID = pgtype.UUID{}
+		return nil
+	}
+	if err := q.LockTodoHierarchy(ctx); err != nil {
+		return databaseError(fmt.Errorf("failed to lock todo hierarchy: %w", err))
+	}
+	height, err := q.GetSubtreeHeight(ctx, params.ID)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to measure subtasks in repository: %w", err))
+	}
+	params.ParentID, err = checkParent(ctx, q, params.ID, parentID, height)
+	return err
+}
+
+// loadSubtree nests the subtasks of the todo at every depth, with their attachments and tags, and loads the
+// checklists of all of them. q is either the repository or the caller's transaction.
+func loadSubtree(ctx context.Context, q db.Querier, todo *domain.TodoItem) error {
+	rootID := pgtype.UUID{Bytes: uuid.MustParse(todo.ID), Valid: true}
+	rows, err := q.ListSubtasks(ctx, rootID)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to list subtasks from repository: %w", err))
+	}
+
+	subtaskIDs := make([]pgtype.UUID, 0, len(rows))
+	children := make(map[[16]byte][]db.TodoItem, len(rows))
+	for _, row := range rows {
+		subtaskIDs = append(subtaskIDs, row.ID)
+		children[row.ParentID.Bytes] = append(children[row.ParentID.Bytes], row)
+	}
+
+	checklistRows, err := q.ListChecklistItemsByTodoIDs(ctx, append([]pgtype.UUID{rootID}, subtaskIDs...))
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to list checklist items from repository: %w", err))
+	}
+	checklists := make(map[[16]byte][]domain.ChecklistItem)
+	for _, row := range checklistRows {
+		checklists[row.TodoID.Bytes] = append(checklists[row.TodoID.Bytes], toDomainChecklistItem(row))
+	}
+	todo.Checklist = checklists[rootID.Bytes]
+	if len(rows) == 0 {
+		return nil
+	}
+
+	attachmentRows, err := q.ListAttachmentsByTodoIDs(ctx, subtaskIDs)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to list attachments from repository: %w", err))
+	}
+	attachments := make(map[[16]byte][]domain.Attachment, len(rows))
+	for _, row := range attachmentRows {
+		attachments[row.TodoID.Bytes] = append(attachments[row.TodoID.Bytes], toDomainAttachment(row))
+	}
+	tags, err := listTodoTags(ctx, q, subtaskIDs)
+	if err != nil {
+		return err
+	}
+
+	// a todo is only nested once, so a cycle in the stored parents can't recurse forever
+	nested := map[[16]byte]bool{rootID.Bytes: true}
+	var nest func(parentID [16]byte) []domain.TodoItem
+	nest = func(parentID [16]byte) []domain.TodoItem {
+		var subtasks []domain.TodoItem
+		for _, row := range children[parentID] {
+			if nested[row.ID.Bytes] {
+				continue
+			}
+			nested[row.ID.Bytes] = true
+			subtask := toDomainTodo(row)
+			subtask.Attachments = attachments[row.ID.Bytes]
+			subtask.Tags = tags[row.ID.Bytes]
+			subtask.Checklist = checklists[row.ID.Bytes]
+			subtask.Subtasks = nest(row.ID.Bytes)
+			subtasks = append(subtasks, subtask)
+		}
+		return subtasks
+	}
+	todo.Subtasks = nest(rootID.Bytes)
+	return nil
+}
+
+// listTodoProgress counts the progress of each of the todos, keyed by todo ID.
+func listTodoProgress(ctx context.Context, q db.Querier, todoIDs []pgtype.UUID) (map[[16]byte]domain.TodoProgress, error) {
+	rows, err := q.ListTodoProgress(ctx, todoIDs)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to count todo progress in repository: %w", err))
+	}
+	progress := make(map[[16]byte]domain.TodoProgress, len(rows))
+	for _, row := range rows {
+		progress[row.TodoID.Bytes] = domain.TodoProgress{Done: int(row.Done), Total: int(row.Total)}
+	}
+	return progress, nil
+}
+
+// uncheckedTodoIDs returns the IDs of the todo and the subtasks that have unchecked checklist items.
+func uncheckedTodoIDs(todo domain.TodoItem) []pgtype.UUID {
+	var ids []pgtype.UUID
+	for _, item := range todo.Checklist {
+		if !item.Done {
+			ids = append(ids, pgtype.UUID{Bytes: uuid.MustParse(todo.ID), Valid: true})
+			break
+		}
+	}
+	for _, subtask := range todo.Subtasks {
+		ids = append(ids, uncheckedTodoIDs(subtask)...)
+	}
+	return ids
+}
+
+// parentUUID converts the parent ID of a stored todo, an empty ID is a top-level todo.
+func parentUUID(parentID string) pgtype.UUID {
+	if parentID == "" {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: uuid.MustParse(parentID), Valid: true}
+}
+
+// parentIDString converts the parent ID of a stored todo, a top-level todo has an empty one.
+func parentIDString(parentID pgtype.UUID) string {
+	if !parentID.Valid {
+		return ""
+	}
+	return uuid.UUID(parentID.Bytes).String()
+}
@@ -0,0 +1,329 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTodoTree(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	childID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	grandchildID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	cancelledID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: pgID, Description: "Move house", Status: "open"}, nil)
+	mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+	mockDB.On("ListTagsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ListTagsByTodoIDsRow{}, nil)
+	mockDB.On("ListSubtasks", mock.Anything, pgID).Return([]db.TodoItem{
+		{ID: childID, ParentID: pgID, Description: "Pack", Status: "open"},
+		{ID: cancelledID, ParentID: pgID, Description: "Sell the sofa", Status: "cancelled"},
+		{ID: grandchildID, ParentID: childID, Description: "Buy boxes", Status: "done"},
+	}, nil)
+	mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, []pgtype.UUID{pgID, childID, cancelledID, grandchildID}).Return([]db.ChecklistItem{
+		{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: pgID, Text: "Book the van", Done: true},
+		{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: childID, Text: "Kitchen"},
+	}, nil)
+	mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, []pgtype.UUID{childID, cancelledID, grandchildID}).Return([]db.Attachment{}, nil)
+	mockDB.On("ListTagsByTodoIDs", mock.Anything, []pgtype.UUID{childID, cancelledID, grandchildID}).Return([]db.ListTagsByTodoIDsRow{
+		{TodoID: grandchildID, ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "shopping"},
+	}, nil)
+
+	service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+	todo, err := service.GetTodoTree(ownerContext(), todoUUID.String())
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, domain.TodoProgress{Done: 1, Total: 2}, todo.Progress)
+	assert.Len(t, todo.Checklist, 1)
+	if !assert.Len(t, todo.Subtasks, 2) {
+		return
+	}
+	child := todo.Subtasks[0]
+	assert.Equal(t, "Pack", child.Description)
+	assert.Equal(t, todoUUID.String(), child.ParentID)
+	assert.Equal(t, domain.TodoProgress{Done: 1, Total: 2}, child.Progress)
+	if assert.Len(t, child.Subtasks, 1) {
+		assert.Equal(t, "Buy boxes", child.Subtasks[0].Description)
+		assert.Equal(t, "shopping", child.Subtasks[0].Tags[0].Name)
+	}
+	assert.Equal(t, "Sell the sofa", todo.Subtasks[1].Description)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestCompleteTodoWithSubtasks(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	childID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	stored := db.TodoItem{ID: pgID, Description: "Move house", Status: string(domain.TodoStatusInProgress), Version: 2}
+
+	setupTree := func(mockDB *MockDBRepository, childStatus domain.TodoStatus) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+		mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+		mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+		mockDB.On("ListOpenBlockerIDs", mock.Anything, mock.Anything).Return([]pgtype.UUID{}, nil)
+		mockDB.On("ListSubtasks", mock.Anything, pgID).Return([]db.TodoItem{
+			{ID: childID, ParentID: pgID, Description: "Pack", Status: string(childStatus), Version: 5},
+		}, nil)
+		mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ChecklistItem{
+			{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: pgID, Text: "Book the van"},
+		}, nil)
+		mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+	}
+
+	tests := []struct {
+		name          string
+		policy        domain.CompletionPolicy
+		setupMocks    func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:   "blocked by an open subtask",
+			policy: domain.CompletionPolicyBlock,
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTree(mockDB, domain.TodoStatusOpen)
+			},
+			expectedErrIs: domain.ErrOpenSubtasks,
+		},
+		{
+			name:   "cascades to the subtask and the checklist",
+			policy: domain.CompletionPolicyCascade,
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTree(mockDB, domain.TodoStatusOpen)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoStatusParams) bool {
+					return params.ID == childID && params.Status == string(domain.TodoStatusDone) && params.ExpectedVersion == 5
+				})).Return(db.TodoItem{ID: childID, ParentID: pgID, Status: string(domain.TodoStatusDone), Version: 6}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"id":"`+uuid.UUID(childID.Bytes).String()+`"`)
+				})).Return(nil).Once()
+				mockDB.On("CheckChecklistItems", mock.Anything, mock.MatchedBy(func(params db.CheckChecklistItemsParams) bool {
+					return len(params.TodoIds) == 1 && params.TodoIds[0] == pgID
+				})).Return(nil)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoStatusParams) bool {
+					return params.ID == pgID && params.ExpectedVersion == 2
+				})).Return(db.TodoItem{ID: pgID, Status: string(domain.TodoStatusDone), Version: 3}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"id":"`+todoUUID.String()+`"`)
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:   "blocked subtask can't cascade",
+			policy: domain.CompletionPolicyCascade,
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTree(mockDB, domain.TodoStatusBlocked)
+			},
+			expectedErrIs: domain.ErrOpenSubtasks,
+		},
+		{
+			name:   "subtask changed meanwhile",
+			policy: domain.CompletionPolicyCascade,
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTree(mockDB, domain.TodoStatusOpen)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.Anything).Return(db.TodoItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrTodoVersionConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMocks(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, tt.policy, slog.Default())
+			todo, err := service.TransitionTodo(ownerContext(), todoUUID.String(), domain.TodoStatusDone, 2)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.TodoStatusDone, todo.Status)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMoveTodoUnderParent(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	parentUUID := uuid.New()
+	parentID := pgtype.UUID{Bytes: parentUUID, Valid: true}
+	stored := db.TodoItem{ID: pgID, Description: "Pack", Version: 3}
+
+	setupTodo := func(mockDB *MockDBRepository, height int32) {
+		mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+		mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+		mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+		mockDB.On("LockTodoHierarchy", mock.Anything).Return(nil)
+		mockDB.On("GetSubtreeHeight", mock.Anything, pgID).Return(height, nil)
+	}
+
+	tests := []struct {
+		name          string
+		parentID      string
+		setupMocks    func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:     "moves under the parent",
+			parentID: parentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, 2)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: parentID}, nil)
+				mockDB.On("ListTodoAncestorIDs", mock.Anything, parentID).Return([]pgtype.UUID{parentID}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.ParentID == parentID
+				})).Return(db.TodoItem{ID: pgID, ParentID: parentID, Version: 4}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"parent_id":"`+parentUUID.String()+`"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "parent not found",
+			parentID: parentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, 1)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: errParentNotFound,
+		},
+		{
+			name:     "under its own subtask",
+			parentID: parentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, 2)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: parentID, ParentID: pgID}, nil)
+				mockDB.On("ListTodoAncestorIDs", mock.Anything, parentID).Return([]pgtype.UUID{parentID, pgID}, nil)
+			},
+			expectedErrIs: errParentCycle,
+		},
+		{
+			name:     "too deep",
+			parentID: parentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, 2)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: parentID}, nil)
+				mockDB.On("ListTodoAncestorIDs", mock.Anything, parentID).Return([]pgtype.UUID{
+					parentID,
+					{Bytes: uuid.New(), Valid: true},
+					{Bytes: uuid.New(), Valid: true},
+				}, nil)
+			},
+			expectedErrIs: errTodoTooDeep,
+		},
+		{
+			name:     "database error",
+			parentID: parentUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository) {
+				setupTodo(mockDB, 1)
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: parentID}, nil)
+				mockDB.On("ListTodoAncestorIDs", mock.Anything, parentID).Return([]pgtype.UUID(nil), errors.New("db error"))
+			},
+			expectedError: "failed to list parent todos from repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMocks(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.UpdateTodo(ownerContext(), todoUUID.String(), domain.TodoItemPatch{ParentID: &tt.parentID}, 0)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.parentID, todo.ParentID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSubtask(t *testing.T) {
+	parentUUID := uuid.New()
+	parentID := pgtype.UUID{Bytes: parentUUID, Valid: true}
+
+	tests := []struct {
+		name          string
+		ancestors     int
+		setupMocks    func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:      "created under the parent",
+			ancestors: domain.MaxTodoDepth - 1,
+			setupMocks: func(mockDB *MockDBRepository) {
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.ParentID == parentID
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:          "parent at the maximum depth",
+			ancestors:     domain.MaxTodoDepth,
+			setupMocks:    func(_ *MockDBRepository) {},
+			expectedErrIs: errTodoTooDeep,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ancestors := []pgtype.UUID{parentID}
+			for len(ancestors) < tt.ancestors {
+				ancestors = append(ancestors, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+			}
+			mockDB := new(MockDBRepository)
+			mockDB.On("LockTodoHierarchy", mock.Anything).Return(nil)
+			mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: parentID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{ID: parentID}, nil)
+			mockDB.On("ListTodoAncestorIDs", mock.Anything, parentID).Return(ancestors, nil)
+			tt.setupMocks(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.CreateTodo(ownerContext(), domain.TodoItem{
+				ID:          uuid.New().String(),
+				Description: "Buy boxes",
+				DueDate:     time.Now().Add(24 * time.Hour),
+				ParentID:    parentUUID.String(),
+			}, nil)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, parentUUID.String(), todo.ParentID)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pgUniqueViolation is the Postgres error code of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+// CreateTag stores a new tag in the request's workspace, its name must not be taken by another tag of the workspace.
+func (s *TodoService) CreateTag(ctx context.Context, tag domain.Tag) (domain.Tag, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	tag.Normalize()
+	if err := tag.Validate(); err != nil {
+		return domain.Tag{}, fmt.Errorf("tag validation failed: %w", err)
+	}
+
+	tagUUID := uuid.New()
+	now := time.Now().UTC()
+	err = s.todoRepository.CreateTag(ctx, db.CreateTagParams{
+		ID:          pgtype.UUID{Bytes: tagUUID, Valid: true},
+		Name:        tag.Name,
+		Colour:      tag.Colour,
+		CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		WorkspaceID: workspace,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.Tag{}, domain.ErrTagNameTaken
+		}
+		return domain.Tag{}, databaseError(fmt.Errorf("failed to save tag to repository: %w", err))
+	}
+
+	tag.ID = tagUUID.String()
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+	return tag, nil
+}
+
+func (s *TodoService) GetTag(ctx context.Context, id string) (domain.Tag, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	tagUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.Tag{}, domain.ErrTagNotFound
+	}
+
+	row, err := s.todoRepository.GetTag(ctx, db.GetTagParams{ID: pgtype.UUID{Bytes: tagUUID, Valid: true}, WorkspaceID: workspace})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Tag{}, domain.ErrTagNotFound
+		}
+		return domain.Tag{}, databaseError(fmt.Errorf("failed to get tag from repository: %w", err))
+	}
+	return toDomainTag(row), nil
+}
+
+// ListTags returns all tags of the request's workspace ordered by name.
+func (s *TodoService) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.todoRepository.ListTags(ctx, workspace)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list tags from repository: %w", err))
+	}
+	tags := make([]domain.Tag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, toDomainTag(row))
+	}
+	return tags, nil
+}
+
+// UpdateTag replaces the name and the colour of a tag, renaming it renames it on every todo.
+func (s *TodoService) UpdateTag(ctx context.Context, id string, tag domain.Tag) (domain.Tag, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	tagUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.Tag{}, domain.ErrTagNotFound
+	}
+
+	tag.Normalize()
+	if err := tag.Validate(); err != nil {
+		return domain.Tag{}, fmt.Errorf("tag validation failed: %w", err)
+	}
+
+	row, err := s.todoRepository.UpdateTag(ctx, db.UpdateTagParams{
+		ID:          pgtype.UUID{Bytes: tagUUID, Valid: true},
+		Name:        tag.Name,
+		Colour:      tag.Colour,
+		UpdatedAt:   pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		WorkspaceID: workspace,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return domain.Tag{}, domain.ErrTagNotFound
+		case isUniqueViolation(err):
+			return domain.Tag{}, domain.ErrTagNameTaken
+		}
+		return domain.Tag{}, databaseError(fmt.Errorf("failed to update tag in repository: %w", err))
+	}
+	return toDomainTag(row), nil
+}
+
+// DeleteTag deletes a tag and removes it from every todo.
+func (s *TodoService) DeleteTag(ctx context.Context, id string) error {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	tagUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrTagNotFound
+	}
+
+	deleted, err := s.todoRepository.DeleteTag(ctx, db.DeleteTagParams{ID: pgtype.UUID{Bytes: tagUUID, Valid: true}, WorkspaceID: workspace})
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to delete tag in repository: %w", err))
+	}
+	if deleted == 0 {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+// saveTodoTags tags a todo of the workspace as part of the caller's transaction, tags that don't exist in the
+// workspace yet are created without a colour. With replace set the current tags of the todo are removed first, a new
+// todo has none. It returns the tags as stored.
+func saveTodoTags(ctx context.Context, q db.Querier, workspace, todoID pgtype.UUID, tags []domain.Tag, replace bool, now time.Time) ([]domain.Tag, error) {
+	if replace {
+		if err := q.DeleteTodoTags(ctx, todoID); err != nil {
+			return nil, databaseError(fmt.Errorf("failed to remove todo tags in repository: %w", err))
+		}
+	}
+
+	saved := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		row, err := q.UpsertTag(ctx, db.UpsertTagParams{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Name:        tag.Name,
+			CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			WorkspaceID: workspace,
+		})
+		if err != nil {
+			return nil, databaseError(fmt.Errorf("failed to save tag to repository: %w", err))
+		}
+		if err := q.AddTodoTag(ctx, db.AddTodoTagParams{TodoID: todoID, TagID: row.ID}); err != nil {
+			return nil, databaseError(fmt.Errorf("failed to save todo tag to repository: %w", err))
+		}
+		saved = append(saved, toDomainTag(row))
+	}
+	return saved, nil
+}
+
+// listTodoTags loads the tags of the todos, keyed by todo ID. q is either the repository or the caller's transaction.
+func listTodoTags(ctx context.Context, q db.Querier, todoIDs []pgtype.UUID) (map[[16]byte][]domain.Tag, error) {
+	rows, err := q.ListTagsByTodoIDs(ctx, todoIDs)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list todo tags from repository: %w", err))
+	}
+	tags := make(map[[16]byte][]domain.Tag, len(todoIDs))
+	for _, row := range rows {
+		tags[row.TodoID.Bytes] = append(tags[row.TodoID.Bytes], toDomainTag(db.Tag{
+			ID:        row.ID,
+			Name:      row.Name,
+			Colour:    row.Colour,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		}))
+	}
+	return tags, nil
+}
+
+// listTags loads the tags of a single todo, ordered by name.
+func listTags(ctx context.Context, q db.Querier, todoID pgtype.UUID) ([]domain.Tag, error) {
+	tags, err := listTodoTags(ctx, q, []pgtype.UUID{todoID})
+	if err != nil {
+		return nil, err
+	}
+	return tags[todoID.Bytes], nil
+}
+
+func toDomainTag(row db.Tag) domain.Tag {
+	return domain.Tag{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		Name:      row.Name,
+		Colour:    row.Colour,
+		CreatedAt: row.CreatedAt.Time.UTC(),
+		UpdatedAt: row.UpdatedAt.Time.UTC(),
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTag(t *testing.T) {
+	tests := []struct {
+		name          string
+		tag           domain.Tag
+		setupMock     func(*MockDBRepository)
+		expectedTag   domain.Tag
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name: "normalizes the name and the colour",
+			tag:  domain.Tag{Name: " Work ", Colour: "#FF8800"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateTag", mock.Anything, mock.MatchedBy(func(params db.CreateTagParams) bool {
+					return params.Name == "work" && params.Colour == "#ff8800" && params.ID.Valid
+				})).Return(nil)
+			},
+			expectedTag: domain.Tag{Name: "work", Colour: "#ff8800"},
+		},
+		{
+			name:          "invalid colour",
+			tag:           domain.Tag{Name: "work", Colour: "orange"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name: "name taken",
+			tag:  domain.Tag{Name: "work"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateTag", mock.Anything, mock.Anything).Return(&pgconn.PgError{Code: pgUniqueViolation})
+			},
+			expectedErrIs: domain.ErrTagNameTaken,
+		},
+		{
+			name: "database error",
+			tag:  domain.Tag{Name: "work"},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("CreateTag", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: "failed to save tag to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			tag, err := service.CreateTag(ownerContext(), tt.tag)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tag.ID)
+				assert.Equal(t, tt.expectedTag.Name, tag.Name)
+				assert.Equal(t, tt.expectedTag.Colour, tag.Colour)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	tagUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: tagUUID, Valid: true}
+
+	tests := []struct {
+		name          string
+		id            string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "renames the tag",
+			id:   tagUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("UpdateTag", mock.Anything, mock.MatchedBy(func(params db.UpdateTagParams) bool {
+					return params.ID == pgID && params.Name == "home"
+				})).Return(db.Tag{ID: pgID, Name: "home"}, nil)
+			},
+		},
+		{
+			name:          "invalid ID",
+			id:            "not-a-uuid",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrTagNotFound,
+		},
+		{
+			name: "tag not found",
+			id:   tagUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("UpdateTag", mock.Anything, mock.Anything).Return(db.Tag{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrTagNotFound,
+		},
+		{
+			name: "name taken",
+			id:   tagUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("UpdateTag", mock.Anything, mock.Anything).Return(db.Tag{}, &pgconn.PgError{Code: pgUniqueViolation})
+			},
+			expectedErrIs: domain.ErrTagNameTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			tag, err := service.UpdateTag(ownerContext(), tt.id, domain.Tag{Name: "Home"})
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tagUUID.String(), tag.ID)
+				assert.Equal(t, "home", tag.Name)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	tagUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: tagUUID, Valid: true}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name: "deletes the tag",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteTag", mock.Anything, db.DeleteTagParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "tag not found",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteTag", mock.Anything, db.DeleteTagParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(int64(0), nil)
+			},
+			expectedErrIs: domain.ErrTagNotFound,
+		},
+		{
+			name: "database error",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteTag", mock.Anything, db.DeleteTagParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(int64(0), errors.New("db error"))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.DeleteTag(ownerContext(), tagUUID.String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSaveTodoTags(t *testing.T) {
+	todoID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	workID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	now := time.Now().UTC()
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("DeleteTodoTags", mock.Anything, todoID).Return(nil)
+	mockDB.On("UpsertTag", mock.Anything, mock.MatchedBy(func(params db.UpsertTagParams) bool {
+		return params.Name == "work" && params.WorkspaceID == testWorkspaceID
+	})).Return(db.Tag{ID: workID, Name: "work", Colour: "#ff8800"}, nil)
+	mockDB.On("AddTodoTag", mock.Anything, db.AddTodoTagParams{TodoID: todoID, TagID: workID}).Return(nil)
+
+	tags, err := saveTodoTags(ownerContext(), mockDB, testWorkspaceID, todoID, []domain.Tag{{Name: "work"}}, true, now)
+
+	assert.NoError(t, err)
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, uuid.UUID(workID.Bytes).String(), tags[0].ID)
+		assert.Equal(t, "#ff8800", tags[0].Colour)
+	}
+	mockDB.AssertExpectations(t)
+}
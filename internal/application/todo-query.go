@@ -0,0 +1,96 @@
+package application
+
+import (
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TodoSort is the order ListTodos returns todos in.
+type TodoSort string
+
+const (
+	SortByCreatedAt TodoSort = "createdAt" // Newest first, the default
+	SortByDueDate   TodoSort = "dueDate"   // Soonest due first
+	SortByPriority  TodoSort = "priority"  // Most urgent first, then in manual order
+)
+
+var errInvalidSort = domain.NewValidationError("sort", "sort must be createdAt, dueDate or priority")
+
+// TodoQuery selects the todos returned by ListTodos. Zero fields don't filter, so the zero query lists every
+// todo from newest to oldest.
+type TodoQuery struct {
+	Statuses      []domain.TodoStatus // Any of the statuses
+	DueAfter      time.Time           // Due at or after, inclusive
+	DueBefore     time.Time           // Due before, exclusive
+	Overdue       bool                // Due in the past and neither done nor cancelled
+	HasAttachment *bool
+	Search        string   // Full-text search over the description, in web search syntax
+	Tags          []string // Tag names, todos with any of them match unless MatchAllTags is set
+	MatchAllTags  bool
+	ListID        string // Only the todos of the list, sorted in the list's default order unless Sort is set
+	Sort          TodoSort
+	Cursor        string // Returned as NextCursor by the previous page, empty for the first page
+	Limit         int
+}
+
+func (q TodoQuery) sort() (TodoSort, error) {
+	switch q.Sort {
+	case "", SortByCreatedAt:
+		return SortByCreatedAt, nil
+	case SortByDueDate, SortByPriority:
+		return q.Sort, nil
+	}
+	return "", errInvalidSort
+}
+
+func (s TodoSort) valid() bool {
+	switch s {
+	case SortByCreatedAt, SortByDueDate, SortByPriority:
+		return true
+	}
+	return false
+}
+
+func (q TodoQuery) pageSize() int {
+	if q.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(q.Limit, MaxPageSize)
+}
+
+// listParams turns the filters of the query into the repository parameters, now decides which todos are overdue.
+// The cursor and the page size are left to the caller.
+func (q TodoQuery) listParams(now time.Time) db.ListTodosParams {
+	var params db.ListTodosParams
+	for _, status := range q.Statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+	if !q.DueAfter.IsZero() {
+		params.DueAfter = pgtype.Timestamptz{Time: q.DueAfter, Valid: true}
+	}
+	if !q.DueBefore.IsZero() {
+		params.DueBefore = pgtype.Timestamptz{Time: q.DueBefore, Valid: true}
+	}
+	if q.Overdue {
+		params.OverdueAt = pgtype.Timestamptz{Time: now, Valid: true}
+	}
+	if q.HasAttachment != nil {
+		params.HasAttachment = pgtype.Bool{Bool: *q.HasAttachment, Valid: true}
+	}
+	if q.Search != "" {
+		params.Search = pgtype.Text{String: q.Search, Valid: true}
+	}
+	// duplicates would never all match, as a todo has every tag at most once
+	seen := make(map[string]bool, len(q.Tags))
+	for _, tag := range q.Tags {
+		if tag = domain.NormalizeTagName(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+	params.MatchAllTags = q.MatchAllTags
+	return params
+}
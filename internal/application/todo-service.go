@@ -1,99 +1,994 @@
 package application
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/a-berahman/todo-list/internal/domain"
 	"github.com/a-berahman/todo-list/internal/infra/db"
 	"github.com/a-berahman/todo-list/internal/ports/outbound"
-	"github.com/avast/retry-go"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	purgeBatchSize = 100
+
+	compensationTimeout = 10 * time.Second
+
+	outboxBatchSize      = 100
+	outboxBaseBackoff    = time.Second
+	outboxMaxBackoff     = 5 * time.Minute
+	outboxLease          = 2 * time.Minute  // How long claimed events are reserved for the relay that claimed them
+	outboxPublishTimeout = 10 * time.Second // Longest a single publish may take
+	outboxPruneBatchSize = 1000
+)
+
+// workspaceFilesPrefix is the storage prefix shared by the files of all workspaces. Files uploaded before todos
+// belonged to workspaces are kept under legacyTodoFilesPrefix.
+const (
+	workspaceFilesPrefix  = "workspaces/"
+	legacyTodoFilesPrefix = "todos/"
+)
+
+// Names of the dependencies reported in domain.DependencyError.
+const (
+	dependencyDatabase    = "database"
+	dependencyFileStorage = "file storage"
+)
+
+// Postgres error codes of statements rejected because of their data or the privileges of the connection, rather
+// than because the database is unavailable.
+const (
+	pgIntegrityConstraintViolationClass = "23"
+	pgInsufficientPrivilege             = "42501"
+)
+
+var (
+	ErrInvalidCursor = domain.NewError(domain.ErrValidation, "InvalidCursor", "invalid pagination cursor")
+
+	errInvalidTodoID = domain.NewValidationError("id", "invalid todo ID")
+)
+
 type TodoService struct {
 	todoRepository   outbound.DBRepository
 	fileStorage      outbound.FileStorage
 	messagePublisher outbound.MessagePublisher
+	uploadLimits     UploadLimits
+	completionPolicy domain.CompletionPolicy
 	logger           *slog.Logger
 }
 
-func NewTodoService(todoRepository outbound.DBRepository, fileStorage outbound.FileStorage, messagePublisher outbound.MessagePublisher, logger *slog.Logger) *TodoService {
-	return &TodoService{todoRepository: todoRepository, fileStorage: fileStorage, messagePublisher: messagePublisher, logger: logger}
+func NewTodoService(todoRepository outbound.DBRepository, fileStorage outbound.FileStorage, messagePublisher outbound.MessagePublisher, uploadLimits UploadLimits, completionPolicy domain.CompletionPolicy, logger *slog.Logger) *TodoService {
+	return &TodoService{todoRepository: todoRepository, fileStorage: fileStorage, messagePublisher: messagePublisher, uploadLimits: uploadLimits, completionPolicy: completionPolicy, logger: logger}
 }
 
-func (s *TodoService) CreateTodo(ctx context.Context, todo domain.TodoItem, fileData []byte) error {
+// CreateTodo stores a new todo together with its attachments and returns it as saved. A todo with a parent ID
+// is created as a subtask of that todo.
+func (s *TodoService) CreateTodo(ctx context.Context, todo domain.TodoItem, files []domain.FileUpload) (domain.TodoItem, error) {
 	ctx, cancel := context.WithTimeout(ctx, 1000*time.Second)
 	defer cancel()
 
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	// the create routes only require todos:write, files sent along need the scope of the attachment routes
+	if len(files) > 0 {
+		if err := domain.RequireScope(ctx, domain.ScopeAttachmentsWrite); err != nil {
+			return domain.TodoItem{}, err
+		}
+	}
+
+	if todo.Priority == "" {
+		todo.Priority = domain.DefaultTodoPriority
+	}
 	if err := todo.Validate(); err != nil {
 		s.logger.Error("todo validation failed", "error", err)
-		return fmt.Errorf("todo validation failed: %w", err)
+		return domain.TodoItem{}, fmt.Errorf("todo validation failed: %w", err)
 	}
 
 	todoUUID, err := uuid.Parse(todo.ID)
 	if err != nil {
-		return fmt.Errorf("invalid todo ID: %w", err)
+		return domain.TodoItem{}, errInvalidTodoID
 	}
 
-	if len(fileData) > 0 { // Handle file upload if file data is provided
-		fileKey := generateFileKey(todo.ID)
-		fileID, err := s.fileStorage.Upload(ctx, fileKey, fileData)
-		if err != nil {
-			s.logger.Error("failed to upload file", "error", err)
-			return fmt.Errorf("failed to upload file: %w", err)
-		}
-		todo.FileID = fileID
+	attachments, err := s.uploadFiles(ctx, uuid.UUID(workspace.Bytes).String(), todo.ID, files)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	attachmentIDs := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		attachmentIDs = append(attachmentIDs, attachment.ID)
 	}
 
 	now := time.Now().UTC()
 	createParams := db.CreateTodoParams{
 		ID:          pgtype.UUID{Bytes: todoUUID, Valid: true},
 		Description: todo.Description,
-		DueDate:     pgtype.Timestamp{Time: todo.DueDate, Valid: true},
-		FileID:      pgtype.Text{String: todo.FileID, Valid: true},
-		CreatedAt:   pgtype.Timestamp{Time: now, Valid: true},
-		UpdatedAt:   pgtype.Timestamp{Time: now, Valid: true},
-	}
-	if err := s.todoRepository.CreateTodo(ctx, createParams); err != nil {
-		return fmt.Errorf("failed to save todo to repository: %w", err)
+		DueDate:     pgtype.Timestamptz{Time: todo.DueDate, Valid: true},
+		Timezone:    pgtype.Text{String: todo.TimeZone, Valid: todo.TimeZone != ""},
+		Priority:    string(todo.Priority),
+		Recurrence:  pgtype.Text{String: todo.Recurrence, Valid: todo.Recurrence != ""},
+		CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		OwnerID:     owner,
+		WorkspaceID: workspace,
 	}
-
 	todoEvent := domain.TodoItemCreateEvent{
+		Type:        domain.EventTypeTodoCreated,
 		ID:          todo.ID,
 		Description: todo.Description,
 		DueDate:     todo.DueDate,
-		FileID:      todo.FileID,
+		TimeZone:    todo.TimeZone,
+		Attachments: attachmentIDs,
+		Tags:        todo.TagNames(),
+		Priority:    string(todo.Priority),
+		Recurrence:  todo.Recurrence,
+		ParentID:    todo.ParentID,
+		ListID:      todo.ListID,
+		Status:      string(domain.TodoStatusOpen),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 
-	if err := s.publishTodoEvent(ctx, todoEvent); err != nil {
-		s.logger.Warn("failed to publish todo event", "error", err)
+	// the todo, its attachments, its tags and its event are committed together so the event can't get lost
+	var tags []domain.Tag
+	err = s.execTx(ctx, func(q db.Querier) error {
+		if todo.ParentID != "" {
+			if err := q.LockTodoHierarchy(ctx); err != nil {
+				return databaseError(fmt.Errorf("failed to lock todo hierarchy: %w", err))
+			}
+			if createParams.ParentID, err = checkParent(ctx, q, createParams.ID, todo.ParentID, 1); err != nil {
+				return err
+			}
+		}
+		if createParams.ListID, err = checkList(ctx, q, todo.ListID); err != nil {
+			return err
+		}
+		// new todos go to the end of the manual order
+		rank, err := rankAtEnd(ctx, q)
+		if err != nil {
+			return err
+		}
+		createParams.Rank = rank
+		if err := q.CreateTodo(ctx, createParams); err != nil {
+			return databaseError(fmt.Errorf("failed to save todo to repository: %w", err))
+		}
+		if err := s.saveAttachments(ctx, q, attachments, false); err != nil {
+			return err
+		}
+		if tags, err = saveTodoTags(ctx, q, workspace, createParams.ID, todo.Tags, false, now); err != nil {
+			return err
+		}
+		return s.enqueueTodoEvent(ctx, q, todoEvent.Type, todoEvent)
+	})
+	if err != nil {
+		s.compensateUploads(ctx, attachments)
+		return domain.TodoItem{}, err
+	}
+
+	todo.DueDate = todo.DueDate.UTC()
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	todo.Version = 1
+	todo.Status = domain.TodoStatusOpen
+	todo.Rank = createParams.Rank
+	todo.Attachments = attachments
+	todo.Tags = tags
+	todo.Progress = domain.TodoProgress{}
+	return todo, nil
+}
+
+// GetTodo returns the todo with its attachments and tags. Todos of other workspaces are returned as well when they
+// are shared with the user.
+func (s *TodoService) GetTodo(ctx context.Context, id string) (domain.TodoItem, error) {
+	return s.getTodo(ctx, id, domain.ShareLevelRead)
+}
+
+// getTodo returns the todo with its attachments and tags, provided the request may do with it what needs the
+// required share level.
+func (s *TodoService) getTodo(ctx context.Context, id string, required domain.ShareLevel) (domain.TodoItem, error) {
+	item, _, err := s.findTodo(ctx, id, required)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return s.withAttachmentsAndTags(ctx, item)
+}
+
+// withAttachmentsAndTags converts a stored todo and adds its attachments and tags.
+func (s *TodoService) withAttachmentsAndTags(ctx context.Context, item db.TodoItem) (domain.TodoItem, error) {
+	var err error
+	todo := toDomainTodo(item)
+	todo.Attachments, err = listAttachments(ctx, s.todoRepository, item.ID)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	todo.Tags, err = listTags(ctx, s.todoRepository, item.ID)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return todo, nil
+}
+
+// findTodo looks the todo up among those of the request's workspace, whose members may do with them what their role
+// allows, and after that among the todos of other workspaces shared with the user, on their own or with their list,
+// at a level allowing what needs the required one. It returns the todo with the workspace it belongs to.
+func (s *TodoService) findTodo(ctx context.Context, id string, required domain.ShareLevel) (db.TodoItem, pgtype.UUID, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return db.TodoItem{}, pgtype.UUID{}, err
+	}
+	todoUUID, err := uuid.Parse(id)
+	if err != nil {
+		return db.TodoItem{}, pgtype.UUID{}, errInvalidTodoID
+	}
+
+	params := db.GetTodoParams{ID: pgtype.UUID{Bytes: todoUUID, Valid: true}, WorkspaceID: workspace}
+	item, err := s.todoRepository.GetTodo(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if params.WorkspaceID, err = s.sharedTodoWorkspace(ctx, params.ID, required); err != nil {
+			return db.TodoItem{}, pgtype.UUID{}, err
+		}
+		item, err = s.todoRepository.GetTodo(ctx, params)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.TodoItem{}, pgtype.UUID{}, domain.ErrTodoNotFound
+		}
+		return db.TodoItem{}, pgtype.UUID{}, databaseError(fmt.Errorf("failed to get todo from repository: %w", err))
+	}
+	return item, params.WorkspaceID, nil
+}
+
+// ListTodos returns the todos selected by query using keyset pagination on the sort key and the ID.
+// An empty cursor starts from the first page, a cursor only continues the sort order it was returned for.
+func (s *TodoService) ListTodos(ctx context.Context, query TodoQuery) (domain.TodoPage, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoPage{}, err
+	}
+	var list pgtype.UUID
+	if query.ListID != "" {
+		id, state, err := getListState(ctx, s.todoRepository, query.ListID)
+		if err != nil {
+			return domain.TodoPage{}, err
+		}
+		list = id
+		if query.Sort == "" {
+			query.Sort = TodoSort(state.DefaultSort)
+		}
+	}
+	sort, err := query.sort()
+	if err != nil {
+		return domain.TodoPage{}, err
+	}
+	limit := query.pageSize()
+
+	params := query.listParams(time.Now().UTC())
+	params.WorkspaceID = workspace
+	params.ListID = list
+	// fetch one extra row to find out whether there is a next page
+	params.PageSize = int32(limit + 1)
+	var cursorKeys []string
+	if query.Cursor != "" {
+		keys, id, err := decodeCursor(query.Cursor, sort)
+		if err != nil {
+			return domain.TodoPage{}, err
+		}
+		cursorKeys = keys
+		params.CursorID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	items, err := s.listTodoRows(ctx, sort, params, cursorKeys)
+	if err != nil {
+		return domain.TodoPage{}, err
+	}
+
+	var page domain.TodoPage
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[len(items)-1]
+		page.NextCursor = encodeCursor(sort, last.ID.Bytes, sortKeys(sort, last)...)
+	}
+
+	todoIDs := make([]pgtype.UUID, 0, len(items))
+	for _, item := range items {
+		todoIDs = append(todoIDs, item.ID)
+	}
+	// one query for the attachments of the whole page instead of one per todo
+	attachmentRows, err := s.todoRepository.ListAttachmentsByTodoIDs(ctx, todoIDs)
+	if err != nil {
+		return domain.TodoPage{}, databaseError(fmt.Errorf("failed to list attachments from repository: %w", err))
+	}
+	attachments := make(map[[16]byte][]domain.Attachment, len(items))
+	for _, row := range attachmentRows {
+		attachments[row.TodoID.Bytes] = append(attachments[row.TodoID.Bytes], toDomainAttachment(row))
+	}
+	tags, err := listTodoTags(ctx, s.todoRepository, todoIDs)
+	if err != nil {
+		return domain.TodoPage{}, err
+	}
+	progress, err := listTodoProgress(ctx, s.todoRepository, todoIDs)
+	if err != nil {
+		return domain.TodoPage{}, err
+	}
+
+	page.Items = make([]domain.TodoItem, 0, len(items))
+	for _, item := range items {
+		todo := toDomainTodo(item)
+		todo.Attachments = attachments[item.ID.Bytes]
+		todo.Tags = tags[item.ID.Bytes]
+		todo.Progress = progress[item.ID.Bytes]
+		page.Items = append(page.Items, todo)
+	}
+
+	return page, nil
+}
+
+// listTodoRows runs the list query of the sort order, cursorKeys are the sort keys decoded from the cursor.
+func (s *TodoService) listTodoRows(ctx context.Context, sort TodoSort, params db.ListTodosParams, cursorKeys []string) ([]db.TodoItem, error) {
+	var (
+		items []db.TodoItem
+		err   error
+	)
+	switch sort {
+	case SortByPriority:
+		byPriority := db.ListTodosByPriorityParams{
+			WorkspaceID:   params.WorkspaceID,
+			Statuses:      params.Statuses,
+			DueAfter:      params.DueAfter,
+			DueBefore:     params.DueBefore,
+			OverdueAt:     params.OverdueAt,
+			HasAttachment: params.HasAttachment,
+			Search:        params.Search,
+			ListID:        params.ListID,
+			Tags:          params.Tags,
+			MatchAllTags:  params.MatchAllTags,
+			CursorID:      params.CursorID,
+			PageSize:      params.PageSize,
+		}
+		if cursorKeys != nil {
+			if len(cursorKeys) != 2 {
+				return nil, ErrInvalidCursor
+			}
+			byPriority.CursorPriority = pgtype.Text{String: cursorKeys[0], Valid: true}
+			byPriority.CursorRank = pgtype.Text{String: cursorKeys[1], Valid: true}
+		}
+		items, err = s.todoRepository.ListTodosByPriority(ctx, byPriority)
+	default:
+		if cursorKeys != nil {
+			key, ok := cursorTime(cursorKeys)
+			if !ok {
+				return nil, ErrInvalidCursor
+			}
+			params.CursorKey = pgtype.Timestamptz{Time: key, Valid: true}
+		}
+		if sort == SortByDueDate {
+			items, err = s.todoRepository.ListTodosByDueDate(ctx, db.ListTodosByDueDateParams(params))
+		} else {
+			items, err = s.todoRepository.ListTodos(ctx, params)
+		}
+	}
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list todos from repository: %w", err))
+	}
+	return items, nil
+}
+
+// UpdateTodo applies the patch to an existing todo, its attachments are managed with AddAttachments and RemoveAttachment.
+// Changing the parent or the list moves the todo together with its subtasks. Users the todo is shared with for editing
+// can change everything but its parent, its list and its tags, all of which refer to other rows of the todo's workspace;
+// sending them unchanged is fine. A non-zero expectedVersion must match the stored version, otherwise
+// domain.ErrTodoVersionConflict is returned.
+func (s *TodoService) UpdateTodo(ctx context.Context, id string, patch domain.TodoItemPatch, expectedVersion int32) (domain.TodoItem, error) {
+	item, workspace, err := s.findTodo(ctx, id, domain.ShareLevelEdit)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	current, err := s.withAttachmentsAndTags(ctx, item)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	requestWorkspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return domain.TodoItem{}, fmt.Errorf("%w: expected version %d, current version %d", domain.ErrTodoVersionConflict, expectedVersion, current.Version)
+	}
+
+	todo := current
+	if err := todo.ApplyPatch(patch); err != nil {
+		return domain.TodoItem{}, fmt.Errorf("todo validation failed: %w", err)
+	}
+	tagsChanged := !todo.HasSameTags(current)
+	if workspace != requestWorkspace && (todo.ParentID != current.ParentID || todo.ListID != current.ListID || tagsChanged) {
+		return domain.TodoItem{}, domain.ErrTodoAccessDenied
+	}
+
+	updateParams := db.UpdateTodoParams{
+		ID:              pgtype.UUID{Bytes: uuid.MustParse(todo.ID), Valid: true},
+		Description:     todo.Description,
+		DueDate:         pgtype.Timestamptz{Time: todo.DueDate, Valid: true},
+		Timezone:        pgtype.Text{String: todo.TimeZone, Valid: todo.TimeZone != ""},
+		Priority:        string(todo.Priority),
+		Recurrence:      pgtype.Text{String: todo.Recurrence, Valid: todo.Recurrence != ""},
+		ParentID:        parentUUID(current.ParentID),
+		ListID:          listUUID(current.ListID),
+		UpdatedAt:       pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		ExpectedVersion: current.Version,
+	}
+	var updated domain.TodoItem
+	err = s.execTx(ctx, func(q db.Querier) error {
+		if todo.ParentID != current.ParentID {
+			if err := moveSubtree(ctx, q, &updateParams, todo.ParentID); err != nil {
+				return err
+			}
+		}
+		if todo.ListID != current.ListID {
+			if updateParams.ListID, err = checkList(ctx, q, todo.ListID); err != nil {
+				return err
+			}
+		}
+		item, err := q.UpdateTodo(ctx, updateParams)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) { // someone else updated the row since we read it
+				return domain.ErrTodoVersionConflict
+			}
+			return databaseError(fmt.Errorf("failed to update todo in repository: %w", err))
+		}
+		updated = toDomainTodo(item)
+		updated.Attachments = current.Attachments
+		updated.Tags = current.Tags
+		if updated.ListID != current.ListID {
+			err := q.MoveSubtasksToList(ctx, db.MoveSubtasksToListParams{ParentID: item.ID, ListID: item.ListID, UpdatedAt: item.UpdatedAt})
+			if err != nil {
+				return databaseError(fmt.Errorf("failed to move subtasks to list in repository: %w", err))
+			}
+		}
+		if tagsChanged {
+			// only members change the tags, so the todo belongs to the request's workspace
+			if updated.Tags, err = saveTodoTags(ctx, q, workspace, item.ID, todo.Tags, true, updated.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoUpdated, domain.TodoItemUpdateEvent{
+			Type:        domain.EventTypeTodoUpdated,
+			ID:          updated.ID,
+			Description: updated.Description,
+			DueDate:     updated.DueDate,
+			TimeZone:    updated.TimeZone,
+			Tags:        updated.TagNames(),
+			Priority:    string(updated.Priority),
+			Recurrence:  updated.Recurrence,
+			ParentID:    updated.ParentID,
+			ListID:      updated.ListID,
+			Version:     updated.Version,
+			UpdatedAt:   updated.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return updated, nil
+}
+
+// TransitionTodo moves the todo to the given status if the lifecycle allows it. A todo can't be completed while it
+// waits for open blockers. Completing a todo with unfinished subtasks or checklist items fails or completes them as
+// well, depending on the completion policy. Completing a recurring todo creates its next occurrence in the same
+// transaction. Since all of that reaches other todos of the todo's workspace, only its members can change the status.
+// A non-zero expectedVersion must match the stored version, otherwise domain.ErrTodoVersionConflict is returned.
+func (s *TodoService) TransitionTodo(ctx context.Context, id string, status domain.TodoStatus, expectedVersion int32) (domain.TodoItem, error) {
+	current, err := s.getTodo(ctx, id, membersOnly)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return domain.TodoItem{}, fmt.Errorf("%w: expected version %d, current version %d", domain.ErrTodoVersionConflict, expectedVersion, current.Version)
+	}
+
+	now := time.Now().UTC()
+	todo := current
+	if err := todo.TransitionTo(status, now); err != nil {
+		return domain.TodoItem{}, err
+	}
+
+	statusParams := db.UpdateTodoStatusParams{
+		ID:              pgtype.UUID{Bytes: uuid.MustParse(todo.ID), Valid: true},
+		Status:          string(todo.Status),
+		CompletedAt:     pgtype.Timestamptz{Time: todo.CompletedAt, Valid: !todo.CompletedAt.IsZero()},
+		UpdatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+		ExpectedVersion: current.Version,
+	}
+	var updated domain.TodoItem
+	err = s.execTx(ctx, func(q db.Querier) error {
+		if todo.Status == domain.TodoStatusDone {
+			if err := checkBlockers(ctx, q, statusParams.ID); err != nil {
+				return err
+			}
+			if err := s.completeSubtasks(ctx, q, todo, now); err != nil {
+				return err
+			}
+		}
+		item, err := q.UpdateTodoStatus(ctx, statusParams)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) { // someone else updated the row since we read it
+				return domain.ErrTodoVersionConflict
+			}
+			return databaseError(fmt.Errorf("failed to update todo status in repository: %w", err))
+		}
+		updated = toDomainTodo(item)
+		updated.Attachments = current.Attachments
+		updated.Tags = current.Tags
+
+		todoEvent := domain.TodoItemLifecycleEvent{
+			Type:      domain.EventTypeTodoStatusChanged,
+			ID:        updated.ID,
+			From:      string(current.Status),
+			To:        string(updated.Status),
+			Version:   updated.Version,
+			ChangedAt: now,
+		}
+		if !updated.CompletedAt.IsZero() {
+			todoEvent.CompletedAt = &updated.CompletedAt
+		}
+		if err := s.enqueueTodoEvent(ctx, q, todoEvent.Type, todoEvent); err != nil {
+			return err
+		}
+
+		if updated.Status != domain.TodoStatusDone {
+			return nil
+		}
+		return s.createNextOccurrence(ctx, q, updated, now)
+	})
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return updated, nil
+}
+
+// DeleteTodo moves the todo and its subtasks to the trash, they can be restored until the trash is purged.
+func (s *TodoService) DeleteTodo(ctx context.Context, id string) error {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	todoUUID, err := uuid.Parse(id)
+	if err != nil {
+		return errInvalidTodoID
+	}
+
+	now := time.Now().UTC()
+	return s.execTx(ctx, func(q db.Querier) error {
+		deleted, err := q.SoftDeleteTodo(ctx, db.SoftDeleteTodoParams{
+			ID:          pgtype.UUID{Bytes: todoUUID, Valid: true},
+			DeletedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			WorkspaceID: workspace,
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to delete todo in repository: %w", err))
+		}
+		if deleted == 0 {
+			return domain.ErrTodoNotFound
+		}
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoDeleted, domain.TodoItemDeleteEvent{
+			Type:      domain.EventTypeTodoDeleted,
+			ID:        id,
+			DeletedAt: now,
+		})
+	})
+}
+
+// RestoreTodo brings a todo back from the trash together with the subtasks deleted along with it.
+func (s *TodoService) RestoreTodo(ctx context.Context, id string) (domain.TodoItem, error) {
+	workspace, err := workspaceID(ctx)
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	todoUUID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.TodoItem{}, errInvalidTodoID
+	}
+
+	now := time.Now().UTC()
+	var restored domain.TodoItem
+	err = s.execTx(ctx, func(q db.Querier) error {
+		// the subtasks are found by the deletion time of the todo, so they go first
+		err := q.RestoreSubtasks(ctx, db.RestoreSubtasksParams{
+			ID:          pgtype.UUID{Bytes: todoUUID, Valid: true},
+			UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			WorkspaceID: workspace,
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to restore subtasks in repository: %w", err))
+		}
+		item, err := q.RestoreTodo(ctx, db.RestoreTodoParams{
+			ID:          pgtype.UUID{Bytes: todoUUID, Valid: true},
+			UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			WorkspaceID: workspace,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) { // never existed, already purged or not in the trash
+				return domain.ErrTodoNotFound
+			}
+			return databaseError(fmt.Errorf("failed to restore todo in repository: %w", err))
+		}
+		restored = toDomainTodo(item)
+		if restored.Attachments, err = listAttachments(ctx, q, item.ID); err != nil {
+			return err
+		}
+		if restored.Tags, err = listTags(ctx, q, item.ID); err != nil {
+			return err
+		}
+
+		return s.enqueueTodoEvent(ctx, q, domain.EventTypeTodoRestored, domain.TodoItemRestoreEvent{
+			Type:       domain.EventTypeTodoRestored,
+			ID:         id,
+			RestoredAt: now,
+		})
+	})
+	if err != nil {
+		return domain.TodoItem{}, err
+	}
+	return restored, nil
+}
+
+// PurgeDeletedTodos permanently removes todos trashed before deletedBefore together with their files.
+// A todo that fails to purge is logged and left in the trash for the next run, the others are still purged. It
+// returns the number of purged todos and the failures joined together.
+func (s *TodoService) PurgeDeletedTodos(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	var failures []error
+	params := db.ListPurgeableTodosParams{
+		DeletedBefore: pgtype.Timestamptz{Time: deletedBefore, Valid: true},
+		BatchSize:     purgeBatchSize,
+	}
+	for {
+		todos, err := s.todoRepository.ListPurgeableTodos(ctx, params)
+		if err != nil {
+			return purged, errors.Join(append(failures, fmt.Errorf("failed to list purgeable todos: %w", err))...)
+		}
+
+		for _, todo := range todos {
+			if err := s.purgeTodo(ctx, todo); err != nil {
+				s.logger.Error("failed to purge todo, skipping it", "error", err, "todoID", uuid.UUID(todo.ID.Bytes).String())
+				failures = append(failures, err)
+				continue
+			}
+			purged++
+		}
+
+		if len(todos) < purgeBatchSize {
+			return purged, errors.Join(failures...)
+		}
+		last := todos[len(todos)-1]
+		params.CursorDeletedAt, params.CursorID = last.DeletedAt, last.ID
+	}
+}
+
+// purgeTodo deletes the files of a trashed todo and then the todo itself.
+func (s *TodoService) purgeTodo(ctx context.Context, todo db.ListPurgeableTodosRow) error {
+	todoID := uuid.UUID(todo.ID.Bytes).String()
+	// files go first, a failure leaves the row in the trash so the next run retries it
+	if err := s.deleteTodoFiles(ctx, todo.WorkspaceID, todo.ID); err != nil {
+		return fmt.Errorf("failed to delete files of todo %s: %w", todoID, err)
+	}
+	if err := s.todoRepository.PurgeTodo(ctx, todo.ID); err != nil {
+		return fmt.Errorf("failed to purge todo %s: %w", todoID, err)
 	}
 	return nil
 }
 
-func (s *TodoService) publishTodoEvent(ctx context.Context, event domain.TodoItemCreateEvent) error {
-	eventJSON, err := json.Marshal(event)
+// deleteTodoFiles deletes the files stored under the prefix of the todo and those its attachments share with the
+// todo it recurs from. Files still referred to by attachments of other todos are kept. Files uploaded under the
+// legacy prefix are found through the attachments referring to them.
+func (s *TodoService) deleteTodoFiles(ctx context.Context, workspaceID, id pgtype.UUID) error {
+	files, err := s.fileStorage.List(ctx, fileKeyPrefix(uuid.UUID(workspaceID.Bytes).String(), uuid.UUID(id.Bytes).String()))
+	if err != nil {
+		return err
+	}
+	attachments, err := s.todoRepository.ListAttachments(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(files)+len(attachments))
+	seen := make(map[string]bool, len(files)+len(attachments))
+	for _, file := range files {
+		seen[file.Key] = true
+		keys = append(keys, file.Key)
+	}
+	for _, attachment := range attachments {
+		if !seen[attachment.StorageKey] {
+			seen[attachment.StorageKey] = true
+			keys = append(keys, attachment.StorageKey)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	shared, err := s.todoRepository.ListSharedStorageKeys(ctx, db.ListSharedStorageKeysParams{StorageKeys: keys, TodoID: id})
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool, len(shared))
+	for _, key := range shared {
+		keep[key] = true
+	}
+
+	for _, key := range keys {
+		if keep[key] {
+			continue
+		}
+		if err := s.fileStorage.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compensateUploads removes files whose attachments could not be saved, so they don't stay in the bucket unreferenced.
+// A failure is only logged, ReconcileFiles picks up whatever is left behind.
+func (s *TodoService) compensateUploads(ctx context.Context, attachments []domain.Attachment) {
+	if len(attachments) == 0 {
+		return
+	}
+
+	// the request context may already be cancelled or timed out, which is often why saving failed
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	for _, attachment := range attachments {
+		if err := s.fileStorage.Delete(ctx, attachment.StorageKey); err != nil {
+			s.logger.Error("failed to delete orphaned file", "error", err, "key", attachment.StorageKey)
+			continue
+		}
+		s.logger.Info("deleted orphaned file after failed save", "key", attachment.StorageKey)
+	}
+}
+
+// RelayOutboxEvents publishes pending outbox events and returns how many were sent. The events are leased to the
+// relay rather than locked, so no transaction stays open while they are published, and each event is marked on its
+// own once published. Events that fail are retried by a later run with exponential backoff, and those the relay
+// didn't get to or couldn't mark are claimed again when the lease ends, so every event is delivered at least once.
+func (s *TodoService) RelayOutboxEvents(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	leasedUntil := now.Add(outboxLease)
+	events, err := s.todoRepository.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+		LeasedUntil:     pgtype.Timestamp{Time: leasedUntil, Valid: true},
+		AvailableBefore: pgtype.Timestamp{Time: now, Valid: true},
+		BatchSize:       outboxBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	slices.SortFunc(events, func(a, b db.Outbox) int { return cmp.Compare(a.ID, b.ID) })
+
+	sent := 0
+	var failures []error
+	for _, event := range events {
+		// past the end of the lease another relay may claim the event too, the rest is left to it
+		if time.Until(leasedUntil) < outboxPublishTimeout {
+			break
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+		err := s.messagePublisher.Publish(publishCtx, string(event.Payload))
+		cancel()
+		if err != nil {
+			s.logger.Warn("failed to publish todo event", "error", err, "outboxID", event.ID, "attempts", event.Attempts+1)
+			failedParams := db.MarkOutboxEventFailedParams{
+				ID:          event.ID,
+				LastError:   pgtype.Text{String: err.Error(), Valid: true},
+				AvailableAt: pgtype.Timestamp{Time: time.Now().UTC().Add(outboxBackoff(event.Attempts + 1)), Valid: true},
+			}
+			if err := s.todoRepository.MarkOutboxEventFailed(ctx, failedParams); err != nil {
+				failures = append(failures, fmt.Errorf("failed to mark outbox event %d as failed: %w", event.ID, err))
+			}
+			continue
+		}
+
+		sentParams := db.MarkOutboxEventSentParams{
+			ID:     event.ID,
+			SentAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		}
+		if err := s.todoRepository.MarkOutboxEventSent(ctx, sentParams); err != nil {
+			// only this event is published again, once its lease ends
+			failures = append(failures, fmt.Errorf("failed to mark outbox event %d as sent: %w", event.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(failures...)
+}
+
+// PruneOutboxEvents deletes the outbox events sent before sentBefore and returns how many were deleted.
+func (s *TodoService) PruneOutboxEvents(ctx context.Context, sentBefore time.Time) (int64, error) {
+	var pruned int64
+	for {
+		deleted, err := s.todoRepository.DeleteSentOutboxEvents(ctx, db.DeleteSentOutboxEventsParams{
+			SentBefore: pgtype.Timestamp{Time: sentBefore, Valid: true},
+			BatchSize:  outboxPruneBatchSize,
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune outbox events: %w", err)
+		}
+		pruned += deleted
+
+		if deleted < outboxPruneBatchSize {
+			return pruned, nil
+		}
+	}
+}
+
+// execTx runs fn in a transaction of the repository. Errors returned by fn are passed through as they are,
+// failing to begin or commit the transaction means the database is unavailable.
+func (s *TodoService) execTx(ctx context.Context, fn func(q db.Querier) error) error {
+	var fnErr error
+	err := s.todoRepository.ExecTx(ctx, func(q db.Querier) error {
+		fnErr = fn(q)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return databaseError(fmt.Errorf("failed to run transaction: %w", err))
+	}
+	return err
+}
+
+// databaseError reports a failed database call. Statements the database rejects because they violate a constraint
+// or a row-level security policy are reported as the caller's mistake, anything else as the database being unavailable.
+func databaseError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return domain.Unavailable(dependencyDatabase, err)
+	}
+
+	var constraint string
+	if pgErr.ConstraintName != "" {
+		constraint = ": " + pgErr.ConstraintName
+	}
+	switch {
+	case pgErr.Code == pgUniqueViolation:
+		return domain.NewError(domain.ErrConflict, "DuplicateRecord", "the change conflicts with an existing record"+constraint)
+	case strings.HasPrefix(pgErr.Code, pgIntegrityConstraintViolationClass):
+		return domain.NewError(domain.ErrUnprocessable, "ConstraintViolation", "the change violates a constraint"+constraint)
+	case pgErr.Code == pgInsufficientPrivilege:
+		return domain.NewError(domain.ErrForbidden, "RecordAccessDenied", "the change touches records the request may not access")
+	}
+	return domain.Unavailable(dependencyDatabase, err)
+}
+
+// enqueueTodoEvent stores the event in the outbox as part of the caller's transaction.
+func (s *TodoService) enqueueTodoEvent(ctx context.Context, q db.Querier, eventType string, event any) error {
+	payload, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal todo event: %w", err)
 	}
-	// TODO: we need to make this configurable
-	return retry.Do(
-		func() error {
-			return s.messagePublisher.Publish(ctx, string(eventJSON))
-		},
-		retry.Attempts(3),
-		retry.Delay(500*time.Millisecond),
-		retry.LastErrorOnly(true),
-	)
+
+	err = q.InsertOutboxEvent(ctx, db.InsertOutboxEventParams{
+		EventType: eventType,
+		Payload:   payload,
+		CreatedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to save todo event to outbox: %w", err))
+	}
+	return nil
+}
+
+// outboxBackoff doubles the delay with every failed attempt, up to outboxMaxBackoff.
+func outboxBackoff(attempts int32) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := int32(1); i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, outboxMaxBackoff)
 }
 
-func generateFileKey(todoID string) string {
-	return fmt.Sprintf("todos/%s/%s", todoID, uuid.New().String())
+// fileKeyPrefix is the storage prefix under which all files of a todo are kept. The files of a workspace share a
+// prefix, so they can be listed, moved or removed together.
+func fileKeyPrefix(workspaceID, todoID string) string {
+	return workspaceFilesPrefix + workspaceID + "/todos/" + todoID + "/"
+}
+
+// toDomainTodo converts a stored todo, times are returned in UTC whatever the zone of the database session.
+func toDomainTodo(item db.TodoItem) domain.TodoItem {
+	return domain.TodoItem{
+		ID:          uuid.UUID(item.ID.Bytes).String(),
+		Description: item.Description,
+		DueDate:     item.DueDate.Time.UTC(),
+		TimeZone:    item.Timezone.String,
+		Priority:    domain.TodoPriority(item.Priority),
+		Rank:        item.Rank,
+		Recurrence:  item.Recurrence.String,
+		ParentID:    parentIDString(item.ParentID),
+		ListID:      listIDString(item.ListID),
+		CreatedAt:   item.CreatedAt.Time.UTC(),
+		UpdatedAt:   item.UpdatedAt.Time.UTC(),
+		Version:     item.Version,
+		Status:      domain.TodoStatus(item.Status),
+		CompletedAt: item.CompletedAt.Time.UTC(),
+	}
+}
+
+// listAttachments loads the attachments of a todo, q is either the repository or the caller's transaction.
+func listAttachments(ctx context.Context, q db.Querier, todoID pgtype.UUID) ([]domain.Attachment, error) {
+	rows, err := q.ListAttachments(ctx, todoID)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list attachments from repository: %w", err))
+	}
+	attachments := make([]domain.Attachment, 0, len(rows))
+	for _, row := range rows {
+		attachments = append(attachments, toDomainAttachment(row))
+	}
+	return attachments, nil
+}
+
+// encodeCursor encodes the position after a todo, keys are the values of the sort keys of the todo.
+func encodeCursor(sort TodoSort, id uuid.UUID, keys ...string) string {
+	raw := string(sort) + "|" + strings.Join(keys, "|") + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor returns the sort keys and the ID of the todo the cursor continues after.
+func decodeCursor(cursor string, sort TodoSort) ([]string, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) < 3 || parts[0] != string(sort) {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(parts[len(parts)-1])
+	if err != nil {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+
+	return parts[1 : len(parts)-1], id, nil
+}
+
+// sortKeys returns the values of the sort keys of a todo in the order they are compared.
+func sortKeys(sort TodoSort, item db.TodoItem) []string {
+	switch sort {
+	case SortByDueDate:
+		return []string{item.DueDate.Time.UTC().Format(time.RFC3339Nano)}
+	case SortByPriority:
+		return []string{item.Priority, item.Rank}
+	}
+	return []string{item.CreatedAt.Time.UTC().Format(time.RFC3339Nano)}
+}
+
+// cursorTime parses the key of a cursor of a sort order by time.
+func cursorTime(keys []string) (time.Time, bool) {
+	if len(keys) != 1 {
+		return time.Time{}, false
+	}
+	key, err := time.Parse(time.RFC3339Nano, keys[0])
+	return key, err == nil
 }
@@ -1,15 +1,25 @@
 package application
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/a-berahman/todo-list/internal/domain"
 	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,200 +34,1943 @@ func (m *MockDBRepository) CreateTodo(ctx context.Context, arg db.CreateTodoPara
 	return args.Error(0)
 }
 
+func (m *MockDBRepository) GetTodo(ctx context.Context, arg db.GetTodoParams) (db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) HasNextOccurrence(ctx context.Context, recursFrom pgtype.UUID) (bool, error) {
+	args := m.Called(ctx, recursFrom)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTodos(ctx context.Context, arg db.ListTodosParams) ([]db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTodosByDueDate(ctx context.Context, arg db.ListTodosByDueDateParams) ([]db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateTodo(ctx context.Context, arg db.UpdateTodoParams) (db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTodosByPriority(ctx context.Context, arg db.ListTodosByPriorityParams) ([]db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateTodoRank(ctx context.Context, arg db.UpdateTodoRankParams) (db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) LockTodoRanks(ctx context.Context, workspaceID pgtype.UUID) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetLastTodoRank(ctx context.Context, workspaceID pgtype.UUID) (string, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDBRepository) GetPreviousTodoRank(ctx context.Context, arg db.GetPreviousTodoRankParams) (string, error) {
+	args := m.Called(ctx, arg)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDBRepository) GetNextTodoRank(ctx context.Context, arg db.GetNextTodoRankParams) (string, error) {
+	args := m.Called(ctx, arg)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateTodoStatus(ctx context.Context, arg db.UpdateTodoStatusParams) (db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) SoftDeleteTodo(ctx context.Context, arg db.SoftDeleteTodoParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) RestoreTodo(ctx context.Context, arg db.RestoreTodoParams) (db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) RestoreSubtasks(ctx context.Context, arg db.RestoreSubtasksParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) LockTodoHierarchy(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetSubtreeHeight(ctx context.Context, id pgtype.UUID) (int32, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTodoAncestorIDs(ctx context.Context, id pgtype.UUID) ([]pgtype.UUID, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]pgtype.UUID), args.Error(1)
+}
+
+func (m *MockDBRepository) ListSubtasks(ctx context.Context, parentID pgtype.UUID) ([]db.TodoItem, error) {
+	args := m.Called(ctx, parentID)
+	return args.Get(0).([]db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTodoProgress(ctx context.Context, todoIds []pgtype.UUID) ([]db.ListTodoProgressRow, error) {
+	args := m.Called(ctx, todoIds)
+	return args.Get(0).([]db.ListTodoProgressRow), args.Error(1)
+}
+
+func (m *MockDBRepository) ListPurgeableTodos(ctx context.Context, arg db.ListPurgeableTodosParams) ([]db.ListPurgeableTodosRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListPurgeableTodosRow), args.Error(1)
+}
+
+func (m *MockDBRepository) PurgeTodo(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListReferencedStorageKeys(ctx context.Context, storageKeys []string) ([]string, error) {
+	args := m.Called(ctx, storageKeys)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDBRepository) ListSharedStorageKeys(ctx context.Context, arg db.ListSharedStorageKeysParams) ([]string, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateAttachment(ctx context.Context, arg db.CreateAttachmentParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetAttachment(ctx context.Context, arg db.GetAttachmentParams) (db.Attachment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Attachment), args.Error(1)
+}
+
+func (m *MockDBRepository) ListAttachments(ctx context.Context, todoID pgtype.UUID) ([]db.Attachment, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]db.Attachment), args.Error(1)
+}
+
+func (m *MockDBRepository) ListAttachmentsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]db.Attachment, error) {
+	args := m.Called(ctx, todoIds)
+	return args.Get(0).([]db.Attachment), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteAttachment(ctx context.Context, arg db.DeleteAttachmentParams) (db.Attachment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Attachment), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateTag(ctx context.Context, arg db.CreateTagParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetTag(ctx context.Context, arg db.GetTagParams) (db.Tag, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Tag), args.Error(1)
+}
+
+func (m *MockDBRepository) ListTags(ctx context.Context, workspaceID pgtype.UUID) ([]db.Tag, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]db.Tag), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateTag(ctx context.Context, arg db.UpdateTagParams) (db.Tag, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Tag), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteTag(ctx context.Context, arg db.DeleteTagParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) UpsertTag(ctx context.Context, arg db.UpsertTagParams) (db.Tag, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Tag), args.Error(1)
+}
+
+func (m *MockDBRepository) AddTodoTag(ctx context.Context, arg db.AddTodoTagParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) DeleteTodoTags(ctx context.Context, todoID pgtype.UUID) error {
+	args := m.Called(ctx, todoID)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListTagsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]db.ListTagsByTodoIDsRow, error) {
+	args := m.Called(ctx, todoIds)
+	return args.Get(0).([]db.ListTagsByTodoIDsRow), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateChecklistItem(ctx context.Context, arg db.CreateChecklistItemParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) CountChecklistItems(ctx context.Context, todoID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) GetChecklistItem(ctx context.Context, arg db.GetChecklistItemParams) (db.ChecklistItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ChecklistItem), args.Error(1)
+}
+
+func (m *MockDBRepository) ListChecklistItemsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]db.ChecklistItem, error) {
+	args := m.Called(ctx, todoIds)
+	return args.Get(0).([]db.ChecklistItem), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateChecklistItem(ctx context.Context, arg db.UpdateChecklistItemParams) (db.ChecklistItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ChecklistItem), args.Error(1)
+}
+
+func (m *MockDBRepository) CheckChecklistItems(ctx context.Context, arg db.CheckChecklistItemsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) DeleteChecklistItem(ctx context.Context, arg db.DeleteChecklistItemParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) AddTodoDependency(ctx context.Context, arg db.AddTodoDependencyParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) RemoveTodoDependency(ctx context.Context, arg db.RemoveTodoDependencyParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) IsTodoBlockedBy(ctx context.Context, arg db.IsTodoBlockedByParams) (bool, error) {
+	args := m.Called(ctx, arg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDBRepository) ListOpenBlockerIDs(ctx context.Context, todoID pgtype.UUID) ([]pgtype.UUID, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]pgtype.UUID), args.Error(1)
+}
+
+func (m *MockDBRepository) ListDependencyGraph(ctx context.Context, todoIds []pgtype.UUID) ([]db.ListDependencyGraphRow, error) {
+	args := m.Called(ctx, todoIds)
+	return args.Get(0).([]db.ListDependencyGraphRow), args.Error(1)
+}
+
+func (m *MockDBRepository) LockTodoDependencies(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListTodosByIDs(ctx context.Context, arg db.ListTodosByIDsParams) ([]db.TodoItem, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.TodoItem), args.Error(1)
+}
+
+func (m *MockDBRepository) GetUserBySubject(ctx context.Context, subject string) (db.User, error) {
+	args := m.Called(ctx, subject)
+	return args.Get(0).(db.User), args.Error(1)
+}
+
+func (m *MockDBRepository) UpsertUser(ctx context.Context, arg db.UpsertUserParams) (db.User, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.User), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetActiveAPIKeyByHash(ctx context.Context, keyHash []byte) (db.ApiKey, error) {
+	args := m.Called(ctx, keyHash)
+	return args.Get(0).(db.ApiKey), args.Error(1)
+}
+
+func (m *MockDBRepository) ListAPIKeys(ctx context.Context) ([]db.ApiKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]db.ApiKey), args.Error(1)
+}
+
+func (m *MockDBRepository) RevokeAPIKey(ctx context.Context, arg db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ApiKey), args.Error(1)
+}
+
+func (m *MockDBRepository) TouchAPIKey(ctx context.Context, arg db.TouchAPIKeyParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) CreateWorkspace(ctx context.Context, arg db.CreateWorkspaceParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListWorkspacesByUser(ctx context.Context, userID pgtype.UUID) ([]db.ListWorkspacesByUserRow, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]db.ListWorkspacesByUserRow), args.Error(1)
+}
+
+func (m *MockDBRepository) LockWorkspace(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) UpsertWorkspaceMember(ctx context.Context, arg db.UpsertWorkspaceMemberParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetWorkspaceMember(ctx context.Context, arg db.GetWorkspaceMemberParams) (db.WorkspaceMember, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockDBRepository) GetDefaultWorkspaceMember(ctx context.Context, userID pgtype.UUID) (db.WorkspaceMember, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(db.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockDBRepository) ListWorkspaceMembers(ctx context.Context, workspaceID pgtype.UUID) ([]db.ListWorkspaceMembersRow, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]db.ListWorkspaceMembersRow), args.Error(1)
+}
+
+func (m *MockDBRepository) CountWorkspaceOwners(ctx context.Context, workspaceID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) CountWorkspaceMemberships(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteWorkspaceMember(ctx context.Context, arg db.DeleteWorkspaceMemberParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) UpsertShare(ctx context.Context, arg db.UpsertShareParams) (db.Share, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Share), args.Error(1)
+}
+
+func (m *MockDBRepository) ListShares(ctx context.Context, todoID pgtype.UUID) ([]db.Share, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]db.Share), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteShare(ctx context.Context, arg db.DeleteShareParams) (db.Share, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Share), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateComment(ctx context.Context, arg db.CreateCommentParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListComments(ctx context.Context, todoID pgtype.UUID) ([]db.Comment, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]db.Comment), args.Error(1)
+}
+
+func (m *MockDBRepository) ListListShares(ctx context.Context, listID pgtype.UUID) ([]db.Share, error) {
+	args := m.Called(ctx, listID)
+	return args.Get(0).([]db.Share), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteListShare(ctx context.Context, arg db.DeleteListShareParams) (db.Share, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Share), args.Error(1)
+}
+
+func (m *MockDBRepository) GetTodoShareLevels(ctx context.Context, arg db.GetTodoShareLevelsParams) (db.GetTodoShareLevelsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.GetTodoShareLevelsRow), args.Error(1)
+}
+
+func (m *MockDBRepository) CreateList(ctx context.Context, arg db.CreateListParams) (db.List, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.List), args.Error(1)
+}
+
+func (m *MockDBRepository) GetList(ctx context.Context, arg db.GetListParams) (db.GetListRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.GetListRow), args.Error(1)
+}
+
+func (m *MockDBRepository) GetListState(ctx context.Context, arg db.GetListStateParams) (db.GetListStateRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.GetListStateRow), args.Error(1)
+}
+
+func (m *MockDBRepository) ListLists(ctx context.Context, arg db.ListListsParams) ([]db.ListListsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListListsRow), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateList(ctx context.Context, arg db.UpdateListParams) (db.List, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.List), args.Error(1)
+}
+
+func (m *MockDBRepository) DeleteList(ctx context.Context, arg db.DeleteListParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) MoveSubtasksToList(ctx context.Context, arg db.MoveSubtasksToListParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.Outbox, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.Outbox), args.Error(1)
+}
+
+func (m *MockDBRepository) InsertOutboxEvent(ctx context.Context, arg db.InsertOutboxEventParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) MarkOutboxEventSent(ctx context.Context, arg db.MarkOutboxEventSentParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) DeleteSentOutboxEvents(ctx context.Context, arg db.DeleteSentOutboxEventsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+// ExecTx runs fn against the mock itself, expectations are checked as if there was no transaction.
+func (m *MockDBRepository) ExecTx(ctx context.Context, fn func(q db.Querier) error) error {
+	return fn(m)
+}
+
 type MockFileStorage struct {
 	mock.Mock
 }
 
-func (m *MockFileStorage) Upload(ctx context.Context, key string, file []byte) (string, error) {
-	args := m.Called(ctx, key, file)
-	return args.String(0), args.Error(1)
-}
+// Upload drains the body like the real storage would, so expectations can match on the uploaded bytes.
+func (m *MockFileStorage) Upload(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	args := m.Called(ctx, key, data, contentType)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileStorage) Open(ctx context.Context, key string) (*outbound.FileContent, error) {
+	args := m.Called(ctx, key)
+	content, _ := args.Get(0).(*outbound.FileContent)
+	return content, args.Error(1)
+}
+
+func (m *MockFileStorage) PresignDownload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) List(ctx context.Context, prefix string) ([]outbound.StoredFile, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).([]outbound.StoredFile), args.Error(1)
+}
+
+type MockMessagePublisher struct {
+	mock.Mock
+}
+
+func (m *MockMessagePublisher) Publish(ctx context.Context, message string) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+// testOwnerID is the user the tests make their requests as, in the workspace testWorkspaceID.
+var (
+	testOwnerID     = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	testWorkspaceID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+)
+
+// ownerContext returns the context of a request authenticated as the test user, the owner of the test workspace.
+func ownerContext() context.Context {
+	return domain.ContextWithPrincipal(context.Background(), domain.Principal{
+		UserID:      uuid.UUID(testOwnerID.Bytes).String(),
+		Subject:     "test-user",
+		WorkspaceID: uuid.UUID(testWorkspaceID.Bytes).String(),
+		Role:        domain.RoleOwner,
+	})
+}
+
+// expectNotShared makes the todos missing from the test workspace look like they aren't shared with the test user
+// either.
+func expectNotShared(mockDB *MockDBRepository) {
+	mockDB.On("GetTodoShareLevels", mock.Anything, mock.Anything).Return(db.GetTodoShareLevelsRow{}, pgx.ErrNoRows)
+}
+
+// testFileKeyPrefix is the storage prefix of the files of the todo in the test workspace.
+func testFileKeyPrefix(todoID string) string {
+	return fileKeyPrefix(uuid.UUID(testWorkspaceID.Bytes).String(), todoID)
+}
+
+var testUploadLimits = UploadLimits{MaxSize: 1 << 20, MaxFiles: 3, AllowedTypes: []string{"text/plain", "image/png"}}
+
+func newFileUploads(data ...[]byte) []domain.FileUpload {
+	files := make([]domain.FileUpload, 0, len(data))
+	for i, content := range data {
+		files = append(files, domain.FileUpload{
+			Filename: fmt.Sprintf("file-%d.txt", i),
+			Content:  bytes.NewReader(content),
+			Size:     int64(len(content)),
+		})
+	}
+	return files
+}
+
+func TestCreateTodo(t *testing.T) {
+
+	validUUID := uuid.New().String()
+	futureTime := time.Now().Add(24 * time.Hour)
+	fileData := []byte("test file content")
+
+	tests := []struct {
+		name          string
+		todo          domain.TodoItem
+		fileData      [][]byte
+		setupMocks    func(*MockDBRepository, *MockFileStorage, *MockMessagePublisher)
+		expectedError string
+		expectedErrIs error
+	}{
+		{
+			name: "successful creation without file",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData: nil,
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.Description == "Test todo" && params.Priority == "P2" && params.Rank == "a5"
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"priority":"P2"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "first todo with a priority",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+				Priority:    domain.TodoPriorityP0,
+			},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("", pgx.ErrNoRows)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.Priority == "P0" && params.Rank == "a0"
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "unknown priority",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+				Priority:    "P7",
+			},
+			setupMocks:    func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name: "failing to lock the ranks",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(errors.New("db error"))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+		{
+			name: "successful creation with a time zone",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+				TimeZone:    "Europe/Berlin",
+			},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.Timezone == pgtype.Text{String: "Europe/Berlin", Valid: true} && params.DueDate.Time.Equal(futureTime)
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"timezone":"Europe/Berlin"`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "successful creation with tags",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+				Tags:        []domain.Tag{{Name: "work"}},
+			},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				tagID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				mockDB.On("CreateTodo", mock.Anything, mock.Anything).Return(nil)
+				mockDB.On("UpsertTag", mock.Anything, mock.MatchedBy(func(params db.UpsertTagParams) bool {
+					return params.Name == "work"
+				})).Return(db.Tag{ID: tagID, Name: "work"}, nil)
+				mockDB.On("AddTodoTag", mock.Anything, mock.MatchedBy(func(params db.AddTodoTagParams) bool {
+					return params.TagID == tagID && uuid.UUID(params.TodoID.Bytes).String() == validUUID
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"tags":["work"]`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "successful creation with file",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo with file",
+				DueDate:     futureTime,
+			},
+			fileData: [][]byte{fileData, []byte("second file")},
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("file-id", nil)
+				fs.On("Upload", mock.Anything, mock.Anything, []byte("second file"), "text/plain").Return("second-file-id", nil)
+				mockDB.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
+					return params.Description == "Test todo with file"
+				})).Return(nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.MatchedBy(func(params db.CreateAttachmentParams) bool {
+					sum := sha256.Sum256(fileData)
+					return params.StorageKey == "file-id" &&
+						params.Filename == "file-0.txt" &&
+						params.ContentType == "text/plain" &&
+						params.Size == int64(len(fileData)) &&
+						params.Checksum == hex.EncodeToString(sum[:])
+				})).Return(nil)
+				mockDB.On("CreateAttachment", mock.Anything, mock.MatchedBy(func(params db.CreateAttachmentParams) bool {
+					return params.StorageKey == "second-file-id"
+				})).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return params.EventType == domain.EventTypeTodoCreated && strings.Contains(string(params.Payload), `"attachments":["`)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "too many files",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData:      [][]byte{fileData, fileData, fileData, fileData},
+			setupMocks:    func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {},
+			expectedErrIs: domain.ErrTooManyFiles,
+		},
+		{
+			name: "failed upload removes the files already stored",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData: [][]byte{fileData, []byte("second file")},
+			setupMocks: func(_ *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("file-id", nil)
+				fs.On("Upload", mock.Anything, mock.Anything, []byte("second file"), "text/plain").Return("", errors.New("upload failed"))
+				fs.On("Delete", mock.Anything, "file-id").Return(nil)
+			},
+			expectedError: "failed to upload file",
+		},
+		{
+			name: "validation error - past due date",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     time.Now().Add(-24 * time.Hour),
+			},
+			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+
+			},
+			expectedError: "todo validation failed: due date must be in the future",
+		},
+		{
+			name: "validation error - empty description",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "",
+				DueDate:     futureTime,
+			},
+			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+			},
+			expectedError: "todo validation failed: description cannot be empty",
+		},
+		{
+			name: "validation error is reported as a validation failure",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     time.Now().Add(-24 * time.Hour),
+			},
+			setupMocks:    func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name: "invalid UUID",
+			todo: domain.TodoItem{
+				ID:          "invalid-uuid",
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+
+			},
+			expectedError: "invalid todo ID",
+		},
+		{
+			name: "file upload error",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData: [][]byte{fileData},
+			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("", errors.New("upload failed"))
+			},
+			expectedError: "failed to upload file",
+		},
+		{
+			name: "database error",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				db.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				db.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				db.On("CreateTodo", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: "failed to save todo to repository",
+		},
+		{
+			name: "database error is reported as an unavailable dependency",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			setupMocks: func(db *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				db.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				db.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				db.On("CreateTodo", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+		{
+			name: "database error removes the uploaded file",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData: [][]byte{fileData},
+			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("file-id", nil)
+				db.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				db.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				db.On("CreateTodo", mock.Anything, mock.Anything).Return(errors.New("db error"))
+				fs.On("Delete", mock.Anything, "file-id").Return(nil)
+			},
+			expectedError: "failed to save todo to repository",
+		},
+		{
+			name: "failed compensation still returns the database error",
+			todo: domain.TodoItem{
+				ID:          validUUID,
+				Description: "Test todo",
+				DueDate:     futureTime,
+			},
+			fileData: [][]byte{fileData},
+			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, _ *MockMessagePublisher) {
+				fs.On("Upload", mock.Anything, mock.Anything, fileData, "text/plain").Return("file-id", nil)
+				db.On("LockTodoRanks", mock.Anything, testWorkspaceID).Return(nil)
+				db.On("GetLastTodoRank", mock.Anything, testWorkspaceID).Return("a4", nil)
+				db.On("CreateTodo", mock.Anything, mock.Anything).Return(nil)
+				db.On("CreateAttachment", mock.Anything, mock.Anything).Return(nil)
+				db.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(errors.New("db error"))
+				fs.On("Delete", mock.Anything, "file-id").Return(errors.New("s3 error"))
+			},
+			expectedError: "failed to save todo event to outbox",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			mockMP := new(MockMessagePublisher)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockDB, mockFS, mockMP)
+			}
+
+			service := NewTodoService(mockDB, mockFS, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+
+			created, err := service.CreateTodo(ownerContext(), tt.todo, newFileUploads(tt.fileData...))
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.todo.ID, created.ID)
+				assert.Equal(t, domain.TodoStatusOpen, created.Status)
+				assert.Equal(t, int32(1), created.Version)
+				assert.NotEmpty(t, created.Rank)
+				assert.False(t, created.CreatedAt.IsZero())
+				assert.Len(t, created.Attachments, len(tt.fileData))
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+			mockMP.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRelayOutboxEvents(t *testing.T) {
+	sentEvent := db.Outbox{ID: 1, EventType: domain.EventTypeTodoCreated, Payload: []byte(`{"type":"todo.created"}`)}
+	failingEvent := db.Outbox{ID: 2, EventType: domain.EventTypeTodoUpdated, Payload: []byte(`{"type":"todo.updated"}`), Attempts: 2}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockDBRepository, *MockMessagePublisher)
+		expectedSent  int
+		expectedError string
+	}{
+		{
+			name: "publishes pending events",
+			setupMocks: func(mockDB *MockDBRepository, mp *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.MatchedBy(func(params db.ClaimOutboxEventsParams) bool {
+					return params.BatchSize == outboxBatchSize && params.AvailableBefore.Valid &&
+						params.LeasedUntil.Time.Sub(params.AvailableBefore.Time) == outboxLease
+				})).Return([]db.Outbox{sentEvent}, nil)
+				mp.On("Publish", mock.Anything, string(sentEvent.Payload)).Return(nil)
+				mockDB.On("MarkOutboxEventSent", mock.Anything, mock.MatchedBy(func(params db.MarkOutboxEventSentParams) bool {
+					return params.ID == sentEvent.ID && params.SentAt.Valid
+				})).Return(nil)
+			},
+			expectedSent: 1,
+		},
+		{
+			name: "failed publish is rescheduled with backoff",
+			setupMocks: func(mockDB *MockDBRepository, mp *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{failingEvent, sentEvent}, nil)
+				mp.On("Publish", mock.Anything, string(failingEvent.Payload)).Return(errors.New("publish failed"))
+				mockDB.On("MarkOutboxEventFailed", mock.Anything, mock.MatchedBy(func(params db.MarkOutboxEventFailedParams) bool {
+					return params.ID == failingEvent.ID &&
+						params.LastError.String == "publish failed" &&
+						time.Until(params.AvailableAt.Time) > 3*time.Second
+				})).Return(nil)
+				mp.On("Publish", mock.Anything, string(sentEvent.Payload)).Return(nil)
+				mockDB.On("MarkOutboxEventSent", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedSent: 1,
+		},
+		{
+			name: "nothing to relay",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{}, nil)
+			},
+		},
+		{
+			name: "claim error",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{}, errors.New("db error"))
+			},
+			expectedError: "failed to claim outbox events",
+		},
+		{
+			name: "mark sent error only affects that event",
+			setupMocks: func(mockDB *MockDBRepository, mp *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{failingEvent, sentEvent}, nil)
+				mp.On("Publish", mock.Anything, mock.Anything).Return(nil)
+				mockDB.On("MarkOutboxEventSent", mock.Anything, mock.MatchedBy(func(params db.MarkOutboxEventSentParams) bool {
+					return params.ID == sentEvent.ID
+				})).Return(errors.New("db error"))
+				mockDB.On("MarkOutboxEventSent", mock.Anything, mock.MatchedBy(func(params db.MarkOutboxEventSentParams) bool {
+					return params.ID == failingEvent.ID
+				})).Return(nil)
+			},
+			expectedSent:  1,
+			expectedError: "failed to mark outbox event 1 as sent",
+		},
+		{
+			name: "publishes with a timeout in claim order",
+			setupMocks: func(mockDB *MockDBRepository, mp *MockMessagePublisher) {
+				mockDB.On("ClaimOutboxEvents", mock.Anything, mock.Anything).Return([]db.Outbox{failingEvent, sentEvent}, nil)
+				var published []string
+				mp.On("Publish", mock.MatchedBy(func(ctx context.Context) bool {
+					deadline, ok := ctx.Deadline()
+					return ok && time.Until(deadline) <= outboxPublishTimeout
+				}), mock.Anything).Run(func(args mock.Arguments) {
+					published = append(published, args.String(1))
+				}).Return(nil)
+				mockDB.On("MarkOutboxEventSent", mock.Anything, mock.MatchedBy(func(params db.MarkOutboxEventSentParams) bool {
+					// the events are claimed out of order, sentEvent has the lower ID
+					return params.ID != sentEvent.ID || len(published) == 1
+				})).Return(nil)
+			},
+			expectedSent: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockMP := new(MockMessagePublisher)
+			tt.setupMocks(mockDB, mockMP)
+
+			service := NewTodoService(mockDB, nil, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			sent, err := service.RelayOutboxEvents(ownerContext())
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedSent, sent)
+
+			mockDB.AssertExpectations(t)
+			mockMP.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPruneOutboxEvents(t *testing.T) {
+	sentBefore := time.Now().Add(-7 * 24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*MockDBRepository)
+		expectedPruned int64
+		expectedError  string
+	}{
+		{
+			name: "deletes in batches until a batch comes up short",
+			setupMocks: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteSentOutboxEvents", mock.Anything, mock.MatchedBy(func(params db.DeleteSentOutboxEventsParams) bool {
+					return params.SentBefore.Time.Equal(sentBefore) && params.BatchSize == outboxPruneBatchSize
+				})).Return(int64(outboxPruneBatchSize), nil).Once()
+				mockDB.On("DeleteSentOutboxEvents", mock.Anything, mock.Anything).Return(int64(3), nil).Once()
+			},
+			expectedPruned: outboxPruneBatchSize + 3,
+		},
+		{
+			name: "database error",
+			setupMocks: func(mockDB *MockDBRepository) {
+				mockDB.On("DeleteSentOutboxEvents", mock.Anything, mock.Anything).Return(int64(0), errors.New("db error"))
+			},
+			expectedError: "failed to prune outbox events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMocks(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			pruned, err := service.PruneOutboxEvents(ownerContext(), sentBefore)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPruned, pruned)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, outboxBackoff(1))
+	assert.Equal(t, 2*time.Second, outboxBackoff(2))
+	assert.Equal(t, 8*time.Second, outboxBackoff(4))
+	assert.Equal(t, outboxMaxBackoff, outboxBackoff(20))
+	assert.Equal(t, outboxMaxBackoff, outboxBackoff(1000))
+}
+
+func TestDatabaseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedKind error
+		expectedCode string
+	}{
+		{
+			name:         "unique violation conflicts",
+			err:          &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "tags_workspace_id_name_key"},
+			expectedKind: domain.ErrConflict,
+			expectedCode: "DuplicateRecord",
+		},
+		{
+			name:         "foreign key violation",
+			err:          &pgconn.PgError{Code: pgForeignKeyViolation},
+			expectedKind: domain.ErrUnprocessable,
+			expectedCode: "ConstraintViolation",
+		},
+		{
+			name:         "check violation",
+			err:          &pgconn.PgError{Code: "23514", ConstraintName: "shares_target_check"},
+			expectedKind: domain.ErrUnprocessable,
+			expectedCode: "ConstraintViolation",
+		},
+		{
+			name:         "row-level security violation",
+			err:          &pgconn.PgError{Code: pgInsufficientPrivilege},
+			expectedKind: domain.ErrForbidden,
+			expectedCode: "RecordAccessDenied",
+		},
+		{
+			name:         "serialization failure",
+			err:          &pgconn.PgError{Code: "40001"},
+			expectedKind: domain.ErrDependencyUnavailable,
+		},
+		{
+			name:         "connection failure",
+			err:          errors.New("dial tcp: connection refused"),
+			expectedKind: domain.ErrDependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := databaseError(fmt.Errorf("failed to save todo: %w", tt.err))
+
+			assert.ErrorIs(t, err, tt.expectedKind)
+			var domainErr *domain.Error
+			if tt.expectedCode != "" && assert.ErrorAs(t, err, &domainErr) {
+				assert.Equal(t, tt.expectedCode, domainErr.Code)
+			}
+		})
+	}
+}
+
+func TestAttachmentKey(t *testing.T) {
+	todoID := uuid.New().String()
+	attachmentID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	key := attachmentKey(workspaceID, todoID, attachmentID)
+
+	assert.Equal(t, "workspaces/"+workspaceID+"/todos/"+todoID+"/"+attachmentID, key)
+	assert.True(t, strings.HasPrefix(key, fileKeyPrefix(workspaceID, todoID)))
+}
+
+func TestGetTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	dueDate := time.Now().Add(24 * time.Hour).UTC()
+
+	tests := []struct {
+		name          string
+		id            string
+		setupMock     func(*MockDBRepository)
+		expectedError string
+		expectedErrIs error
+	}{
+		{
+			name: "successful get",
+			id:   todoUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgtype.UUID{Bytes: todoUUID, Valid: true}, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{
+					ID:          pgtype.UUID{Bytes: todoUUID, Valid: true},
+					Description: "Test todo",
+					DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: true},
+				}, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgtype.UUID{Bytes: todoUUID, Valid: true}).Return([]db.Attachment{
+					{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: pgtype.UUID{Bytes: todoUUID, Valid: true}, Filename: "notes.txt"},
+				}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+			},
+		},
+		{
+			name:          "invalid UUID",
+			id:            "invalid-uuid",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedError: "invalid todo ID",
+		},
+		{
+			name: "not found",
+			id:   todoUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, pgx.ErrNoRows)
+				expectNotShared(mockDB)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name: "database error",
+			id:   todoUUID.String(),
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, errors.New("db error"))
+			},
+			expectedError: "failed to get todo from repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.GetTodo(ownerContext(), tt.id)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, todoUUID.String(), todo.ID)
+				assert.Equal(t, "Test todo", todo.Description)
+				assert.True(t, dueDate.Equal(todo.DueDate))
+				assert.Len(t, todo.Attachments, 1)
+				assert.Equal(t, "notes.txt", todo.Attachments[0].Filename)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListTodos(t *testing.T) {
+	now := time.Now().UTC()
+	rows := make([]db.TodoItem, 3)
+	for i := range rows {
+		rows[i] = db.TodoItem{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Description: "Test todo",
+			DueDate:     pgtype.Timestamptz{Time: now.Add(time.Duration(i) * time.Hour), Valid: true},
+			CreatedAt:   pgtype.Timestamptz{Time: now.Add(-time.Duration(i) * time.Minute), Valid: true},
+			Priority:    "P1",
+			Rank:        fmt.Sprintf("a%d", i),
+		}
+	}
+	validCursor := encodeCursor(SortByCreatedAt, rows[0].ID.Bytes, rows[0].CreatedAt.Time.Format(time.RFC3339Nano))
+	hasAttachment := true
+
+	tests := []struct {
+		name                string
+		query               TodoQuery
+		setupMock           func(*MockDBRepository)
+		expectedItems       int
+		expectedCursor      string
+		expectedAttachments []int                 // Number of attachments per returned item, unchecked when nil
+		expectedProgress    []domain.TodoProgress // Progress per returned item, unchecked when nil
+		expectedErrIs       error
+		expectedError       string
+	}{
+		{
+			name:  "first page with more results",
+			query: TodoQuery{Limit: 2},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, db.ListTodosParams{WorkspaceID: testWorkspaceID, PageSize: 3}).Return(rows, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, []pgtype.UUID{rows[0].ID, rows[1].ID}).Return([]db.Attachment{
+					{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: rows[1].ID},
+					{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, TodoID: rows[1].ID},
+				}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, []pgtype.UUID{rows[0].ID, rows[1].ID}).Return([]db.ListTodoProgressRow{
+					{TodoID: rows[0].ID, Done: 1, Total: 3},
+				}, nil)
+			},
+			expectedAttachments: []int{0, 2},
+			expectedItems:       2,
+			expectedCursor:      encodeCursor(SortByCreatedAt, rows[1].ID.Bytes, rows[1].CreatedAt.Time.Format(time.RFC3339Nano)),
+		},
+		{
+			name:  "last page",
+			query: TodoQuery{Cursor: validCursor, Limit: 5},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, mock.MatchedBy(func(params db.ListTodosParams) bool {
+					return params.PageSize == 6 &&
+						params.CursorKey.Valid && params.CursorKey.Time.Equal(rows[0].CreatedAt.Time) &&
+						params.CursorID == rows[0].ID
+				})).Return(rows[1:], nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+			expectedItems: 2,
+		},
+		{
+			name: "default page size",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, db.ListTodosParams{WorkspaceID: testWorkspaceID, PageSize: DefaultPageSize + 1}).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+		},
+		{
+			name:  "page size capped",
+			query: TodoQuery{Limit: MaxPageSize + 50},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, db.ListTodosParams{WorkspaceID: testWorkspaceID, PageSize: MaxPageSize + 1}).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+		},
+		{
+			name: "filters",
+			query: TodoQuery{
+				Statuses:      []domain.TodoStatus{domain.TodoStatusOpen, domain.TodoStatusBlocked},
+				DueAfter:      now,
+				DueBefore:     now.Add(time.Hour),
+				Overdue:       true,
+				HasAttachment: &hasAttachment,
+				Search:        "groceries",
+				Tags:          []string{"Work", "work", "home"},
+				MatchAllTags:  true,
+			},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, mock.MatchedBy(func(params db.ListTodosParams) bool {
+					return assert.ObjectsAreEqual([]string{"open", "blocked"}, params.Statuses) &&
+						params.DueAfter.Time.Equal(now) && params.DueBefore.Time.Equal(now.Add(time.Hour)) &&
+						params.OverdueAt.Valid && params.HasAttachment == pgtype.Bool{Bool: true, Valid: true} &&
+						params.Search == pgtype.Text{String: "groceries", Valid: true} &&
+						assert.ObjectsAreEqual([]string{"work", "home"}, params.Tags) && params.MatchAllTags
+				})).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+		},
+		{
+			name:  "sorted by due date",
+			query: TodoQuery{Sort: SortByDueDate, Limit: 2},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodosByDueDate", mock.Anything, db.ListTodosByDueDateParams{WorkspaceID: testWorkspaceID, PageSize: 3}).Return(rows, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+			expectedItems:  2,
+			expectedCursor: encodeCursor(SortByDueDate, rows[1].ID.Bytes, rows[1].DueDate.Time.Format(time.RFC3339Nano)),
+		},
+		{
+			name:  "sorted by priority",
+			query: TodoQuery{Sort: SortByPriority, Limit: 2},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodosByPriority", mock.Anything, db.ListTodosByPriorityParams{WorkspaceID: testWorkspaceID, PageSize: 3}).Return(rows, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+			expectedItems:  2,
+			expectedCursor: encodeCursor(SortByPriority, rows[1].ID.Bytes, rows[1].Priority, rows[1].Rank),
+		},
+		{
+			name:  "next page by priority",
+			query: TodoQuery{Sort: SortByPriority, Cursor: encodeCursor(SortByPriority, rows[0].ID.Bytes, "P1", "a0V")},
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodosByPriority", mock.Anything, db.ListTodosByPriorityParams{
+					WorkspaceID:    testWorkspaceID,
+					CursorPriority: pgtype.Text{String: "P1", Valid: true},
+					CursorRank:     pgtype.Text{String: "a0V", Valid: true},
+					CursorID:       rows[0].ID,
+					PageSize:       DefaultPageSize + 1,
+				}).Return(rows[1:], nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListTodoProgress", mock.Anything, mock.Anything).Return([]db.ListTodoProgressRow{}, nil)
+			},
+			expectedItems: 2,
+		},
+		{
+			name:          "priority cursor without a rank",
+			query:         TodoQuery{Sort: SortByPriority, Cursor: encodeCursor(SortByPriority, rows[0].ID.Bytes, "P1")},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: ErrInvalidCursor,
+		},
+		{
+			name:          "cursor of another sort order",
+			query:         TodoQuery{Sort: SortByDueDate, Cursor: validCursor},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: ErrInvalidCursor,
+		},
+		{
+			name:          "unknown sort order",
+			query:         TodoQuery{Sort: "title"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrValidation,
+		},
+		{
+			name:          "invalid cursor",
+			query:         TodoQuery{Cursor: "not-a-cursor"},
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: ErrInvalidCursor,
+		},
+		{
+			name: "database error",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, mock.Anything).Return([]db.TodoItem{}, errors.New("db error"))
+			},
+			expectedError: "failed to list todos from repository",
+		},
+		{
+			name: "attachments error",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("ListTodos", mock.Anything, mock.Anything).Return(rows, nil)
+				mockDB.On("ListAttachmentsByTodoIDs", mock.Anything, mock.Anything).Return([]db.Attachment{}, errors.New("db error"))
+			},
+			expectedError: "failed to list attachments from repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			page, err := service.ListTodos(ownerContext(), tt.query)
 
-type MockMessagePublisher struct {
-	mock.Mock
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Len(t, page.Items, tt.expectedItems)
+				assert.Equal(t, tt.expectedCursor, page.NextCursor)
+				for i, expected := range tt.expectedAttachments {
+					assert.Len(t, page.Items[i].Attachments, expected)
+				}
+				for i, expected := range tt.expectedProgress {
+					assert.Equal(t, expected, page.Items[i].Progress)
+				}
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
 }
 
-func (m *MockMessagePublisher) Publish(ctx context.Context, message string) error {
-	args := m.Called(ctx, message)
-	return args.Error(0)
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 12, 29, 15, 4, 5, 123456000, time.UTC)
+	id := uuid.New()
+
+	keys, gotID, err := decodeCursor(encodeCursor(SortByCreatedAt, id, sortKeys(SortByCreatedAt, db.TodoItem{
+		CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+	})...), SortByCreatedAt)
+	assert.NoError(t, err)
+	gotCreatedAt, ok := cursorTime(keys)
+	assert.True(t, ok)
+	assert.True(t, createdAt.Equal(gotCreatedAt))
+	assert.Equal(t, id, gotID)
+
+	keys, gotID, err = decodeCursor(encodeCursor(SortByPriority, id, "P1", "a0V"), SortByPriority)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"P1", "a0V"}, keys)
+	assert.Equal(t, id, gotID)
 }
 
-func TestCreateTodo(t *testing.T) {
+func TestUpdateTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	futureTime := time.Now().Add(24 * time.Hour).UTC()
+	newDescription := "Updated todo"
+	newTags := []string{"Home", "home", "errands"}
+	homeID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	errandsID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	urgent := domain.TodoPriorityP0
 
-	validUUID := uuid.New().String()
-	futureTime := time.Now().Add(24 * time.Hour)
-	fileData := []byte("test file content")
+	stored := db.TodoItem{
+		ID:          pgID,
+		Description: "Test todo",
+		DueDate:     pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+		Version:     3,
+	}
+	updatedRow := stored
+	updatedRow.Description = newDescription
+	updatedRow.Version = 4
 
 	tests := []struct {
-		name          string
-		todo          domain.TodoItem
-		fileData      []byte
-		setupMocks    func(*MockDBRepository, *MockFileStorage, *MockMessagePublisher)
-		expectedError string
+		name            string
+		patch           domain.TodoItemPatch
+		expectedVersion int32
+		setupMocks      func(*MockDBRepository, *MockFileStorage, *MockMessagePublisher)
+		expectedError   string
+		expectedErrIs   error
 	}{
 		{
-			name: "successful creation without file",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "Test todo",
-				DueDate:     futureTime,
+			name:            "successful update with matching version",
+			patch:           domain.TodoItemPatch{Description: &newDescription},
+			expectedVersion: 3,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.Description == newDescription && params.ExpectedVersion == 3
+				})).Return(updatedRow, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					message := string(params.Payload)
+					return strings.Contains(message, domain.EventTypeTodoUpdated)
+				})).Return(nil)
 			},
-			fileData: nil,
-			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage, mp *MockMessagePublisher) {
-				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
-					return params.Description == "Test todo"
+		},
+		{
+			name:  "tags are replaced",
+			patch: domain.TodoItemPatch{Tags: &newTags},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ListTagsByTodoIDsRow{
+					{TodoID: pgID, ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "work"},
+				}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.Anything).Return(updatedRow, nil)
+				mockDB.On("DeleteTodoTags", mock.Anything, pgID).Return(nil)
+				mockDB.On("UpsertTag", mock.Anything, mock.MatchedBy(func(params db.UpsertTagParams) bool {
+					return params.Name == "home"
+				})).Return(db.Tag{ID: homeID, Name: "home"}, nil).Once()
+				mockDB.On("UpsertTag", mock.Anything, mock.MatchedBy(func(params db.UpsertTagParams) bool {
+					return params.Name == "errands"
+				})).Return(db.Tag{ID: errandsID, Name: "errands"}, nil).Once()
+				mockDB.On("AddTodoTag", mock.Anything, db.AddTodoTagParams{TodoID: pgID, TagID: homeID}).Return(nil)
+				mockDB.On("AddTodoTag", mock.Anything, db.AddTodoTagParams{TodoID: pgID, TagID: errandsID}).Return(nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					return strings.Contains(string(params.Payload), `"tags":["home","errands"]`)
 				})).Return(nil)
-				mp.On("Publish", mock.Anything, mock.Anything).Return(nil)
 			},
 		},
 		{
-			name: "successful creation with file",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "Test todo with file",
-				DueDate:     futureTime,
+			name:  "priority change",
+			patch: domain.TodoItemPatch{Priority: &urgent},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.Priority == "P0"
+				})).Return(updatedRow, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
 			},
-			fileData: fileData,
-			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage, mp *MockMessagePublisher) {
-				fs.On("Upload", mock.Anything, mock.Anything, fileData).Return("file-id", nil)
-				mockDB.On("CreateTodo", mock.Anything, mock.MatchedBy(func(params db.CreateTodoParams) bool {
-					return params.Description == "Test todo with file" && params.FileID.String == "file-id"
-				})).Return(nil)
-				mp.On("Publish", mock.Anything, mock.Anything).Return(nil)
+		},
+		{
+			name:  "unconditional update",
+			patch: domain.TodoItemPatch{DueDate: &futureTime},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoParams) bool {
+					return params.DueDate.Time.Equal(futureTime)
+				})).Return(updatedRow, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
 			},
 		},
 		{
-			name: "validation error - past due date",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "Test todo",
-				DueDate:     time.Now().Add(-24 * time.Hour),
+			name:            "stale version",
+			patch:           domain.TodoItemPatch{Description: &newDescription},
+			expectedVersion: 2,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
 			},
-			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
-
+			expectedErrIs: domain.ErrTodoVersionConflict,
+		},
+		{
+			name:  "concurrent update between read and write",
+			patch: domain.TodoItemPatch{Description: &newDescription},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, pgx.ErrNoRows)
 			},
-			expectedError: "todo validation failed: due date must be in the future",
+			expectedErrIs: domain.ErrTodoVersionConflict,
 		},
 		{
-			name: "validation error - empty description",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "",
-				DueDate:     futureTime,
+			name:  "not found",
+			patch: domain.TodoItemPatch{Description: &newDescription},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+				expectNotShared(mockDB)
 			},
-			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name:  "validation error",
+			patch: domain.TodoItemPatch{DueDate: &stored.DueDate.Time},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
 			},
-			expectedError: "todo validation failed: description cannot be empty",
+			expectedError: "todo validation failed: due date must be in the future",
 		},
 		{
-			name: "invalid UUID",
-			todo: domain.TodoItem{
-				ID:          "invalid-uuid",
-				Description: "Test todo",
-				DueDate:     futureTime,
+			name:  "database error",
+			patch: domain.TodoItemPatch{Description: &newDescription},
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, errors.New("db error"))
 			},
-			setupMocks: func(_ *MockDBRepository, _ *MockFileStorage, _ *MockMessagePublisher) {
+			expectedError: "failed to update todo in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			mockMP := new(MockMessagePublisher)
+			tt.setupMocks(mockDB, mockFS, mockMP)
+
+			service := NewTodoService(mockDB, mockFS, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.UpdateTodo(ownerContext(), todoUUID.String(), tt.patch, tt.expectedVersion)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, int32(4), todo.Version)
+			}
+
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+			mockMP.AssertExpectations(t)
+		})
+	}
+}
 
+func TestTransitionTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+	stored := db.TodoItem{ID: pgID, Description: "Test todo", Status: string(domain.TodoStatusInProgress), Version: 2}
+
+	tests := []struct {
+		name            string
+		status          domain.TodoStatus
+		expectedVersion int32
+		setupMocks      func(*MockDBRepository, *MockMessagePublisher)
+		expectedError   string
+		expectedErrIs   error
+	}{
+		{
+			name:            "complete a todo",
+			status:          domain.TodoStatusDone,
+			expectedVersion: 2,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListOpenBlockerIDs", mock.Anything, mock.Anything).Return([]pgtype.UUID{}, nil)
+				mockDB.On("ListSubtasks", mock.Anything, pgID).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ChecklistItem{}, nil)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoStatusParams) bool {
+					return params.Status == string(domain.TodoStatusDone) && params.CompletedAt.Valid && params.ExpectedVersion == 2
+				})).Return(db.TodoItem{
+					ID:          pgID,
+					Status:      string(domain.TodoStatusDone),
+					CompletedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					Version:     3,
+				}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					message := string(params.Payload)
+					return strings.Contains(message, domain.EventTypeTodoStatusChanged) &&
+						strings.Contains(message, `"from":"in_progress"`) &&
+						strings.Contains(message, `"to":"done"`) &&
+						strings.Contains(message, `"completed_at"`)
+				})).Return(nil)
 			},
-			expectedError: "invalid todo ID",
 		},
 		{
-			name: "file upload error",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "Test todo",
-				DueDate:     futureTime,
+			name:   "block a todo",
+			status: domain.TodoStatusBlocked,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.MatchedBy(func(params db.UpdateTodoStatusParams) bool {
+					return params.Status == string(domain.TodoStatusBlocked) && !params.CompletedAt.Valid
+				})).Return(db.TodoItem{ID: pgID, Status: string(domain.TodoStatusBlocked), Version: 3}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.Anything).Return(nil)
 			},
-			fileData: fileData,
-			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, mp *MockMessagePublisher) {
-				fs.On("Upload", mock.Anything, mock.Anything, fileData).Return("", errors.New("upload failed"))
+		},
+		{
+			name:   "transition not allowed",
+			status: domain.TodoStatusInProgress,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
 			},
-			expectedError: "failed to upload file",
+			expectedErrIs: domain.ErrInvalidStatusTransition,
 		},
 		{
-			name: "database error",
-			todo: domain.TodoItem{
-				ID:          validUUID,
-				Description: "Test todo",
-				DueDate:     futureTime,
+			name:            "stale version",
+			status:          domain.TodoStatusDone,
+			expectedVersion: 1,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
 			},
-			setupMocks: func(db *MockDBRepository, fs *MockFileStorage, mp *MockMessagePublisher) {
-				db.On("CreateTodo", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			expectedErrIs: domain.ErrTodoVersionConflict,
+		},
+		{
+			name:   "database error",
+			status: domain.TodoStatusDone,
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(stored, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("ListOpenBlockerIDs", mock.Anything, mock.Anything).Return([]pgtype.UUID{}, nil)
+				mockDB.On("ListSubtasks", mock.Anything, pgID).Return([]db.TodoItem{}, nil)
+				mockDB.On("ListChecklistItemsByTodoIDs", mock.Anything, []pgtype.UUID{pgID}).Return([]db.ChecklistItem{}, nil)
+				mockDB.On("UpdateTodoStatus", mock.Anything, mock.Anything).Return(db.TodoItem{}, errors.New("db error"))
 			},
-			expectedError: "failed to save todo to repository",
+			expectedError: "failed to update todo status in repository",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-
 			mockDB := new(MockDBRepository)
-			mockFS := new(MockFileStorage)
 			mockMP := new(MockMessagePublisher)
+			tt.setupMocks(mockDB, mockMP)
 
-			if tt.setupMocks != nil {
-				tt.setupMocks(mockDB, mockFS, mockMP)
+			service := NewTodoService(mockDB, nil, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.TransitionTodo(ownerContext(), todoUUID.String(), tt.status, tt.expectedVersion)
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.status, todo.Status)
 			}
 
-			service := NewTodoService(mockDB, mockFS, mockMP, slog.Default())
+			mockDB.AssertExpectations(t)
+			mockMP.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTodo(t *testing.T) {
+	todoUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		id            string
+		setupMocks    func(*MockDBRepository, *MockMessagePublisher)
+		expectedError string
+		expectedErrIs error
+	}{
+		{
+			name: "successful delete",
+			id:   todoUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("SoftDeleteTodo", mock.Anything, mock.MatchedBy(func(params db.SoftDeleteTodoParams) bool {
+					return params.ID.Bytes == todoUUID && params.DeletedAt.Valid
+				})).Return(int64(1), nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					message := string(params.Payload)
+					return strings.Contains(message, domain.EventTypeTodoDeleted)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "not found or already deleted",
+			id:   todoUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("SoftDeleteTodo", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name:          "invalid UUID",
+			id:            "invalid-uuid",
+			setupMocks:    func(_ *MockDBRepository, _ *MockMessagePublisher) {},
+			expectedError: "invalid todo ID",
+		},
+		{
+			name: "database error",
+			id:   todoUUID.String(),
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("SoftDeleteTodo", mock.Anything, mock.Anything).Return(int64(0), errors.New("db error"))
+			},
+			expectedError: "failed to delete todo in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockMP := new(MockMessagePublisher)
+			tt.setupMocks(mockDB, mockMP)
 
-			err := service.CreateTodo(context.Background(), tt.todo, tt.fileData)
+			service := NewTodoService(mockDB, nil, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			err := service.DeleteTodo(ownerContext(), tt.id)
 
-			if tt.expectedError != "" {
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError)
-			} else {
+			default:
 				assert.NoError(t, err)
 			}
 
 			mockDB.AssertExpectations(t)
-			mockFS.AssertExpectations(t)
 			mockMP.AssertExpectations(t)
 		})
 	}
 }
 
-func TestPublishTodoEvent(t *testing.T) {
+func TestRestoreTodo(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+
 	tests := []struct {
 		name          string
-		event         domain.TodoItemCreateEvent
-		setupMock     func(*MockMessagePublisher)
+		setupMocks    func(*MockDBRepository, *MockMessagePublisher)
 		expectedError string
+		expectedErrIs error
 	}{
 		{
-			name: "successful publish",
-			event: domain.TodoItemCreateEvent{
-				ID:          uuid.New().String(),
-				Description: "Test event",
-				DueDate:     time.Now().Add(24 * time.Hour),
-			},
-			setupMock: func(mp *MockMessagePublisher) {
-				mp.On("Publish", mock.Anything, mock.Anything).Return(nil)
+			name: "successful restore",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("RestoreSubtasks", mock.Anything, mock.MatchedBy(func(params db.RestoreSubtasksParams) bool {
+					return params.ID == pgID
+				})).Return(nil)
+				mockDB.On("RestoreTodo", mock.Anything, mock.MatchedBy(func(params db.RestoreTodoParams) bool {
+					return params.ID == pgID
+				})).Return(db.TodoItem{ID: pgID, Description: "Test todo", Version: 3}, nil)
+				mockDB.On("ListAttachments", mock.Anything, pgID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListTagsByTodoIDs", mock.Anything, mock.Anything).Return([]db.ListTagsByTodoIDsRow{}, nil)
+				mockDB.On("InsertOutboxEvent", mock.Anything, mock.MatchedBy(func(params db.InsertOutboxEventParams) bool {
+					message := string(params.Payload)
+					return strings.Contains(message, domain.EventTypeTodoRestored)
+				})).Return(nil)
 			},
 		},
 		{
-			name: "publish error with retry",
-			event: domain.TodoItemCreateEvent{
-				ID:          uuid.New().String(),
-				Description: "Test event",
+			name: "not in the trash",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("RestoreSubtasks", mock.Anything, mock.MatchedBy(func(params db.RestoreSubtasksParams) bool {
+					return params.ID == pgID
+				})).Return(nil)
+				mockDB.On("RestoreTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, pgx.ErrNoRows)
 			},
-			setupMock: func(mp *MockMessagePublisher) {
-				mp.On("Publish", mock.Anything, mock.Anything).
-					Return(errors.New("publish failed")).Times(3)
+			expectedErrIs: domain.ErrTodoNotFound,
+		},
+		{
+			name: "database error",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockMessagePublisher) {
+				mockDB.On("RestoreSubtasks", mock.Anything, mock.MatchedBy(func(params db.RestoreSubtasksParams) bool {
+					return params.ID == pgID
+				})).Return(nil)
+				mockDB.On("RestoreTodo", mock.Anything, mock.Anything).Return(db.TodoItem{}, errors.New("db error"))
 			},
-			expectedError: "publish failed",
+			expectedError: "failed to restore todo in repository",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
 			mockMP := new(MockMessagePublisher)
-			if tt.setupMock != nil {
-				tt.setupMock(mockMP)
+			tt.setupMocks(mockDB, mockMP)
+
+			service := NewTodoService(mockDB, nil, mockMP, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			todo, err := service.RestoreTodo(ownerContext(), todoUUID.String())
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			case tt.expectedError != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, todoUUID.String(), todo.ID)
 			}
 
-			service := NewTodoService(nil, nil, mockMP, slog.Default())
-			err := service.publishTodoEvent(context.Background(), tt.event)
+			mockDB.AssertExpectations(t)
+			mockMP.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPurgeDeletedTodos(t *testing.T) {
+	firstID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	secondID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	firstPrefix := testFileKeyPrefix(uuid.UUID(firstID.Bytes).String())
+	secondPrefix := testFileKeyPrefix(uuid.UUID(secondID.Bytes).String())
+	first := db.ListPurgeableTodosRow{ID: firstID, WorkspaceID: testWorkspaceID}
+	second := db.ListPurgeableTodosRow{ID: secondID, WorkspaceID: testWorkspaceID}
+	deletedBefore := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*MockDBRepository, *MockFileStorage)
+		expectedPurged int
+		expectedError  string
+	}{
+		{
+			name: "purges todos and the files no other todo shares",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("ListPurgeableTodos", mock.Anything, mock.MatchedBy(func(params db.ListPurgeableTodosParams) bool {
+					return params.DeletedBefore.Time.Equal(deletedBefore) && params.BatchSize == purgeBatchSize
+				})).Return([]db.ListPurgeableTodosRow{first, second}, nil)
+				fs.On("List", mock.Anything, firstPrefix).Return([]outbound.StoredFile{{Key: firstPrefix + "a"}, {Key: firstPrefix + "b"}}, nil)
+				// b is carried over to the next occurrence, c was carried over from the previous one
+				mockDB.On("ListAttachments", mock.Anything, firstID).Return([]db.Attachment{
+					{TodoID: firstID, StorageKey: firstPrefix + "b"},
+					{TodoID: firstID, StorageKey: "todos/previous/c"},
+				}, nil)
+				mockDB.On("ListSharedStorageKeys", mock.Anything, db.ListSharedStorageKeysParams{
+					StorageKeys: []string{firstPrefix + "a", firstPrefix + "b", "todos/previous/c"},
+					TodoID:      firstID,
+				}).Return([]string{firstPrefix + "b"}, nil)
+				fs.On("Delete", mock.Anything, firstPrefix+"a").Return(nil)
+				fs.On("Delete", mock.Anything, "todos/previous/c").Return(nil)
+				fs.On("List", mock.Anything, secondPrefix).Return([]outbound.StoredFile{}, nil)
+				mockDB.On("ListAttachments", mock.Anything, secondID).Return([]db.Attachment{}, nil)
+				mockDB.On("PurgeTodo", mock.Anything, firstID).Return(nil)
+				mockDB.On("PurgeTodo", mock.Anything, secondID).Return(nil)
+			},
+			expectedPurged: 2,
+		},
+		{
+			name: "nothing to purge",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("ListPurgeableTodos", mock.Anything, mock.Anything).Return([]db.ListPurgeableTodosRow{}, nil)
+			},
+		},
+		{
+			name: "file deletion failure keeps the todo and purges the others",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				mockDB.On("ListPurgeableTodos", mock.Anything, mock.Anything).Return([]db.ListPurgeableTodosRow{first, second}, nil)
+				fs.On("List", mock.Anything, firstPrefix).Return([]outbound.StoredFile{{Key: firstPrefix + "a"}}, nil)
+				mockDB.On("ListAttachments", mock.Anything, firstID).Return([]db.Attachment{}, nil)
+				mockDB.On("ListSharedStorageKeys", mock.Anything, mock.Anything).Return([]string{}, nil)
+				fs.On("Delete", mock.Anything, firstPrefix+"a").Return(errors.New("s3 error"))
+				fs.On("List", mock.Anything, secondPrefix).Return([]outbound.StoredFile{}, nil)
+				mockDB.On("ListAttachments", mock.Anything, secondID).Return([]db.Attachment{}, nil)
+				mockDB.On("PurgeTodo", mock.Anything, secondID).Return(nil)
+			},
+			expectedPurged: 1,
+			expectedError:  "failed to delete files of todo",
+		},
+		{
+			name: "database error",
+			setupMocks: func(mockDB *MockDBRepository, _ *MockFileStorage) {
+				mockDB.On("ListPurgeableTodos", mock.Anything, mock.Anything).Return([]db.ListPurgeableTodosRow{}, errors.New("db error"))
+			},
+			expectedError: "failed to list purgeable todos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			purged, err := service.PurgeDeletedTodos(ownerContext(), deletedBefore)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -225,17 +1978,143 @@ func TestPublishTodoEvent(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.Equal(t, tt.expectedPurged, purged)
 
-			mockMP.AssertExpectations(t)
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGenerateFileKey(t *testing.T) {
-	todoID := uuid.New().String()
-	key := generateFileKey(todoID)
+func TestPurgeDeletedTodosPagesPastFailures(t *testing.T) {
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	batch := make([]db.ListPurgeableTodosRow, 0, purgeBatchSize)
+	for i := 0; i < purgeBatchSize; i++ {
+		batch = append(batch, db.ListPurgeableTodosRow{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			WorkspaceID: testWorkspaceID,
+			DeletedAt:   pgtype.Timestamptz{Time: deletedAt, Valid: true},
+		})
+	}
+	last := batch[len(batch)-1]
+
+	mockDB := new(MockDBRepository)
+	mockFS := new(MockFileStorage)
+	mockDB.On("ListPurgeableTodos", mock.Anything, mock.MatchedBy(func(params db.ListPurgeableTodosParams) bool {
+		return !params.CursorID.Valid
+	})).Return(batch, nil).Once()
+	// the failed todos are still in the trash, the second page has to start after them
+	mockDB.On("ListPurgeableTodos", mock.Anything, mock.MatchedBy(func(params db.ListPurgeableTodosParams) bool {
+		return params.CursorID == last.ID && params.CursorDeletedAt == last.DeletedAt
+	})).Return([]db.ListPurgeableTodosRow{}, nil).Once()
+	mockFS.On("List", mock.Anything, mock.Anything).Return([]outbound.StoredFile{}, errors.New("s3 error"))
+
+	service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+	purged, err := service.PurgeDeletedTodos(ownerContext(), time.Now().Add(-time.Hour))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, purged)
+	mockDB.AssertExpectations(t)
+}
+
+func TestReconcileFiles(t *testing.T) {
+	modifiedBefore := time.Now().Add(-time.Hour)
+	old := modifiedBefore.Add(-time.Hour)
+	files := []outbound.StoredFile{
+		{Key: "todos/1/a", LastModified: old},
+		{Key: "todos/1/b", LastModified: old},
+		{Key: "workspaces/w/todos/2/c", LastModified: old},
+		{Key: "workspaces/w/todos/3/d", LastModified: time.Now()},
+	}
+
+	tests := []struct {
+		name           string
+		remove         bool
+		setupMocks     func(*MockDBRepository, *MockFileStorage)
+		expectedReport FileReconciliationReport
+		expectedError  string
+	}{
+		{
+			name: "reports orphans without deleting them",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				// files uploaded before workspaces are still kept under the legacy prefix
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return(files[2:], nil)
+				fs.On("List", mock.Anything, legacyTodoFilesPrefix).Return(files[:2], nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, []string{"workspaces/w/todos/2/c", "todos/1/a", "todos/1/b"}).
+					Return([]string{"todos/1/b"}, nil)
+			},
+			expectedReport: FileReconciliationReport{Scanned: 4, Skipped: 1, Orphans: []string{"workspaces/w/todos/2/c", "todos/1/a"}},
+		},
+		{
+			name:   "removes orphans",
+			remove: true,
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return(files, nil)
+				fs.On("List", mock.Anything, legacyTodoFilesPrefix).Return([]outbound.StoredFile{}, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, mock.Anything).
+					Return([]string{"todos/1/b"}, nil)
+				fs.On("Delete", mock.Anything, "todos/1/a").Return(nil)
+				fs.On("Delete", mock.Anything, "workspaces/w/todos/2/c").Return(nil)
+			},
+			expectedReport: FileReconciliationReport{Scanned: 4, Skipped: 1, Orphans: []string{"todos/1/a", "workspaces/w/todos/2/c"}, Removed: 2},
+		},
+		{
+			name: "nothing stored",
+			setupMocks: func(_ *MockDBRepository, fs *MockFileStorage) {
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return([]outbound.StoredFile{}, nil)
+				fs.On("List", mock.Anything, legacyTodoFilesPrefix).Return([]outbound.StoredFile{}, nil)
+			},
+		},
+		{
+			name: "list error",
+			setupMocks: func(_ *MockDBRepository, fs *MockFileStorage) {
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return([]outbound.StoredFile{}, errors.New("s3 error"))
+			},
+			expectedError: "failed to list stored files",
+		},
+		{
+			name: "database error",
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return(files, nil)
+				fs.On("List", mock.Anything, legacyTodoFilesPrefix).Return([]outbound.StoredFile{}, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, mock.Anything).Return([]string{}, errors.New("db error"))
+			},
+			expectedError:  "failed to list referenced files",
+			expectedReport: FileReconciliationReport{Scanned: 4, Skipped: 1},
+		},
+		{
+			name:   "delete error",
+			remove: true,
+			setupMocks: func(mockDB *MockDBRepository, fs *MockFileStorage) {
+				fs.On("List", mock.Anything, workspaceFilesPrefix).Return(files[:1], nil)
+				fs.On("List", mock.Anything, legacyTodoFilesPrefix).Return([]outbound.StoredFile{}, nil)
+				mockDB.On("ListReferencedStorageKeys", mock.Anything, mock.Anything).Return([]string{}, nil)
+				fs.On("Delete", mock.Anything, "todos/1/a").Return(errors.New("s3 error"))
+			},
+			expectedError:  "failed to delete orphaned file todos/1/a",
+			expectedReport: FileReconciliationReport{Scanned: 1, Orphans: []string{"todos/1/a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockFS := new(MockFileStorage)
+			tt.setupMocks(mockDB, mockFS)
+
+			service := NewTodoService(mockDB, mockFS, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+			report, err := service.ReconcileFiles(ownerContext(), modifiedBefore, tt.remove)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedReport, report)
 
-	assert.Contains(t, key, todoID)
-	assert.Contains(t, key, "todos/")
-	assert.Regexp(t, `^todos/[^/]+/[^/]+$`, key)
+			mockDB.AssertExpectations(t)
+			mockFS.AssertExpectations(t)
+		})
+	}
 }
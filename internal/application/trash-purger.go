@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TrashPurger periodically removes todos that have been in the trash for longer than the retention.
+type TrashPurger struct {
+	todoService *TodoService
+	retention   time.Duration
+	interval    time.Duration
+	logger      *slog.Logger
+}
+
+func NewTrashPurger(todoService *TodoService, retention, interval time.Duration, logger *slog.Logger) *TrashPurger {
+	return &TrashPurger{todoService: todoService, retention: retention, interval: interval, logger: logger}
+}
+
+// Run purges the trash once immediately and then on every interval until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	purged, err := p.todoService.PurgeDeletedTodos(ctx, time.Now().UTC().Add(-p.retention))
+	if err != nil {
+		p.logger.Error("failed to purge trash", "error", err, "purged", purged)
+		return
+	}
+	if purged > 0 {
+		p.logger.Info("purged trash", "purged", purged)
+	}
+}
@@ -0,0 +1,30 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrashPurgerRun(t *testing.T) {
+	retention := 24 * time.Hour
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("ListPurgeableTodos", mock.Anything, mock.MatchedBy(func(params db.ListPurgeableTodosParams) bool {
+		// the cutoff must be roughly now minus the retention
+		return time.Since(params.DeletedBefore.Time)-retention < time.Minute
+	})).Return([]db.ListPurgeableTodosRow{}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	purger := NewTrashPurger(NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default()), retention, time.Hour, slog.Default())
+	purger.Run(ctx)
+
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UserService keeps track of the users requests are authenticated as.
+type UserService struct {
+	userRepository outbound.DBRepository
+	logger         *slog.Logger
+}
+
+func NewUserService(userRepository outbound.DBRepository, logger *slog.Logger) *UserService {
+	return &UserService{userRepository: userRepository, logger: logger}
+}
+
+// SignIn returns the user a verified token was issued to, creating the user together with a personal workspace on
+// their first sign-in. The email and the name are updated when the token carries different ones.
+func (s *UserService) SignIn(ctx context.Context, subject, email, name string) (domain.User, error) {
+	if subject == "" {
+		return domain.User{}, domain.ErrInvalidToken
+	}
+
+	row, err := s.userRepository.GetUserBySubject(ctx, subject)
+	switch {
+	case err == nil:
+		if row.Email == email && row.Name == name {
+			return toDomainUser(row), nil
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return domain.User{}, databaseError(fmt.Errorf("failed to get user from repository: %w", err))
+	}
+
+	err = s.userRepository.ExecTx(ctx, func(q db.Querier) error {
+		// two first requests of a user may race, the upsert makes the second one find the user the first one
+		// created and wait for its transaction, so only one of them creates the personal workspace
+		saved, err := q.UpsertUser(ctx, db.UpsertUserParams{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Subject:   subject,
+			Email:     email,
+			Name:      name,
+			CreatedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to save user to repository: %w", err))
+		}
+		row = saved
+
+		memberships, err := q.CountWorkspaceMemberships(ctx, saved.ID)
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to count workspace memberships in repository: %w", err))
+		}
+		if memberships > 0 {
+			return nil
+		}
+		_, err = createWorkspace(ctx, q, saved.ID, personalWorkspaceName)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return toDomainUser(row), nil
+}
+
+// ownerID returns the ID of the user the request is made for, who creates todos and grants shares.
+func ownerID(ctx context.Context) (pgtype.UUID, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return pgtype.UUID{}, domain.ErrUnauthenticatedRequest
+	}
+	userUUID, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return pgtype.UUID{}, domain.ErrUnauthenticatedRequest
+	}
+	return pgtype.UUID{Bytes: userUUID, Valid: true}, nil
+}
+
+func toDomainUser(row db.User) domain.User {
+	return domain.User{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		Subject:   row.Subject,
+		Email:     row.Email,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt.Time.UTC(),
+		UpdatedAt: row.UpdatedAt.Time.UTC(),
+	}
+}
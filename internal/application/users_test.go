@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSignIn(t *testing.T) {
+	existing := db.User{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Subject: "auth0|42",
+		Email:   "ada@example.com",
+		Name:    "Ada",
+	}
+
+	tests := []struct {
+		name          string
+		subject       string
+		email         string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+		expectedError string
+	}{
+		{
+			name:    "known user",
+			subject: "auth0|42",
+			email:   "ada@example.com",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetUserBySubject", mock.Anything, "auth0|42").Return(existing, nil)
+			},
+		},
+		{
+			name:    "changed email",
+			subject: "auth0|42",
+			email:   "ada@example.org",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetUserBySubject", mock.Anything, "auth0|42").Return(existing, nil)
+				mockDB.On("UpsertUser", mock.Anything, mock.MatchedBy(func(params db.UpsertUserParams) bool {
+					return params.Subject == "auth0|42" && params.Email == "ada@example.org"
+				})).Return(db.User{ID: existing.ID, Subject: "auth0|42", Email: "ada@example.org", Name: "Ada"}, nil)
+				mockDB.On("CountWorkspaceMemberships", mock.Anything, existing.ID).Return(int64(1), nil)
+			},
+		},
+		{
+			name:    "first sign-in",
+			subject: "auth0|42",
+			email:   "ada@example.com",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetUserBySubject", mock.Anything, "auth0|42").Return(db.User{}, pgx.ErrNoRows)
+				mockDB.On("UpsertUser", mock.Anything, mock.MatchedBy(func(params db.UpsertUserParams) bool {
+					return params.ID.Valid && params.CreatedAt.Valid
+				})).Return(existing, nil)
+				mockDB.On("CountWorkspaceMemberships", mock.Anything, existing.ID).Return(int64(0), nil)
+				mockDB.On("CreateWorkspace", mock.Anything, mock.MatchedBy(func(params db.CreateWorkspaceParams) bool {
+					return params.ID.Valid && params.Name == "Personal"
+				})).Return(nil)
+				mockDB.On("UpsertWorkspaceMember", mock.Anything, mock.MatchedBy(func(params db.UpsertWorkspaceMemberParams) bool {
+					return params.UserID == existing.ID && params.Role == string(domain.RoleOwner)
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "without subject",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrInvalidToken,
+		},
+		{
+			name:    "database error",
+			subject: "auth0|42",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetUserBySubject", mock.Anything, "auth0|42").Return(db.User{}, errors.New("db error"))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+			expectedError: "failed to get user from repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewUserService(mockDB, slog.Default())
+			user, err := service.SignIn(context.Background(), tt.subject, tt.email, "Ada")
+
+			switch {
+			case tt.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				if tt.expectedError != "" {
+					assert.Contains(t, err.Error(), tt.expectedError)
+				}
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, uuid.UUID(existing.ID.Bytes).String(), user.ID)
+				assert.Equal(t, tt.email, user.Email)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOwnerID(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		expectedErrIs error
+	}{
+		{
+			name: "authenticated request",
+			ctx:  ownerContext(),
+		},
+		{
+			name:          "without principal",
+			ctx:           context.Background(),
+			expectedErrIs: domain.ErrUnauthenticatedRequest,
+		},
+		{
+			name:          "principal without user ID",
+			ctx:           domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "auth0|42"}),
+			expectedErrIs: domain.ErrUnauthenticatedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ownerID(tt.ctx)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testOwnerID, id)
+		})
+	}
+}
+
+func TestGetTodoOfAnotherWorkspace(t *testing.T) {
+	todoUUID := uuid.New()
+	pgID := pgtype.UUID{Bytes: todoUUID, Valid: true}
+
+	mockDB := new(MockDBRepository)
+	mockDB.On("GetTodo", mock.Anything, db.GetTodoParams{ID: pgID, WorkspaceID: testWorkspaceID}).Return(db.TodoItem{}, pgx.ErrNoRows)
+	expectNotShared(mockDB)
+
+	service := NewTodoService(mockDB, nil, nil, testUploadLimits, domain.CompletionPolicyBlock, slog.Default())
+	_, err := service.GetTodo(ownerContext(), todoUUID.String())
+
+	assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	mockDB.AssertExpectations(t)
+}
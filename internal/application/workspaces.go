@@ -0,0 +1,315 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	pgForeignKeyViolation = "23503"
+
+	// personalWorkspaceName is the name of the workspace every user gets on their first sign-in.
+	personalWorkspaceName = "Personal"
+)
+
+var (
+	errWorkspaceName = domain.NewValidationError("name", "name is required")
+	errWorkspaceRole = domain.NewValidationError("role", "role must be one of owner, editor and viewer")
+	errMemberUserID  = domain.NewValidationError("userId", "the user was not found")
+)
+
+// WorkspaceService manages the workspaces todos belong to and who may work in them.
+type WorkspaceService struct {
+	workspaceRepository outbound.DBRepository
+	logger              *slog.Logger
+}
+
+func NewWorkspaceService(workspaceRepository outbound.DBRepository, logger *slog.Logger) *WorkspaceService {
+	return &WorkspaceService{workspaceRepository: workspaceRepository, logger: logger}
+}
+
+// ResolveWorkspace returns the membership of the user of the request in the workspace, or in the first workspace
+// they joined when workspaceID is empty. Workspaces the user isn't a member of can't be told apart from those that
+// don't exist. A user without any workspace gets domain.ErrWorkspaceRequired.
+func (s *WorkspaceService) ResolveWorkspace(ctx context.Context, workspaceID string) (domain.WorkspaceMember, error) {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return domain.WorkspaceMember{}, err
+	}
+
+	var row db.WorkspaceMember
+	if workspaceID == "" {
+		row, err = s.workspaceRepository.GetDefaultWorkspaceMember(ctx, user)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.WorkspaceMember{}, domain.ErrWorkspaceRequired
+		}
+	} else {
+		row, err = workspaceMember(ctx, s.workspaceRepository, workspaceID, user)
+	}
+	if err != nil {
+		return domain.WorkspaceMember{}, err
+	}
+	return toDomainWorkspaceMember(row), nil
+}
+
+// CreateWorkspace creates a workspace with the user of the request as its owner.
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, name string) (domain.Workspace, error) {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return domain.Workspace{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.Workspace{}, errWorkspaceName
+	}
+
+	var workspace domain.Workspace
+	err = s.workspaceRepository.ExecTx(ctx, func(q db.Querier) error {
+		workspace, err = createWorkspace(ctx, q, user, name)
+		return err
+	})
+	if err != nil {
+		return domain.Workspace{}, err
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces returns the workspaces the user of the request is a member of, in the order they joined them.
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]domain.Workspace, error) {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.workspaceRepository.ListWorkspacesByUser(ctx, user)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list workspaces from repository: %w", err))
+	}
+	workspaces := make([]domain.Workspace, 0, len(rows))
+	for _, row := range rows {
+		workspaces = append(workspaces, domain.Workspace{
+			ID:        uuid.UUID(row.ID.Bytes).String(),
+			Name:      row.Name,
+			Role:      domain.Role(row.Role),
+			CreatedAt: row.CreatedAt.Time.UTC(),
+			UpdatedAt: row.UpdatedAt.Time.UTC(),
+		})
+	}
+	return workspaces, nil
+}
+
+// ListWorkspaceMembers returns the members of a workspace the user of the request is a member of.
+func (s *WorkspaceService) ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	caller, err := workspaceMember(ctx, s.workspaceRepository, workspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.workspaceRepository.ListWorkspaceMembers(ctx, caller.WorkspaceID)
+	if err != nil {
+		return nil, databaseError(fmt.Errorf("failed to list workspace members from repository: %w", err))
+	}
+	members := make([]domain.WorkspaceMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, domain.WorkspaceMember{
+			WorkspaceID: uuid.UUID(row.WorkspaceID.Bytes).String(),
+			UserID:      uuid.UUID(row.UserID.Bytes).String(),
+			Email:       row.Email,
+			Name:        row.Name,
+			Role:        domain.Role(row.Role),
+			CreatedAt:   row.CreatedAt.Time.UTC(),
+		})
+	}
+	return members, nil
+}
+
+// SetWorkspaceMember adds the user to the workspace with the role, or changes the role of a member. Only owners
+// can manage members, and the last owner can't be demoted.
+func (s *WorkspaceService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string, role domain.Role) error {
+	if !role.IsValid() {
+		return errWorkspaceRole
+	}
+	memberUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return errMemberUserID
+	}
+	member := pgtype.UUID{Bytes: memberUUID, Valid: true}
+
+	return s.manageMembers(ctx, workspaceID, func(q db.Querier, workspace pgtype.UUID) error {
+		if role != domain.RoleOwner {
+			if err := keepAnOwner(ctx, q, workspace, member); err != nil {
+				return err
+			}
+		}
+		err := q.UpsertWorkspaceMember(ctx, db.UpsertWorkspaceMemberParams{
+			WorkspaceID: workspace,
+			UserID:      member,
+			Role:        string(role),
+			CreatedAt:   pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+				return errMemberUserID
+			}
+			return databaseError(fmt.Errorf("failed to save workspace member to repository: %w", err))
+		}
+		return nil
+	})
+}
+
+// RemoveWorkspaceMember takes the user out of the workspace. Only owners can remove members, and the last owner
+// can't be removed.
+func (s *WorkspaceService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	memberUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrWorkspaceMemberNotFound
+	}
+	member := pgtype.UUID{Bytes: memberUUID, Valid: true}
+
+	return s.manageMembers(ctx, workspaceID, func(q db.Querier, workspace pgtype.UUID) error {
+		if err := keepAnOwner(ctx, q, workspace, member); err != nil {
+			return err
+		}
+		deleted, err := q.DeleteWorkspaceMember(ctx, db.DeleteWorkspaceMemberParams{WorkspaceID: workspace, UserID: member})
+		if err != nil {
+			return databaseError(fmt.Errorf("failed to delete workspace member in repository: %w", err))
+		}
+		if deleted == 0 {
+			return domain.ErrWorkspaceMemberNotFound
+		}
+		return nil
+	})
+}
+
+// manageMembers runs fn in a transaction holding the lock of the workspace, after making sure the user of the
+// request owns it.
+func (s *WorkspaceService) manageMembers(ctx context.Context, workspaceID string, fn func(q db.Querier, workspace pgtype.UUID) error) error {
+	user, err := ownerID(ctx)
+	if err != nil {
+		return err
+	}
+	return s.workspaceRepository.ExecTx(ctx, func(q db.Querier) error {
+		caller, err := workspaceMember(ctx, q, workspaceID, user)
+		if err != nil {
+			return err
+		}
+		if domain.Role(caller.Role) != domain.RoleOwner {
+			return domain.ErrWorkspaceOwnerRequired
+		}
+		if err := q.LockWorkspace(ctx, caller.WorkspaceID); err != nil {
+			return databaseError(fmt.Errorf("failed to lock workspace: %w", err))
+		}
+		return fn(q, caller.WorkspaceID)
+	})
+}
+
+// workspaceMember returns the membership of the user in the workspace.
+func workspaceMember(ctx context.Context, q db.Querier, workspaceID string, user pgtype.UUID) (db.WorkspaceMember, error) {
+	workspaceUUID, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return db.WorkspaceMember{}, domain.ErrWorkspaceNotFound
+	}
+	row, err := q.GetWorkspaceMember(ctx, db.GetWorkspaceMemberParams{
+		WorkspaceID: pgtype.UUID{Bytes: workspaceUUID, Valid: true},
+		UserID:      user,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.WorkspaceMember{}, domain.ErrWorkspaceNotFound
+		}
+		return db.WorkspaceMember{}, databaseError(fmt.Errorf("failed to get workspace member from repository: %w", err))
+	}
+	return row, nil
+}
+
+// keepAnOwner fails with domain.ErrLastWorkspaceOwner when the member is the only owner left in the workspace.
+func keepAnOwner(ctx context.Context, q db.Querier, workspace, member pgtype.UUID) error {
+	row, err := q.GetWorkspaceMember(ctx, db.GetWorkspaceMemberParams{WorkspaceID: workspace, UserID: member})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) { // not a member yet, so not an owner either
+			return nil
+		}
+		return databaseError(fmt.Errorf("failed to get workspace member from repository: %w", err))
+	}
+	if domain.Role(row.Role) != domain.RoleOwner {
+		return nil
+	}
+	owners, err := q.CountWorkspaceOwners(ctx, workspace)
+	if err != nil {
+		return databaseError(fmt.Errorf("failed to count workspace owners in repository: %w", err))
+	}
+	if owners <= 1 {
+		return domain.ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+// createWorkspace creates a workspace owned by the user.
+func createWorkspace(ctx context.Context, q db.Querier, owner pgtype.UUID, name string) (domain.Workspace, error) {
+	now := time.Now().UTC()
+	workspaceUUID := uuid.New()
+	workspace := pgtype.UUID{Bytes: workspaceUUID, Valid: true}
+	err := q.CreateWorkspace(ctx, db.CreateWorkspaceParams{
+		ID:        workspace,
+		Name:      name,
+		CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		return domain.Workspace{}, databaseError(fmt.Errorf("failed to save workspace to repository: %w", err))
+	}
+	err = q.UpsertWorkspaceMember(ctx, db.UpsertWorkspaceMemberParams{
+		WorkspaceID: workspace,
+		UserID:      owner,
+		Role:        string(domain.RoleOwner),
+		CreatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		return domain.Workspace{}, databaseError(fmt.Errorf("failed to save workspace member to repository: %w", err))
+	}
+	return domain.Workspace{
+		ID:        workspaceUUID.String(),
+		Name:      name,
+		Role:      domain.RoleOwner,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// workspaceID returns the ID of the workspace the request is made in. Todos are only looked up in that workspace,
+// so the ones of other workspaces can't be told apart from those that don't exist.
+func workspaceID(ctx context.Context) (pgtype.UUID, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return pgtype.UUID{}, domain.ErrUnauthenticatedRequest
+	}
+	workspaceUUID, err := uuid.Parse(principal.WorkspaceID)
+	if err != nil {
+		return pgtype.UUID{}, domain.ErrWorkspaceRequired
+	}
+	return pgtype.UUID{Bytes: workspaceUUID, Valid: true}, nil
+}
+
+func toDomainWorkspaceMember(row db.WorkspaceMember) domain.WorkspaceMember {
+	return domain.WorkspaceMember{
+		WorkspaceID: uuid.UUID(row.WorkspaceID.Bytes).String(),
+		UserID:      uuid.UUID(row.UserID.Bytes).String(),
+		Role:        domain.Role(row.Role),
+		CreatedAt:   row.CreatedAt.Time.UTC(),
+	}
+}
@@ -0,0 +1,266 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/infra/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolveWorkspace(t *testing.T) {
+	workspace := uuid.UUID(testWorkspaceID.Bytes).String()
+	membership := db.WorkspaceMember{WorkspaceID: testWorkspaceID, UserID: testOwnerID, Role: string(domain.RoleEditor)}
+
+	tests := []struct {
+		name          string
+		workspaceID   string
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:        "requested workspace",
+			workspaceID: workspace,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, db.GetWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: testOwnerID}).Return(membership, nil)
+			},
+		},
+		{
+			name: "default workspace",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetDefaultWorkspaceMember", mock.Anything, testOwnerID).Return(membership, nil)
+			},
+		},
+		{
+			name:        "not a member",
+			workspaceID: workspace,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, mock.Anything).Return(db.WorkspaceMember{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrWorkspaceNotFound,
+		},
+		{
+			name:          "invalid workspace ID",
+			workspaceID:   "not-a-uuid",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: domain.ErrWorkspaceNotFound,
+		},
+		{
+			name: "without workspaces",
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetDefaultWorkspaceMember", mock.Anything, testOwnerID).Return(db.WorkspaceMember{}, pgx.ErrNoRows)
+			},
+			expectedErrIs: domain.ErrWorkspaceRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewWorkspaceService(mockDB, slog.Default())
+			member, err := service.ResolveWorkspace(ownerContext(), tt.workspaceID)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, workspace, member.WorkspaceID)
+				assert.Equal(t, domain.RoleEditor, member.Role)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	mockDB := new(MockDBRepository)
+	mockDB.On("CreateWorkspace", mock.Anything, mock.MatchedBy(func(params db.CreateWorkspaceParams) bool {
+		return params.ID.Valid && params.Name == "Household"
+	})).Return(nil)
+	mockDB.On("UpsertWorkspaceMember", mock.Anything, mock.MatchedBy(func(params db.UpsertWorkspaceMemberParams) bool {
+		return params.UserID == testOwnerID && params.Role == string(domain.RoleOwner)
+	})).Return(nil)
+
+	service := NewWorkspaceService(mockDB, slog.Default())
+	workspace, err := service.CreateWorkspace(ownerContext(), "  Household ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Household", workspace.Name)
+	assert.Equal(t, domain.RoleOwner, workspace.Role)
+	mockDB.AssertExpectations(t)
+
+	_, err = service.CreateWorkspace(ownerContext(), " ")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestSetWorkspaceMember(t *testing.T) {
+	workspace := uuid.UUID(testWorkspaceID.Bytes).String()
+	memberID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	callerParams := db.GetWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: testOwnerID}
+	owner := db.WorkspaceMember{WorkspaceID: testWorkspaceID, UserID: testOwnerID, Role: string(domain.RoleOwner)}
+
+	tests := []struct {
+		name          string
+		userID        string
+		role          domain.Role
+		setupMock     func(*MockDBRepository)
+		expectedErrIs error
+	}{
+		{
+			name:   "adds a member",
+			userID: uuid.UUID(memberID.Bytes).String(),
+			role:   domain.RoleViewer,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, callerParams).Return(owner, nil)
+				mockDB.On("LockWorkspace", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("GetWorkspaceMember", mock.Anything, db.GetWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: memberID}).Return(db.WorkspaceMember{}, pgx.ErrNoRows)
+				mockDB.On("UpsertWorkspaceMember", mock.Anything, mock.MatchedBy(func(params db.UpsertWorkspaceMemberParams) bool {
+					return params.WorkspaceID == testWorkspaceID && params.UserID == memberID && params.Role == string(domain.RoleViewer)
+				})).Return(nil)
+			},
+		},
+		{
+			name:   "caller isn't an owner",
+			userID: uuid.UUID(memberID.Bytes).String(),
+			role:   domain.RoleEditor,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, callerParams).Return(db.WorkspaceMember{WorkspaceID: testWorkspaceID, Role: string(domain.RoleEditor)}, nil)
+			},
+			expectedErrIs: domain.ErrWorkspaceOwnerRequired,
+		},
+		{
+			name:   "demotes the last owner",
+			userID: uuid.UUID(testOwnerID.Bytes).String(),
+			role:   domain.RoleEditor,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, callerParams).Return(owner, nil)
+				mockDB.On("LockWorkspace", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("CountWorkspaceOwners", mock.Anything, testWorkspaceID).Return(int64(1), nil)
+			},
+			expectedErrIs: domain.ErrLastWorkspaceOwner,
+		},
+		{
+			name:   "unknown user",
+			userID: uuid.UUID(memberID.Bytes).String(),
+			role:   domain.RoleOwner,
+			setupMock: func(mockDB *MockDBRepository) {
+				mockDB.On("GetWorkspaceMember", mock.Anything, callerParams).Return(owner, nil)
+				mockDB.On("LockWorkspace", mock.Anything, testWorkspaceID).Return(nil)
+				mockDB.On("UpsertWorkspaceMember", mock.Anything, mock.Anything).Return(&pgconn.PgError{Code: pgForeignKeyViolation})
+			},
+			expectedErrIs: errMemberUserID,
+		},
+		{
+			name:          "invalid role",
+			userID:        uuid.UUID(memberID.Bytes).String(),
+			role:          "admin",
+			setupMock:     func(_ *MockDBRepository) {},
+			expectedErrIs: errWorkspaceRole,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			tt.setupMock(mockDB)
+
+			service := NewWorkspaceService(mockDB, slog.Default())
+			err := service.SetWorkspaceMember(ownerContext(), workspace, tt.userID, tt.role)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveWorkspaceMember(t *testing.T) {
+	workspace := uuid.UUID(testWorkspaceID.Bytes).String()
+	memberID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	memberParams := db.DeleteWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: memberID}
+	owner := db.WorkspaceMember{WorkspaceID: testWorkspaceID, UserID: testOwnerID, Role: string(domain.RoleOwner)}
+
+	tests := []struct {
+		name          string
+		removed       int64
+		removeErr     error
+		expectedErrIs error
+	}{
+		{name: "removes the member", removed: 1},
+		{name: "member not found", removed: 0, expectedErrIs: domain.ErrWorkspaceMemberNotFound},
+		{name: "database error", removeErr: errors.New("db error"), expectedErrIs: domain.ErrDependencyUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDBRepository)
+			mockDB.On("GetWorkspaceMember", mock.Anything, db.GetWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: testOwnerID}).Return(owner, nil)
+			mockDB.On("LockWorkspace", mock.Anything, testWorkspaceID).Return(nil)
+			mockDB.On("GetWorkspaceMember", mock.Anything, db.GetWorkspaceMemberParams{WorkspaceID: testWorkspaceID, UserID: memberID}).
+				Return(db.WorkspaceMember{WorkspaceID: testWorkspaceID, UserID: memberID, Role: string(domain.RoleEditor)}, nil)
+			mockDB.On("DeleteWorkspaceMember", mock.Anything, memberParams).Return(tt.removed, tt.removeErr)
+
+			service := NewWorkspaceService(mockDB, slog.Default())
+			err := service.RemoveWorkspaceMember(ownerContext(), workspace, uuid.UUID(memberID.Bytes).String())
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceID(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		expectedErrIs error
+	}{
+		{
+			name: "resolved workspace",
+			ctx:  ownerContext(),
+		},
+		{
+			name:          "without workspace",
+			ctx:           domain.ContextWithPrincipal(context.Background(), domain.Principal{UserID: uuid.UUID(testOwnerID.Bytes).String()}),
+			expectedErrIs: domain.ErrWorkspaceRequired,
+		},
+		{
+			name:          "without principal",
+			ctx:           context.Background(),
+			expectedErrIs: domain.ErrUnauthenticatedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := workspaceID(tt.ctx)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testWorkspaceID, id)
+		})
+	}
+}
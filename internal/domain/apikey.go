@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Scope is a permission an API key is granted.
+type Scope string
+
+const (
+	ScopeTodosRead        Scope = "todos:read"
+	ScopeTodosWrite       Scope = "todos:write"
+	ScopeAttachmentsWrite Scope = "attachments:write"
+)
+
+// Scopes are the scopes API keys can be granted.
+var Scopes = []Scope{ScopeTodosRead, ScopeTodosWrite, ScopeAttachmentsWrite}
+
+var (
+	ErrAPIKeyNotFound    = NewError(ErrNotFound, "APIKeyNotFound", "api key not found")
+	ErrInvalidAPIKey     = NewError(ErrUnauthenticated, "InvalidAPIKey", "the API key is invalid or revoked")
+	ErrInsufficientScope = NewError(ErrForbidden, "InsufficientScope", "the API key lacks the scope this request requires")
+	ErrAdminRequired     = NewError(ErrForbidden, "AdminRequired", "only administrators can manage API keys")
+)
+
+// APIKey authenticates a service instead of a user token. Requests made with the key act as its owner but can
+// only do what its scopes allow. Only a hash of the key is stored, Prefix tells keys apart in listings.
+type APIKey struct {
+	ID         string
+	OwnerID    string
+	Name       string
+	Prefix     string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// IsValid reports whether the scope is one API keys can be granted.
+func (s Scope) IsValid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// HasScope reports whether the principal may do what the scope allows. Users signed in with a token aren't
+// limited by scopes, only API keys are, and both are limited by their role in the workspace.
+func (p Principal) HasScope(scope Scope) bool {
+	return p.Role.Allows(scope) && (p.APIKeyID == "" || slices.Contains(p.Scopes, scope))
+}
+
+// RequireScope fails with ErrWorkspaceReadOnly when the principal of ctx is a viewer of the workspace the scope
+// would change, and with ErrInsufficientScope when its API key wasn't granted the scope.
+func RequireScope(ctx context.Context, scope Scope) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticatedRequest
+	}
+	if !principal.Role.Allows(scope) {
+		return ErrWorkspaceReadOnly
+	}
+	if !principal.HasScope(scope) {
+		return ErrInsufficientScope
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{
+			name: "user signed in with a token",
+			ctx:  ContextWithPrincipal(context.Background(), Principal{UserID: "ada"}),
+		},
+		{
+			name: "api key with the scope",
+			ctx:  ContextWithPrincipal(context.Background(), Principal{UserID: "ada", APIKeyID: "bot", Scopes: []Scope{ScopeAttachmentsWrite}}),
+		},
+		{
+			name:        "api key without the scope",
+			ctx:         ContextWithPrincipal(context.Background(), Principal{UserID: "ada", APIKeyID: "bot", Scopes: []Scope{ScopeTodosWrite}}),
+			expectedErr: ErrInsufficientScope,
+		},
+		{
+			name: "editor of the workspace",
+			ctx:  ContextWithPrincipal(context.Background(), Principal{UserID: "ada", WorkspaceID: "team", Role: RoleEditor}),
+		},
+		{
+			name:        "viewer of the workspace",
+			ctx:         ContextWithPrincipal(context.Background(), Principal{UserID: "ada", WorkspaceID: "team", Role: RoleViewer}),
+			expectedErr: ErrWorkspaceReadOnly,
+		},
+		{
+			name:        "unauthenticated",
+			ctx:         context.Background(),
+			expectedErr: ErrUnauthenticatedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequireScope(tt.ctx, ScopeAttachmentsWrite)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
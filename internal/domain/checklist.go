@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxChecklistItems      = 50
+	maxChecklistTextLength = 255
+)
+
+var (
+	ErrChecklistItemNotFound = NewError(ErrNotFound, "ChecklistItemNotFound", "checklist item not found")
+	ErrChecklistFull         = NewError(ErrConflict, "ChecklistFull", fmt.Sprintf("a todo can have at most %d checklist items", MaxChecklistItems))
+
+	errEmptyChecklistText   = NewValidationError("text", "checklist item text cannot be empty")
+	errChecklistTextTooLong = NewValidationError("text", fmt.Sprintf("checklist item text must be at most %d characters", maxChecklistTextLength))
+)
+
+// ChecklistItem is a step of a todo that is ticked off rather than tracked like a subtask.
+type ChecklistItem struct {
+	ID        string
+	TodoID    string
+	Text      string
+	Done      bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ChecklistItemPatch describes a change to a checklist item. Nil fields are left untouched.
+type ChecklistItemPatch struct {
+	Text *string
+	Done *bool
+}
+
+// Validate trims the text of the item and checks it.
+func (c *ChecklistItem) Validate() error {
+	c.Text = strings.TrimSpace(c.Text)
+	if c.Text == "" {
+		return errEmptyChecklistText
+	}
+	if utf8.RuneCountInString(c.Text) > maxChecklistTextLength {
+		return errChecklistTextTooLong
+	}
+	return nil
+}
+
+// ApplyPatch applies the patch to the checklist item.
+func (c *ChecklistItem) ApplyPatch(patch ChecklistItemPatch) error {
+	if patch.Text != nil {
+		c.Text = *patch.Text
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	if patch.Done != nil {
+		c.Done = *patch.Done
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklistItemApplyPatch(t *testing.T) {
+	text := "  Pack the charger "
+	empty := " "
+	long := strings.Repeat("a", maxChecklistTextLength+1)
+	done := true
+
+	tests := []struct {
+		name        string
+		patch       ChecklistItemPatch
+		expected    ChecklistItem
+		expectedErr error
+	}{
+		{name: "text is trimmed", patch: ChecklistItemPatch{Text: &text}, expected: ChecklistItem{Text: "Pack the charger"}},
+		{name: "ticked off", patch: ChecklistItemPatch{Done: &done}, expected: ChecklistItem{Text: "Pack", Done: true}},
+		{name: "empty text", patch: ChecklistItemPatch{Text: &empty}, expectedErr: errEmptyChecklistText},
+		{name: "text too long", patch: ChecklistItemPatch{Text: &long}, expectedErr: errChecklistTextTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := ChecklistItem{Text: "Pack"}
+
+			err := item.ApplyPatch(tt.patch)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, item)
+		})
+	}
+}
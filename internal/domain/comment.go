@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxCommentTextLength = 2000
+
+var (
+	errEmptyCommentText   = NewValidationError("text", "comment text cannot be empty")
+	errCommentTextTooLong = NewValidationError("text", fmt.Sprintf("comment text must be at most %d characters", maxCommentTextLength))
+)
+
+// Comment is a remark left on a todo by a member of its workspace or by a user it is shared with for commenting.
+type Comment struct {
+	ID        string
+	TodoID    string
+	AuthorID  string // Empty once the author is deleted
+	Text      string
+	CreatedAt time.Time
+}
+
+// Validate trims the text of the comment and checks it.
+func (c *Comment) Validate() error {
+	c.Text = strings.TrimSpace(c.Text)
+	if c.Text == "" {
+		return errEmptyCommentText
+	}
+	if utf8.RuneCountInString(c.Text) > maxCommentTextLength {
+		return errCommentTextTooLong
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		expected    string
+		expectedErr error
+	}{
+		{name: "trims the text", text: "  Booked the hotel \n", expected: "Booked the hotel"},
+		{name: "blank text", text: " \t", expectedErr: errEmptyCommentText},
+		{name: "too long", text: strings.Repeat("a", maxCommentTextLength+1), expectedErr: errCommentTextTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment := Comment{Text: tt.text}
+			err := comment.Validate()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, comment.Text)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"container/heap"
+	"sort"
+)
+
+var (
+	ErrTodoDependencyNotFound = NewError(ErrNotFound, "TodoDependencyNotFound", "dependency not found")
+	ErrDependencyCycle        = NewError(ErrConflict, "DependencyCycle", "todos can't wait for each other in a cycle")
+	ErrOpenBlockers           = NewError(ErrConflict, "OpenBlockers", "todo is blocked by todos that aren't finished")
+)
+
+// TodoDependency says the todo can't be completed before its blocker is done or cancelled.
+type TodoDependency struct {
+	TodoID    string
+	BlockerID string
+}
+
+// DependencyGraph holds todos and the dependencies between them.
+type DependencyGraph struct {
+	Todos        []TodoItem
+	Dependencies []TodoDependency
+}
+
+// TopologicalOrder returns the IDs of the todos so that every todo comes after its blockers. Todos that don't depend on
+// each other keep their order in Todos, dependencies on todos outside the graph are ignored. It fails with
+// ErrDependencyCycle when todos wait for each other.
+func (g DependencyGraph) TopologicalOrder() ([]string, error) {
+	index := make(map[string]int, len(g.Todos))
+	for i, todo := range g.Todos {
+		index[todo.ID] = i
+	}
+	waitingFor := make([]int, len(g.Todos))
+	unblocks := make([][]int, len(g.Todos))
+	for _, dependency := range g.Dependencies {
+		todo, ok := index[dependency.TodoID]
+		if !ok {
+			continue
+		}
+		blocker, ok := index[dependency.BlockerID]
+		if !ok {
+			continue
+		}
+		waitingFor[todo]++
+		unblocks[blocker] = append(unblocks[blocker], todo)
+	}
+
+	// the todo that comes first in Todos goes next among those whose blockers are all placed
+	ready := &todoIndexes{}
+	for i, blockers := range waitingFor {
+		if blockers == 0 {
+			heap.Push(ready, i)
+		}
+	}
+	order := make([]string, 0, len(g.Todos))
+	for ready.Len() > 0 {
+		i := heap.Pop(ready).(int)
+		order = append(order, g.Todos[i].ID)
+		for _, todo := range unblocks[i] {
+			waitingFor[todo]--
+			if waitingFor[todo] == 0 {
+				heap.Push(ready, todo)
+			}
+		}
+	}
+	if len(order) < len(g.Todos) {
+		return nil, ErrDependencyCycle
+	}
+	return order, nil
+}
+
+// todoIndexes is a min-heap of positions in DependencyGraph.Todos.
+type todoIndexes struct {
+	sort.IntSlice
+}
+
+func (h *todoIndexes) Push(x any) {
+	h.IntSlice = append(h.IntSlice, x.(int))
+}
+
+func (h *todoIndexes) Pop() any {
+	last := h.IntSlice[len(h.IntSlice)-1]
+	h.IntSlice = h.IntSlice[:len(h.IntSlice)-1]
+	return last
+}
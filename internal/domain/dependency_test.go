@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopologicalOrder(t *testing.T) {
+	todos := []TodoItem{{ID: "pack"}, {ID: "move"}, {ID: "book van"}, {ID: "clean"}}
+
+	tests := []struct {
+		name          string
+		dependencies  []TodoDependency
+		expectedOrder []string
+		expectedErr   error
+	}{
+		{
+			name:          "without dependencies",
+			expectedOrder: []string{"pack", "move", "book van", "clean"},
+		},
+		{
+			name: "blockers come first",
+			dependencies: []TodoDependency{
+				{TodoID: "move", BlockerID: "pack"},
+				{TodoID: "move", BlockerID: "book van"},
+				{TodoID: "pack", BlockerID: "clean"},
+			},
+			expectedOrder: []string{"book van", "clean", "pack", "move"},
+		},
+		{
+			name:          "blocker outside the graph",
+			dependencies:  []TodoDependency{{TodoID: "pack", BlockerID: "buy boxes"}},
+			expectedOrder: []string{"pack", "move", "book van", "clean"},
+		},
+		{
+			name: "cycle",
+			dependencies: []TodoDependency{
+				{TodoID: "move", BlockerID: "pack"},
+				{TodoID: "pack", BlockerID: "clean"},
+				{TodoID: "clean", BlockerID: "move"},
+			},
+			expectedErr: ErrDependencyCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := DependencyGraph{Todos: todos, Dependencies: tt.dependencies}
+
+			order, err := graph.TopologicalOrder()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOrder, order)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of failure callers have to tell apart. Every domain error matches exactly one of them with errors.Is,
+// so a transport can decide how to report it without knowing each error.
+var (
+	ErrValidation            = errors.New("validation failed")
+	ErrNotFound              = errors.New("not found")
+	ErrConflict              = errors.New("conflict")
+	ErrUnprocessable         = errors.New("unprocessable")
+	ErrUnauthenticated       = errors.New("unauthenticated")
+	ErrForbidden             = errors.New("forbidden")
+	ErrDependencyUnavailable = errors.New("dependency unavailable")
+)
+
+// Error is a failure of a known kind. Code is a stable identifier clients can match on.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func NewError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// ValidationError reports an input field the domain rules reject.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func NewValidationError(field, reason string) *ValidationError {
+	return &ValidationError{Field: field, Reason: reason}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// DependencyError reports that a dependency such as the database or the file storage failed to serve a request.
+type DependencyError struct {
+	Dependency string
+	Err        error
+}
+
+// Unavailable wraps err as a failure of the named dependency.
+func Unavailable(dependency string, err error) *DependencyError {
+	return &DependencyError{Dependency: dependency, Err: err}
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Dependency, e.Err)
+}
+
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DependencyError) Is(target error) bool {
+	return target == ErrDependencyUnavailable
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	kinds := []error{ErrValidation, ErrNotFound, ErrConflict, ErrUnauthenticated, ErrForbidden, ErrDependencyUnavailable}
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedKind error
+	}{
+		{name: "todo not found", err: ErrTodoNotFound, expectedKind: ErrNotFound},
+		{name: "attachment not found", err: ErrAttachmentNotFound, expectedKind: ErrNotFound},
+		{name: "version conflict", err: ErrTodoVersionConflict, expectedKind: ErrConflict},
+		{name: "invalid status transition", err: fmt.Errorf("%w: from done to blocked", ErrInvalidStatusTransition), expectedKind: ErrConflict},
+		{name: "invalid status", err: ErrInvalidTodoStatus, expectedKind: ErrValidation},
+		{name: "too many files", err: ErrTooManyFiles, expectedKind: ErrValidation},
+		{name: "invalid token", err: ErrInvalidToken, expectedKind: ErrUnauthenticated},
+		{name: "insufficient scope", err: ErrInsufficientScope, expectedKind: ErrForbidden},
+		{name: "wrapped validation error", err: fmt.Errorf("todo validation failed: %w", errDueDateInPast), expectedKind: ErrValidation},
+		{name: "dependency error", err: Unavailable("database", errors.New("connection refused")), expectedKind: ErrDependencyUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, kind := range kinds {
+				assert.Equal(t, kind == tt.expectedKind, errors.Is(tt.err, kind), "kind %q", kind)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	todo := TodoItem{Description: "Test todo"}
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, todo.Validate(), &validationErr)
+	assert.Equal(t, "dueDate", validationErr.Field)
+}
+
+func TestDependencyError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("failed to save todo: %w", Unavailable("database", cause))
+
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, "failed to save todo: database: connection refused")
+}
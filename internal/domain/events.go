@@ -0,0 +1,161 @@
+package domain
+
+import "time"
+
+const (
+	EventTypeTodoCreated  = "todo.created"
+	EventTypeTodoUpdated  = "todo.updated"
+	EventTypeTodoDeleted  = "todo.deleted"
+	EventTypeTodoRestored = "todo.restored"
+
+	EventTypeTodoStatusChanged = "todo.status_changed"
+	EventTypeTodoMoved         = "todo.moved"
+
+	EventTypeTodoAttachmentAdded   = "todo.attachment_added"
+	EventTypeTodoAttachmentRemoved = "todo.attachment_removed"
+
+	EventTypeTodoChecklistItemAdded   = "todo.checklist_item_added"
+	EventTypeTodoChecklistItemUpdated = "todo.checklist_item_updated"
+	EventTypeTodoChecklistItemRemoved = "todo.checklist_item_removed"
+
+	EventTypeTodoDependencyAdded   = "todo.dependency_added"
+	EventTypeTodoDependencyRemoved = "todo.dependency_removed"
+
+	EventTypeTodoCommentAdded = "todo.comment_added"
+
+	EventTypeTodoShared   = "todo.shared"
+	EventTypeTodoUnshared = "todo.unshared"
+
+	EventTypeListShared   = "list.shared"
+	EventTypeListUnshared = "list.unshared"
+)
+
+type TodoItemCreateEvent struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	DueDate     time.Time `json:"due_date"`
+	TimeZone    string    `json:"timezone,omitempty"`
+	Attachments []string  `json:"attachments"` // Attachment IDs
+	Tags        []string  `json:"tags"`        // Tag names
+	Priority    string    `json:"priority"`
+	Recurrence  string    `json:"recurrence,omitempty"`  // RRULE
+	RecursFrom  string    `json:"recurs_from,omitempty"` // ID of the completed todo this occurrence follows
+	ParentID    string    `json:"parent_id,omitempty"`
+	ListID      string    `json:"list_id,omitempty"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type TodoItemUpdateEvent struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	DueDate     time.Time `json:"due_date"`
+	TimeZone    string    `json:"timezone,omitempty"`
+	Tags        []string  `json:"tags"` // Tag names
+	Priority    string    `json:"priority"`
+	Recurrence  string    `json:"recurrence,omitempty"` // RRULE
+	ParentID    string    `json:"parent_id,omitempty"`
+	ListID      string    `json:"list_id,omitempty"`
+	Version     int32     `json:"version"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type TodoItemDeleteEvent struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TodoItemRestoreEvent struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id"`
+	RestoredAt time.Time `json:"restored_at"`
+}
+
+// TodoItemLifecycleEvent is published on every status transition of a todo.
+type TodoItemLifecycleEvent struct {
+	Type        string     `json:"type"`
+	ID          string     `json:"id"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Version     int32      `json:"version"`
+	ChangedAt   time.Time  `json:"changed_at"`
+}
+
+// TodoItemMoveEvent is published when a todo is moved to another position.
+type TodoItemMoveEvent struct {
+	Type    string    `json:"type"`
+	ID      string    `json:"id"`
+	Rank    string    `json:"rank"`
+	Version int32     `json:"version"`
+	MovedAt time.Time `json:"moved_at"`
+}
+
+// TodoItemAttachmentEvent is published when a file is attached to or removed from a todo.
+type TodoItemAttachmentEvent struct {
+	Type         string    `json:"type"`
+	ID           string    `json:"id"`
+	AttachmentID string    `json:"attachment_id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	ChangedAt    time.Time `json:"changed_at"`
+}
+
+// TodoItemChecklistEvent is published when an item is added to, changed on or removed from the checklist of a todo.
+type TodoItemChecklistEvent struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	ItemID    string    `json:"item_id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// TodoItemCommentEvent is published when a comment is left on a todo.
+type TodoItemCommentEvent struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	CommentID string    `json:"comment_id"`
+	AuthorID  string    `json:"author_id,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TodoItemDependencyEvent is published when a todo starts or stops waiting for a blocker.
+type TodoItemDependencyEvent struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	BlockerID string    `json:"blocker_id"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// TodoItemShareEvent is published when a todo is shared with a user or a workspace, when the level of a share
+// changes and when a share is revoked, so the recipients can be told. Level and GrantedBy are empty once revoked.
+type TodoItemShareEvent struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	ShareID     string    `json:"share_id"`
+	UserID      string    `json:"user_id,omitempty"`
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	Level       string    `json:"level,omitempty"`
+	GrantedBy   string    `json:"granted_by,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// ListShareEvent is published like TodoItemShareEvent when a list is shared, when the level of a share changes and
+// when a share is revoked. Level and GrantedBy are empty once revoked.
+type ListShareEvent struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	ShareID     string    `json:"share_id"`
+	UserID      string    `json:"user_id,omitempty"`
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	Level       string    `json:"level,omitempty"`
+	GrantedBy   string    `json:"granted_by,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+var (
+	ErrFileTooLarge        = NewError(ErrValidation, "FileTooLarge", "file exceeds the maximum upload size")
+	ErrTooManyFiles        = NewError(ErrValidation, "TooManyFiles", "too many files in a single upload")
+	ErrUnsupportedFileType = NewError(ErrValidation, "UnsupportedFileType", "file type is not supported")
+	ErrAttachmentNotFound  = NewError(ErrNotFound, "AttachmentNotFound", "attachment not found")
+)
+
+// Attachment is a file stored for a todo.
+type Attachment struct {
+	ID          string
+	TodoID      string
+	StorageKey  string
+	Filename    string
+	ContentType string
+	Size        int64
+	Checksum    string // Hex encoded SHA-256 of the content
+	UploadedAt  time.Time
+}
+
+// FileUpload is a file attached to a todo, its content is streamed to the file storage.
+// The content type is detected from the content, whatever the client claims is ignored.
+type FileUpload struct {
+	Filename string
+	Content  io.Reader
+	Size     int64 // -1 when the length isn't known up front
+}
+
+// FileDownload is an opened attachment, the caller must close Content.
+type FileDownload struct {
+	Filename    string
+	Content     io.ReadCloser
+	ContentType string
+	Size        int64
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxListNameLength        = 100
+	maxListDescriptionLength = 1000
+)
+
+var (
+	ErrListNotFound  = NewError(ErrNotFound, "ListNotFound", "list not found")
+	ErrListNameTaken = NewError(ErrConflict, "ListNameTaken", "a list with this name already exists")
+
+	errEmptyListName          = NewValidationError("name", "list name cannot be empty")
+	errListNameTooLong        = NewValidationError("name", fmt.Sprintf("list name must be at most %d characters", maxListNameLength))
+	errListDescriptionTooLong = NewValidationError("description", fmt.Sprintf("list description must be at most %d characters", maxListDescriptionLength))
+)
+
+// List groups the todos of a workspace into a project. Names are unique within the workspace and a todo belongs to
+// at most one list. Archived lists keep their todos but take no new ones.
+type List struct {
+	ID          string
+	Name        string
+	Description string
+	Colour      string // Hex colour such as #ff8800, empty when the list has none
+	Archived    bool
+	DefaultSort string // Order the todos of the list are returned in unless another one is asked for
+	Counts      ListCounts
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ListCounts counts the todos of a list for a dashboard. Open todos are neither done nor cancelled, overdue ones
+// are open todos past their due date.
+type ListCounts struct {
+	Open    int
+	Overdue int
+	Done    int
+}
+
+// ListPatch describes a change to an existing list. Nil fields are left untouched.
+type ListPatch struct {
+	Name        *string
+	Description *string
+	Colour      *string // An empty colour removes it
+	Archived    *bool
+	DefaultSort *string
+}
+
+// Normalize trims the name and the description and puts the colour in lower case.
+func (l *List) Normalize() {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Description = strings.TrimSpace(l.Description)
+	l.Colour = strings.ToLower(strings.TrimSpace(l.Colour))
+}
+
+func (l *List) Validate() error {
+	if l.Name == "" {
+		return errEmptyListName
+	}
+	if utf8.RuneCountInString(l.Name) > maxListNameLength {
+		return errListNameTooLong
+	}
+	if utf8.RuneCountInString(l.Description) > maxListDescriptionLength {
+		return errListDescriptionTooLong
+	}
+	if l.Colour != "" && !colourPattern.MatchString(l.Colour) {
+		return errInvalidColour
+	}
+	return nil
+}
+
+// ApplyPatch applies the patch to the list, the patched list is normalized and validated as a whole.
+func (l *List) ApplyPatch(patch ListPatch) error {
+	if patch.Name != nil {
+		l.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		l.Description = *patch.Description
+	}
+	if patch.Colour != nil {
+		l.Colour = *patch.Colour
+	}
+	if patch.Archived != nil {
+		l.Archived = *patch.Archived
+	}
+	if patch.DefaultSort != nil {
+		l.DefaultSort = *patch.DefaultSort
+	}
+	l.Normalize()
+	return l.Validate()
+}
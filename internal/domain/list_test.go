@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		list          List
+		expectedError error
+	}{
+		{name: "valid list", list: List{Name: "Home renovation", Description: "Kitchen first", Colour: "#ff8800"}},
+		{name: "no colour", list: List{Name: "Home renovation"}},
+		{name: "empty name", list: List{Colour: "#ff8800"}, expectedError: errEmptyListName},
+		{name: "name too long", list: List{Name: strings.Repeat("a", maxListNameLength+1)}, expectedError: errListNameTooLong},
+		{name: "description too long", list: List{Name: "Home", Description: strings.Repeat("a", maxListDescriptionLength+1)}, expectedError: errListDescriptionTooLong},
+		{name: "named colour", list: List{Name: "Home", Colour: "orange"}, expectedError: errInvalidColour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.list.Validate()
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListApplyPatch(t *testing.T) {
+	name := "  Garden "
+	colour := "#FF8800"
+	noColour := ""
+	archived := true
+	blank := " "
+
+	tests := []struct {
+		name          string
+		patch         ListPatch
+		expected      List
+		expectedError error
+	}{
+		{
+			name:     "renames and normalizes",
+			patch:    ListPatch{Name: &name, Colour: &colour},
+			expected: List{Name: "Garden", Colour: "#ff8800", DefaultSort: "dueDate"},
+		},
+		{
+			name:     "archives",
+			patch:    ListPatch{Archived: &archived},
+			expected: List{Name: "Home", Colour: "#00ff00", Archived: true, DefaultSort: "dueDate"},
+		},
+		{
+			name:     "empty colour removes it",
+			patch:    ListPatch{Colour: &noColour},
+			expected: List{Name: "Home", DefaultSort: "dueDate"},
+		},
+		{
+			name:          "blank name",
+			patch:         ListPatch{Name: &blank},
+			expectedError: errEmptyListName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := List{Name: "Home", Colour: "#00ff00", DefaultSort: "dueDate"}
+
+			err := list.ApplyPatch(tt.patch)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, list)
+		})
+	}
+}
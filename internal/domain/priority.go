@@ -0,0 +1,32 @@
+package domain
+
+// TodoPriority is how urgent a todo is, P0 being the most urgent. Priorities compare like strings.
+type TodoPriority string
+
+const (
+	TodoPriorityP0 TodoPriority = "P0"
+	TodoPriorityP1 TodoPriority = "P1"
+	TodoPriorityP2 TodoPriority = "P2"
+	TodoPriorityP3 TodoPriority = "P3"
+
+	// DefaultTodoPriority is given to todos created without a priority.
+	DefaultTodoPriority = TodoPriorityP2
+)
+
+var errInvalidPriority = NewValidationError("priority", "priority must be one of P0, P1, P2 or P3")
+
+func ParseTodoPriority(s string) (TodoPriority, error) {
+	priority := TodoPriority(s)
+	if !priority.valid() {
+		return "", errInvalidPriority
+	}
+	return priority, nil
+}
+
+func (p TodoPriority) valid() bool {
+	switch p {
+	case TodoPriorityP0, TodoPriorityP1, TodoPriorityP2, TodoPriorityP3:
+		return true
+	}
+	return false
+}
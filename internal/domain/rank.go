@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ranks order todos manually. A rank is an integer part followed by a fraction, both written with rankDigits so
+// ranks compare like strings. The first character of the integer part encodes how many digits follow: a to z
+// count up from one digit for positive integers, Z to A the same for negative ones. Appending or prepending a
+// todo steps the integer, moving one between two others extends the fraction, so no other todo has to be
+// renumbered.
+
+// rankDigits are the digits of ranks in ascending byte order.
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestRankInteger can't be decremented, ranks before it only extend the fraction.
+var smallestRankInteger = "A" + strings.Repeat("0", 26)
+
+var (
+	errInvalidRank   = errors.New("invalid rank")
+	errPositionEmpty = NewValidationError("before", "either before or after must be set")
+	errPositionBoth  = NewValidationError("after", "only one of before and after may be set")
+	errPositionSelf  = NewValidationError("before", "a todo can't be moved next to itself")
+)
+
+// TodoPosition places a todo right before or right after another todo, exactly one of them is set.
+type TodoPosition struct {
+	Before string // ID of the todo to move in front of
+	After  string // ID of the todo to move behind
+}
+
+// Validate checks the position of the todo with the given ID.
+func (p TodoPosition) Validate(id string) error {
+	switch {
+	case p.Before == "" && p.After == "":
+		return errPositionEmpty
+	case p.Before != "" && p.After != "":
+		return errPositionBoth
+	case p.Before == id || p.After == id:
+		return errPositionSelf
+	}
+	return nil
+}
+
+// RankBetween returns a rank that sorts after before and before after. An empty before is the start of the order,
+// an empty after its end, so RankBetween("", "") is the rank of the first todo.
+func RankBetween(before, after string) (string, error) {
+	if before != "" {
+		if err := validateRank(before); err != nil {
+			return "", err
+		}
+	}
+	if after != "" {
+		if err := validateRank(after); err != nil {
+			return "", err
+		}
+	}
+	if before != "" && after != "" && before >= after {
+		return "", fmt.Errorf("%w: %q does not sort before %q", errInvalidRank, before, after)
+	}
+
+	switch {
+	case before == "" && after == "":
+		return "a0", nil
+	case before == "":
+		integer := after[:rankIntegerLength(after[0])]
+		if integer == smallestRankInteger {
+			return integer + rankMidpoint("", after[len(integer):]), nil
+		}
+		if integer < after { // after has a fraction, its integer alone sorts before it
+			return integer, nil
+		}
+		decremented, ok := decrementRankInteger(integer)
+		if !ok {
+			return "", fmt.Errorf("%w: no rank before %q", errInvalidRank, after)
+		}
+		return decremented, nil
+	case after == "":
+		integer := before[:rankIntegerLength(before[0])]
+		incremented, ok := incrementRankInteger(integer)
+		if !ok {
+			return integer + rankMidpoint(before[len(integer):], ""), nil
+		}
+		return incremented, nil
+	}
+
+	integerBefore := before[:rankIntegerLength(before[0])]
+	integerAfter := after[:rankIntegerLength(after[0])]
+	if integerBefore == integerAfter {
+		return integerBefore + rankMidpoint(before[len(integerBefore):], after[len(integerAfter):]), nil
+	}
+	incremented, ok := incrementRankInteger(integerBefore)
+	if ok && incremented < after {
+		return incremented, nil
+	}
+	return integerBefore + rankMidpoint(before[len(integerBefore):], ""), nil
+}
+
+// rankMidpoint returns a fraction between the fractions a and b, an empty b is the end of the order.
+// Fractions never end in a zero digit, otherwise nothing would fit in front of them.
+func rankMidpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && rankDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 { // keep the common prefix, a is padded with zeros
+			return b[:n] + rankMidpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(rankDigits, a[0])
+	}
+	digitB := len(rankDigits)
+	if b != "" {
+		digitB = strings.IndexByte(rankDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(rankDigits[(digitA+digitB+1)/2])
+	}
+	// the first digits are adjacent
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(rankDigits[digitA]) + rankMidpoint(rest, "")
+}
+
+func rankDigitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return rankDigits[0]
+}
+
+// rankIntegerLength is the length of the integer part starting with head, head included.
+func rankIntegerLength(head byte) int {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2
+	}
+	return 0
+}
+
+func incrementRankInteger(integer string) (string, bool) {
+	head, digits := integer[0], []byte(integer[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(rankDigits, digits[i]) + 1
+		if d < len(rankDigits) {
+			digits[i] = rankDigits[d]
+			return string(head) + string(digits), true
+		}
+		digits[i] = rankDigits[0]
+	}
+
+	// every digit carried over, the integer needs one more digit or, when negative, one less
+	switch head {
+	case 'Z':
+		return "a" + string(rankDigits[0]), true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		digits = append(digits, rankDigits[0])
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+func decrementRankInteger(integer string) (string, bool) {
+	last := rankDigits[len(rankDigits)-1]
+	head, digits := integer[0], []byte(integer[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(rankDigits, digits[i]) - 1
+		if d >= 0 {
+			digits[i] = rankDigits[d]
+			return string(head) + string(digits), true
+		}
+		digits[i] = last
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(last), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		digits = append(digits, last)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+func validateRank(rank string) error {
+	length := rankIntegerLength(rank[0])
+	if length == 0 || length > len(rank) {
+		return fmt.Errorf("%w: %q", errInvalidRank, rank)
+	}
+	for i := 1; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return fmt.Errorf("%w: %q", errInvalidRank, rank)
+		}
+	}
+	if rank == smallestRankInteger || (len(rank) > length && rank[len(rank)-1] == rankDigits[0]) {
+		return fmt.Errorf("%w: %q", errInvalidRank, rank)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name          string
+		before        string
+		after         string
+		expected      string
+		expectedError bool
+	}{
+		{name: "first rank", expected: "a0"},
+		{name: "append steps the integer", before: "a0", expected: "a1"},
+		{name: "append carries into a longer integer", before: "az", expected: "b00"},
+		{name: "prepend steps the integer down", after: "a0", expected: "Zz"},
+		{name: "prepend in front of a fraction keeps the integer", after: "a0V", expected: "a0"},
+		{name: "between adjacent integers extends the fraction", before: "a0", after: "a1", expected: "a0V"},
+		{name: "between integers with room", before: "a0", after: "a5", expected: "a1"},
+		{name: "between fractions", before: "a0V", after: "a0W", expected: "a0VV"},
+		{name: "after a migrated rank", before: "h00000009", expected: "h0000000A"},
+		{name: "wrong order", before: "a1", after: "a0", expectedError: true},
+		{name: "equal ranks", before: "a1", after: "a1", expectedError: true},
+		{name: "trailing zero", before: "a0V0", expectedError: true},
+		{name: "truncated integer", before: "c1", expectedError: true},
+		{name: "foreign digit", before: "a-", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, err := RankBetween(tt.before, tt.after)
+
+			if tt.expectedError {
+				assert.ErrorIs(t, err, errInvalidRank)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rank)
+		})
+	}
+}
+
+func TestRankBetweenKeepsOrder(t *testing.T) {
+	// moving todos to the same spot over and over only grows the rank slowly
+	before, after := "a0", "a1"
+	for i := 0; i < 100; i++ {
+		rank, err := RankBetween(before, after)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Less(t, before, rank)
+		assert.Less(t, rank, after)
+		if i%2 == 0 {
+			before = rank
+		} else {
+			after = rank
+		}
+	}
+	assert.LessOrEqual(t, len(before), 60)
+
+	// appending thousands of todos keeps the ranks short
+	rank := ""
+	for i := 0; i < 5000; i++ {
+		next, err := RankBetween(rank, "")
+		if !assert.NoError(t, err) {
+			return
+		}
+		if rank != "" {
+			assert.Less(t, rank, next)
+		}
+		rank = next
+	}
+	assert.Len(t, rank, 4)
+}
+
+func TestTodoPositionValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		position      TodoPosition
+		expectedError error
+	}{
+		{name: "before", position: TodoPosition{Before: "b"}},
+		{name: "after", position: TodoPosition{After: "b"}},
+		{name: "neither", position: TodoPosition{}, expectedError: errPositionEmpty},
+		{name: "both", position: TodoPosition{Before: "b", After: "c"}, expectedError: errPositionBoth},
+		{name: "next to itself", position: TodoPosition{After: "a"}, expectedError: errPositionSelf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.position.Validate("a")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+const maxRecurrenceLength = 255
+
+var (
+	errInvalidRecurrence     = NewValidationError("recurrence", fmt.Sprintf("recurrence must be an RFC 5545 RRULE of at most %d characters, e.g. FREQ=WEEKLY;BYDAY=MO", maxRecurrenceLength))
+	errRecurrenceHasStart    = NewValidationError("recurrence", "recurrence must not have a DTSTART, it starts at the due date")
+	errRecurrenceTooFrequent = NewValidationError("recurrence", "recurrence can't repeat more often than hourly")
+)
+
+// NormalizeRecurrence trims the rule, drops an RRULE: prefix and puts it in upper case.
+func NormalizeRecurrence(rule string) string {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	return strings.TrimPrefix(rule, "RRULE:")
+}
+
+// SetRecurrence makes the todo recur by the RRULE, an empty rule stops it from recurring.
+func (t *TodoItem) SetRecurrence(rule string) error {
+	rule = NormalizeRecurrence(rule)
+	if err := validateRecurrence(rule); err != nil {
+		return err
+	}
+	t.Recurrence = rule
+	return nil
+}
+
+// NextOccurrence returns the todo that follows this one in its recurrence, due at the first date of the rule after
+// its due date. The rule is evaluated in the time zone of the todo, so a todo due at 9:00 stays due at 9:00 across
+// daylight saving changes. The occurrence carries over the description, time zone, priority, tags and attachments;
+// it has no ID, status or timestamps yet. It returns false when the todo doesn't recur or its rule has ended.
+func (t *TodoItem) NextOccurrence() (TodoItem, bool) {
+	if t.Recurrence == "" {
+		return TodoItem{}, false
+	}
+
+	// a floating UNTIL is in the zone of the todo
+	loc := t.Location()
+	option, err := rrule.StrToROptionInLocation(t.Recurrence, loc)
+	if err != nil { // the rule was validated when it was set
+		return TodoItem{}, false
+	}
+	// the todo itself is the first of the COUNT occurrences, the next one carries the rest
+	if option.Count == 1 {
+		return TodoItem{}, false
+	}
+
+	option.Dtstart = t.DueDate.In(loc)
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return TodoItem{}, false
+	}
+	dueDate := rule.After(option.Dtstart.Truncate(time.Second), false)
+	if dueDate.IsZero() {
+		return TodoItem{}, false
+	}
+
+	recurrence := t.Recurrence
+	if option.Count > 1 {
+		recurrence = withRecurrenceCount(recurrence, option.Count-1)
+	}
+	return TodoItem{
+		Description: t.Description,
+		DueDate:     dueDate.UTC(),
+		TimeZone:    t.TimeZone,
+		Attachments: t.Attachments,
+		Tags:        t.Tags,
+		Priority:    t.Priority,
+		Recurrence:  recurrence,
+		ListID:      t.ListID,
+	}, true
+}
+
+func validateRecurrence(rule string) error {
+	if rule == "" {
+		return nil
+	}
+	if len(rule) > maxRecurrenceLength || strings.ContainsAny(rule, "\r\n") {
+		return errInvalidRecurrence
+	}
+	if strings.Contains(rule, "DTSTART") {
+		return errRecurrenceHasStart
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil || option.Count < 0 {
+		return errInvalidRecurrence
+	}
+	if _, err := rrule.NewRRule(*option); err != nil {
+		return errInvalidRecurrence
+	}
+	if option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return errRecurrenceTooFrequent
+	}
+	return nil
+}
+
+// withRecurrenceCount replaces the COUNT of the rule, leaving its other parts as they were written.
+func withRecurrenceCount(rule string, count int) string {
+	parts := strings.Split(rule, ";")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "COUNT=") {
+			parts[i] = "COUNT=" + strconv.Itoa(count)
+		}
+	}
+	return strings.Join(parts, ";")
+}
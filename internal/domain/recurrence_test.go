@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRecurrence(t *testing.T) {
+	tests := []struct {
+		name               string
+		rule               string
+		expectedRecurrence string
+		expectedErr        error
+	}{
+		{name: "weekly", rule: "FREQ=WEEKLY;BYDAY=MO,WE", expectedRecurrence: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{name: "normalized", rule: " rrule:freq=monthly;bymonthday=-1 ", expectedRecurrence: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{name: "empty stops recurring", rule: "", expectedRecurrence: ""},
+		{name: "without frequency", rule: "BYDAY=MO", expectedErr: errInvalidRecurrence},
+		{name: "unknown part", rule: "FREQ=DAILY;EVERY=2", expectedErr: errInvalidRecurrence},
+		{name: "out of bounds", rule: "FREQ=MONTHLY;BYMONTHDAY=32", expectedErr: errInvalidRecurrence},
+		{name: "negative count", rule: "FREQ=DAILY;COUNT=-1", expectedErr: errInvalidRecurrence},
+		{name: "with a start", rule: "FREQ=DAILY;DTSTART=20300101T000000Z", expectedErr: errRecurrenceHasStart},
+		{name: "every minute", rule: "FREQ=MINUTELY", expectedErr: errRecurrenceTooFrequent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{Recurrence: "FREQ=YEARLY"}
+
+			err := todo.SetRecurrence(tt.rule)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, "FREQ=YEARLY", todo.Recurrence)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRecurrence, todo.Recurrence)
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	// 10:00 on Friday 29 March 2030 in Berlin, which switches to summer time two days later
+	dueDate := time.Date(2030, time.March, 29, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		recurrence         string
+		timeZone           string
+		expectedDueDate    time.Time
+		expectedRecurrence string
+		expectedNone       bool
+	}{
+		{
+			name:            "daily across a daylight saving change",
+			recurrence:      "FREQ=DAILY;INTERVAL=3",
+			timeZone:        "Europe/Berlin",
+			expectedDueDate: time.Date(2030, time.April, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "daily in UTC",
+			recurrence:      "FREQ=DAILY;INTERVAL=3",
+			expectedDueDate: time.Date(2030, time.April, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "next weekday of the rule",
+			recurrence:      "FREQ=WEEKLY;BYDAY=TU,TH",
+			expectedDueDate: time.Date(2030, time.April, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "last day of the month",
+			recurrence:      "FREQ=MONTHLY;BYMONTHDAY=-1",
+			expectedDueDate: time.Date(2030, time.March, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:               "count carries over",
+			recurrence:         "FREQ=WEEKLY;COUNT=4",
+			expectedDueDate:    time.Date(2030, time.April, 5, 9, 0, 0, 0, time.UTC),
+			expectedRecurrence: "FREQ=WEEKLY;COUNT=3",
+		},
+		{name: "last of the count", recurrence: "FREQ=WEEKLY;COUNT=1", expectedNone: true},
+		{name: "past the end", recurrence: "FREQ=WEEKLY;UNTIL=20300401T000000Z", expectedNone: true},
+		{name: "not recurring", expectedNone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{
+				ID:          "7f3c1e2a-0000-4000-8000-000000000001",
+				Description: "Water the plants",
+				DueDate:     dueDate,
+				TimeZone:    tt.timeZone,
+				Tags:        []Tag{{Name: "home"}},
+				Priority:    TodoPriorityP1,
+				Recurrence:  tt.recurrence,
+				ListID:      "7f3c1e2a-0000-4000-8000-0000000000aa",
+				Status:      TodoStatusDone,
+			}
+
+			next, ok := todo.NextOccurrence()
+
+			if tt.expectedNone {
+				assert.False(t, ok)
+				return
+			}
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.expectedDueDate, next.DueDate)
+			expectedRecurrence := tt.expectedRecurrence
+			if expectedRecurrence == "" {
+				expectedRecurrence = tt.recurrence
+			}
+			assert.Equal(t, expectedRecurrence, next.Recurrence)
+			assert.Empty(t, next.ID)
+			assert.Empty(t, next.Status)
+			assert.Equal(t, todo.Description, next.Description)
+			assert.Equal(t, todo.TimeZone, next.TimeZone)
+			assert.Equal(t, todo.Tags, next.Tags)
+			assert.Equal(t, todo.Priority, next.Priority)
+			assert.Equal(t, todo.ListID, next.ListID)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// ShareLevel is how far a todo or list is shared with users outside its workspace. Every level includes the ones before it.
+type ShareLevel string
+
+const (
+	ShareLevelRead    ShareLevel = "read"
+	ShareLevelComment ShareLevel = "comment"
+	ShareLevelEdit    ShareLevel = "edit"
+)
+
+// ShareLevels are the levels a todo or list can be shared at, lowest first.
+var ShareLevels = []ShareLevel{ShareLevelRead, ShareLevelComment, ShareLevelEdit}
+
+var (
+	ErrShareNotFound    = NewError(ErrNotFound, "ShareNotFound", "share not found")
+	ErrTodoAccessDenied = NewError(ErrForbidden, "TodoAccessDenied", "the todo isn't shared with you at a level that allows this")
+)
+
+// Share gives a user, or every member of a workspace, access to a todo of another workspace, or to every todo of a
+// list. Exactly one of TodoID and ListID is set, and exactly one of UserID and WorkspaceID; sharing with a workspace
+// shares with a group of users that changes with its members.
+type Share struct {
+	ID          string
+	TodoID      string
+	ListID      string
+	UserID      string
+	WorkspaceID string
+	Level       ShareLevel
+	GrantedBy   string // The user who last granted the share
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Validate checks the grantee and the level of a share about to be granted.
+func (s Share) Validate() error {
+	if (s.UserID == "") == (s.WorkspaceID == "") {
+		return NewValidationError("userId", "either a user or a workspace to share with is required")
+	}
+	if !s.Level.IsValid() {
+		return NewValidationError("level", "must be one of read, comment or edit")
+	}
+	return nil
+}
+
+// IsValid reports whether todos can be shared at the level.
+func (l ShareLevel) IsValid() bool {
+	return slices.Contains(ShareLevels, l)
+}
+
+// Allows reports whether a share at the level is enough for what needs the required level.
+func (l ShareLevel) Allows(required ShareLevel) bool {
+	granted := slices.Index(ShareLevels, l)
+	return granted >= 0 && slices.Contains(ShareLevels[:granted+1], required)
+}
+
+// MaxShareLevel returns the highest of the levels a todo is shared with a user at, directly and through their
+// workspaces. It is empty when there are none.
+func MaxShareLevel(levels []ShareLevel) ShareLevel {
+	var highest ShareLevel
+	for _, level := range levels {
+		if slices.Index(ShareLevels, level) > slices.Index(ShareLevels, highest) {
+			highest = level
+		}
+	}
+	return highest
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareLevelAllows(t *testing.T) {
+	tests := []struct {
+		level    ShareLevel
+		required ShareLevel
+		expected bool
+	}{
+		{level: ShareLevelRead, required: ShareLevelRead, expected: true},
+		{level: ShareLevelRead, required: ShareLevelEdit, expected: false},
+		{level: ShareLevelComment, required: ShareLevelRead, expected: true},
+		{level: ShareLevelComment, required: ShareLevelEdit, expected: false},
+		{level: ShareLevelEdit, required: ShareLevelComment, expected: true},
+		{level: ShareLevelEdit, required: "", expected: false},
+		{level: "", required: ShareLevelRead, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.level)+" "+string(tt.required), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.level.Allows(tt.required))
+		})
+	}
+}
+
+func TestMaxShareLevel(t *testing.T) {
+	assert.Equal(t, ShareLevelEdit, MaxShareLevel([]ShareLevel{ShareLevelRead, ShareLevelEdit, ShareLevelComment}))
+	assert.Equal(t, ShareLevelRead, MaxShareLevel([]ShareLevel{ShareLevelRead}))
+	assert.Equal(t, ShareLevel(""), MaxShareLevel(nil))
+}
+
+func TestShareValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		share Share
+		field string
+	}{
+		{name: "with a user", share: Share{UserID: "user", Level: ShareLevelRead}},
+		{name: "with a workspace", share: Share{WorkspaceID: "workspace", Level: ShareLevelEdit}},
+		{name: "without a grantee", share: Share{Level: ShareLevelRead}, field: "userId"},
+		{name: "with both", share: Share{UserID: "user", WorkspaceID: "workspace", Level: ShareLevelRead}, field: "userId"},
+		{name: "unknown level", share: Share{UserID: "user", Level: "admin"}, field: "level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.share.Validate()
+
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type TodoStatus string
+
+const (
+	TodoStatusOpen       TodoStatus = "open"
+	TodoStatusInProgress TodoStatus = "in_progress"
+	TodoStatusBlocked    TodoStatus = "blocked"
+	TodoStatusDone       TodoStatus = "done"
+	TodoStatusCancelled  TodoStatus = "cancelled"
+)
+
+var (
+	ErrInvalidTodoStatus       = NewError(ErrValidation, "InvalidStatus", "invalid todo status")
+	ErrInvalidStatusTransition = NewError(ErrConflict, "InvalidStatusTransition", "invalid todo status transition")
+)
+
+// todoStatusTransitions lists the states reachable from each state. Finished todos have to be reopened first.
+var todoStatusTransitions = map[TodoStatus][]TodoStatus{
+	TodoStatusOpen:       {TodoStatusInProgress, TodoStatusBlocked, TodoStatusDone, TodoStatusCancelled},
+	TodoStatusInProgress: {TodoStatusOpen, TodoStatusBlocked, TodoStatusDone, TodoStatusCancelled},
+	TodoStatusBlocked:    {TodoStatusOpen, TodoStatusInProgress, TodoStatusCancelled},
+	TodoStatusDone:       {TodoStatusOpen},
+	TodoStatusCancelled:  {TodoStatusOpen},
+}
+
+func ParseTodoStatus(s string) (TodoStatus, error) {
+	status := TodoStatus(s)
+	if _, ok := todoStatusTransitions[status]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTodoStatus, s)
+	}
+	return status, nil
+}
+
+func (s TodoStatus) CanTransitionTo(next TodoStatus) bool {
+	for _, allowed := range todoStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the todo to the next status, tracking when it was completed.
+func (t *TodoItem) TransitionTo(next TodoStatus, at time.Time) error {
+	if !t.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidStatusTransition, t.Status, next)
+	}
+
+	t.Status = next
+	if next == TodoStatusDone {
+		t.CompletedAt = at
+	} else {
+		t.CompletedAt = time.Time{}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTodoStatus(t *testing.T) {
+	status, err := ParseTodoStatus("in_progress")
+	assert.NoError(t, err)
+	assert.Equal(t, TodoStatusInProgress, status)
+
+	_, err = ParseTodoStatus("finished")
+	assert.ErrorIs(t, err, ErrInvalidTodoStatus)
+}
+
+func TestTransitionTo(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name                string
+		from                TodoStatus
+		to                  TodoStatus
+		expectedError       error
+		expectedCompletedAt time.Time
+	}{
+		{name: "open to in progress", from: TodoStatusOpen, to: TodoStatusInProgress},
+		{name: "in progress to done", from: TodoStatusInProgress, to: TodoStatusDone, expectedCompletedAt: now},
+		{name: "blocked to in progress", from: TodoStatusBlocked, to: TodoStatusInProgress},
+		{name: "reopen done todo", from: TodoStatusDone, to: TodoStatusOpen},
+		{name: "reopen cancelled todo", from: TodoStatusCancelled, to: TodoStatusOpen},
+		{name: "blocked cannot be done", from: TodoStatusBlocked, to: TodoStatusDone, expectedError: ErrInvalidStatusTransition},
+		{name: "done cannot be cancelled", from: TodoStatusDone, to: TodoStatusCancelled, expectedError: ErrInvalidStatusTransition},
+		{name: "same status", from: TodoStatusOpen, to: TodoStatusOpen, expectedError: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{Status: tt.from, CompletedAt: now.Add(-time.Hour)}
+
+			err := todo.TransitionTo(tt.to, now)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, tt.from, todo.Status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, todo.Status)
+			assert.Equal(t, tt.expectedCompletedAt, todo.CompletedAt)
+		})
+	}
+}
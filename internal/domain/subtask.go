@@ -0,0 +1,92 @@
+package domain
+
+import "fmt"
+
+// MaxTodoDepth is how deeply todos nest, a top-level todo is at depth 1 and its subtasks at depth 2.
+const MaxTodoDepth = 4
+
+var (
+	ErrOpenSubtasks = NewError(ErrConflict, "OpenSubtasks", "todo has unfinished subtasks or checklist items")
+
+	errOwnParent = NewValidationError("parentId", "a todo can't be its own parent")
+)
+
+// CompletionPolicy decides what happens to the unfinished subtasks and checklist items of a todo being completed.
+type CompletionPolicy string
+
+const (
+	// CompletionPolicyBlock refuses to complete the todo until they are finished.
+	CompletionPolicyBlock CompletionPolicy = "block"
+	// CompletionPolicyCascade completes them together with the todo.
+	CompletionPolicyCascade CompletionPolicy = "cascade"
+)
+
+// TodoProgress counts how many of the direct subtasks and checklist items of a todo are done.
+// Cancelled subtasks don't count.
+type TodoProgress struct {
+	Done  int
+	Total int
+}
+
+// CountProgress sets the progress of the todo and of its subtasks at every depth.
+func (t *TodoItem) CountProgress() {
+	t.Progress = TodoProgress{}
+	for i := range t.Subtasks {
+		subtask := &t.Subtasks[i]
+		subtask.CountProgress()
+		if subtask.Status == TodoStatusCancelled {
+			continue
+		}
+		t.Progress.Total++
+		if subtask.Status == TodoStatusDone {
+			t.Progress.Done++
+		}
+	}
+	for _, item := range t.Checklist {
+		t.Progress.Total++
+		if item.Done {
+			t.Progress.Done++
+		}
+	}
+}
+
+// SubtasksToComplete returns the unfinished subtasks at every depth that have to be completed along with the todo,
+// deepest first. Under the block policy it fails with ErrOpenSubtasks while any subtask or checklist item is
+// unfinished, under the cascade policy only when one of the subtasks can't be completed, e.g. because it is blocked.
+func (t *TodoItem) SubtasksToComplete(policy CompletionPolicy) ([]TodoItem, error) {
+	open, unchecked := t.unfinishedSubtasks()
+	if len(open) == 0 && unchecked == 0 {
+		return nil, nil
+	}
+	if policy != CompletionPolicyCascade {
+		return nil, fmt.Errorf("%w: %d open subtasks and %d unchecked checklist items", ErrOpenSubtasks, len(open), unchecked)
+	}
+
+	for _, subtask := range open {
+		if !subtask.Status.CanTransitionTo(TodoStatusDone) {
+			return nil, fmt.Errorf("%w: subtask %s is %s", ErrOpenSubtasks, subtask.ID, subtask.Status)
+		}
+	}
+	return open, nil
+}
+
+// unfinishedSubtasks returns the subtasks that are neither done nor cancelled, deepest first, and counts the
+// unchecked checklist items of the todo and its subtasks.
+func (t *TodoItem) unfinishedSubtasks() ([]TodoItem, int) {
+	var open []TodoItem
+	unchecked := 0
+	for _, item := range t.Checklist {
+		if !item.Done {
+			unchecked++
+		}
+	}
+	for _, subtask := range t.Subtasks {
+		nested, nestedUnchecked := subtask.unfinishedSubtasks()
+		open = append(open, nested...)
+		unchecked += nestedUnchecked
+		if subtask.Status != TodoStatusDone && subtask.Status != TodoStatusCancelled {
+			open = append(open, subtask)
+		}
+	}
+	return open, unchecked
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountProgress(t *testing.T) {
+	todo := TodoItem{
+		Subtasks: []TodoItem{
+			{ID: "done", Status: TodoStatusDone},
+			{ID: "cancelled", Status: TodoStatusCancelled},
+			{
+				ID:        "open",
+				Status:    TodoStatusOpen,
+				Checklist: []ChecklistItem{{Done: true}, {Done: false}},
+			},
+		},
+		Checklist: []ChecklistItem{{Done: true}},
+	}
+
+	todo.CountProgress()
+
+	assert.Equal(t, TodoProgress{Done: 2, Total: 3}, todo.Progress)
+	assert.Equal(t, TodoProgress{Done: 1, Total: 2}, todo.Subtasks[2].Progress)
+	assert.Equal(t, TodoProgress{}, todo.Subtasks[0].Progress)
+}
+
+func TestSubtasksToComplete(t *testing.T) {
+	nested := TodoItem{
+		Subtasks: []TodoItem{
+			{ID: "done", Status: TodoStatusDone},
+			{
+				ID:       "open",
+				Status:   TodoStatusOpen,
+				Subtasks: []TodoItem{{ID: "nested", Status: TodoStatusInProgress}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		todo          TodoItem
+		policy        CompletionPolicy
+		expectedIDs   []string
+		expectedError bool
+	}{
+		{name: "without subtasks", todo: TodoItem{}, policy: CompletionPolicyBlock},
+		{
+			name:   "finished subtasks",
+			todo:   TodoItem{Subtasks: []TodoItem{{Status: TodoStatusDone}, {Status: TodoStatusCancelled}}},
+			policy: CompletionPolicyBlock,
+		},
+		{name: "blocked by open subtasks", todo: nested, policy: CompletionPolicyBlock, expectedError: true},
+		{
+			name:          "blocked by an unchecked item",
+			todo:          TodoItem{Checklist: []ChecklistItem{{Done: false}}},
+			policy:        CompletionPolicyBlock,
+			expectedError: true,
+		},
+		{name: "cascades deepest first", todo: nested, policy: CompletionPolicyCascade, expectedIDs: []string{"nested", "open"}},
+		{
+			name:        "cascades to checklist items only",
+			todo:        TodoItem{Checklist: []ChecklistItem{{Done: false}}},
+			policy:      CompletionPolicyCascade,
+			expectedIDs: []string{},
+		},
+		{
+			name:          "blocked subtask can't be completed",
+			todo:          TodoItem{Subtasks: []TodoItem{{ID: "blocked", Status: TodoStatusBlocked}}},
+			policy:        CompletionPolicyCascade,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtasks, err := tt.todo.SubtasksToComplete(tt.policy)
+
+			if tt.expectedError {
+				assert.ErrorIs(t, err, ErrOpenSubtasks)
+				return
+			}
+			assert.NoError(t, err)
+			ids := []string{}
+			for _, subtask := range subtasks {
+				ids = append(ids, subtask.ID)
+			}
+			if tt.expectedIDs == nil {
+				tt.expectedIDs = []string{}
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
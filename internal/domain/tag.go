@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTodoTags      = 20
+	maxTagNameLength = 50
+)
+
+var (
+	ErrTagNotFound  = NewError(ErrNotFound, "TagNotFound", "tag not found")
+	ErrTagNameTaken = NewError(ErrConflict, "TagNameTaken", "a tag with this name already exists")
+
+	errEmptyTagName   = NewValidationError("name", "tag name cannot be empty")
+	errTagNameTooLong = NewValidationError("name", fmt.Sprintf("tag name must be at most %d characters", maxTagNameLength))
+	errInvalidColour  = NewValidationError("colour", "colour must be a hex colour such as #ff8800")
+	errInvalidTodoTag = NewValidationError("tags", fmt.Sprintf("tags must be at most %d characters", maxTagNameLength))
+	errTooManyTags    = NewValidationError("tags", fmt.Sprintf("a todo can have at most %d tags", MaxTodoTags))
+)
+
+var colourPattern = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// Tag labels todos to group them. Every user has their own tags, names are unique per user and kept in lower case
+// so "Work" and "work" are the same tag.
+type Tag struct {
+	ID        string
+	Name      string
+	Colour    string // Hex colour such as #ff8800, empty when the tag has none
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeTagName trims the name and puts it in lower case.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Normalize puts the name and the colour of the tag in their canonical form.
+func (t *Tag) Normalize() {
+	t.Name = NormalizeTagName(t.Name)
+	t.Colour = strings.ToLower(strings.TrimSpace(t.Colour))
+}
+
+func (t *Tag) Validate() error {
+	if err := validateTagName(t.Name); err != nil {
+		return err
+	}
+	if t.Colour != "" && !colourPattern.MatchString(t.Colour) {
+		return errInvalidColour
+	}
+	return nil
+}
+
+// SetTags replaces the tags of the todo with the named ones. Names are normalized, blank and repeated names
+// are dropped.
+func (t *TodoItem) SetTags(names []string) error {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if validateTagName(name) != nil {
+			return errInvalidTodoTag
+		}
+		seen[name] = true
+		tags = append(tags, Tag{Name: name})
+	}
+	if len(tags) > MaxTodoTags {
+		return errTooManyTags
+	}
+	t.Tags = tags
+	return nil
+}
+
+// HasSameTags reports whether the todo has the tags of the other todo, in any order.
+func (t *TodoItem) HasSameTags(other TodoItem) bool {
+	if len(t.Tags) != len(other.Tags) {
+		return false
+	}
+	names := make(map[string]bool, len(t.Tags))
+	for _, tag := range t.Tags {
+		names[tag.Name] = true
+	}
+	for _, tag := range other.Tags {
+		if !names[tag.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// TagNames returns the names of the todo's tags.
+func (t *TodoItem) TagNames() []string {
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func validateTodoTags(tags []Tag) error {
+	if len(tags) > MaxTodoTags {
+		return errTooManyTags
+	}
+	for _, tag := range tags {
+		if validateTagName(tag.Name) != nil {
+			return errInvalidTodoTag
+		}
+	}
+	return nil
+}
+
+func validateTagName(name string) error {
+	if name == "" {
+		return errEmptyTagName
+	}
+	if utf8.RuneCountInString(name) > maxTagNameLength {
+		return errTagNameTooLong
+	}
+	return nil
+}
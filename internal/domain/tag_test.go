@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTags(t *testing.T) {
+	tooMany := make([]string, MaxTodoTags+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("a", i+1)
+	}
+
+	tests := []struct {
+		name          string
+		names         []string
+		expected      []string
+		expectedError error
+	}{
+		{name: "normalizes and drops repeated names", names: []string{" Work", "work", "HOME"}, expected: []string{"work", "home"}},
+		{name: "blank names are dropped", names: []string{""}, expected: []string{}},
+		{name: "name too long", names: []string{strings.Repeat("a", maxTagNameLength+1)}, expectedError: errInvalidTodoTag},
+		{name: "too many tags", names: tooMany, expectedError: errTooManyTags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{Tags: []Tag{{Name: "old"}}}
+
+			err := todo.SetTags(tt.names)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, []string{"old"}, todo.TagNames())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, todo.TagNames())
+		})
+	}
+}
+
+func TestTagValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		tag           Tag
+		expectedError error
+	}{
+		{name: "valid tag", tag: Tag{Name: "work", Colour: "#ff8800"}},
+		{name: "short colour", tag: Tag{Name: "work", Colour: "#f80"}},
+		{name: "no colour", tag: Tag{Name: "work"}},
+		{name: "empty name", tag: Tag{Colour: "#ff8800"}, expectedError: errEmptyTagName},
+		{name: "named colour", tag: Tag{Name: "work", Colour: "orange"}, expectedError: errInvalidColour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tag.Validate()
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHasSameTags(t *testing.T) {
+	todo := TodoItem{Tags: []Tag{{Name: "work"}, {Name: "home"}}}
+
+	assert.True(t, todo.HasSameTags(TodoItem{Tags: []Tag{{Name: "home"}, {Name: "work"}}}))
+	assert.False(t, todo.HasSameTags(TodoItem{Tags: []Tag{{Name: "home"}}}))
+	assert.False(t, todo.HasSameTags(TodoItem{Tags: []Tag{{Name: "home"}, {Name: "garden"}}}))
+	assert.True(t, (&TodoItem{}).HasSameTags(TodoItem{}))
+}
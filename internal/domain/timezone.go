@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var errInvalidTimeZone = NewValidationError("timezone", "timezone must be an IANA time zone name, e.g. Europe/Berlin")
+
+// LoadTimeZone loads an IANA time zone such as Europe/Berlin. Local is rejected, it is the zone of the server
+// rather than of a user.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("unknown time zone " + name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Location is the zone the todo's times are shown in, UTC unless it has a time zone.
+func (t *TodoItem) Location() *time.Location {
+	if t.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := LoadTimeZone(t.TimeZone)
+	if err != nil { // the zone was validated when it was set
+		return time.UTC
+	}
+	return loc
+}
+
+func validateTimeZone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := LoadTimeZone(name); err != nil {
+		return errInvalidTimeZone
+	}
+	return nil
+}
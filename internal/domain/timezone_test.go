@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoItemLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeZone     string
+		expectedZone string
+	}{
+		{name: "no time zone", timeZone: "", expectedZone: "UTC"},
+		{name: "IANA time zone", timeZone: "America/New_York", expectedZone: "America/New_York"},
+		{name: "unknown time zone", timeZone: "Mars/Olympus_Mons", expectedZone: "UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{TimeZone: tt.timeZone}
+
+			assert.Equal(t, tt.expectedZone, todo.Location().String())
+		})
+	}
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	todo := TodoItem{Description: "Test todo", DueDate: time.Now().Add(time.Hour), TimeZone: "Local", Priority: DefaultTodoPriority}
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, todo.Validate(), &validationErr)
+	assert.Equal(t, "timezone", validationErr.Field)
+
+	todo.TimeZone = "Asia/Tokyo"
+	assert.NoError(t, todo.Validate())
+}
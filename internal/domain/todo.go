@@ -1,31 +1,121 @@
 package domain
 
 import (
-	"errors"
 	"time"
 )
 
+var (
+	ErrTodoNotFound        = NewError(ErrNotFound, "TodoNotFound", "todo not found")
+	ErrTodoVersionConflict = NewError(ErrConflict, "TodoVersionConflict", "todo was modified concurrently")
+
+	errEmptyDescription = NewValidationError("description", "description cannot be empty")
+	errDueDateInPast    = NewValidationError("dueDate", "due date must be in the future")
+)
+
 type TodoItem struct {
 	ID          string
 	Description string
 	DueDate     time.Time
-	FileID      string
+	TimeZone    string // IANA name such as Europe/Berlin, empty when the todo has none
+	Attachments []Attachment
+	Tags        []Tag
+	Priority    TodoPriority
+	Rank        string // Manual position, todos are ordered by it within a priority
+	Recurrence  string // RFC 5545 RRULE such as FREQ=WEEKLY;BYDAY=MO, empty when the todo doesn't recur
+	ParentID    string // The todo this one is a subtask of, empty for a top-level todo
+	ListID      string // The list the todo belongs to, empty when it belongs to none
+	Subtasks    []TodoItem
+	Checklist   []ChecklistItem
+	Progress    TodoProgress
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int32
+	Status      TodoStatus
+	CompletedAt time.Time // Zero unless the todo is done
+}
+
+// TodoPage is a single page of todo items. NextCursor is empty on the last page.
+type TodoPage struct {
+	Items      []TodoItem
+	NextCursor string
 }
-type TodoItemCreateEvent struct {
-	ID          string    `json:"id"`
-	Description string    `json:"description"`
-	DueDate     time.Time `json:"due_date"`
-	FileID      string    `json:"file_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+
+// TodoItemPatch describes a change to an existing todo item. Nil fields are left untouched.
+type TodoItemPatch struct {
+	Description *string
+	DueDate     *time.Time
+	TimeZone    *string   // An empty zone removes it
+	Tags        *[]string // Names of the tags replacing the current ones
+	Priority    *TodoPriority
+	Recurrence  *string // An empty rule stops the todo from recurring
+	ParentID    *string // An empty ID makes the todo a top-level one
+	ListID      *string // An empty ID takes the todo out of its list
 }
 
 func (t *TodoItem) Validate() error {
 	if t.Description == "" {
-		return errors.New("description cannot be empty")
+		return errEmptyDescription
 	}
 	if time.Now().After(t.DueDate) {
-		return errors.New("due date must be in the future")
+		return errDueDateInPast
+	}
+	if err := validateTodoTags(t.Tags); err != nil {
+		return err
+	}
+	if !t.Priority.valid() {
+		return errInvalidPriority
+	}
+	if err := validateRecurrence(t.Recurrence); err != nil {
+		return err
+	}
+	return validateTimeZone(t.TimeZone)
+}
+
+// ApplyPatch applies the patch to the todo item. Only the fields being changed are validated,
+// so an overdue todo can still have its description edited.
+func (t *TodoItem) ApplyPatch(patch TodoItemPatch) error {
+	if patch.Description != nil {
+		if *patch.Description == "" {
+			return errEmptyDescription
+		}
+		t.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		if time.Now().After(*patch.DueDate) {
+			return errDueDateInPast
+		}
+		t.DueDate = *patch.DueDate
+	}
+	if patch.TimeZone != nil {
+		if err := validateTimeZone(*patch.TimeZone); err != nil {
+			return err
+		}
+		t.TimeZone = *patch.TimeZone
+	}
+	if patch.Tags != nil {
+		if err := t.SetTags(*patch.Tags); err != nil {
+			return err
+		}
+	}
+	if patch.Priority != nil {
+		if !patch.Priority.valid() {
+			return errInvalidPriority
+		}
+		t.Priority = *patch.Priority
+	}
+	if patch.Recurrence != nil {
+		if err := t.SetRecurrence(*patch.Recurrence); err != nil {
+			return err
+		}
+	}
+	if patch.ParentID != nil {
+		if *patch.ParentID != "" && *patch.ParentID == t.ID {
+			return errOwnParent
+		}
+		t.ParentID = *patch.ParentID
+	}
+	if patch.ListID != nil {
+		t.ListID = *patch.ListID
 	}
 	return nil
 }
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch(t *testing.T) {
+	pastDueDate := time.Now().Add(-24 * time.Hour)
+	futureDueDate := time.Now().Add(24 * time.Hour)
+	newDescription := "Updated"
+	emptyDescription := ""
+	timeZone := "Europe/Berlin"
+	noTimeZone := ""
+	invalidTimeZone := "Mars/Olympus_Mons"
+	urgent := TodoPriorityP0
+	invalidPriority := TodoPriority("P9")
+	monthly := "freq=monthly"
+	parentID := "7f3c1e2a-0000-4000-8000-000000000001"
+	noParent := ""
+	listID := "7f3c1e2a-0000-4000-8000-0000000000aa"
+
+	tests := []struct {
+		name          string
+		patch         TodoItemPatch
+		expected      TodoItem
+		expectedError string
+	}{
+		{
+			name:     "empty patch keeps the todo unchanged",
+			patch:    TodoItemPatch{},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate},
+		},
+		{
+			name:     "description change on an overdue todo",
+			patch:    TodoItemPatch{Description: &newDescription},
+			expected: TodoItem{Description: newDescription, DueDate: pastDueDate},
+		},
+		{
+			name:     "due date change",
+			patch:    TodoItemPatch{DueDate: &futureDueDate},
+			expected: TodoItem{Description: "Original", DueDate: futureDueDate},
+		},
+		{
+			name:     "time zone change",
+			patch:    TodoItemPatch{TimeZone: &timeZone},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate, TimeZone: timeZone},
+		},
+		{
+			name:     "empty time zone removes it",
+			patch:    TodoItemPatch{TimeZone: &noTimeZone},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate},
+		},
+		{
+			name:          "unknown time zone",
+			patch:         TodoItemPatch{TimeZone: &invalidTimeZone},
+			expectedError: "timezone must be an IANA time zone name, e.g. Europe/Berlin",
+		},
+		{
+			name:     "priority change",
+			patch:    TodoItemPatch{Priority: &urgent},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate, Priority: TodoPriorityP0},
+		},
+		{
+			name:     "recurrence change",
+			patch:    TodoItemPatch{Recurrence: &monthly},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate, Recurrence: "FREQ=MONTHLY"},
+		},
+		{
+			name:     "parent change",
+			patch:    TodoItemPatch{ParentID: &parentID},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate, ParentID: parentID},
+		},
+		{
+			name:     "empty parent makes a top-level todo",
+			patch:    TodoItemPatch{ParentID: &noParent},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate},
+		},
+		{
+			name:     "list change",
+			patch:    TodoItemPatch{ListID: &listID},
+			expected: TodoItem{Description: "Original", DueDate: pastDueDate, ListID: listID},
+		},
+		{
+			name:          "unknown priority",
+			patch:         TodoItemPatch{Priority: &invalidPriority},
+			expectedError: "priority must be one of P0, P1, P2 or P3",
+		},
+		{
+			name:          "empty description",
+			patch:         TodoItemPatch{Description: &emptyDescription},
+			expectedError: "description cannot be empty",
+		},
+		{
+			name:          "due date in the past",
+			patch:         TodoItemPatch{DueDate: &pastDueDate},
+			expectedError: "due date must be in the future",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{Description: "Original", DueDate: pastDueDate}
+
+			err := todo.ApplyPatch(tt.patch)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, todo)
+		})
+	}
+}
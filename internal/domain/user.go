@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrUnauthenticatedRequest = NewError(ErrUnauthenticated, "Unauthenticated", "authentication is required")
+	ErrInvalidToken           = NewError(ErrUnauthenticated, "InvalidToken", "the access token is invalid or expired")
+)
+
+// User owns todos and tags. Users are created on their first sign-in, Subject is the identity provider's ID of
+// the user.
+type User struct {
+	ID        string
+	Subject   string
+	Email     string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Principal is the authenticated user a request is made for. APIKeyID and Scopes are set when the request was
+// authenticated with an API key of the user rather than a token. WorkspaceID is the workspace the request is made
+// in and Role the user's role there, both are empty until the workspace is resolved.
+type Principal struct {
+	UserID      string
+	Subject     string
+	APIKeyID    string
+	Scopes      []Scope
+	WorkspaceID string
+	Role        Role
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, ok is false for unauthenticated requests.
+func PrincipalFromContext(ctx context.Context) (principal Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
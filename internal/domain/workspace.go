@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Role is what a member may do in a workspace.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// Roles are the roles workspace members can have.
+var Roles = []Role{RoleOwner, RoleEditor, RoleViewer}
+
+var (
+	ErrWorkspaceNotFound       = NewError(ErrNotFound, "WorkspaceNotFound", "workspace not found")
+	ErrWorkspaceMemberNotFound = NewError(ErrNotFound, "WorkspaceMemberNotFound", "workspace member not found")
+	ErrWorkspaceRequired       = NewError(ErrForbidden, "WorkspaceRequired", "the user isn't a member of any workspace")
+	ErrWorkspaceReadOnly       = NewError(ErrForbidden, "WorkspaceReadOnly", "viewers can't change the workspace")
+	ErrWorkspaceOwnerRequired  = NewError(ErrForbidden, "WorkspaceOwnerRequired", "only owners can manage the members of the workspace")
+	ErrLastWorkspaceOwner      = NewError(ErrConflict, "LastWorkspaceOwner", "the workspace needs at least one owner")
+	ErrUserRequired            = NewError(ErrForbidden, "UserRequired", "API keys can't manage workspaces")
+)
+
+// Workspace is the tenant todos belong to. Everything a request does to todos is limited to the workspace it was
+// made in, Role is what the user the workspace was looked up for may do in it.
+type Workspace struct {
+	ID        string
+	Name      string
+	Role      Role
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WorkspaceMember is a user with a role in a workspace.
+type WorkspaceMember struct {
+	WorkspaceID string
+	UserID      string
+	Email       string
+	Name        string
+	Role        Role
+	CreatedAt   time.Time
+}
+
+// IsValid reports whether the role is one members can have.
+func (r Role) IsValid() bool {
+	return slices.Contains(Roles, r)
+}
+
+// Allows reports whether members with the role may do what the scope allows. Viewers can only read, a principal
+// outside any workspace has no role and isn't limited by it.
+func (r Role) Allows(scope Scope) bool {
+	return r != RoleViewer || scope == ScopeTodosRead
+}
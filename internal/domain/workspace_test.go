@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		scope    Scope
+		expected bool
+	}{
+		{role: RoleOwner, scope: ScopeTodosWrite, expected: true},
+		{role: RoleEditor, scope: ScopeAttachmentsWrite, expected: true},
+		{role: RoleViewer, scope: ScopeTodosRead, expected: true},
+		{role: RoleViewer, scope: ScopeTodosWrite, expected: false},
+		{role: RoleViewer, scope: ScopeAttachmentsWrite, expected: false},
+		{role: "", scope: ScopeTodosWrite, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.scope), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.role.Allows(tt.scope))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// The algorithms tokens can be signed with.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	errMissingExpiry   = errors.New("token has no expiry")
+	errInvalidIssuer   = errors.New("token was issued by another issuer")
+	errInvalidAudience = errors.New("token was issued for another audience")
+	errMissingSubject  = errors.New("token has no subject")
+)
+
+// Identity is what a verified token tells about its user.
+type Identity struct {
+	Subject string
+	Email   string
+	Name    string
+}
+
+// JWTVerifier verifies bearer tokens against a key from the configuration. Nothing is fetched from the identity
+// provider, so tokens are verified offline.
+type JWTVerifier struct {
+	parser   *jwt.Parser
+	key      interface{}
+	issuer   string
+	audience string
+}
+
+// NewJWTVerifier returns a verifier of tokens signed with the algorithm. The key is the shared secret for HS256 and
+// the PEM encoded public key for RS256. The issuer and the audience are only checked when they are set.
+func NewJWTVerifier(algorithm string, key []byte, issuer, audience string) (*JWTVerifier, error) {
+	var verificationKey interface{}
+	switch algorithm {
+	case AlgorithmHS256:
+		if len(key) == 0 {
+			return nil, errors.New("missing HS256 secret")
+		}
+		verificationKey = key
+	case AlgorithmRS256:
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RS256 public key: %w", err)
+		}
+		verificationKey = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	return &JWTVerifier{
+		// only the configured algorithm is accepted, so a token can't pick how its signature is checked
+		parser:   &jwt.Parser{ValidMethods: []string{algorithm}},
+		key:      verificationKey,
+		issuer:   issuer,
+		audience: audience,
+	}, nil
+}
+
+// Verify checks the signature of the token and its exp, nbf, iat, iss and aud claims, and returns the identity of
+// its user. Tokens must expire and name their subject.
+func (v *JWTVerifier) Verify(token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return v.key, nil
+	})
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return Identity{}, errMissingExpiry
+	}
+	if v.issuer != "" && !claims.VerifyIssuer(v.issuer, true) {
+		return Identity{}, errInvalidIssuer
+	}
+	if v.audience != "" && !claims.VerifyAudience(v.audience, true) {
+		return Identity{}, errInvalidAudience
+	}
+
+	identity := Identity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	if identity.Subject == "" {
+		return Identity{}, errMissingSubject
+	}
+	return identity, nil
+}
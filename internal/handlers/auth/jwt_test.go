@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "auth0|42",
+		"email": "ada@example.com",
+		"name":  "Ada",
+		"iss":   "https://auth.example.com/",
+		"aud":   "todo-list",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	verifier, err := NewJWTVerifier(AlgorithmHS256, []byte(testSecret), "https://auth.example.com/", "todo-list")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name        string
+		token       func() string
+		expectedErr bool
+	}{
+		{
+			name:  "valid token",
+			token: func() string { return signHS256(t, testSecret, validClaims()) },
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return signHS256(t, testSecret, claims)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "without expiry",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "exp")
+				return signHS256(t, testSecret, claims)
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "signed with another secret",
+			token:       func() string { return signHS256(t, "another secret of at least 32 bytes", validClaims()) },
+			expectedErr: true,
+		},
+		{
+			name: "another issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://evil.example.com/"
+				return signHS256(t, testSecret, claims)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "another audience",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = "billing"
+				return signHS256(t, testSecret, claims)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "without subject",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "sub")
+				return signHS256(t, testSecret, claims)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "unsigned",
+			token: func() string {
+				token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				return token
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "malformed",
+			token:       func() string { return "not-a-token" },
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.Verify(tt.token())
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Identity{Subject: "auth0|42", Email: "ada@example.com", Name: "Ada"}, identity)
+		})
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err) {
+		return
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+
+	verifier, err := NewJWTVerifier(AlgorithmRS256, publicPEM, "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims()).SignedString(privateKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	identity, err := verifier.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "auth0|42", identity.Subject)
+
+	// a token signed with the public key as an HS256 secret must not pass as RS256
+	forged := signHS256(t, string(publicPEM), validClaims())
+	_, err = verifier.Verify(forged)
+	assert.Error(t, err)
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		key       []byte
+	}{
+		{name: "HS256 without secret", algorithm: AlgorithmHS256},
+		{name: "RS256 without PEM key", algorithm: AlgorithmRS256, key: []byte("not a key")},
+		{name: "unsupported algorithm", algorithm: "none", key: []byte(testSecret)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTVerifier(tt.algorithm, tt.key, "", "")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/ports/inbound"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
+)
+
+// Authenticate requires a bearer token or an API key on every request and makes the user they were issued to the
+// principal of the request context, which scopes everything the services do to that user. Requests made with an
+// API key are further limited to the key's scopes, see RequireScope.
+func Authenticate(verifier *JWTVerifier, userService inbound.UserService, apiKeyService inbound.APIKeyService, logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scheme, credentials, found := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if !found || credentials == "" {
+				c.Response().Header().Add(echo.HeaderWWWAuthenticate, bearerScheme)
+				c.Response().Header().Add(echo.HeaderWWWAuthenticate, apiKeyScheme)
+				return domain.ErrUnauthenticatedRequest
+			}
+
+			var principal domain.Principal
+			var err error
+			switch {
+			case strings.EqualFold(scheme, bearerScheme):
+				principal, err = authenticateToken(c, verifier, userService, logger, credentials)
+			case strings.EqualFold(scheme, apiKeyScheme):
+				principal, err = authenticateAPIKey(c, apiKeyService, credentials)
+			default:
+				c.Response().Header().Add(echo.HeaderWWWAuthenticate, bearerScheme)
+				c.Response().Header().Add(echo.HeaderWWWAuthenticate, apiKeyScheme)
+				return domain.ErrUnauthenticatedRequest
+			}
+			if err != nil {
+				return err
+			}
+
+			c.SetRequest(c.Request().WithContext(domain.ContextWithPrincipal(c.Request().Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+func authenticateToken(c echo.Context, verifier *JWTVerifier, userService inbound.UserService, logger *slog.Logger, token string) (domain.Principal, error) {
+	identity, err := verifier.Verify(token)
+	if err != nil {
+		// the reason stays in the log, clients only learn that the token was rejected
+		logger.Info("rejected access token", "error", err, "path", c.Request().URL.Path)
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, bearerScheme+` error="invalid_token"`)
+		return domain.Principal{}, domain.ErrInvalidToken
+	}
+
+	user, err := userService.SignIn(c.Request().Context(), identity.Subject, identity.Email, identity.Name)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	return domain.Principal{UserID: user.ID, Subject: user.Subject}, nil
+}
+
+func authenticateAPIKey(c echo.Context, apiKeyService inbound.APIKeyService, secret string) (domain.Principal, error) {
+	key, err := apiKeyService.AuthenticateAPIKey(c.Request().Context(), secret)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, apiKeyScheme+` error="invalid_key"`)
+		}
+		return domain.Principal{}, err
+	}
+	// keys have no subject, so they never pass as one of the administrators
+	return domain.Principal{UserID: key.OwnerID, APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+// WorkspaceHeader names the workspace a request is made in. Requests without it go to the first workspace the
+// user joined.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// ResolveWorkspace puts the workspace the request is made in and the user's role there into the principal, which
+// scopes the todos the services see to that workspace. Naming a workspace the user isn't a member of fails as if it
+// didn't exist. A user without any workspace may still make the requests that don't need one, like creating a
+// workspace.
+func ResolveWorkspace(workspaceService inbound.WorkspaceService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			principal, ok := domain.PrincipalFromContext(ctx)
+			if !ok {
+				return domain.ErrUnauthenticatedRequest
+			}
+
+			requested := c.Request().Header.Get(WorkspaceHeader)
+			member, err := workspaceService.ResolveWorkspace(ctx, requested)
+			switch {
+			case err == nil:
+				principal.WorkspaceID = member.WorkspaceID
+				principal.Role = member.Role
+				c.SetRequest(c.Request().WithContext(domain.ContextWithPrincipal(ctx, principal)))
+			case requested == "" && errors.Is(err, domain.ErrWorkspaceRequired):
+			default:
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireScope rejects requests made with an API key that wasn't granted the scope. Requests authenticated with a
+// token pass, users aren't limited by scopes. Viewers of the workspace are rejected unless the scope only reads.
+func RequireScope(scopes ...domain.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, scope := range scopes {
+				if err := domain.RequireScope(c.Request().Context(), scope); err != nil {
+					if errors.Is(err, domain.ErrInsufficientScope) {
+						c.Response().Header().Set(echo.HeaderWWWAuthenticate, fmt.Sprintf(`%s error="insufficient_scope", scope="%s"`, apiKeyScheme, scope))
+					}
+					return err
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireUser only lets users signed in with a token through. Workspaces aren't managed with API keys, whose
+// scopes only cover todos.
+func RequireUser() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := domain.PrincipalFromContext(c.Request().Context())
+			if !ok {
+				return domain.ErrUnauthenticatedRequest
+			}
+			if principal.APIKeyID != "" {
+				return domain.ErrUserRequired
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireAdmin only lets the users with one of the subjects through, and only when they signed in with a token.
+func RequireAdmin(subjects []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := domain.PrincipalFromContext(c.Request().Context())
+			if !ok {
+				return domain.ErrUnauthenticatedRequest
+			}
+			if principal.APIKeyID != "" || !slices.Contains(subjects, principal.Subject) {
+				return domain.ErrAdminRequired
+			}
+			return next(c)
+		}
+	}
+}
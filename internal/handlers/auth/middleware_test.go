@@ -0,0 +1,410 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) SignIn(ctx context.Context, subject, email, name string) (domain.User, error) {
+	args := m.Called(ctx, subject, email, name)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) MintAPIKey(ctx context.Context, name string, scopes []domain.Scope) (domain.APIKey, string, error) {
+	args := m.Called(ctx, name, scopes)
+	return args.Get(0).(domain.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id string) (domain.APIKey, string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, secret string) (domain.APIKey, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(domain.APIKey), args.Error(1)
+}
+
+type MockWorkspaceService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceService) ResolveWorkspace(ctx context.Context, workspaceID string) (domain.WorkspaceMember, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).(domain.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceService) CreateWorkspace(ctx context.Context, name string) (domain.Workspace, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(domain.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaces(ctx context.Context) ([]domain.Workspace, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]domain.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string, role domain.Role) error {
+	args := m.Called(ctx, workspaceID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	args := m.Called(ctx, workspaceID, userID)
+	return args.Error(0)
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier, err := NewJWTVerifier(AlgorithmHS256, []byte(testSecret), "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	userID := "5f0c7c1e-8a4b-4b7e-9d0a-3f1e2c4b6a8d"
+	keyID := "0b8f4e6a-2c1d-4f3e-8a9b-7c6d5e4f3a2b"
+
+	tests := []struct {
+		name                string
+		authorization       string
+		setupMock           func(*MockUserService, *MockAPIKeyService)
+		expectedErrIs       error
+		expectedChallenge   string
+		expectedPrincipalID string
+	}{
+		{
+			name:          "valid token",
+			authorization: "Bearer " + signHS256(t, testSecret, validClaims()),
+			setupMock: func(m *MockUserService, _ *MockAPIKeyService) {
+				m.On("SignIn", mock.Anything, "auth0|42", "ada@example.com", "Ada").Return(domain.User{ID: userID, Subject: "auth0|42"}, nil)
+			},
+			expectedPrincipalID: userID,
+		},
+		{
+			name:              "without token",
+			setupMock:         func(_ *MockUserService, _ *MockAPIKeyService) {},
+			expectedErrIs:     domain.ErrUnauthenticatedRequest,
+			expectedChallenge: "Bearer",
+		},
+		{
+			name:              "another scheme",
+			authorization:     "Basic YWRhOnNlY3JldA==",
+			setupMock:         func(_ *MockUserService, _ *MockAPIKeyService) {},
+			expectedErrIs:     domain.ErrUnauthenticatedRequest,
+			expectedChallenge: "Bearer",
+		},
+		{
+			name:              "invalid token",
+			authorization:     "Bearer " + signHS256(t, "another secret of at least 32 bytes", validClaims()),
+			setupMock:         func(_ *MockUserService, _ *MockAPIKeyService) {},
+			expectedErrIs:     domain.ErrInvalidToken,
+			expectedChallenge: `Bearer error="invalid_token"`,
+		},
+		{
+			name:          "valid api key",
+			authorization: "ApiKey tdl_secret",
+			setupMock: func(_ *MockUserService, m *MockAPIKeyService) {
+				m.On("AuthenticateAPIKey", mock.Anything, "tdl_secret").Return(domain.APIKey{ID: keyID, OwnerID: userID, Scopes: []domain.Scope{domain.ScopeTodosRead}}, nil)
+			},
+			expectedPrincipalID: userID,
+		},
+		{
+			name:          "revoked api key",
+			authorization: "ApiKey tdl_secret",
+			setupMock: func(_ *MockUserService, m *MockAPIKeyService) {
+				m.On("AuthenticateAPIKey", mock.Anything, "tdl_secret").Return(domain.APIKey{}, domain.ErrInvalidAPIKey)
+			},
+			expectedErrIs:     domain.ErrInvalidAPIKey,
+			expectedChallenge: `ApiKey error="invalid_key"`,
+		},
+		{
+			name:          "user service unavailable",
+			authorization: "Bearer " + signHS256(t, testSecret, validClaims()),
+			setupMock: func(m *MockUserService, _ *MockAPIKeyService) {
+				m.On("SignIn", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(domain.User{}, domain.Unavailable("database", errors.New("db error")))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			userService := &MockUserService{}
+			apiKeyService := &MockAPIKeyService{}
+			tt.setupMock(userService, apiKeyService)
+
+			var principal domain.Principal
+			err := Authenticate(verifier, userService, apiKeyService, slog.Default())(func(c echo.Context) error {
+				principal, _ = domain.PrincipalFromContext(c.Request().Context())
+				return nil
+			})(c)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedChallenge, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			assert.Equal(t, tt.expectedPrincipalID, principal.UserID)
+			userService.AssertExpectations(t)
+			apiKeyService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name              string
+		principal         *domain.Principal
+		expectedErrIs     error
+		expectedChallenge string
+	}{
+		{
+			name:      "user signed in with a token",
+			principal: &domain.Principal{UserID: "user", Subject: "auth0|42"},
+		},
+		{
+			name:      "api key with the scope",
+			principal: &domain.Principal{UserID: "user", APIKeyID: "key", Scopes: []domain.Scope{domain.ScopeTodosRead, domain.ScopeTodosWrite}},
+		},
+		{
+			name:              "api key without the scope",
+			principal:         &domain.Principal{UserID: "user", APIKeyID: "key", Scopes: []domain.Scope{domain.ScopeTodosRead}},
+			expectedErrIs:     domain.ErrInsufficientScope,
+			expectedChallenge: `ApiKey error="insufficient_scope", scope="todos:write"`,
+		},
+		{
+			name:          "viewer of the workspace",
+			principal:     &domain.Principal{UserID: "user", Subject: "auth0|42", WorkspaceID: "team", Role: domain.RoleViewer},
+			expectedErrIs: domain.ErrWorkspaceReadOnly,
+		},
+		{
+			name:          "unauthenticated",
+			expectedErrIs: domain.ErrUnauthenticatedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/todos", nil)
+			if tt.principal != nil {
+				req = req.WithContext(domain.ContextWithPrincipal(req.Context(), *tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			called := false
+			err := RequireScope(domain.ScopeTodosWrite)(func(c echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				assert.False(t, called)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, called)
+			}
+			assert.Equal(t, tt.expectedChallenge, rec.Header().Get(echo.HeaderWWWAuthenticate))
+		})
+	}
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	workspaceID := "9d3c2b1a-0f9e-4d8c-b7a6-5e4d3c2b1a0f"
+	principal := domain.Principal{UserID: "user", Subject: "auth0|42"}
+
+	tests := []struct {
+		name                string
+		header              string
+		setupMock           func(*MockWorkspaceService)
+		expectedErrIs       error
+		expectedWorkspaceID string
+		expectedRole        domain.Role
+	}{
+		{
+			name:   "requested workspace",
+			header: workspaceID,
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("ResolveWorkspace", mock.Anything, workspaceID).Return(domain.WorkspaceMember{WorkspaceID: workspaceID, Role: domain.RoleViewer}, nil)
+			},
+			expectedWorkspaceID: workspaceID,
+			expectedRole:        domain.RoleViewer,
+		},
+		{
+			name: "default workspace",
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("ResolveWorkspace", mock.Anything, "").Return(domain.WorkspaceMember{WorkspaceID: workspaceID, Role: domain.RoleOwner}, nil)
+			},
+			expectedWorkspaceID: workspaceID,
+			expectedRole:        domain.RoleOwner,
+		},
+		{
+			name:   "not a member",
+			header: workspaceID,
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("ResolveWorkspace", mock.Anything, workspaceID).Return(domain.WorkspaceMember{}, domain.ErrWorkspaceNotFound)
+			},
+			expectedErrIs: domain.ErrWorkspaceNotFound,
+		},
+		{
+			name: "user without workspaces",
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("ResolveWorkspace", mock.Anything, "").Return(domain.WorkspaceMember{}, domain.ErrWorkspaceRequired)
+			},
+		},
+		{
+			name: "database error",
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("ResolveWorkspace", mock.Anything, "").Return(domain.WorkspaceMember{}, domain.Unavailable("database", errors.New("db error")))
+			},
+			expectedErrIs: domain.ErrDependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			req = req.WithContext(domain.ContextWithPrincipal(req.Context(), principal))
+			if tt.header != "" {
+				req.Header.Set(WorkspaceHeader, tt.header)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			workspaceService := &MockWorkspaceService{}
+			tt.setupMock(workspaceService)
+
+			var resolved domain.Principal
+			err := ResolveWorkspace(workspaceService)(func(c echo.Context) error {
+				resolved, _ = domain.PrincipalFromContext(c.Request().Context())
+				return nil
+			})(c)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, principal.UserID, resolved.UserID)
+				assert.Equal(t, tt.expectedWorkspaceID, resolved.WorkspaceID)
+				assert.Equal(t, tt.expectedRole, resolved.Role)
+			}
+			workspaceService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     domain.Principal
+		expectedErrIs error
+	}{
+		{
+			name:      "administrator",
+			principal: domain.Principal{UserID: "user", Subject: "auth0|42"},
+		},
+		{
+			name:          "another user",
+			principal:     domain.Principal{UserID: "user", Subject: "auth0|7"},
+			expectedErrIs: domain.ErrAdminRequired,
+		},
+		{
+			name:          "api key of an administrator",
+			principal:     domain.Principal{UserID: "user", Subject: "auth0|42", APIKeyID: "key"},
+			expectedErrIs: domain.ErrAdminRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+			req = req.WithContext(domain.ContextWithPrincipal(req.Context(), tt.principal))
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			err := RequireAdmin([]string{"auth0|42"})(func(c echo.Context) error { return nil })(c)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     domain.Principal
+		expectedErrIs error
+	}{
+		{
+			name:      "user signed in with a token",
+			principal: domain.Principal{UserID: "user", Subject: "auth0|42"},
+		},
+		{
+			name:          "api key",
+			principal:     domain.Principal{UserID: "user", APIKeyID: "key", Scopes: domain.Scopes},
+			expectedErrIs: domain.ErrUserRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/workspaces", nil)
+			req = req.WithContext(domain.ContextWithPrincipal(req.Context(), tt.principal))
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			err := RequireUser()(func(c echo.Context) error { return nil })(c)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -8,9 +8,15 @@ import (
 )
 
 type Handler struct {
-	TodoHandler *todo.TodoHandler
+	TodoHandler      *todo.TodoHandler
+	APIKeyHandler    *todo.APIKeyHandler
+	WorkspaceHandler *todo.WorkspaceHandler
 }
 
-func NewHandler(todoService *application.TodoService, logger *slog.Logger) *Handler {
-	return &Handler{TodoHandler: todo.NewTodoHandler(todoService, logger)}
+func NewHandler(todoService *application.TodoService, apiKeyService *application.APIKeyService, workspaceService *application.WorkspaceService, logger *slog.Logger) *Handler {
+	return &Handler{
+		TodoHandler:      todo.NewTodoHandler(todoService, logger),
+		APIKeyHandler:    todo.NewAPIKeyHandler(apiKeyService, logger),
+		WorkspaceHandler: todo.NewWorkspaceHandler(workspaceService, logger),
+	}
 }
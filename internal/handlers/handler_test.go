@@ -12,17 +12,23 @@ import (
 
 func TestNewHandler(t *testing.T) {
 	tests := []struct {
-		name        string
-		todoService *application.TodoService
-		logger      *slog.Logger
-		want        *Handler
+		name             string
+		todoService      *application.TodoService
+		apiKeyService    *application.APIKeyService
+		workspaceService *application.WorkspaceService
+		logger           *slog.Logger
+		want             *Handler
 	}{
 		{
-			name:        "should create new handler successfully",
-			todoService: &application.TodoService{},
-			logger:      slog.Default(),
+			name:             "should create new handler successfully",
+			todoService:      &application.TodoService{},
+			apiKeyService:    &application.APIKeyService{},
+			workspaceService: &application.WorkspaceService{},
+			logger:           slog.Default(),
 			want: &Handler{
-				TodoHandler: todo.NewTodoHandler(&application.TodoService{}, slog.Default()),
+				TodoHandler:      todo.NewTodoHandler(&application.TodoService{}, slog.Default()),
+				APIKeyHandler:    todo.NewAPIKeyHandler(&application.APIKeyService{}, slog.Default()),
+				WorkspaceHandler: todo.NewWorkspaceHandler(&application.WorkspaceService{}, slog.Default()),
 			},
 		},
 		{
@@ -30,17 +36,21 @@ func TestNewHandler(t *testing.T) {
 			todoService: nil,
 			logger:      slog.Default(),
 			want: &Handler{
-				TodoHandler: todo.NewTodoHandler(nil, slog.Default()),
+				TodoHandler:      todo.NewTodoHandler(nil, slog.Default()),
+				APIKeyHandler:    todo.NewAPIKeyHandler(nil, slog.Default()),
+				WorkspaceHandler: todo.NewWorkspaceHandler(nil, slog.Default()),
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewHandler(tt.todoService, tt.logger)
+			got := NewHandler(tt.todoService, tt.apiKeyService, tt.workspaceService, tt.logger)
 			assert.NotNil(t, got)
 			assert.IsType(t, tt.want, got)
 			assert.NotNil(t, got.TodoHandler)
+			assert.NotNil(t, got.APIKeyHandler)
+			assert.NotNil(t, got.WorkspaceHandler)
 		})
 	}
 }
@@ -1,13 +1,223 @@
 package schemas
 
 import (
-	"mime/multipart"
-
 	_ "github.com/go-playground/validator"
 )
 
+// CreateTodoRequest carries the fields of a new todo, either as a form or as JSON. Forms attach any number of files
+// as "file" parts, JSON bodies inline them in Attachments.
 type CreateTodoRequest struct {
-	Description string                `form:"description" validate:"required,max=255"`
-	DueDate     string                `form:"dueDate" validate:"required,datetime=2006-01-02T15:04:05Z"`
-	FileID      *multipart.FileHeader `form:"file" validate:"omitempty"`
+	Description string             `json:"description" form:"description" validate:"required,max=255"`
+	DueDate     string             `json:"dueDate" form:"dueDate" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	TimeZone    string             `json:"timezone" form:"timezone" validate:"omitempty,max=64"`
+	Tags        []string           `json:"tags" form:"tags" validate:"omitempty,max=20,dive,max=50"`        // Tag names, new ones are created
+	Priority    string             `json:"priority" form:"priority" validate:"omitempty,oneof=P0 P1 P2 P3"` // P2 when empty
+	Recurrence  string             `json:"recurrence" form:"recurrence" validate:"omitempty,max=255"`       // RRULE such as FREQ=WEEKLY;BYDAY=MO
+	ParentID    string             `json:"parentId" form:"parentId" validate:"omitempty,uuid"`              // Creates the todo as a subtask
+	ListID      string             `json:"listId" form:"listId" validate:"omitempty,uuid"`                  // Creates the todo in the list
+	Attachments []AttachmentUpload `json:"attachments" validate:"omitempty,dive"`
+}
+
+// AttachmentUpload is a file sent inline in a JSON body, Content is base64 encoded.
+type AttachmentUpload struct {
+	Filename string `json:"filename" validate:"required,max=255"`
+	Content  string `json:"content" validate:"required,base64"`
+}
+
+// UpdateTodoRequest replaces the fields of a todo, a todo updated without TimeZone, Tags or Recurrence no longer
+// has them, one updated without Priority gets the default priority, one updated without ParentID becomes a
+// top-level todo and one updated without ListID leaves its list.
+type UpdateTodoRequest struct {
+	ID          string   `param:"id" validate:"required,uuid"`
+	Description string   `form:"description" validate:"required,max=255"`
+	DueDate     string   `form:"dueDate" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	TimeZone    string   `form:"timezone" validate:"omitempty,max=64"`
+	Tags        []string `form:"tags" validate:"omitempty,max=20,dive,max=50"`
+	Priority    string   `form:"priority" validate:"omitempty,oneof=P0 P1 P2 P3"`
+	Recurrence  string   `form:"recurrence" validate:"omitempty,max=255"`
+	ParentID    string   `form:"parentId" validate:"omitempty,uuid"`
+	ListID      string   `form:"listId" validate:"omitempty,uuid"`
+}
+
+// PatchTodoRequest only carries the fields present in the request, nil fields are left unchanged.
+type PatchTodoRequest struct {
+	ID          string    `param:"id" validate:"required,uuid"`
+	Description *string   `form:"description" validate:"omitempty,max=255"`
+	DueDate     *string   `form:"dueDate" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	TimeZone    *string   `form:"timezone" validate:"omitempty,max=64"`         // An empty zone removes it
+	Tags        *[]string `form:"tags" validate:"omitempty,max=20,dive,max=50"` // A single empty tag removes all
+	Priority    *string   `form:"priority" validate:"omitempty,oneof=P0 P1 P2 P3"`
+	Recurrence  *string   `form:"recurrence" validate:"omitempty,max=255"` // An empty rule stops the todo from recurring
+	ParentID    *string   `form:"parentId" validate:"omitempty,uuid"`      // An empty ID makes the todo a top-level one
+	ListID      *string   `form:"listId" validate:"omitempty,uuid"`        // Moves the todo to the list, an empty ID takes it out of its list
+}
+
+type TransitionTodoRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	Status string `form:"status" validate:"required,oneof=open in_progress blocked done cancelled"`
+}
+
+// MoveTodoRequest places a todo right before or right after another todo, exactly one of them is set.
+type MoveTodoRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	Before string `json:"before" form:"before" validate:"omitempty,uuid"`
+	After  string `json:"after" form:"after" validate:"omitempty,uuid"`
+}
+
+type TodoIDRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+}
+
+// ListTodosRequest filters, sorts and pages the todos. Status may be repeated to match any of the statuses.
+type ListTodosRequest struct {
+	Cursor        string   `query:"cursor" validate:"omitempty"`
+	Limit         int      `query:"limit" validate:"omitempty,min=1,max=100"`
+	Q             string   `query:"q" validate:"omitempty,max=255"` // Full-text search over the description
+	Status        []string `query:"status" validate:"omitempty,dive,oneof=open in_progress blocked done cancelled"`
+	DueAfter      string   `query:"dueAfter" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DueBefore     string   `query:"dueBefore" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Overdue       bool     `query:"overdue"`
+	HasAttachment *bool    `query:"hasAttachment"`
+	Sort          string   `query:"sort" validate:"omitempty,oneof=createdAt dueDate priority"`
+	Tag           []string `query:"tag" validate:"omitempty,dive,max=50"`
+	TagMatch      string   `query:"tagMatch" validate:"omitempty,oneof=any all"` // Whether todos need any or all tags
+	ListID        string   `query:"listId" validate:"omitempty,uuid"`            // Sorted in the list's default order unless Sort is set
+}
+
+// AttachmentRequest selects an attachment of a todo. Mode "redirect" (the default) answers with a presigned
+// download link, "stream" sends the file through the API.
+type AttachmentRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	FileID string `param:"fileId" validate:"required,uuid"`
+	Mode   string `query:"mode" validate:"omitempty,oneof=redirect stream"`
+}
+
+// AddAttachmentsRequest selects the todo files are added to. Like CreateTodoRequest it takes "file" parts or
+// base64 encoded Attachments in a JSON body.
+type AddAttachmentsRequest struct {
+	ID          string             `param:"id" validate:"required,uuid"`
+	Attachments []AttachmentUpload `json:"attachments" validate:"omitempty,dive"`
+}
+
+type RemoveAttachmentRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	FileID string `param:"fileId" validate:"required,uuid"`
+}
+
+// AddChecklistItemRequest adds an item to the checklist of a todo, either as a form or as JSON.
+type AddChecklistItemRequest struct {
+	ID   string `param:"id" validate:"required,uuid"`
+	Text string `json:"text" form:"text" validate:"required,max=255"`
+}
+
+// PatchChecklistItemRequest only carries the fields present in the request, nil fields are left unchanged.
+type PatchChecklistItemRequest struct {
+	ID     string  `param:"id" validate:"required,uuid"`
+	ItemID string  `param:"itemId" validate:"required,uuid"`
+	Text   *string `json:"text" form:"text" validate:"omitempty,max=255"`
+	Done   *bool   `json:"done" form:"done"`
+}
+
+type ChecklistItemRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	ItemID string `param:"itemId" validate:"required,uuid"`
+}
+
+// AddDependencyRequest makes the todo wait for the blocker, either as a form or as JSON.
+type AddDependencyRequest struct {
+	ID        string `param:"id" validate:"required,uuid"`
+	BlockerID string `json:"blockerId" form:"blockerId" validate:"required,uuid"`
+}
+
+type DependencyRequest struct {
+	ID        string `param:"id" validate:"required,uuid"`
+	BlockerID string `param:"blockerId" validate:"required,uuid"`
+}
+
+// DependencyGraphRequest selects the todos of the graph, ID is repeated once per todo.
+type DependencyGraphRequest struct {
+	ID []string `query:"id" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+// AddCommentRequest leaves a comment on a todo, either as a form or as JSON.
+type AddCommentRequest struct {
+	ID   string `param:"id" validate:"required,uuid"`
+	Text string `json:"text" form:"text" validate:"required,max=2000"`
+}
+
+// ShareTodoRequest shares the todo or list with a user or with every member of a workspace, either as a form or as
+// JSON. Exactly one of UserID and WorkspaceID is required.
+type ShareTodoRequest struct {
+	ID          string `param:"id" validate:"required,uuid"`
+	UserID      string `json:"userId" form:"userId" validate:"omitempty,uuid"`
+	WorkspaceID string `json:"workspaceId" form:"workspaceId" validate:"omitempty,uuid"`
+	Level       string `json:"level" form:"level" validate:"required,oneof=read comment edit"`
+}
+
+type ShareRequest struct {
+	ID      string `param:"id" validate:"required,uuid"`
+	ShareID string `param:"shareId" validate:"required,uuid"`
+}
+
+// CreateTagRequest carries the fields of a tag, either as a form or as JSON.
+type CreateTagRequest struct {
+	Name   string `json:"name" form:"name" validate:"required,max=50"`
+	Colour string `json:"colour" form:"colour" validate:"omitempty,hexcolor"`
+}
+
+// UpdateTagRequest replaces the name and the colour of a tag, a tag updated without Colour no longer has one.
+type UpdateTagRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	Name   string `json:"name" form:"name" validate:"required,max=50"`
+	Colour string `json:"colour" form:"colour" validate:"omitempty,hexcolor"`
+}
+
+// CreateListRequest carries the fields of a list, either as a form or as JSON.
+type CreateListRequest struct {
+	Name        string `json:"name" form:"name" validate:"required,max=100"`
+	Description string `json:"description" form:"description" validate:"omitempty,max=1000"`
+	Colour      string `json:"colour" form:"colour" validate:"omitempty,hexcolor"`
+	DefaultSort string `json:"defaultSort" form:"defaultSort" validate:"omitempty,oneof=createdAt dueDate priority"` // createdAt when empty
+}
+
+// PatchListRequest only carries the fields present in the request, nil fields are left unchanged.
+type PatchListRequest struct {
+	ID          string  `param:"id" validate:"required,uuid"`
+	Name        *string `json:"name" form:"name" validate:"omitempty,max=100"`
+	Description *string `json:"description" form:"description" validate:"omitempty,max=1000"`
+	Colour      *string `json:"colour" form:"colour" validate:"omitempty,max=7"` // An empty colour removes it
+	Archived    *bool   `json:"archived" form:"archived"`
+	DefaultSort *string `json:"defaultSort" form:"defaultSort" validate:"omitempty,oneof=createdAt dueDate priority"`
+}
+
+// ListListsRequest leaves archived lists out unless IncludeArchived is set.
+type ListListsRequest struct {
+	IncludeArchived bool `query:"includeArchived"`
+}
+
+// CreateAPIKeyRequest names a new API key and lists what it may do.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" form:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" form:"scopes" validate:"required,min=1,dive,oneof=todos:read todos:write attachments:write"`
+}
+
+// CreateWorkspaceRequest names a new workspace, either as a form or as JSON.
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" form:"name" validate:"required,max=100"`
+}
+
+type WorkspaceIDRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+}
+
+// SetWorkspaceMemberRequest adds the user to the workspace or changes their role, either as a form or as JSON.
+type SetWorkspaceMemberRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	UserID string `param:"userId" validate:"required,uuid"`
+	Role   string `json:"role" form:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type WorkspaceMemberRequest struct {
+	ID     string `param:"id" validate:"required,uuid"`
+	UserID string `param:"userId" validate:"required,uuid"`
 }
@@ -1,19 +1,169 @@
 package schemas
 
 type APIResponse struct {
-	Success bool           `json:"success"`         // True for success, false for errors
-	Data    interface{}    `json:"data,omitempty"`  // Response data for success (e.g., TodoResponse)
-	Error   *ErrorResponse `json:"error,omitempty"` // Error details for failure
+	Success bool        `json:"success"`        // Always true, failures are sent as a Problem
+	Data    interface{} `json:"data,omitempty"` // Response data (e.g., TodoResponse)
 }
 
-type ErrorResponse struct {
-	Error   string      `json:"error"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+// Problem describes a failed request as RFC 7807 problem details, it is sent as application/problem+json.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`             // Stable identifier of the problem, e.g. TodoNotFound
+	Errors   []FieldError `json:"errors,omitempty"` // The fields that failed validation
+}
+
+// FieldError is a single field that failed validation. Tag and Param name the validation rule, e.g. max and 255.
+type FieldError struct {
+	Field  string `json:"field"`
+	Tag    string `json:"tag,omitempty"`
+	Param  string `json:"param,omitempty"`
+	Detail string `json:"detail"`
 }
 
 type TodoResponse struct {
-	Description string `json:"description"`
-	DueDate     string `json:"dueDate"`
-	FileID      string `json:"fileId,omitempty"`
+	ID          string                  `json:"id"`
+	Description string                  `json:"description"`
+	DueDate     string                  `json:"dueDate"`
+	TimeZone    string                  `json:"timezone,omitempty"`
+	Attachments []AttachmentResponse    `json:"attachments"`
+	Tags        []TagResponse           `json:"tags"`
+	Priority    string                  `json:"priority,omitempty"`
+	Rank        string                  `json:"rank,omitempty"`       // Manual position, compares like a string
+	Recurrence  string                  `json:"recurrence,omitempty"` // RRULE, completing the todo creates the next occurrence
+	ParentID    string                  `json:"parentId,omitempty"`   // The todo this one is a subtask of
+	ListID      string                  `json:"listId,omitempty"`     // The list the todo belongs to
+	Subtasks    []TodoResponse          `json:"subtasks,omitempty"`   // Only returned when fetching a single todo
+	Checklist   []ChecklistItemResponse `json:"checklist,omitempty"`  // Only returned when fetching a single todo
+	Progress    *ProgressResponse       `json:"progress,omitempty"`   // Left out when the todo has no subtasks or checklist
+	Status      string                  `json:"status,omitempty"`
+	CompletedAt string                  `json:"completedAt,omitempty"`
+	CreatedAt   string                  `json:"createdAt,omitempty"`
+	UpdatedAt   string                  `json:"updatedAt,omitempty"`
+	Version     int32                   `json:"version,omitempty"`
+}
+
+type AttachmentResponse struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum"` // Hex encoded SHA-256
+	UploadedAt  string `json:"uploadedAt"`
+}
+
+type ChecklistItemResponse struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Done      bool   `json:"done"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+type CommentResponse struct {
+	ID        string `json:"id"`
+	AuthorID  string `json:"authorId,omitempty"` // Empty once the author is deleted
+	Text      string `json:"text"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+// ProgressResponse counts how many of the direct subtasks and checklist items of a todo are done, cancelled
+// subtasks don't count.
+type ProgressResponse struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type DependencyResponse struct {
+	TodoID    string `json:"todoId"`
+	BlockerID string `json:"blockerId"` // The todo that has to be finished first
+}
+
+// DependencyGraphResponse holds the requested todos, their blockers at every depth and the dependencies between them.
+// Order lists the todo IDs so that every todo comes after its blockers.
+type DependencyGraphResponse struct {
+	Todos        []TodoResponse       `json:"todos"`
+	Dependencies []DependencyResponse `json:"dependencies"`
+	Order        []string             `json:"order"`
+}
+
+// ShareResponse describes who a todo or list is shared with, either TodoID or ListID is set and either UserID or
+// WorkspaceID.
+type ShareResponse struct {
+	ID          string `json:"id"`
+	TodoID      string `json:"todoId,omitempty"`
+	ListID      string `json:"listId,omitempty"` // Shared with every todo of the list
+	UserID      string `json:"userId,omitempty"`
+	WorkspaceID string `json:"workspaceId,omitempty"` // Shared with every member of the workspace
+	Level       string `json:"level"`
+	GrantedBy   string `json:"grantedBy,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	UpdatedAt   string `json:"updatedAt,omitempty"`
+}
+
+type TagResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Colour    string `json:"colour,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// ListResponse describes a list with the counts of its todos, Open counts the todos that are neither done nor
+// cancelled and Overdue those of them past their due date.
+type ListResponse struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Colour      string             `json:"colour,omitempty"`
+	Archived    bool               `json:"archived"`
+	DefaultSort string             `json:"defaultSort"`
+	Counts      ListCountsResponse `json:"counts"`
+	CreatedAt   string             `json:"createdAt,omitempty"`
+	UpdatedAt   string             `json:"updatedAt,omitempty"`
+}
+
+type ListCountsResponse struct {
+	Open    int `json:"open"`
+	Overdue int `json:"overdue"`
+	Done    int `json:"done"`
+}
+
+// APIKeyResponse describes an API key. Key is only returned when the key is minted or rotated, it can't be looked
+// up afterwards.
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	OwnerID    string   `json:"ownerId"` // The user requests made with the key act as
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"` // Start of the key, tells keys apart
+	Scopes     []string `json:"scopes"`
+	Key        string   `json:"key,omitempty"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"` // Left out for keys that were never used
+	RevokedAt  string   `json:"revokedAt,omitempty"`
+}
+
+// WorkspaceResponse describes a workspace, Role is what the user who asked may do in it.
+type WorkspaceResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt,omitempty"` // When the user joined the workspace
+}
+
+type TodoListResponse struct {
+	Items      []TodoResponse `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"` // Empty on the last page
 }
@@ -0,0 +1,138 @@
+package todo
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/a-berahman/todo-list/internal/ports/inbound"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler serves the admin endpoints that manage the API keys of services.
+type APIKeyHandler struct {
+	apiKeyService inbound.APIKeyService
+	logger        *slog.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService *application.APIKeyService, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService, logger: logger}
+}
+
+// MintAPIKey handles creating an API key, the response is the only one that contains the key.
+func (h *APIKeyHandler) MintAPIKey(c echo.Context) error {
+	var req schemas.CreateAPIKeyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	scopes := make([]domain.Scope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, domain.Scope(scope))
+	}
+	key, secret, err := h.apiKeyService.MintAPIKey(c.Request().Context(), req.Name, scopes)
+	if err != nil {
+		return err
+	}
+
+	resp := toAPIKeyResponse(key, zone)
+	resp.Key = secret
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// ListAPIKeys handles listing every API key, newest first.
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]schemas.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toAPIKeyResponse(key, zone))
+	}
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// RevokeAPIKey handles revoking an API key.
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request().Context(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RotateAPIKey handles replacing an API key with a new one, the old key stops working right away.
+func (h *APIKeyHandler) RotateAPIKey(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	key, secret, err := h.apiKeyService.RotateAPIKey(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := toAPIKeyResponse(key, zone)
+	resp.Key = secret
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// toAPIKeyResponse renders the times of the key in zone, UTC when it is nil.
+func toAPIKeyResponse(key domain.APIKey, zone *time.Location) schemas.APIKeyResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.APIKeyResponse{
+		ID:      key.ID,
+		OwnerID: key.OwnerID,
+		Name:    key.Name,
+		Prefix:  key.Prefix,
+		Scopes:  make([]string, 0, len(key.Scopes)),
+	}
+	for _, scope := range key.Scopes {
+		resp.Scopes = append(resp.Scopes, string(scope))
+	}
+	if !key.CreatedAt.IsZero() {
+		resp.CreatedAt = key.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if key.LastUsedAt != nil {
+		resp.LastUsedAt = key.LastUsedAt.In(zone).Format(time.RFC3339)
+	}
+	if key.RevokedAt != nil {
+		resp.RevokedAt = key.RevokedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
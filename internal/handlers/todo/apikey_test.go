@@ -0,0 +1,198 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) MintAPIKey(ctx context.Context, name string, scopes []domain.Scope) (domain.APIKey, string, error) {
+	args := m.Called(ctx, name, scopes)
+	return args.Get(0).(domain.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id string) (domain.APIKey, string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, secret string) (domain.APIKey, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(domain.APIKey), args.Error(1)
+}
+
+func TestMintAPIKey(t *testing.T) {
+	keyID := uuid.New().String()
+	createdAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockAPIKeyService{}
+	mockService.On("MintAPIKey", mock.Anything, "release bot", []domain.Scope{domain.ScopeTodosRead, domain.ScopeAttachmentsWrite}).
+		Return(domain.APIKey{
+			ID:        keyID,
+			Name:      "release bot",
+			Prefix:    "tdl_abcdefgh",
+			Scopes:    []domain.Scope{domain.ScopeTodosRead, domain.ScopeAttachmentsWrite},
+			CreatedAt: createdAt,
+		}, "tdl_abcdefghsecret", nil)
+
+	handler := &APIKeyHandler{apiKeyService: mockService, logger: slog.Default()}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(`{"name":"release bot","scopes":["todos:read","attachments:write"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	serve(c, handler.MintAPIKey)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp struct {
+		Success bool                   `json:"success"`
+		Data    schemas.APIKeyResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, schemas.APIKeyResponse{
+		ID:        keyID,
+		Name:      "release bot",
+		Prefix:    "tdl_abcdefgh",
+		Scopes:    []string{"todos:read", "attachments:write"},
+		Key:       "tdl_abcdefghsecret",
+		CreatedAt: "2024-06-01T12:00:00Z",
+	}, resp.Data)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAPIKeysLeavesOutKeys(t *testing.T) {
+	lastUsedAt := time.Date(2024, 6, 2, 8, 30, 0, 0, time.UTC)
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockAPIKeyService{}
+	mockService.On("ListAPIKeys", mock.Anything).Return([]domain.APIKey{
+		{ID: uuid.New().String(), Name: "release bot", Prefix: "tdl_abcdefgh", LastUsedAt: &lastUsedAt},
+	}, nil)
+
+	handler := &APIKeyHandler{apiKeyService: mockService, logger: slog.Default()}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	serve(c, handler.ListAPIKeys)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Success bool                     `json:"success"`
+		Data    []schemas.APIKeyResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Data, 1) {
+		assert.Empty(t, resp.Data[0].Key)
+		assert.Equal(t, "2024-06-02T08:30:00Z", resp.Data[0].LastUsedAt)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	keyID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "successful revoke", expectedStatus: http.StatusNoContent},
+		{name: "key not found", serviceErr: domain.ErrAPIKeyNotFound, expectedStatus: http.StatusNotFound, expectedError: "APIKeyNotFound"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockAPIKeyService{}
+			mockService.On("RevokeAPIKey", mock.Anything, keyID).Return(tt.serviceErr)
+
+			handler := &APIKeyHandler{apiKeyService: mockService, logger: slog.Default()}
+
+			req := httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+keyID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/api-keys/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(keyID)
+
+			serve(c, handler.RevokeAPIKey)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	keyID := uuid.New().String()
+	newKeyID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockAPIKeyService{}
+	mockService.On("RotateAPIKey", mock.Anything, keyID).Return(domain.APIKey{ID: newKeyID, Name: "release bot"}, "tdl_newsecret", nil)
+
+	handler := &APIKeyHandler{apiKeyService: mockService, logger: slog.Default()}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api-keys/"+keyID+"/rotate", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/api-keys/:id/rotate")
+	c.SetParamNames("id")
+	c.SetParamValues(keyID)
+
+	serve(c, handler.RotateAPIKey)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp struct {
+		Success bool                   `json:"success"`
+		Data    schemas.APIKeyResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, newKeyID, resp.Data.ID)
+	assert.Equal(t, "tdl_newsecret", resp.Data.Key)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package todo
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+const attachmentModeStream = "stream"
+
+// AddAttachments handles uploading one or more files to an existing todo, the second step of creating a todo
+// with attachments from JSON without inlining them.
+func (h *TodoHandler) AddAttachments(c echo.Context) error {
+	cleanup, err := parseMultipartForm(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var req schemas.AddAttachmentsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	files, closeFiles, err := h.requestFiles(c, req.Attachments)
+	if err != nil {
+		return err
+	}
+	defer closeFiles()
+
+	if len(files) == 0 {
+		return errNoFiles
+	}
+
+	attachments, err := h.todoService.AddAttachments(c.Request().Context(), req.ID, files)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toAttachmentResponses(attachments, zone),
+	})
+}
+
+// ListAttachments handles listing the files attached to a todo.
+func (h *TodoHandler) ListAttachments(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := h.todoService.ListAttachments(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toAttachmentResponses(attachments, zone),
+	})
+}
+
+// RemoveAttachment handles detaching a file from a todo, the file is deleted from the storage.
+func (h *TodoHandler) RemoveAttachment(c echo.Context) error {
+	var req schemas.RemoveAttachmentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.RemoveAttachment(c.Request().Context(), req.ID, req.FileID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetAttachment handles downloading a todo's attachment, either by redirecting to a presigned link or by streaming it.
+func (h *TodoHandler) GetAttachment(c echo.Context) error {
+	var req schemas.AttachmentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if req.Mode == attachmentModeStream {
+		file, err := h.todoService.OpenAttachment(c.Request().Context(), req.ID, req.FileID)
+		if err != nil {
+			return err
+		}
+		defer file.Content.Close()
+
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = echo.MIMEOctetStream
+		}
+		if file.Filename != "" {
+			c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+		}
+		if file.Size > 0 {
+			c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(file.Size, 10))
+		}
+		return c.Stream(http.StatusOK, contentType, file.Content)
+	}
+
+	url, err := h.todoService.AttachmentURL(c.Request().Context(), req.ID, req.FileID)
+	if err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusFound, url)
+}
@@ -0,0 +1,416 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAttachment(t *testing.T) {
+	todoID := uuid.New().String()
+	fileID := uuid.New().String()
+	presignedURL := "https://todo-bucket.s3.amazonaws.com/todos/" + todoID + "/" + fileID + "?X-Amz-Signature=abc"
+
+	tests := []struct {
+		name                string
+		query               string
+		setupMock           func(*MockTodoService)
+		expectedStatus      int
+		expectedLocation    string
+		expectedBody        string
+		expectedType        string
+		expectedError       string
+		expectedDisposition string
+	}{
+		{
+			name: "redirects to a presigned url by default",
+			setupMock: func(m *MockTodoService) {
+				m.On("AttachmentURL", mock.Anything, todoID, fileID).Return(presignedURL, nil)
+			},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: presignedURL,
+		},
+		{
+			name:  "streams the file",
+			query: "?mode=stream",
+			setupMock: func(m *MockTodoService) {
+				m.On("OpenAttachment", mock.Anything, todoID, fileID).Return(&domain.FileDownload{
+					Filename:    "notes.txt",
+					Content:     io.NopCloser(strings.NewReader("file content")),
+					ContentType: "text/plain",
+					Size:        12,
+				}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedBody:        "file content",
+			expectedType:        "text/plain",
+			expectedDisposition: `attachment; filename=notes.txt`,
+		},
+		{
+			name:  "streams unknown content types as binary",
+			query: "?mode=stream",
+			setupMock: func(m *MockTodoService) {
+				m.On("OpenAttachment", mock.Anything, todoID, fileID).Return(&domain.FileDownload{
+					Content: io.NopCloser(strings.NewReader("data")),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "data",
+			expectedType:   echo.MIMEOctetStream,
+		},
+		{
+			name: "todo not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("AttachmentURL", mock.Anything, todoID, fileID).Return("", domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name:  "attachment not found",
+			query: "?mode=stream",
+			setupMock: func(m *MockTodoService) {
+				m.On("OpenAttachment", mock.Anything, todoID, fileID).Return(nil, domain.ErrAttachmentNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "AttachmentNotFound",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("AttachmentURL", mock.Anything, todoID, fileID).Return("", errors.New("s3 error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID+"/attachments/"+fileID+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/attachments/:fileId")
+			c.SetParamNames("id", "fileId")
+			c.SetParamValues(todoID, fileID)
+
+			serve(c, handler.GetAttachment)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			switch {
+			case tt.expectedError != "":
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			case tt.expectedLocation != "":
+				assert.Equal(t, tt.expectedLocation, rec.Header().Get(echo.HeaderLocation))
+			default:
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+				assert.Equal(t, tt.expectedType, rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, tt.expectedDisposition, rec.Header().Get(echo.HeaderContentDisposition))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAddAttachments(t *testing.T) {
+	todoID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		files          []string
+		jsonBody       string // Sent instead of a multipart body when set
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedFiles  int
+		expectedError  string
+	}{
+		{
+			name:  "attaches the files",
+			files: []string{"a.txt", "b.png"},
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.MatchedBy(func(files []domain.FileUpload) bool {
+					return len(files) == 2 && files[0].Filename == "a.txt" && files[1].Filename == "b.png"
+				})).Return([]domain.Attachment{{ID: "a"}, {ID: "b"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedFiles:  2,
+		},
+		{
+			name:     "attaches base64 files from JSON",
+			jsonBody: `{"attachments":[{"filename":"a.txt","content":"Y29udGVudA=="}]}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.MatchedBy(func(files []domain.FileUpload) bool {
+					return len(files) == 1 && files[0].Filename == "a.txt" && files[0].Size == int64(len("content"))
+				})).Return([]domain.Attachment{{ID: "a"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedFiles:  1,
+		},
+		{
+			name:           "no files in JSON",
+			jsonBody:       `{"attachments":[]}`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:           "no files",
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:  "unsupported file type",
+			files: []string{"a.exe"},
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.Anything).Return([]domain.Attachment{}, domain.ErrUnsupportedFileType)
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedError:  "UnsupportedFileType",
+		},
+		{
+			name:  "file too large",
+			files: []string{"a.txt"},
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.Anything).Return([]domain.Attachment{}, domain.ErrFileTooLarge)
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "FileTooLarge",
+		},
+		{
+			name:  "todo not found",
+			files: []string{"a.txt"},
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.Anything).Return([]domain.Attachment{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name:  "service error",
+			files: []string{"a.txt"},
+			setupMock: func(m *MockTodoService) {
+				m.On("AddAttachments", mock.Anything, todoID, mock.Anything).Return([]domain.Attachment{}, errors.New("s3 error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			for _, filename := range tt.files {
+				part, err := writer.CreateFormFile("file", filename)
+				assert.NoError(t, err)
+				_, err = part.Write([]byte("content"))
+				assert.NoError(t, err)
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/attachments", body)
+			req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			if tt.jsonBody != "" {
+				req = httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/attachments", strings.NewReader(tt.jsonBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/attachments")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.AddAttachments)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Data []schemas.AttachmentResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Len(t, resp.Data, tt.expectedFiles)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	todoID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "lists the attachments",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListAttachments", mock.Anything, todoID).Return([]domain.Attachment{{ID: "a", Filename: "a.txt"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "todo not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListAttachments", mock.Anything, todoID).Return([]domain.Attachment{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID+"/attachments", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/attachments")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.ListAttachments)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Data []schemas.AttachmentResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, "a.txt", resp.Data[0].Filename)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveAttachment(t *testing.T) {
+	todoID := uuid.New().String()
+	fileID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "removes the attachment",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveAttachment", mock.Anything, todoID, fileID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "attachment not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveAttachment", mock.Anything, todoID, fileID).Return(domain.ErrAttachmentNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "AttachmentNotFound",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveAttachment", mock.Anything, todoID, fileID).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+todoID+"/attachments/"+fileID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/attachments/:fileId")
+			c.SetParamNames("id", "fileId")
+			c.SetParamValues(todoID, fileID)
+
+			serve(c, handler.RemoveAttachment)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
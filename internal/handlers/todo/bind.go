@@ -0,0 +1,25 @@
+package todo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// errInvalidDueDate is returned for due dates that aren't RFC 3339 timestamps.
+var errInvalidDueDate = domain.NewValidationError("dueDate", "due date must be an RFC 3339 timestamp")
+
+// bindAndValidate binds the request parameters into req and validates them. Failures are returned as they are,
+// the central error handler lists every field that failed validation.
+func bindAndValidate(c echo.Context, req any) error {
+	if err := c.Bind(req); err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusUnsupportedMediaType {
+			return err // the body is neither a form nor JSON
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to parse request parameters").SetInternal(err)
+	}
+	return c.Validate(req)
+}
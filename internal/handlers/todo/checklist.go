@@ -0,0 +1,90 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// AddChecklistItem handles adding an item to the end of a todo's checklist.
+func (h *TodoHandler) AddChecklistItem(c echo.Context) error {
+	var req schemas.AddChecklistItemRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	item, err := h.todoService.AddChecklistItem(c.Request().Context(), req.ID, domain.ChecklistItem{Text: req.Text})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toChecklistItemResponse(item, zone),
+	})
+}
+
+// UpdateChecklistItem handles renaming a checklist item or ticking it off, only the fields sent are changed.
+func (h *TodoHandler) UpdateChecklistItem(c echo.Context) error {
+	var req schemas.PatchChecklistItemRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	item, err := h.todoService.UpdateChecklistItem(c.Request().Context(), req.ID, req.ItemID, domain.ChecklistItemPatch{
+		Text: req.Text,
+		Done: req.Done,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toChecklistItemResponse(item, zone),
+	})
+}
+
+// RemoveChecklistItem handles removing an item from a todo's checklist.
+func (h *TodoHandler) RemoveChecklistItem(c echo.Context) error {
+	var req schemas.ChecklistItemRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.RemoveChecklistItem(c.Request().Context(), req.ID, req.ItemID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toChecklistItemResponse renders the times of the item in zone, UTC when it is nil.
+func toChecklistItemResponse(item domain.ChecklistItem, zone *time.Location) schemas.ChecklistItemResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.ChecklistItemResponse{
+		ID:   item.ID,
+		Text: item.Text,
+		Done: item.Done,
+	}
+	if !item.CreatedAt.IsZero() {
+		resp.CreatedAt = item.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !item.UpdatedAt.IsZero() {
+		resp.UpdatedAt = item.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,223 @@
+package todo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddChecklistItem(t *testing.T) {
+	todoID := uuid.New().String()
+	now := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+	item := domain.ChecklistItem{ID: uuid.New().String(), TodoID: todoID, Text: "Book the van", CreatedAt: now, UpdatedAt: now}
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful add",
+			body: `{"text":"Book the van"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("AddChecklistItem", mock.Anything, todoID, domain.ChecklistItem{Text: "Book the van"}).Return(item, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "checklist full",
+			body: `{"text":"Book the van"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("AddChecklistItem", mock.Anything, todoID, mock.Anything).Return(domain.ChecklistItem{}, domain.ErrChecklistFull)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "ChecklistFull",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/checklist", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/checklist")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.AddChecklistItem)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                          `json:"success"`
+					Data    schemas.ChecklistItemResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, item.ID, resp.Data.ID)
+				assert.Equal(t, "Book the van", resp.Data.Text)
+				assert.False(t, resp.Data.Done)
+				assert.Equal(t, "2030-01-01T12:00:00Z", resp.Data.CreatedAt)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateChecklistItem(t *testing.T) {
+	todoID := uuid.New().String()
+	itemID := uuid.New().String()
+	done := true
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful update",
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateChecklistItem", mock.Anything, todoID, itemID, domain.ChecklistItemPatch{Done: &done}).
+					Return(domain.ChecklistItem{ID: itemID, TodoID: todoID, Text: "Book the van", Done: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "item not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateChecklistItem", mock.Anything, todoID, itemID, mock.Anything).Return(domain.ChecklistItem{}, domain.ErrChecklistItemNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "ChecklistItemNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/todos/"+todoID+"/checklist/"+itemID, strings.NewReader(`{"done":true}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/checklist/:itemId")
+			c.SetParamNames("id", "itemId")
+			c.SetParamValues(todoID, itemID)
+
+			serve(c, handler.UpdateChecklistItem)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                          `json:"success"`
+					Data    schemas.ChecklistItemResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.True(t, resp.Data.Done)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveChecklistItem(t *testing.T) {
+	todoID := uuid.New().String()
+	itemID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful remove",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveChecklistItem", mock.Anything, todoID, itemID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "todo not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveChecklistItem", mock.Anything, todoID, itemID).Return(domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+todoID+"/checklist/"+itemID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/checklist/:itemId")
+			c.SetParamNames("id", "itemId")
+			c.SetParamValues(todoID, itemID)
+
+			serve(c, handler.RemoveChecklistItem)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// AddComment handles leaving a comment on a todo.
+func (h *TodoHandler) AddComment(c echo.Context) error {
+	var req schemas.AddCommentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	comment, err := h.todoService.AddComment(c.Request().Context(), req.ID, domain.Comment{Text: req.Text})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toCommentResponse(comment, zone),
+	})
+}
+
+// ListComments handles listing the comments on a todo, oldest first.
+func (h *TodoHandler) ListComments(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	comments, err := h.todoService.ListComments(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]schemas.CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		resp = append(resp, toCommentResponse(comment, zone))
+	}
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// toCommentResponse renders the time of the comment in zone, UTC when it is nil.
+func toCommentResponse(comment domain.Comment, zone *time.Location) schemas.CommentResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.CommentResponse{
+		ID:       comment.ID,
+		AuthorID: comment.AuthorID,
+		Text:     comment.Text,
+	}
+	if !comment.CreatedAt.IsZero() {
+		resp.CreatedAt = comment.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddComment(t *testing.T) {
+	todoID := uuid.New().String()
+	authorID := uuid.New().String()
+	now := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful comment",
+			setupMock: func(m *MockTodoService) {
+				m.On("AddComment", mock.Anything, todoID, domain.Comment{Text: "Booked the hotel"}).Return(domain.Comment{
+					ID:        uuid.New().String(),
+					TodoID:    todoID,
+					AuthorID:  authorID,
+					Text:      "Booked the hotel",
+					CreatedAt: now,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "shared for reading only",
+			setupMock: func(m *MockTodoService) {
+				m.On("AddComment", mock.Anything, todoID, mock.Anything).Return(domain.Comment{}, domain.ErrTodoAccessDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "TodoAccessDenied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/comments", strings.NewReader(`{"text":"Booked the hotel"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/comments")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.AddComment)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                    `json:"success"`
+					Data    schemas.CommentResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, authorID, resp.Data.AuthorID)
+				assert.Equal(t, "Booked the hotel", resp.Data.Text)
+				assert.Equal(t, "2030-01-01T12:00:00Z", resp.Data.CreatedAt)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListComments(t *testing.T) {
+	todoID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockTodoService{}
+	mockService.On("ListComments", mock.Anything, todoID).Return([]domain.Comment{
+		{ID: uuid.New().String(), TodoID: todoID, Text: "Booked the hotel"},
+		{ID: uuid.New().String(), TodoID: todoID, Text: "And the flights"},
+	}, nil)
+
+	handler := &TodoHandler{
+		todoService: mockService,
+		logger:      slog.Default(),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID+"/comments", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/todos/:id/comments")
+	c.SetParamNames("id")
+	c.SetParamValues(todoID)
+
+	serve(c, handler.ListComments)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Success bool                      `json:"success"`
+		Data    []schemas.CommentResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Data, 2) {
+		assert.Equal(t, "Booked the hotel", resp.Data[0].Text)
+	}
+	mockService.AssertExpectations(t)
+}
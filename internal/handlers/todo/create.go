@@ -1,12 +1,9 @@
 package todo
 
 import (
-	"errors"
-	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/a-berahman/todo-list/internal/application"
@@ -30,110 +27,93 @@ func NewTodoHandler(todoService *application.TodoService, logger *slog.Logger) *
 
 // CreateTodo handles the creation of a new todo item.
 func (h *TodoHandler) CreateTodo(c echo.Context) error {
-	req, err := h.parseAndValidateRequest(c)
+	cleanup, err := parseMultipartForm(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
-			Error:   "BadRequest",
-			Message: http.StatusText(http.StatusBadRequest),
-			Details: err.Error(),
-		})
+		return err
 	}
+	defer cleanup()
 
-	fileData, fileID, err := h.processFile(c)
+	var req schemas.CreateTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	zone, err := requestTimeZone(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
-			Error:   "FileProcessingError",
-			Message: http.StatusText(http.StatusBadRequest),
-			Details: err.Error(),
-		})
+		return err
 	}
 
+	files, closeFiles, err := h.requestFiles(c, req.Attachments)
+	if err != nil {
+		return err
+	}
+	defer closeFiles()
+
 	dueDate, err := time.Parse(time.RFC3339, req.DueDate)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
-			Error:   "InvalidDueDate",
-			Message: http.StatusText(http.StatusBadRequest),
-			Details: err.Error(),
-		})
+		return errInvalidDueDate
 	}
 
 	todoItem := domain.TodoItem{
 		ID:          uuid.New().String(),
 		Description: req.Description,
 		DueDate:     dueDate,
+		TimeZone:    req.TimeZone,
+		Priority:    domain.TodoPriority(req.Priority),
+		ParentID:    req.ParentID,
+		ListID:      req.ListID,
+	}
+	if err := todoItem.SetTags(req.Tags); err != nil {
+		return err
+	}
+	if err := todoItem.SetRecurrence(req.Recurrence); err != nil {
+		return err
 	}
 
-	if err := h.todoService.CreateTodo(c.Request().Context(), todoItem, fileData); err != nil {
-		return c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
-			Error:   "CreateTodoFailed",
-			Message: http.StatusText(http.StatusInternalServerError),
-			Details: err.Error(),
-		})
+	created, err := h.todoService.CreateTodo(c.Request().Context(), todoItem, files)
+	if err != nil {
+		return err
 	}
 
+	setETag(c, created.Version)
 	response := schemas.APIResponse{
 		Success: true,
-		Data: schemas.TodoResponse{
-			Description: req.Description,
-			DueDate:     req.DueDate,
-			FileID:      fileID,
-		},
+		Data:    toTodoResponse(created, zone),
 	}
 	return c.JSON(http.StatusCreated, response)
 }
 
-func (h *TodoHandler) parseAndValidateRequest(c echo.Context) (*schemas.CreateTodoRequest, error) {
-	var req schemas.CreateTodoRequest
-
-	if err := c.Bind(&req); err != nil {
-		return nil, errors.New("failed to parse form data")
-	}
-
-	if err := c.Validate(req); err != nil {
-		return nil, errors.New("validation failed for one or more fields")
-	}
-
-	return &req, nil
-}
-
-func (h *TodoHandler) processFile(c echo.Context) ([]byte, string, error) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		if err == http.ErrMissingFile {
-			return nil, "", nil
+// processFiles opens every uploaded "file" part for streaming, it returns no files when none were sent.
+// The returned close function must be called once the files have been handled.
+func (h *TodoHandler) processFiles(c echo.Context) ([]domain.FileUpload, func(), error) {
+	var opened []multipart.File
+	closeFiles := func() {
+		for _, src := range opened {
+			if err := src.Close(); err != nil {
+				h.logger.Warn("failed to close the uploaded file", "error", err)
+			}
 		}
-		return nil, "", errors.New("failed to process file")
 	}
 
-	if err := h.validateFileExtension(file.Filename); err != nil {
-		return nil, "", err
+	form := c.Request().MultipartForm
+	if form == nil {
+		return nil, closeFiles, nil
 	}
 
-	src, err := file.Open()
-	if err != nil {
-		return nil, "", errors.New("failed to open the uploaded file")
-	}
-	defer src.Close()
-
-	fileData, err := io.ReadAll(src)
-	if err != nil {
-		return nil, "", errors.New("failed to read the uploaded file")
-	}
-
-	fileID := uuid.New().String()
-	return fileData, fileID, nil
-}
-
-func (h *TodoHandler) validateFileExtension(filename string) error {
-	allowedExtensions := map[string]bool{
-		".txt": true,
-		".png": true,
-		".jpg": true,
-	}
-
-	extension := strings.ToLower(filepath.Ext(filename))
-	if !allowedExtensions[extension] {
-		return errors.New("the uploaded file type is not supported")
+	headers := form.File[formFieldFile]
+	files := make([]domain.FileUpload, 0, len(headers))
+	for _, header := range headers {
+		src, err := header.Open()
+		if err != nil {
+			closeFiles()
+			return nil, func() {}, echo.NewHTTPError(http.StatusBadRequest, "failed to open the uploaded file").SetInternal(err)
+		}
+		opened = append(opened, src)
+		files = append(files, domain.FileUpload{
+			Filename: header.Filename,
+			Content:  src,
+			Size:     header.Size,
+		})
 	}
-	return nil
+	return files, closeFiles, nil
 }
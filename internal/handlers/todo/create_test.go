@@ -5,14 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"log/slog"
 
+	"github.com/a-berahman/todo-list/internal/application"
 	"github.com/a-berahman/todo-list/internal/domain"
 	"github.com/a-berahman/todo-list/internal/handlers/schemas"
 	"github.com/labstack/echo/v4"
@@ -24,11 +28,204 @@ type MockTodoService struct {
 	mock.Mock
 }
 
-func (m *MockTodoService) CreateTodo(ctx context.Context, todo domain.TodoItem, fileData []byte) error {
-	args := m.Called(ctx, todo, fileData)
+func (m *MockTodoService) CreateTodo(ctx context.Context, todo domain.TodoItem, files []domain.FileUpload) (domain.TodoItem, error) {
+	args := m.Called(ctx, todo, files)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) GetTodo(ctx context.Context, id string) (domain.TodoItem, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) GetTodoTree(ctx context.Context, id string) (domain.TodoItem, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) ListTodos(ctx context.Context, query application.TodoQuery) (domain.TodoPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(domain.TodoPage), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateTodo(ctx context.Context, id string, patch domain.TodoItemPatch, expectedVersion int32) (domain.TodoItem, error) {
+	args := m.Called(ctx, id, patch, expectedVersion)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) TransitionTodo(ctx context.Context, id string, status domain.TodoStatus, expectedVersion int32) (domain.TodoItem, error) {
+	args := m.Called(ctx, id, status, expectedVersion)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) MoveTodo(ctx context.Context, id string, position domain.TodoPosition, expectedVersion int32) (domain.TodoItem, error) {
+	args := m.Called(ctx, id, position, expectedVersion)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) DeleteTodo(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) RestoreTodo(ctx context.Context, id string) (domain.TodoItem, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.TodoItem), args.Error(1)
+}
+
+func (m *MockTodoService) AddAttachments(ctx context.Context, todoID string, files []domain.FileUpload) ([]domain.Attachment, error) {
+	args := m.Called(ctx, todoID, files)
+	return args.Get(0).([]domain.Attachment), args.Error(1)
+}
+
+func (m *MockTodoService) ListAttachments(ctx context.Context, todoID string) ([]domain.Attachment, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]domain.Attachment), args.Error(1)
+}
+
+func (m *MockTodoService) RemoveAttachment(ctx context.Context, todoID, attachmentID string) error {
+	args := m.Called(ctx, todoID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) OpenAttachment(ctx context.Context, todoID, fileID string) (*domain.FileDownload, error) {
+	args := m.Called(ctx, todoID, fileID)
+	file, _ := args.Get(0).(*domain.FileDownload)
+	return file, args.Error(1)
+}
+
+func (m *MockTodoService) AttachmentURL(ctx context.Context, todoID, fileID string) (string, error) {
+	args := m.Called(ctx, todoID, fileID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTodoService) AddChecklistItem(ctx context.Context, todoID string, item domain.ChecklistItem) (domain.ChecklistItem, error) {
+	args := m.Called(ctx, todoID, item)
+	return args.Get(0).(domain.ChecklistItem), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateChecklistItem(ctx context.Context, todoID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error) {
+	args := m.Called(ctx, todoID, itemID, patch)
+	return args.Get(0).(domain.ChecklistItem), args.Error(1)
+}
+
+func (m *MockTodoService) RemoveChecklistItem(ctx context.Context, todoID, itemID string) error {
+	args := m.Called(ctx, todoID, itemID)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) AddDependency(ctx context.Context, todoID, blockerID string) (domain.TodoDependency, error) {
+	args := m.Called(ctx, todoID, blockerID)
+	return args.Get(0).(domain.TodoDependency), args.Error(1)
+}
+
+func (m *MockTodoService) RemoveDependency(ctx context.Context, todoID, blockerID string) error {
+	args := m.Called(ctx, todoID, blockerID)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) GetDependencyGraph(ctx context.Context, todoIDs []string) (domain.DependencyGraph, []string, error) {
+	args := m.Called(ctx, todoIDs)
+	return args.Get(0).(domain.DependencyGraph), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockTodoService) AddComment(ctx context.Context, todoID string, comment domain.Comment) (domain.Comment, error) {
+	args := m.Called(ctx, todoID, comment)
+	return args.Get(0).(domain.Comment), args.Error(1)
+}
+
+func (m *MockTodoService) ListComments(ctx context.Context, todoID string) ([]domain.Comment, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]domain.Comment), args.Error(1)
+}
+
+func (m *MockTodoService) ShareTodo(ctx context.Context, todoID string, share domain.Share) (domain.Share, error) {
+	args := m.Called(ctx, todoID, share)
+	return args.Get(0).(domain.Share), args.Error(1)
+}
+
+func (m *MockTodoService) ListShares(ctx context.Context, todoID string) ([]domain.Share, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]domain.Share), args.Error(1)
+}
+
+func (m *MockTodoService) RevokeShare(ctx context.Context, todoID, shareID string) error {
+	args := m.Called(ctx, todoID, shareID)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) CreateTag(ctx context.Context, tag domain.Tag) (domain.Tag, error) {
+	args := m.Called(ctx, tag)
+	return args.Get(0).(domain.Tag), args.Error(1)
+}
+
+func (m *MockTodoService) GetTag(ctx context.Context, id string) (domain.Tag, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Tag), args.Error(1)
+}
+
+func (m *MockTodoService) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Tag), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateTag(ctx context.Context, id string, tag domain.Tag) (domain.Tag, error) {
+	args := m.Called(ctx, id, tag)
+	return args.Get(0).(domain.Tag), args.Error(1)
+}
+
+func (m *MockTodoService) DeleteTag(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) CreateList(ctx context.Context, list domain.List) (domain.List, error) {
+	args := m.Called(ctx, list)
+	return args.Get(0).(domain.List), args.Error(1)
+}
+
+func (m *MockTodoService) GetList(ctx context.Context, id string) (domain.List, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.List), args.Error(1)
+}
+
+func (m *MockTodoService) ListLists(ctx context.Context, includeArchived bool) ([]domain.List, error) {
+	args := m.Called(ctx, includeArchived)
+	return args.Get(0).([]domain.List), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateList(ctx context.Context, id string, patch domain.ListPatch) (domain.List, error) {
+	args := m.Called(ctx, id, patch)
+	return args.Get(0).(domain.List), args.Error(1)
+}
+
+func (m *MockTodoService) DeleteList(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTodoService) ShareList(ctx context.Context, listID string, share domain.Share) (domain.Share, error) {
+	args := m.Called(ctx, listID, share)
+	return args.Get(0).(domain.Share), args.Error(1)
+}
+
+func (m *MockTodoService) ListListShares(ctx context.Context, listID string) ([]domain.Share, error) {
+	args := m.Called(ctx, listID)
+	return args.Get(0).([]domain.Share), args.Error(1)
+}
+
+func (m *MockTodoService) RevokeListShare(ctx context.Context, listID, shareID string) error {
+	args := m.Called(ctx, listID, shareID)
+	return args.Error(0)
+}
+
+// serve runs the handler the way echo does, returned errors are rendered by the central error handler.
+func serve(c echo.Context, handler echo.HandlerFunc) {
+	if err := handler(c); err != nil {
+		NewHTTPErrorHandler(slog.Default())(err, c)
+	}
+}
+
 type CustomValidator struct{}
 
 func (cv *CustomValidator) Validate(i interface{}) error {
@@ -46,8 +243,10 @@ func TestCreateTodo(t *testing.T) {
 		fileContent    []byte
 		fileName       string
 		fileExtension  string
+		fileCount      int // How many copies of the file are sent, one when zero
 		setupMock      func(*MockTodoService)
 		expectedStatus int
+		expectedFiles  int
 		expectedError  bool
 	}{
 		{
@@ -56,7 +255,7 @@ func TestCreateTodo(t *testing.T) {
 			dueDate:     time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 			setupMock: func(m *MockTodoService) {
 				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
-					Return(nil)
+					Return(domain.TodoItem{Version: 1}, nil)
 			},
 			expectedStatus: http.StatusCreated,
 			expectedError:  false,
@@ -69,25 +268,77 @@ func TestCreateTodo(t *testing.T) {
 			fileName:      "test",
 			fileExtension: ".txt",
 			setupMock: func(m *MockTodoService) {
-				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
-					Return(nil)
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.MatchedBy(func(files []domain.FileUpload) bool {
+					if len(files) != 1 {
+						return false
+					}
+					data, err := io.ReadAll(files[0].Content)
+					return err == nil && string(data) == "test content" && files[0].Size == int64(len(data)) && files[0].Filename == "test.txt"
+				})).Return(domain.TodoItem{Version: 1, Attachments: []domain.Attachment{{ID: "file-id", Filename: "test.txt"}}}, nil)
 			},
 			expectedStatus: http.StatusCreated,
+			expectedFiles:  1,
 			expectedError:  false,
 		},
 		{
-			name:          "invalid file extension",
-			description:   "Test todo",
+			name:          "successful creation with several files",
+			description:   "Test todo with files",
 			dueDate:       time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 			fileContent:   []byte("test content"),
 			fileName:      "test",
-			fileExtension: ".invalid",
+			fileExtension: ".txt",
+			fileCount:     2,
 			setupMock: func(m *MockTodoService) {
-
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.MatchedBy(func(files []domain.FileUpload) bool {
+					return len(files) == 2
+				})).Return(domain.TodoItem{Version: 1, Attachments: []domain.Attachment{{ID: "first"}, {ID: "second"}}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedFiles:  2,
+			expectedError:  false,
+		},
+		{
+			name:          "too many files",
+			description:   "Test todo with files",
+			dueDate:       time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			fileContent:   []byte("test content"),
+			fileName:      "test",
+			fileExtension: ".txt",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
+					Return(domain.TodoItem{}, domain.ErrTooManyFiles)
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  true,
 		},
+		{
+			name:          "file too large",
+			description:   "Test todo with file",
+			dueDate:       time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			fileContent:   []byte("test content"),
+			fileName:      "test",
+			fileExtension: ".txt",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
+					Return(domain.TodoItem{}, domain.ErrFileTooLarge)
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  true,
+		},
+		{
+			name:          "unsupported file type",
+			description:   "Test todo",
+			dueDate:       time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			fileContent:   []byte("MZ\x90\x00"),
+			fileName:      "test",
+			fileExtension: ".txt",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
+					Return(domain.TodoItem{}, domain.ErrUnsupportedFileType)
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedError:  true,
+		},
 		{
 			name:        "invalid due date",
 			description: "Test todo",
@@ -98,13 +349,35 @@ func TestCreateTodo(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  true,
 		},
+		{
+			name:        "due date in the past",
+			description: "Test todo",
+			dueDate:     time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
+					Return(domain.TodoItem{}, fmt.Errorf("todo validation failed: %w", domain.NewValidationError("dueDate", "due date must be in the future")))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  true,
+		},
+		{
+			name:        "database unavailable",
+			description: "Test todo",
+			dueDate:     time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
+					Return(domain.TodoItem{}, domain.Unavailable("database", errors.New("connection refused")))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  true,
+		},
 		{
 			name:        "service error",
 			description: "Test todo",
 			dueDate:     time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 			setupMock: func(m *MockTodoService) {
 				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.Anything).
-					Return(errors.New("service error"))
+					Return(domain.TodoItem{}, errors.New("service error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  true,
@@ -133,10 +406,12 @@ func TestCreateTodo(t *testing.T) {
 			_ = writer.WriteField("dueDate", tt.dueDate)
 
 			if tt.fileContent != nil {
-				part, err := writer.CreateFormFile("file", tt.fileName+tt.fileExtension)
-				assert.NoError(t, err)
-				_, err = part.Write(tt.fileContent)
-				assert.NoError(t, err)
+				for i := 0; i < max(tt.fileCount, 1); i++ {
+					part, err := writer.CreateFormFile("file", tt.fileName+tt.fileExtension)
+					assert.NoError(t, err)
+					_, err = part.Write(tt.fileContent)
+					assert.NoError(t, err)
+				}
 			}
 
 			writer.Close()
@@ -146,23 +421,182 @@ func TestCreateTodo(t *testing.T) {
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
-			handler.CreateTodo(c)
+			serve(c, handler.CreateTodo)
 
 			if tt.expectedError {
 				assert.Equal(t, tt.expectedStatus, rec.Code)
-				var errResp schemas.ErrorResponse
+				var errResp schemas.Problem
 				err := json.Unmarshal(rec.Body.Bytes(), &errResp)
 				assert.NoError(t, err)
-				assert.NotEmpty(t, errResp.Error)
+				assert.NotEmpty(t, errResp.Code)
 
-				if tt.fileExtension == ".invalid" {
-					assert.Equal(t, "FileProcessingError", errResp.Error)
+				if tt.expectedStatus == http.StatusUnsupportedMediaType {
+					assert.Equal(t, "UnsupportedFileType", errResp.Code)
 				}
 			}
 
 			if tt.setupMock != nil && !tt.expectedError {
 				mockService.AssertExpectations(t)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Equal(t, `"1"`, rec.Header().Get(headerETag))
+
+				var resp struct {
+					Data schemas.TodoResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Len(t, resp.Data.Attachments, tt.expectedFiles)
 			}
 		})
 	}
 }
+
+func TestCreateTodoJSON(t *testing.T) {
+	dueDate := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:        "without attachments",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"description":"Test todo","dueDate":"` + dueDate + `"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.MatchedBy(func(todo domain.TodoItem) bool {
+					return todo.Description == "Test todo" && todo.DueDate.Format(time.RFC3339) == dueDate
+				}), mock.MatchedBy(func(files []domain.FileUpload) bool {
+					return len(files) == 0
+				})).Return(domain.TodoItem{Version: 1}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "due date with an offset and a time zone",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"description":"Test todo","dueDate":"2030-06-01T14:00:00+02:00","timezone":"Europe/Berlin"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.MatchedBy(func(todo domain.TodoItem) bool {
+					return todo.DueDate.Equal(time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)) && todo.TimeZone == "Europe/Berlin"
+				}), mock.Anything).Return(domain.TodoItem{Version: 1}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "recurring",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"description":"Test todo","dueDate":"` + dueDate + `","recurrence":"RRULE:freq=weekly;byday=mo"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.MatchedBy(func(todo domain.TodoItem) bool {
+					return todo.Recurrence == "FREQ=WEEKLY;BYDAY=MO"
+				}), mock.Anything).Return(domain.TodoItem{Version: 1, Recurrence: "FREQ=WEEKLY;BYDAY=MO"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid recurrence",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"description":"Test todo","dueDate":"` + dueDate + `","recurrence":"every monday"}`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:        "with base64 attachments",
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			body: `{"description":"Test todo","dueDate":"` + dueDate + `","attachments":[` +
+				`{"filename":"notes.txt","content":"dGVzdCBjb250ZW50"}]}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTodo", mock.Anything, mock.AnythingOfType("domain.TodoItem"), mock.MatchedBy(func(files []domain.FileUpload) bool {
+					if len(files) != 1 {
+						return false
+					}
+					data, err := io.ReadAll(files[0].Content)
+					return err == nil && string(data) == "test content" && files[0].Size == 12 && files[0].Filename == "notes.txt"
+				})).Return(domain.TodoItem{Version: 1, Attachments: []domain.Attachment{{ID: "file-id"}}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "attachment that isn't base64",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"description":"Test todo","dueDate":"` + dueDate + `","attachments":[{"filename":"a.txt","content":"not base64!"}]}`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:           "malformed JSON",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"description":`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "BadRequest",
+		},
+		{
+			name:           "unsupported content type",
+			contentType:    echo.MIMETextPlain,
+			body:           "Test todo",
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedError:  "UnsupportedMediaType",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+			handler := &TodoHandler{todoService: mockService, logger: slog.Default()}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			serve(c, handler.CreateTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTodoBodyTooLarge(t *testing.T) {
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+	handler := &TodoHandler{todoService: &MockTodoService{}, logger: slog.Default()}
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("description", "Test todo")
+	part, err := writer.CreateFormFile("file", "test.txt")
+	assert.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte("a"), 4096))
+	assert.NoError(t, err)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/todos", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(rec, req.Body, 1024)
+	c := e.NewContext(req, rec)
+
+	serve(c, handler.CreateTodo)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	var errResp schemas.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+	assert.Equal(t, "FileTooLarge", errResp.Code)
+}
@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// DeleteTodo handles moving a todo item to the trash.
+func (h *TodoHandler) DeleteTodo(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.DeleteTodo(c.Request().Context(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreTodo handles bringing a todo item back from the trash.
+func (h *TodoHandler) RestoreTodo(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	todoItem, err := h.todoService.RestoreTodo(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, todoItem.Version)
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTodoResponse(todoItem, zone),
+	})
+}
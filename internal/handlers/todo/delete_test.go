@@ -0,0 +1,155 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteTodo(t *testing.T) {
+	todoID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful delete",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteTodo", mock.Anything, todoID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "todo not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteTodo", mock.Anything, todoID).Return(domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteTodo", mock.Anything, todoID).Return(errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+todoID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.DeleteTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestoreTodo(t *testing.T) {
+	todoID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful restore",
+			setupMock: func(m *MockTodoService) {
+				m.On("RestoreTodo", mock.Anything, todoID).Return(domain.TodoItem{ID: todoID, Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "todo not in the trash",
+			setupMock: func(m *MockTodoService) {
+				m.On("RestoreTodo", mock.Anything, todoID).Return(domain.TodoItem{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("RestoreTodo", mock.Anything, todoID).Return(domain.TodoItem{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/restore", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/restore")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.RestoreTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				assert.Equal(t, `"3"`, rec.Header().Get(headerETag))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// AddDependency handles making a todo wait for another todo.
+func (h *TodoHandler) AddDependency(c echo.Context) error {
+	var req schemas.AddDependencyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	dependency, err := h.todoService.AddDependency(c.Request().Context(), req.ID, req.BlockerID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    schemas.DependencyResponse{TodoID: dependency.TodoID, BlockerID: dependency.BlockerID},
+	})
+}
+
+// RemoveDependency handles a todo no longer waiting for another todo.
+func (h *TodoHandler) RemoveDependency(c echo.Context) error {
+	var req schemas.DependencyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.RemoveDependency(c.Request().Context(), req.ID, req.BlockerID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetDependencyGraph handles fetching the dependency graph of a set of todos in the order they can be completed.
+func (h *TodoHandler) GetDependencyGraph(c echo.Context) error {
+	var req schemas.DependencyGraphRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	graph, order, err := h.todoService.GetDependencyGraph(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := schemas.DependencyGraphResponse{
+		Todos:        make([]schemas.TodoResponse, 0, len(graph.Todos)),
+		Dependencies: make([]schemas.DependencyResponse, 0, len(graph.Dependencies)),
+		Order:        order,
+	}
+	for _, todoItem := range graph.Todos {
+		resp.Todos = append(resp.Todos, toTodoResponse(todoItem, zone))
+	}
+	for _, dependency := range graph.Dependencies {
+		resp.Dependencies = append(resp.Dependencies, schemas.DependencyResponse{
+			TodoID:    dependency.TodoID,
+			BlockerID: dependency.BlockerID,
+		})
+	}
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
@@ -0,0 +1,184 @@
+package todo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddDependency(t *testing.T) {
+	todoID := uuid.New().String()
+	blockerID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful add",
+			setupMock: func(m *MockTodoService) {
+				m.On("AddDependency", mock.Anything, todoID, blockerID).Return(domain.TodoDependency{TodoID: todoID, BlockerID: blockerID}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "cycle",
+			setupMock: func(m *MockTodoService) {
+				m.On("AddDependency", mock.Anything, todoID, blockerID).Return(domain.TodoDependency{}, domain.ErrDependencyCycle)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "DependencyCycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/dependencies", strings.NewReader(`{"blockerId":"`+blockerID+`"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/dependencies")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.AddDependency)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                       `json:"success"`
+					Data    schemas.DependencyResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, schemas.DependencyResponse{TodoID: todoID, BlockerID: blockerID}, resp.Data)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	todoID := uuid.New().String()
+	blockerID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful remove",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveDependency", mock.Anything, todoID, blockerID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "dependency not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("RemoveDependency", mock.Anything, todoID, blockerID).Return(domain.ErrTodoDependencyNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoDependencyNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+todoID+"/dependencies/"+blockerID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/dependencies/:blockerId")
+			c.SetParamNames("id", "blockerId")
+			c.SetParamValues(todoID, blockerID)
+
+			serve(c, handler.RemoveDependency)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDependencyGraph(t *testing.T) {
+	moveID := uuid.New().String()
+	packID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockTodoService{}
+	mockService.On("GetDependencyGraph", mock.Anything, []string{moveID}).Return(domain.DependencyGraph{
+		Todos:        []domain.TodoItem{{ID: moveID, Description: "Move house"}, {ID: packID, Description: "Pack"}},
+		Dependencies: []domain.TodoDependency{{TodoID: moveID, BlockerID: packID}},
+	}, []string{packID, moveID}, nil)
+
+	handler := &TodoHandler{
+		todoService: mockService,
+		logger:      slog.Default(),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/graph?id="+moveID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/todos/graph")
+
+	serve(c, handler.GetDependencyGraph)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Success bool                            `json:"success"`
+		Data    schemas.DependencyGraphResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Data.Todos, 2) {
+		assert.Equal(t, "Pack", resp.Data.Todos[1].Description)
+	}
+	assert.Equal(t, []schemas.DependencyResponse{{TodoID: moveID, BlockerID: packID}}, resp.Data.Dependencies)
+	assert.Equal(t, []string{packID, moveID}, resp.Data.Order)
+	mockService.AssertExpectations(t)
+}
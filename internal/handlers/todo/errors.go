@@ -0,0 +1,152 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	mimeProblemJSON = "application/problem+json"
+
+	// problemTypePrefix namespaces the type URIs of the problems, the code is appended in kebab case.
+	problemTypePrefix = "urn:todo-list:problem:"
+)
+
+// kindStatuses maps the kinds of domain errors to the status reported for them.
+var kindStatuses = map[error]int{
+	domain.ErrValidation:            http.StatusBadRequest,
+	domain.ErrNotFound:              http.StatusNotFound,
+	domain.ErrConflict:              http.StatusConflict,
+	domain.ErrUnprocessable:         http.StatusUnprocessableEntity,
+	domain.ErrUnauthenticated:       http.StatusUnauthorized,
+	domain.ErrForbidden:             http.StatusForbidden,
+	domain.ErrDependencyUnavailable: http.StatusServiceUnavailable,
+}
+
+// NewHTTPErrorHandler renders the errors returned by handlers and middlewares as RFC 7807 problem details. Domain
+// errors are reported by their kind so clients can tell their own mistakes from our outages, the cause of a 5xx
+// is only logged.
+func NewHTTPErrorHandler(logger *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		problem := toProblem(err)
+		problem.Instance = c.Request().URL.Path
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("request failed", "error", err, "method", c.Request().Method, "path", c.Request().URL.Path, "status", problem.Status)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, mimeProblemJSON)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(problem.Status)
+		} else {
+			err = c.JSON(problem.Status, problem)
+		}
+		if err != nil {
+			logger.Error("failed to send error response", "error", err)
+		}
+	}
+}
+
+func toProblem(err error) schemas.Problem {
+	status, code, detail := http.StatusInternalServerError, "InternalServerError", "an unexpected error occurred"
+	var fieldErrors []schemas.FieldError
+
+	var (
+		httpErr       *echo.HTTPError
+		validateErrs  validator.ValidationErrors
+		dependencyErr *domain.DependencyError
+		domainErr     *domain.Error
+		validationErr *domain.ValidationError
+	)
+	switch {
+	case errors.As(err, &httpErr):
+		status = httpErr.Code
+		code = strings.ReplaceAll(http.StatusText(status), " ", "")
+		detail = fmt.Sprint(httpErr.Message)
+	case errors.As(err, &validateErrs):
+		status, code, detail = http.StatusBadRequest, "ValidationFailed", "validation failed for one or more fields"
+		for _, fe := range validateErrs {
+			fieldErrors = append(fieldErrors, schemas.FieldError{
+				Field:  fieldPath(fe),
+				Tag:    fe.Tag(),
+				Param:  fe.Param(),
+				Detail: fieldErrorDetail(fe),
+			})
+		}
+	case errors.As(err, &dependencyErr):
+		status, code = http.StatusServiceUnavailable, "DependencyUnavailable"
+		detail = dependencyErr.Dependency + " is unavailable, try again later"
+	// the version comes from If-Match, so a stale one fails the precondition rather than conflicting
+	case errors.Is(err, domain.ErrTodoVersionConflict):
+		status, code, detail = http.StatusPreconditionFailed, "PreconditionFailed", err.Error()
+	case errors.Is(err, domain.ErrFileTooLarge):
+		status, code, detail = http.StatusRequestEntityTooLarge, domain.ErrFileTooLarge.Code, err.Error()
+	case errors.Is(err, domain.ErrUnsupportedFileType):
+		status, code, detail = http.StatusUnsupportedMediaType, domain.ErrUnsupportedFileType.Code, err.Error()
+	case errors.As(err, &domainErr):
+		if kindStatus, ok := kindStatuses[domainErr.Kind]; ok {
+			status, code, detail = kindStatus, domainErr.Code, err.Error()
+		}
+	case errors.As(err, &validationErr):
+		status, code, detail = http.StatusBadRequest, "ValidationFailed", err.Error()
+		fieldErrors = []schemas.FieldError{{Field: validationErr.Field, Detail: validationErr.Reason}}
+	}
+
+	problemType := "about:blank" // plain HTTP errors carry nothing beyond their status
+	if httpErr == nil {
+		problemType = problemTypePrefix + kebabCase(code)
+	}
+	return schemas.Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: fieldErrors,
+	}
+}
+
+// fieldPath locates the field within the request, e.g. attachments[0].content, leaving out the request type.
+func fieldPath(fe validator.FieldError) string {
+	if _, path, found := strings.Cut(fe.Namespace(), "."); found {
+		return path
+	}
+	return fe.Field()
+}
+
+func fieldErrorDetail(fe validator.FieldError) string {
+	switch {
+	case fe.Tag() == "required":
+		return fieldPath(fe) + " is required"
+	case fe.Param() != "":
+		return fmt.Sprintf("%s must satisfy %s=%s", fieldPath(fe), fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("%s must be a valid %s", fieldPath(fe), fe.Tag())
+}
+
+// kebabCase turns a code such as TodoNotFound into todo-not-found.
+func kebabCase(code string) string {
+	var b strings.Builder
+	for i, r := range code {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
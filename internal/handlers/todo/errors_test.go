@@ -0,0 +1,224 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedError   string
+		expectedDetails string
+	}{
+		{
+			name:            "validation error",
+			err:             fmt.Errorf("todo validation failed: %w", domain.NewValidationError("dueDate", "due date must be in the future")),
+			expectedStatus:  http.StatusBadRequest,
+			expectedError:   "ValidationFailed",
+			expectedDetails: "todo validation failed: due date must be in the future",
+		},
+		{
+			name:           "invalid cursor",
+			err:            application.ErrInvalidCursor,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "InvalidCursor",
+		},
+		{
+			name:           "too many files",
+			err:            fmt.Errorf("%w: 4 files, the limit is 3", domain.ErrTooManyFiles),
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "TooManyFiles",
+		},
+		{
+			name:           "not found",
+			err:            domain.ErrTodoNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name:           "invalid token",
+			err:            domain.ErrInvalidToken,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "InvalidToken",
+		},
+		{
+			name:           "insufficient scope",
+			err:            domain.ErrInsufficientScope,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "InsufficientScope",
+		},
+		{
+			name:           "conflict",
+			err:            fmt.Errorf("%w: from done to blocked", domain.ErrInvalidStatusTransition),
+			expectedStatus: http.StatusConflict,
+			expectedError:  "InvalidStatusTransition",
+		},
+		{
+			name:           "unprocessable",
+			err:            domain.NewError(domain.ErrUnprocessable, "ConstraintViolation", "the change violates a constraint"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "ConstraintViolation",
+		},
+		{
+			name:           "version conflict fails the precondition",
+			err:            domain.ErrTodoVersionConflict,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "PreconditionFailed",
+		},
+		{
+			name:           "file too large",
+			err:            domain.ErrFileTooLarge,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "FileTooLarge",
+		},
+		{
+			name:           "unsupported file type",
+			err:            domain.ErrUnsupportedFileType,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedError:  "UnsupportedFileType",
+		},
+		{
+			name:            "dependency unavailable hides the cause",
+			err:             fmt.Errorf("failed to save todo: %w", domain.Unavailable("database", errors.New("dial tcp: connection refused"))),
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedError:   "DependencyUnavailable",
+			expectedDetails: "database is unavailable, try again later",
+		},
+		{
+			name:            "echo error",
+			err:             echo.NewHTTPError(http.StatusRequestEntityTooLarge),
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedError:   "RequestEntityTooLarge",
+			expectedDetails: http.StatusText(http.StatusRequestEntityTooLarge),
+		},
+		{
+			name:            "unknown error hides the cause",
+			err:             errors.New("something broke"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedError:   "InternalServerError",
+			expectedDetails: "an unexpected error occurred",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			NewHTTPErrorHandler(slog.Default())(tt.err, c)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			var errResp schemas.Problem
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+			assert.Equal(t, tt.expectedError, errResp.Code)
+			assert.Equal(t, http.StatusText(tt.expectedStatus), errResp.Title)
+			if tt.expectedDetails != "" {
+				assert.Equal(t, tt.expectedDetails, errResp.Detail)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorHandlerProblem(t *testing.T) {
+	v := validator.New()
+	validateErr := v.Struct(struct {
+		Description string `validate:"required"`
+		Limit       int    `validate:"max=100"`
+	}{Limit: 101})
+	nestedErr := v.Struct(schemas.AddAttachmentsRequest{
+		ID:          "8c5a8ca4-1f3e-4a6c-9a0e-07d3b0a5c1f2",
+		Attachments: []schemas.AttachmentUpload{{Filename: "a.txt", Content: "not base64!"}},
+	})
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedType   string
+		expectedFields []schemas.FieldError
+	}{
+		{
+			name:         "field validation errors",
+			err:          validateErr,
+			expectedType: "urn:todo-list:problem:validation-failed",
+			expectedFields: []schemas.FieldError{
+				{Field: "Description", Tag: "required", Detail: "Description is required"},
+				{Field: "Limit", Tag: "max", Param: "100", Detail: "Limit must satisfy max=100"},
+			},
+		},
+		{
+			name:         "nested field validation errors",
+			err:          nestedErr,
+			expectedType: "urn:todo-list:problem:validation-failed",
+			expectedFields: []schemas.FieldError{
+				{Field: "Attachments[0].Content", Tag: "base64", Detail: "Attachments[0].Content must be a valid base64"},
+			},
+		},
+		{
+			name:         "domain validation error",
+			err:          fmt.Errorf("todo validation failed: %w", domain.NewValidationError("dueDate", "due date must be in the future")),
+			expectedType: "urn:todo-list:problem:validation-failed",
+			expectedFields: []schemas.FieldError{
+				{Field: "dueDate", Detail: "due date must be in the future"},
+			},
+		},
+		{
+			name:         "domain error",
+			err:          domain.ErrTodoNotFound,
+			expectedType: "urn:todo-list:problem:todo-not-found",
+		},
+		{
+			name:         "echo error",
+			err:          echo.NewHTTPError(http.StatusMethodNotAllowed),
+			expectedType: "about:blank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/todos?limit=101", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			NewHTTPErrorHandler(slog.Default())(tt.err, c)
+
+			assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+			var problem schemas.Problem
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedType, problem.Type)
+			assert.Equal(t, rec.Code, problem.Status)
+			assert.Equal(t, "/api/v1/todos", problem.Instance)
+			assert.Equal(t, tt.expectedFields, problem.Errors)
+		})
+	}
+}
+
+func TestHTTPErrorHandlerCommittedResponse(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	assert.NoError(t, c.NoContent(http.StatusNoContent))
+
+	NewHTTPErrorHandler(slog.Default())(errors.New("too late"), c)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
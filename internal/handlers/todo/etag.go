@@ -0,0 +1,49 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+var (
+	errInvalidETag = domain.NewError(domain.ErrValidation, "InvalidIfMatch", "the If-Match header must contain a single entity tag")
+
+	// errWeakETag fails the precondition, If-Match compares entity tags strongly so a weak one never matches.
+	errWeakETag = echo.NewHTTPError(http.StatusPreconditionFailed, "weak entity tags never match If-Match")
+)
+
+// setETag exposes the todo version as a strong entity tag so clients can send it back in If-Match.
+func setETag(c echo.Context, version int32) {
+	if version > 0 {
+		c.Response().Header().Set(headerETag, strconv.Quote(strconv.Itoa(int(version))))
+	}
+}
+
+// parseIfMatch returns the version expected by the client, or 0 when the request is unconditional.
+func parseIfMatch(header string) (int32, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	if strings.HasPrefix(header, "W/") {
+		return 0, errWeakETag
+	}
+
+	tag := strings.Trim(header, `"`)
+	version, err := strconv.ParseInt(tag, 10, 32)
+	if err != nil || version <= 0 {
+		return 0, errInvalidETag
+	}
+
+	return int32(version), nil
+}
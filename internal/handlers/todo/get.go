@@ -0,0 +1,94 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// GetTodo handles fetching a single todo item by its ID, together with its subtasks and checklist.
+func (h *TodoHandler) GetTodo(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	todoItem, err := h.todoService.GetTodoTree(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, todoItem.Version)
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTodoResponse(todoItem, zone),
+	})
+}
+
+// toTodoResponse renders the times of the todo in zone, or in the todo's own time zone when zone is nil.
+func toTodoResponse(todoItem domain.TodoItem, zone *time.Location) schemas.TodoResponse {
+	requested := zone
+	if zone == nil {
+		zone = todoItem.Location()
+	}
+	resp := schemas.TodoResponse{
+		ID:          todoItem.ID,
+		Description: todoItem.Description,
+		DueDate:     todoItem.DueDate.In(zone).Format(time.RFC3339),
+		TimeZone:    todoItem.TimeZone,
+		Attachments: toAttachmentResponses(todoItem.Attachments, zone),
+		Tags:        toTagResponses(todoItem.Tags, zone),
+		Priority:    string(todoItem.Priority),
+		Rank:        todoItem.Rank,
+		Recurrence:  todoItem.Recurrence,
+		ParentID:    todoItem.ParentID,
+		ListID:      todoItem.ListID,
+		Status:      string(todoItem.Status),
+		Version:     todoItem.Version,
+	}
+	for _, subtask := range todoItem.Subtasks {
+		resp.Subtasks = append(resp.Subtasks, toTodoResponse(subtask, requested))
+	}
+	for _, item := range todoItem.Checklist {
+		resp.Checklist = append(resp.Checklist, toChecklistItemResponse(item, zone))
+	}
+	if todoItem.Progress.Total > 0 {
+		resp.Progress = &schemas.ProgressResponse{Done: todoItem.Progress.Done, Total: todoItem.Progress.Total}
+	}
+	if !todoItem.CompletedAt.IsZero() {
+		resp.CompletedAt = todoItem.CompletedAt.In(zone).Format(time.RFC3339)
+	}
+	if !todoItem.CreatedAt.IsZero() {
+		resp.CreatedAt = todoItem.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !todoItem.UpdatedAt.IsZero() {
+		resp.UpdatedAt = todoItem.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
+
+// toAttachmentResponses renders the upload times in zone, UTC when it is nil.
+func toAttachmentResponses(attachments []domain.Attachment, zone *time.Location) []schemas.AttachmentResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := make([]schemas.AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		resp = append(resp, schemas.AttachmentResponse{
+			ID:          attachment.ID,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Checksum:    attachment.Checksum,
+			UploadedAt:  attachment.UploadedAt.In(zone).Format(time.RFC3339),
+		})
+	}
+	return resp
+}
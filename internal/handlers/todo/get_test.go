@@ -0,0 +1,217 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTodo(t *testing.T) {
+	todoID := uuid.New().String()
+	subtaskID := uuid.New().String()
+	dueDate := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful get",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetTodoTree", mock.Anything, todoID).Return(domain.TodoItem{
+					ID:          todoID,
+					Description: "Test todo",
+					DueDate:     dueDate,
+					Subtasks: []domain.TodoItem{{
+						ID:          subtaskID,
+						Description: "Test subtask",
+						DueDate:     dueDate,
+						ParentID:    todoID,
+						Status:      domain.TodoStatusDone,
+					}},
+					Checklist: []domain.ChecklistItem{{ID: uuid.New().String(), TodoID: todoID, Text: "Test item"}},
+					Progress:  domain.TodoProgress{Done: 1, Total: 2},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "todo not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetTodoTree", mock.Anything, todoID).Return(domain.TodoItem{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetTodoTree", mock.Anything, todoID).Return(domain.TodoItem{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.GetTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                 `json:"success"`
+					Data    schemas.TodoResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.True(t, resp.Success)
+				assert.Equal(t, todoID, resp.Data.ID)
+				assert.Equal(t, dueDate.Format(time.RFC3339), resp.Data.DueDate)
+				if assert.Len(t, resp.Data.Subtasks, 1) {
+					assert.Equal(t, subtaskID, resp.Data.Subtasks[0].ID)
+					assert.Equal(t, todoID, resp.Data.Subtasks[0].ParentID)
+					assert.Nil(t, resp.Data.Subtasks[0].Progress)
+				}
+				if assert.Len(t, resp.Data.Checklist, 1) {
+					assert.Equal(t, "Test item", resp.Data.Checklist[0].Text)
+				}
+				assert.Equal(t, &schemas.ProgressResponse{Done: 1, Total: 2}, resp.Data.Progress)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTodoTimeZone(t *testing.T) {
+	todoID := uuid.New().String()
+	dueDate := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		todoTimeZone    string
+		header          string
+		expectedStatus  int
+		expectedDueDate string
+		// expectedSubtask is the due date of a subtask without a time zone of its own.
+		expectedSubtask string
+	}{
+		{
+			name:            "UTC without a time zone",
+			expectedStatus:  http.StatusOK,
+			expectedDueDate: "2030-06-01T12:00:00Z",
+			expectedSubtask: "2030-06-01T12:00:00Z",
+		},
+		{
+			name:            "todo time zone",
+			todoTimeZone:    "Europe/Berlin",
+			expectedStatus:  http.StatusOK,
+			expectedDueDate: "2030-06-01T14:00:00+02:00",
+			expectedSubtask: "2030-06-01T12:00:00Z",
+		},
+		{
+			name:            "requested time zone wins over the todo's",
+			todoTimeZone:    "Europe/Berlin",
+			header:          "America/New_York",
+			expectedStatus:  http.StatusOK,
+			expectedDueDate: "2030-06-01T08:00:00-04:00",
+			expectedSubtask: "2030-06-01T08:00:00-04:00",
+		},
+		{
+			name:           "unknown requested time zone",
+			header:         "Mars/Olympus_Mons",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			mockService.On("GetTodoTree", mock.Anything, todoID).Return(domain.TodoItem{
+				ID:          todoID,
+				Description: "Test todo",
+				DueDate:     dueDate,
+				TimeZone:    tt.todoTimeZone,
+				Subtasks: []domain.TodoItem{{
+					ID:          uuid.New().String(),
+					Description: "Test subtask",
+					DueDate:     dueDate,
+					ParentID:    todoID,
+				}},
+			}, nil).Maybe()
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID, nil)
+			if tt.header != "" {
+				req.Header.Set("Time-Zone", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.GetTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, []schemas.FieldError{{Field: "Time-Zone", Detail: errInvalidTimeZoneHeader.Reason}}, errResp.Errors)
+				return
+			}
+			var resp struct {
+				Success bool                 `json:"success"`
+				Data    schemas.TodoResponse `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedDueDate, resp.Data.DueDate)
+			assert.Equal(t, tt.todoTimeZone, resp.Data.TimeZone)
+			if assert.Len(t, resp.Data.Subtasks, 1) {
+				assert.Equal(t, tt.expectedSubtask, resp.Data.Subtasks[0].DueDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+func isJSONRequest(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+}
+
+// requestFiles returns the files sent with the request, negotiated by its content type: JSON bodies carry them
+// base64 encoded in attachments, multipart bodies as "file" parts. The returned close function must be called
+// once the files have been handled.
+func (h *TodoHandler) requestFiles(c echo.Context, attachments []schemas.AttachmentUpload) ([]domain.FileUpload, func(), error) {
+	if isJSONRequest(c) {
+		files, err := decodeAttachments(attachments)
+		return files, func() {}, err
+	}
+	return h.processFiles(c)
+}
+
+// decodeAttachments decodes base64 encoded attachments. The whole body is in memory already, so they aren't streamed.
+func decodeAttachments(attachments []schemas.AttachmentUpload) ([]domain.FileUpload, error) {
+	files := make([]domain.FileUpload, 0, len(attachments))
+	for i, attachment := range attachments {
+		content, err := base64.StdEncoding.DecodeString(attachment.Content)
+		if err != nil {
+			return nil, domain.NewValidationError(fmt.Sprintf("attachments[%d].content", i), "content must be base64 encoded")
+		}
+		files = append(files, domain.FileUpload{
+			Filename: attachment.Filename,
+			Content:  bytes.NewReader(content),
+			Size:     int64(len(content)),
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,89 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// ListTodos handles listing todo items page by page using an opaque cursor, optionally filtered, sorted and
+// searched.
+func (h *TodoHandler) ListTodos(c echo.Context) error {
+	var req schemas.ListTodosRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	query, err := toTodoQuery(req)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.todoService.ListTodos(c.Request().Context(), query)
+	if err != nil {
+		return err
+	}
+
+	items := make([]schemas.TodoResponse, 0, len(page.Items))
+	for _, todoItem := range page.Items {
+		items = append(items, toTodoResponse(todoItem, zone))
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data: schemas.TodoListResponse{
+			Items:      items,
+			NextCursor: page.NextCursor,
+		},
+	})
+}
+
+func toTodoQuery(req schemas.ListTodosRequest) (application.TodoQuery, error) {
+	query := application.TodoQuery{
+		Overdue:       req.Overdue,
+		HasAttachment: req.HasAttachment,
+		Search:        req.Q,
+		Tags:          req.Tag,
+		MatchAllTags:  req.TagMatch == "all",
+		ListID:        req.ListID,
+		Sort:          application.TodoSort(req.Sort),
+		Cursor:        req.Cursor,
+		Limit:         req.Limit,
+	}
+	for _, status := range req.Status {
+		todoStatus, err := domain.ParseTodoStatus(status)
+		if err != nil {
+			return application.TodoQuery{}, err
+		}
+		query.Statuses = append(query.Statuses, todoStatus)
+	}
+
+	var err error
+	if query.DueAfter, err = parseDueDateBound("dueAfter", req.DueAfter); err != nil {
+		return application.TodoQuery{}, err
+	}
+	if query.DueBefore, err = parseDueDateBound("dueBefore", req.DueBefore); err != nil {
+		return application.TodoQuery{}, err
+	}
+	return query, nil
+}
+
+// parseDueDateBound parses an optional RFC 3339 bound of the due date range, it is zero when value is empty.
+func parseDueDateBound(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	bound, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.NewValidationError(field, field+" must be an RFC 3339 timestamp")
+	}
+	return bound, nil
+}
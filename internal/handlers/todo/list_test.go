@@ -0,0 +1,154 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListTodos(t *testing.T) {
+	items := []domain.TodoItem{
+		{ID: uuid.New().String(), Description: "First", DueDate: time.Now().Add(time.Hour)},
+		{ID: uuid.New().String(), Description: "Second", DueDate: time.Now().Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+		expectedItems  int
+		expectedCursor string
+	}{
+		{
+			name:  "successful list with next page",
+			query: "?limit=2",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListTodos", mock.Anything, application.TodoQuery{Limit: 2}).Return(domain.TodoPage{Items: items, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  2,
+			expectedCursor: "next",
+		},
+		{
+			name:  "empty page",
+			query: "?cursor=abc",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListTodos", mock.Anything, application.TodoQuery{Cursor: "abc"}).Return(domain.TodoPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListTodos", mock.Anything, application.TodoQuery{Cursor: "bad"}).Return(domain.TodoPage{}, application.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "InvalidCursor",
+		},
+		{
+			name:  "filters, sort and search",
+			query: "?q=milk+eggs&status=open&status=blocked&dueAfter=2030-01-01T00:00:00%2B01:00&overdue=true&hasAttachment=false&sort=dueDate",
+			setupMock: func(m *MockTodoService) {
+				hasAttachment := false
+				m.On("ListTodos", mock.Anything, application.TodoQuery{
+					Statuses:      []domain.TodoStatus{domain.TodoStatusOpen, domain.TodoStatusBlocked},
+					DueAfter:      time.Date(2030, time.January, 1, 0, 0, 0, 0, time.FixedZone("", 3600)),
+					Overdue:       true,
+					HasAttachment: &hasAttachment,
+					Search:        "milk eggs",
+					Sort:          application.SortByDueDate,
+				}).Return(domain.TodoPage{Items: items}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  2,
+		},
+		{
+			name:  "all of several tags",
+			query: "?tag=work&tag=home&tagMatch=all",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListTodos", mock.Anything, application.TodoQuery{
+					Tags:         []string{"work", "home"},
+					MatchAllTags: true,
+				}).Return(domain.TodoPage{Items: items}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  2,
+		},
+		{
+			name:           "invalid due date bound",
+			query:          "?dueBefore=tomorrow",
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=abc",
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "BadRequest",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("ListTodos", mock.Anything, application.TodoQuery{}).Return(domain.TodoPage{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/todos"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			serve(c, handler.ListTodos)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                     `json:"success"`
+					Data    schemas.TodoListResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.True(t, resp.Success)
+				assert.Len(t, resp.Data.Items, tt.expectedItems)
+				assert.Equal(t, tt.expectedCursor, resp.Data.NextCursor)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
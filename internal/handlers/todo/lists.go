@@ -0,0 +1,158 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateList handles the creation of a new list in the request's workspace.
+func (h *TodoHandler) CreateList(c echo.Context) error {
+	var req schemas.CreateListRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	list, err := h.todoService.CreateList(c.Request().Context(), domain.List{
+		Name:        req.Name,
+		Description: req.Description,
+		Colour:      req.Colour,
+		DefaultSort: req.DefaultSort,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toListResponse(list, zone),
+	})
+}
+
+// ListLists handles listing the lists ordered by name with the counts of their todos, for a dashboard.
+func (h *TodoHandler) ListLists(c echo.Context) error {
+	var req schemas.ListListsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	lists, err := h.todoService.ListLists(c.Request().Context(), req.IncludeArchived)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toListResponses(lists, zone),
+	})
+}
+
+// GetList handles fetching a single list by its ID with the counts of its todos.
+func (h *TodoHandler) GetList(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	list, err := h.todoService.GetList(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toListResponse(list, zone),
+	})
+}
+
+// UpdateList handles a partial update of a list, only the fields sent are changed.
+func (h *TodoHandler) UpdateList(c echo.Context) error {
+	var req schemas.PatchListRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	list, err := h.todoService.UpdateList(c.Request().Context(), req.ID, domain.ListPatch{
+		Name:        req.Name,
+		Description: req.Description,
+		Colour:      req.Colour,
+		Archived:    req.Archived,
+		DefaultSort: req.DefaultSort,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toListResponse(list, zone),
+	})
+}
+
+// DeleteList handles deleting a list, its todos are kept without a list.
+func (h *TodoHandler) DeleteList(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.DeleteList(c.Request().Context(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toListResponse renders the times of the list in zone, UTC when it is nil.
+func toListResponse(list domain.List, zone *time.Location) schemas.ListResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.ListResponse{
+		ID:          list.ID,
+		Name:        list.Name,
+		Description: list.Description,
+		Colour:      list.Colour,
+		Archived:    list.Archived,
+		DefaultSort: list.DefaultSort,
+		Counts: schemas.ListCountsResponse{
+			Open:    list.Counts.Open,
+			Overdue: list.Counts.Overdue,
+			Done:    list.Counts.Done,
+		},
+	}
+	if !list.CreatedAt.IsZero() {
+		resp.CreatedAt = list.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !list.UpdatedAt.IsZero() {
+		resp.UpdatedAt = list.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toListResponses(lists []domain.List, zone *time.Location) []schemas.ListResponse {
+	resp := make([]schemas.ListResponse, 0, len(lists))
+	for _, list := range lists {
+		resp = append(resp, toListResponse(list, zone))
+	}
+	return resp
+}
@@ -0,0 +1,337 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateList(t *testing.T) {
+	now := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+	list := domain.List{
+		ID:          uuid.New().String(),
+		Name:        "Home",
+		Colour:      "#ff8800",
+		DefaultSort: "dueDate",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful creation",
+			body: `{"name":"Home","colour":"#ff8800","defaultSort":"dueDate"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateList", mock.Anything, domain.List{Name: "Home", Colour: "#ff8800", DefaultSort: "dueDate"}).Return(list, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "name taken",
+			body: `{"name":"Home"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateList", mock.Anything, mock.Anything).Return(domain.List{}, domain.ErrListNameTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "ListNameTaken",
+		},
+		{
+			name: "service error",
+			body: `{"name":"Home"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateList", mock.Anything, mock.Anything).Return(domain.List{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/lists", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			serve(c, handler.CreateList)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                 `json:"success"`
+					Data    schemas.ListResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, list.ID, resp.Data.ID)
+				assert.Equal(t, "Home", resp.Data.Name)
+				assert.Equal(t, "dueDate", resp.Data.DefaultSort)
+				assert.Equal(t, "2030-01-01T12:00:00Z", resp.Data.CreatedAt)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListLists(t *testing.T) {
+	lists := []domain.List{
+		{ID: uuid.New().String(), Name: "Home", DefaultSort: "createdAt", Counts: domain.ListCounts{Open: 3, Overdue: 1, Done: 2}},
+		{ID: uuid.New().String(), Name: "Old", Archived: true, DefaultSort: "createdAt"},
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		includeArchived bool
+		expected        []domain.List
+	}{
+		{name: "without archived lists", query: "", includeArchived: false, expected: lists[:1]},
+		{name: "with archived lists", query: "?includeArchived=true", includeArchived: true, expected: lists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			mockService.On("ListLists", mock.Anything, tt.includeArchived).Return(tt.expected, nil)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/lists"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			serve(c, handler.ListLists)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var resp struct {
+				Success bool                   `json:"success"`
+				Data    []schemas.ListResponse `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Len(t, resp.Data, len(tt.expected))
+			assert.Equal(t, schemas.ListCountsResponse{Open: 3, Overdue: 1, Done: 2}, resp.Data[0].Counts)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetList(t *testing.T) {
+	listID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful get",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetList", mock.Anything, listID).Return(domain.List{ID: listID, Name: "Home"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "list not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetList", mock.Anything, listID).Return(domain.List{}, domain.ErrListNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "ListNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/lists/"+listID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/lists/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(listID)
+
+			serve(c, handler.GetList)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateList(t *testing.T) {
+	listID := uuid.New().String()
+	archived := true
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "archive",
+			body: `{"archived":true}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateList", mock.Anything, listID, domain.ListPatch{Archived: &archived}).
+					Return(domain.List{ID: listID, Name: "Home", Archived: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "name taken",
+			body: `{"name":"Work"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateList", mock.Anything, listID, mock.Anything).Return(domain.List{}, domain.ErrListNameTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "ListNameTaken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/lists/"+listID, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/lists/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(listID)
+
+			serve(c, handler.UpdateList)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteList(t *testing.T) {
+	listID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful delete",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteList", mock.Anything, listID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "list not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteList", mock.Anything, listID).Return(domain.ErrListNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "ListNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/lists/"+listID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/lists/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(listID)
+
+			serve(c, handler.DeleteList)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
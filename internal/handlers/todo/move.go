@@ -0,0 +1,38 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// MoveTodo handles moving a todo item right before or right after another one.
+func (h *TodoHandler) MoveTodo(c echo.Context) error {
+	var req schemas.MoveTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, err := parseIfMatch(c.Request().Header.Get(headerIfMatch))
+	if err != nil {
+		return err
+	}
+
+	position := domain.TodoPosition{Before: req.Before, After: req.After}
+	todoItem, err := h.todoService.MoveTodo(c.Request().Context(), req.ID, position, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, todoItem.Version)
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTodoResponse(todoItem, zone),
+	})
+}
@@ -0,0 +1,117 @@
+package todo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMoveTodo(t *testing.T) {
+	todoID := uuid.New().String()
+	otherID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		body           string
+		ifMatch        string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+		expectedRank   string
+	}{
+		{
+			name:    "successful move",
+			body:    `{"before":"` + otherID + `"}`,
+			ifMatch: `"3"`,
+			setupMock: func(m *MockTodoService) {
+				m.On("MoveTodo", mock.Anything, todoID, domain.TodoPosition{Before: otherID}, int32(3)).
+					Return(domain.TodoItem{ID: todoID, Priority: domain.TodoPriorityP1, Rank: "a0V", Version: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRank:   "a0V",
+		},
+		{
+			name: "other todo not found",
+			body: `{"after":"` + otherID + `"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("MoveTodo", mock.Anything, todoID, domain.TodoPosition{After: otherID}, int32(0)).
+					Return(domain.TodoItem{}, domain.NewValidationError("after", "the todo to move after was not found"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name: "version conflict",
+			body: `{"after":"` + otherID + `"}`,
+			setupMock: func(m *MockTodoService) {
+				m.On("MoveTodo", mock.Anything, todoID, mock.Anything, int32(0)).Return(domain.TodoItem{}, domain.ErrTodoVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "PreconditionFailed",
+		},
+		{
+			name:           "malformed If-Match",
+			body:           `{"after":"` + otherID + `"}`,
+			ifMatch:        `"abc"`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "InvalidIfMatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/move", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.ifMatch != "" {
+				req.Header.Set(headerIfMatch, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/move")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.MoveTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                 `json:"success"`
+					Data    schemas.TodoResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedRank, resp.Data.Rank)
+				assert.Equal(t, "P1", resp.Data.Priority)
+				assert.Equal(t, `"4"`, rec.Header().Get(headerETag))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
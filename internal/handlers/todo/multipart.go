@@ -0,0 +1,41 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// multipartMemoryLimit is how much of a multipart body is kept in memory, larger files are spooled
+// to temporary files so they can be streamed to the storage without being buffered.
+const multipartMemoryLimit = 1 << 20
+
+// formFieldFile is the multipart field files are uploaded in, it can be repeated to send several files.
+const formFieldFile = "file"
+
+// errNoFiles is returned when a request that has to upload files doesn't carry any.
+var errNoFiles = domain.NewValidationError(formFieldFile, "at least one file is required")
+
+// parseMultipartForm parses multipart bodies before they are bound, echo would otherwise keep up to 32MB in memory.
+// The returned func removes the temporary files of the form and has to be deferred by the handler, net/http only
+// removes them for the request it served and middleware may have replaced it.
+func parseMultipartForm(c echo.Context) (func(), error) {
+	req := c.Request()
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return func() {}, nil
+	}
+
+	err := req.ParseMultipartForm(multipartMemoryLimit)
+	if err == nil {
+		return func() { _ = req.MultipartForm.RemoveAll() }, nil
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrFileTooLarge, err)
+	}
+	return nil, echo.NewHTTPError(http.StatusBadRequest, "failed to parse form data").SetInternal(err)
+}
@@ -0,0 +1,165 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// ShareTodo handles sharing a todo with a user or a workspace, sharing it again changes the level.
+func (h *TodoHandler) ShareTodo(c echo.Context) error {
+	var req schemas.ShareTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	share, err := h.todoService.ShareTodo(c.Request().Context(), req.ID, domain.Share{
+		UserID:      req.UserID,
+		WorkspaceID: req.WorkspaceID,
+		Level:       domain.ShareLevel(req.Level),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toShareResponse(share, zone),
+	})
+}
+
+// ListShares handles listing the users and workspaces a todo is shared with.
+func (h *TodoHandler) ListShares(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	shares, err := h.todoService.ListShares(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toShareResponses(shares, zone),
+	})
+}
+
+// RevokeShare handles taking back the access a share gave to a todo.
+func (h *TodoHandler) RevokeShare(c echo.Context) error {
+	var req schemas.ShareRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.RevokeShare(c.Request().Context(), req.ID, req.ShareID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ShareList handles sharing every todo of a list with a user or a workspace, sharing it again changes the level.
+func (h *TodoHandler) ShareList(c echo.Context) error {
+	var req schemas.ShareTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	share, err := h.todoService.ShareList(c.Request().Context(), req.ID, domain.Share{
+		UserID:      req.UserID,
+		WorkspaceID: req.WorkspaceID,
+		Level:       domain.ShareLevel(req.Level),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toShareResponse(share, zone),
+	})
+}
+
+// ListListShares handles listing the users and workspaces a list is shared with.
+func (h *TodoHandler) ListListShares(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	shares, err := h.todoService.ListListShares(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toShareResponses(shares, zone),
+	})
+}
+
+// RevokeListShare handles taking back the access a share gave to the todos of a list.
+func (h *TodoHandler) RevokeListShare(c echo.Context) error {
+	var req schemas.ShareRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.RevokeListShare(c.Request().Context(), req.ID, req.ShareID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toShareResponse renders the times of the share in zone, UTC when it is nil.
+func toShareResponse(share domain.Share, zone *time.Location) schemas.ShareResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.ShareResponse{
+		ID:          share.ID,
+		TodoID:      share.TodoID,
+		ListID:      share.ListID,
+		UserID:      share.UserID,
+		WorkspaceID: share.WorkspaceID,
+		Level:       string(share.Level),
+		GrantedBy:   share.GrantedBy,
+	}
+	if !share.CreatedAt.IsZero() {
+		resp.CreatedAt = share.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !share.UpdatedAt.IsZero() {
+		resp.UpdatedAt = share.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toShareResponses(shares []domain.Share, zone *time.Location) []schemas.ShareResponse {
+	resp := make([]schemas.ShareResponse, 0, len(shares))
+	for _, share := range shares {
+		resp = append(resp, toShareResponse(share, zone))
+	}
+	return resp
+}
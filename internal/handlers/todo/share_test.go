@@ -0,0 +1,286 @@
+package todo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareTodo(t *testing.T) {
+	todoID := uuid.New().String()
+	userID := uuid.New().String()
+	share := domain.Share{UserID: userID, Level: domain.ShareLevelEdit}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful share",
+			setupMock: func(m *MockTodoService) {
+				m.On("ShareTodo", mock.Anything, todoID, share).Return(domain.Share{
+					ID:     uuid.New().String(),
+					TodoID: todoID,
+					UserID: userID,
+					Level:  domain.ShareLevelEdit,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "todo shared with the user",
+			setupMock: func(m *MockTodoService) {
+				m.On("ShareTodo", mock.Anything, todoID, share).Return(domain.Share{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID+"/shares", strings.NewReader(`{"userId":"`+userID+`","level":"edit"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/shares")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.ShareTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                  `json:"success"`
+					Data    schemas.ShareResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, userID, resp.Data.UserID)
+				assert.Equal(t, "edit", resp.Data.Level)
+				assert.Empty(t, resp.Data.WorkspaceID)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListShares(t *testing.T) {
+	todoID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockTodoService{}
+	mockService.On("ListShares", mock.Anything, todoID).Return([]domain.Share{
+		{ID: uuid.New().String(), TodoID: todoID, WorkspaceID: workspaceID, Level: domain.ShareLevelRead},
+	}, nil)
+
+	handler := &TodoHandler{
+		todoService: mockService,
+		logger:      slog.Default(),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/"+todoID+"/shares", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/todos/:id/shares")
+	c.SetParamNames("id")
+	c.SetParamValues(todoID)
+
+	serve(c, handler.ListShares)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Success bool                    `json:"success"`
+		Data    []schemas.ShareResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Data, 1) {
+		assert.Equal(t, workspaceID, resp.Data[0].WorkspaceID)
+		assert.Equal(t, "read", resp.Data[0].Level)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeShare(t *testing.T) {
+	todoID := uuid.New().String()
+	shareID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful revoke",
+			setupMock: func(m *MockTodoService) {
+				m.On("RevokeShare", mock.Anything, todoID, shareID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "share not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("RevokeShare", mock.Anything, todoID, shareID).Return(domain.ErrShareNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "ShareNotFound",
+		},
+		{
+			name: "shared todo",
+			setupMock: func(m *MockTodoService) {
+				m.On("RevokeShare", mock.Anything, todoID, shareID).Return(domain.ErrTodoAccessDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "TodoAccessDenied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+todoID+"/shares/"+shareID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/shares/:shareId")
+			c.SetParamNames("id", "shareId")
+			c.SetParamValues(todoID, shareID)
+
+			serve(c, handler.RevokeShare)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShareList(t *testing.T) {
+	listID := uuid.New().String()
+	userID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockTodoService{}
+	mockService.On("ShareList", mock.Anything, listID, domain.Share{UserID: userID, Level: domain.ShareLevelEdit}).
+		Return(domain.Share{ID: uuid.New().String(), ListID: listID, UserID: userID, Level: domain.ShareLevelEdit}, nil)
+
+	handler := &TodoHandler{
+		todoService: mockService,
+		logger:      slog.Default(),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/lists/"+listID+"/shares", strings.NewReader(`{"userId":"`+userID+`","level":"edit"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/lists/:id/shares")
+	c.SetParamNames("id")
+	c.SetParamValues(listID)
+
+	serve(c, handler.ShareList)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Success bool                  `json:"success"`
+		Data    schemas.ShareResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, listID, resp.Data.ListID)
+	assert.Empty(t, resp.Data.TodoID)
+	assert.Equal(t, "edit", resp.Data.Level)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeListShare(t *testing.T) {
+	listID := uuid.New().String()
+	shareID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "successful revoke", expectedStatus: http.StatusNoContent},
+		{name: "share not found", err: domain.ErrShareNotFound, expectedStatus: http.StatusNotFound, expectedError: "ShareNotFound"},
+		{name: "list not found", err: domain.ErrListNotFound, expectedStatus: http.StatusNotFound, expectedError: "ListNotFound"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			mockService.On("RevokeListShare", mock.Anything, listID, shareID).Return(tt.err)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/lists/"+listID+"/shares/"+shareID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/lists/:id/shares/:shareId")
+			c.SetParamNames("id", "shareId")
+			c.SetParamValues(listID, shareID)
+
+			serve(c, handler.RevokeListShare)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
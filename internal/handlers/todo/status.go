@@ -0,0 +1,42 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// TransitionTodo handles moving a todo item to another lifecycle status.
+func (h *TodoHandler) TransitionTodo(c echo.Context) error {
+	var req schemas.TransitionTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	status, err := domain.ParseTodoStatus(req.Status)
+	if err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, err := parseIfMatch(c.Request().Header.Get(headerIfMatch))
+	if err != nil {
+		return err
+	}
+
+	todoItem, err := h.todoService.TransitionTodo(c.Request().Context(), req.ID, status, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, todoItem.Version)
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTodoResponse(todoItem, zone),
+	})
+}
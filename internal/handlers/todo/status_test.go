@@ -0,0 +1,139 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionTodo(t *testing.T) {
+	todoID := uuid.New().String()
+	completedAt := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		status         string
+		ifMatch        string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:    "successful transition",
+			status:  "done",
+			ifMatch: `"2"`,
+			setupMock: func(m *MockTodoService) {
+				m.On("TransitionTodo", mock.Anything, todoID, domain.TodoStatusDone, int32(2)).
+					Return(domain.TodoItem{ID: todoID, Status: domain.TodoStatusDone, CompletedAt: completedAt, Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown status",
+			status:         "finished",
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "InvalidStatus",
+		},
+		{
+			name:   "transition not allowed",
+			status: "in_progress",
+			setupMock: func(m *MockTodoService) {
+				m.On("TransitionTodo", mock.Anything, todoID, domain.TodoStatusInProgress, int32(0)).
+					Return(domain.TodoItem{}, domain.ErrInvalidStatusTransition)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "InvalidStatusTransition",
+		},
+		{
+			name:    "version conflict",
+			status:  "done",
+			ifMatch: `"1"`,
+			setupMock: func(m *MockTodoService) {
+				m.On("TransitionTodo", mock.Anything, todoID, domain.TodoStatusDone, int32(1)).
+					Return(domain.TodoItem{}, domain.ErrTodoVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "PreconditionFailed",
+		},
+		{
+			name:   "todo not found",
+			status: "done",
+			setupMock: func(m *MockTodoService) {
+				m.On("TransitionTodo", mock.Anything, todoID, domain.TodoStatusDone, int32(0)).
+					Return(domain.TodoItem{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name:   "service error",
+			status: "done",
+			setupMock: func(m *MockTodoService) {
+				m.On("TransitionTodo", mock.Anything, todoID, domain.TodoStatusDone, int32(0)).
+					Return(domain.TodoItem{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			form := url.Values{"status": {tt.status}}
+			req := httptest.NewRequest(http.MethodPut, "/todos/"+todoID+"/status", strings.NewReader(form.Encode()))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			if tt.ifMatch != "" {
+				req.Header.Set(headerIfMatch, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id/status")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			serve(c, handler.TransitionTodo)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Data schemas.TodoResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, "done", resp.Data.Status)
+				assert.Equal(t, completedAt.Format(time.RFC3339), resp.Data.CompletedAt)
+				assert.Equal(t, `"3"`, rec.Header().Get(headerETag))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateTag handles the creation of a new tag.
+func (h *TodoHandler) CreateTag(c echo.Context) error {
+	var req schemas.CreateTagRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	tag, err := h.todoService.CreateTag(c.Request().Context(), domain.Tag{Name: req.Name, Colour: req.Colour})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toTagResponse(tag, zone),
+	})
+}
+
+// ListTags handles listing all tags ordered by name.
+func (h *TodoHandler) ListTags(c echo.Context) error {
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.todoService.ListTags(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTagResponses(tags, zone),
+	})
+}
+
+// GetTag handles fetching a single tag by its ID.
+func (h *TodoHandler) GetTag(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	tag, err := h.todoService.GetTag(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTagResponse(tag, zone),
+	})
+}
+
+// UpdateTag handles renaming or recolouring a tag.
+func (h *TodoHandler) UpdateTag(c echo.Context) error {
+	var req schemas.UpdateTagRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	tag, err := h.todoService.UpdateTag(c.Request().Context(), req.ID, domain.Tag{Name: req.Name, Colour: req.Colour})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTagResponse(tag, zone),
+	})
+}
+
+// DeleteTag handles deleting a tag, it is removed from every todo.
+func (h *TodoHandler) DeleteTag(c echo.Context) error {
+	var req schemas.TodoIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.todoService.DeleteTag(c.Request().Context(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toTagResponse renders the times of the tag in zone, UTC when it is nil.
+func toTagResponse(tag domain.Tag, zone *time.Location) schemas.TagResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.TagResponse{
+		ID:     tag.ID,
+		Name:   tag.Name,
+		Colour: tag.Colour,
+	}
+	if !tag.CreatedAt.IsZero() {
+		resp.CreatedAt = tag.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !tag.UpdatedAt.IsZero() {
+		resp.UpdatedAt = tag.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toTagResponses(tags []domain.Tag, zone *time.Location) []schemas.TagResponse {
+	resp := make([]schemas.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, toTagResponse(tag, zone))
+	}
+	return resp
+}
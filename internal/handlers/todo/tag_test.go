@@ -0,0 +1,213 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTag(t *testing.T) {
+	now := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+	tag := domain.Tag{ID: uuid.New().String(), Name: "work", Colour: "#ff8800", CreatedAt: now, UpdatedAt: now}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful creation",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTag", mock.Anything, domain.Tag{Name: "Work", Colour: "#ff8800"}).Return(tag, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "name taken",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTag", mock.Anything, mock.Anything).Return(domain.Tag{}, domain.ErrTagNameTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "TagNameTaken",
+		},
+		{
+			name: "service error",
+			setupMock: func(m *MockTodoService) {
+				m.On("CreateTag", mock.Anything, mock.Anything).Return(domain.Tag{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/tags", strings.NewReader(`{"name":"Work","colour":"#ff8800"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			serve(c, handler.CreateTag)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                `json:"success"`
+					Data    schemas.TagResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tag.ID, resp.Data.ID)
+				assert.Equal(t, "work", resp.Data.Name)
+				assert.Equal(t, "#ff8800", resp.Data.Colour)
+				assert.Equal(t, "2030-01-01T12:00:00Z", resp.Data.CreatedAt)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTag(t *testing.T) {
+	tagID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful get",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetTag", mock.Anything, tagID).Return(domain.Tag{ID: tagID, Name: "work"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "tag not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("GetTag", mock.Anything, tagID).Return(domain.Tag{}, domain.ErrTagNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TagNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/tags/"+tagID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tags/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tagID)
+
+			serve(c, handler.GetTag)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	tagID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful delete",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteTag", mock.Anything, tagID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "tag not found",
+			setupMock: func(m *MockTodoService) {
+				m.On("DeleteTag", mock.Anything, tagID).Return(domain.ErrTagNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TagNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/tags/"+tagID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tags/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tagID)
+
+			serve(c, handler.DeleteTag)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
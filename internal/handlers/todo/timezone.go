@@ -0,0 +1,27 @@
+package todo
+
+import (
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// headerTimeZone lets a client ask for the times of a response in its own IANA time zone.
+const headerTimeZone = "Time-Zone"
+
+var errInvalidTimeZoneHeader = domain.NewValidationError(headerTimeZone, "Time-Zone must be an IANA time zone name, e.g. Europe/Berlin")
+
+// requestTimeZone returns the zone named by the Time-Zone header, or nil when the header is missing and the todos
+// are shown in their own zone.
+func requestTimeZone(c echo.Context) (*time.Location, error) {
+	name := c.Request().Header.Get(headerTimeZone)
+	if name == "" {
+		return nil, nil
+	}
+	zone, err := domain.LoadTimeZone(name)
+	if err != nil {
+		return nil, errInvalidTimeZoneHeader
+	}
+	return zone, nil
+}
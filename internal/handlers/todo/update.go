@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/labstack/echo/v4"
+)
+
+// UpdateTodo handles the full replacement of a todo item's description, due date, time zone, tags, priority,
+// recurrence, parent and list.
+func (h *TodoHandler) UpdateTodo(c echo.Context) error {
+	cleanup, err := parseMultipartForm(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var req schemas.UpdateTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	dueDate, err := time.Parse(time.RFC3339, req.DueDate)
+	if err != nil {
+		return errInvalidDueDate
+	}
+	priority := domain.DefaultTodoPriority
+	if req.Priority != "" {
+		if priority, err = domain.ParseTodoPriority(req.Priority); err != nil {
+			return err
+		}
+	}
+
+	return h.updateTodo(c, req.ID, domain.TodoItemPatch{
+		Description: &req.Description,
+		DueDate:     &dueDate,
+		TimeZone:    &req.TimeZone,
+		Tags:        &req.Tags,
+		Priority:    &priority,
+		Recurrence:  &req.Recurrence,
+		ParentID:    &req.ParentID,
+		ListID:      &req.ListID,
+	})
+}
+
+// PatchTodo handles a partial update of a todo item, only the fields sent are changed.
+func (h *TodoHandler) PatchTodo(c echo.Context) error {
+	cleanup, err := parseMultipartForm(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var req schemas.PatchTodoRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	patch := domain.TodoItemPatch{Description: req.Description, TimeZone: req.TimeZone, Tags: req.Tags, Recurrence: req.Recurrence, ParentID: req.ParentID, ListID: req.ListID}
+	if req.DueDate != nil {
+		dueDate, err := time.Parse(time.RFC3339, *req.DueDate)
+		if err != nil {
+			return errInvalidDueDate
+		}
+		patch.DueDate = &dueDate
+	}
+	if req.Priority != nil {
+		priority, err := domain.ParseTodoPriority(*req.Priority)
+		if err != nil {
+			return err
+		}
+		patch.Priority = &priority
+	}
+
+	return h.updateTodo(c, req.ID, patch)
+}
+
+func (h *TodoHandler) updateTodo(c echo.Context, id string, patch domain.TodoItemPatch) error {
+	expectedVersion, err := parseIfMatch(c.Request().Header.Get(headerIfMatch))
+	if err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	todoItem, err := h.todoService.UpdateTodo(c.Request().Context(), id, patch, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, todoItem.Version)
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    toTodoResponse(todoItem, zone),
+	})
+}
@@ -0,0 +1,238 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateTodo(t *testing.T) {
+	todoID := uuid.New().String()
+	dueDate := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		method         string
+		form           url.Values
+		ifMatch        string
+		setupMock      func(*MockTodoService)
+		expectedStatus int
+		expectedError  string
+		expectedETag   string
+	}{
+		{
+			name:    "successful full update",
+			method:  http.MethodPut,
+			form:    url.Values{"description": {"Updated"}, "dueDate": {dueDate.Format(time.RFC3339)}},
+			ifMatch: `"3"`,
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.MatchedBy(func(patch domain.TodoItemPatch) bool {
+					return *patch.Description == "Updated" && patch.DueDate.Equal(dueDate) && *patch.TimeZone == "" &&
+						*patch.Priority == domain.DefaultTodoPriority
+				}), int32(3)).Return(domain.TodoItem{ID: todoID, Description: "Updated", DueDate: dueDate, Version: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:   "successful partial update",
+			method: http.MethodPatch,
+			form:   url.Values{"description": {"Updated"}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.MatchedBy(func(patch domain.TodoItemPatch) bool {
+					return *patch.Description == "Updated" && patch.DueDate == nil && patch.TimeZone == nil && patch.Priority == nil
+				}), int32(0)).Return(domain.TodoItem{ID: todoID, Description: "Updated", DueDate: dueDate, Version: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"2"`,
+		},
+		{
+			name:   "time zone change",
+			method: http.MethodPatch,
+			form:   url.Values{"timezone": {"Asia/Tokyo"}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.MatchedBy(func(patch domain.TodoItemPatch) bool {
+					return *patch.TimeZone == "Asia/Tokyo" && patch.Description == nil
+				}), int32(0)).Return(domain.TodoItem{ID: todoID, Description: "Updated", DueDate: dueDate, TimeZone: "Asia/Tokyo", Version: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"2"`,
+		},
+		{
+			name:   "priority change",
+			method: http.MethodPatch,
+			form:   url.Values{"priority": {"P0"}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.MatchedBy(func(patch domain.TodoItemPatch) bool {
+					return *patch.Priority == domain.TodoPriorityP0 && patch.Description == nil
+				}), int32(0)).Return(domain.TodoItem{ID: todoID, Description: "Updated", DueDate: dueDate, Priority: domain.TodoPriorityP0, Version: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"2"`,
+		},
+		{
+			name:   "stop recurring",
+			method: http.MethodPatch,
+			form:   url.Values{"recurrence": {""}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.MatchedBy(func(patch domain.TodoItemPatch) bool {
+					return patch.Recurrence != nil && *patch.Recurrence == "" && patch.Description == nil
+				}), int32(0)).Return(domain.TodoItem{ID: todoID, Description: "Updated", DueDate: dueDate, Version: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"2"`,
+		},
+		{
+			name:           "unknown priority",
+			method:         http.MethodPatch,
+			form:           url.Values{"priority": {"urgent"}},
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:           "invalid due date",
+			method:         http.MethodPatch,
+			form:           url.Values{"dueDate": {"invalid-date"}},
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ValidationFailed",
+		},
+		{
+			name:           "malformed If-Match",
+			method:         http.MethodPatch,
+			form:           url.Values{"description": {"Updated"}},
+			ifMatch:        `"abc"`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "InvalidIfMatch",
+		},
+		{
+			name:           "weak If-Match fails the precondition",
+			method:         http.MethodPatch,
+			form:           url.Values{"description": {"Updated"}},
+			ifMatch:        `W/"3"`,
+			setupMock:      func(_ *MockTodoService) {},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "PreconditionFailed",
+		},
+		{
+			name:    "version conflict",
+			method:  http.MethodPatch,
+			form:    url.Values{"description": {"Updated"}},
+			ifMatch: `"1"`,
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.Anything, int32(1)).
+					Return(domain.TodoItem{}, domain.ErrTodoVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "PreconditionFailed",
+		},
+		{
+			name:   "todo not found",
+			method: http.MethodPut,
+			form:   url.Values{"description": {"Updated"}, "dueDate": {dueDate.Format(time.RFC3339)}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.Anything, int32(0)).
+					Return(domain.TodoItem{}, domain.ErrTodoNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "TodoNotFound",
+		},
+		{
+			name:   "service error",
+			method: http.MethodPatch,
+			form:   url.Values{"description": {"Updated"}},
+			setupMock: func(m *MockTodoService) {
+				m.On("UpdateTodo", mock.Anything, todoID, mock.Anything, int32(0)).
+					Return(domain.TodoItem{}, errors.New("service error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "InternalServerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockTodoService{}
+			tt.setupMock(mockService)
+
+			handler := &TodoHandler{
+				todoService: mockService,
+				logger:      slog.Default(),
+			}
+
+			req := httptest.NewRequest(tt.method, "/todos/"+todoID, strings.NewReader(tt.form.Encode()))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			if tt.ifMatch != "" {
+				req.Header.Set(headerIfMatch, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(todoID)
+
+			if tt.method == http.MethodPut {
+				serve(c, handler.UpdateTodo)
+			} else {
+				serve(c, handler.PatchTodo)
+			}
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				assert.Equal(t, tt.expectedETag, rec.Header().Get(headerETag))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header      string
+		expected    int32
+		expectedErr error
+	}{
+		{header: "", expected: 0},
+		{header: "*", expected: 0},
+		{header: `"7"`, expected: 7},
+		{header: `W/"7"`, expectedErr: errWeakETag},
+		{header: `"0"`, expectedErr: errInvalidETag},
+		{header: `"abc"`, expectedErr: errInvalidETag},
+		{header: `"1", "2"`, expectedErr: errInvalidETag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
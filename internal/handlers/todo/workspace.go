@@ -0,0 +1,149 @@
+package todo
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/application"
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/a-berahman/todo-list/internal/ports/inbound"
+	"github.com/labstack/echo/v4"
+)
+
+// WorkspaceHandler serves the endpoints that manage workspaces and their members.
+type WorkspaceHandler struct {
+	workspaceService inbound.WorkspaceService
+	logger           *slog.Logger
+}
+
+func NewWorkspaceHandler(workspaceService *application.WorkspaceService, logger *slog.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService, logger: logger}
+}
+
+// CreateWorkspace handles creating a workspace, the user who creates it becomes its owner.
+func (h *WorkspaceHandler) CreateWorkspace(c echo.Context) error {
+	var req schemas.CreateWorkspaceRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(c.Request().Context(), req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, schemas.APIResponse{
+		Success: true,
+		Data:    toWorkspaceResponse(workspace, zone),
+	})
+}
+
+// ListWorkspaces handles listing the workspaces the user is a member of.
+func (h *WorkspaceHandler) ListWorkspaces(c echo.Context) error {
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := h.workspaceService.ListWorkspaces(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]schemas.WorkspaceResponse, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		resp = append(resp, toWorkspaceResponse(workspace, zone))
+	}
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// ListWorkspaceMembers handles listing the members of a workspace.
+func (h *WorkspaceHandler) ListWorkspaceMembers(c echo.Context) error {
+	var req schemas.WorkspaceIDRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	zone, err := requestTimeZone(c)
+	if err != nil {
+		return err
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+
+	members, err := h.workspaceService.ListWorkspaceMembers(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]schemas.WorkspaceMemberResponse, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, schemas.WorkspaceMemberResponse{
+			UserID:    member.UserID,
+			Email:     member.Email,
+			Name:      member.Name,
+			Role:      string(member.Role),
+			CreatedAt: member.CreatedAt.In(zone).Format(time.RFC3339),
+		})
+	}
+	return c.JSON(http.StatusOK, schemas.APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
+// SetWorkspaceMember handles adding a user to a workspace or changing their role.
+func (h *WorkspaceHandler) SetWorkspaceMember(c echo.Context) error {
+	var req schemas.SetWorkspaceMemberRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.workspaceService.SetWorkspaceMember(c.Request().Context(), req.ID, req.UserID, domain.Role(req.Role)); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveWorkspaceMember handles taking a user out of a workspace.
+func (h *WorkspaceHandler) RemoveWorkspaceMember(c echo.Context) error {
+	var req schemas.WorkspaceMemberRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.workspaceService.RemoveWorkspaceMember(c.Request().Context(), req.ID, req.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toWorkspaceResponse renders the times of the workspace in zone, UTC when it is nil.
+func toWorkspaceResponse(workspace domain.Workspace, zone *time.Location) schemas.WorkspaceResponse {
+	if zone == nil {
+		zone = time.UTC
+	}
+	resp := schemas.WorkspaceResponse{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+		Role: string(workspace.Role),
+	}
+	if !workspace.CreatedAt.IsZero() {
+		resp.CreatedAt = workspace.CreatedAt.In(zone).Format(time.RFC3339)
+	}
+	if !workspace.UpdatedAt.IsZero() {
+		resp.UpdatedAt = workspace.UpdatedAt.In(zone).Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,224 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/a-berahman/todo-list/internal/handlers/schemas"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWorkspaceService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceService) ResolveWorkspace(ctx context.Context, workspaceID string) (domain.WorkspaceMember, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).(domain.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceService) CreateWorkspace(ctx context.Context, name string) (domain.Workspace, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(domain.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaces(ctx context.Context) ([]domain.Workspace, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]domain.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string, role domain.Role) error {
+	args := m.Called(ctx, workspaceID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	args := m.Called(ctx, workspaceID, userID)
+	return args.Error(0)
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	workspaceID := uuid.New().String()
+	createdAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockWorkspaceService{}
+	mockService.On("CreateWorkspace", mock.Anything, "Household").
+		Return(domain.Workspace{ID: workspaceID, Name: "Household", Role: domain.RoleOwner, CreatedAt: createdAt, UpdatedAt: createdAt}, nil)
+
+	handler := &WorkspaceHandler{workspaceService: mockService, logger: slog.Default()}
+
+	req := httptest.NewRequest(http.MethodPost, "/workspaces", strings.NewReader(`{"name":"Household"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	serve(c, handler.CreateWorkspace)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp struct {
+		Success bool                      `json:"success"`
+		Data    schemas.WorkspaceResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, schemas.WorkspaceResponse{
+		ID:        workspaceID,
+		Name:      "Household",
+		Role:      "owner",
+		CreatedAt: "2024-06-01T12:00:00Z",
+		UpdatedAt: "2024-06-01T12:00:00Z",
+	}, resp.Data)
+	mockService.AssertExpectations(t)
+}
+
+func TestListWorkspaceMembers(t *testing.T) {
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		members        []domain.WorkspaceMember
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "lists the members",
+			members:        []domain.WorkspaceMember{{WorkspaceID: workspaceID, UserID: userID, Email: "ada@example.com", Name: "Ada", Role: domain.RoleEditor}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not a member",
+			serviceErr:     domain.ErrWorkspaceNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "WorkspaceNotFound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockWorkspaceService{}
+			mockService.On("ListWorkspaceMembers", mock.Anything, workspaceID).Return(tt.members, tt.serviceErr)
+
+			handler := &WorkspaceHandler{workspaceService: mockService, logger: slog.Default()}
+
+			req := httptest.NewRequest(http.MethodGet, "/workspaces/"+workspaceID+"/members", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/workspaces/:id/members")
+			c.SetParamNames("id")
+			c.SetParamValues(workspaceID)
+
+			serve(c, handler.ListWorkspaceMembers)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			} else {
+				var resp struct {
+					Success bool                              `json:"success"`
+					Data    []schemas.WorkspaceMemberResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				if assert.Len(t, resp.Data, 1) {
+					assert.Equal(t, userID, resp.Data[0].UserID)
+					assert.Equal(t, "editor", resp.Data[0].Role)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetWorkspaceMember(t *testing.T) {
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "successful set", expectedStatus: http.StatusNoContent},
+		{name: "caller isn't an owner", serviceErr: domain.ErrWorkspaceOwnerRequired, expectedStatus: http.StatusForbidden, expectedError: "WorkspaceOwnerRequired"},
+		{name: "last owner", serviceErr: domain.ErrLastWorkspaceOwner, expectedStatus: http.StatusConflict, expectedError: "LastWorkspaceOwner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &CustomValidator{}
+
+			mockService := &MockWorkspaceService{}
+			mockService.On("SetWorkspaceMember", mock.Anything, workspaceID, userID, domain.RoleViewer).Return(tt.serviceErr)
+
+			handler := &WorkspaceHandler{workspaceService: mockService, logger: slog.Default()}
+
+			req := httptest.NewRequest(http.MethodPut, "/workspaces/"+workspaceID+"/members/"+userID, strings.NewReader(`{"role":"viewer"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/workspaces/:id/members/:userId")
+			c.SetParamNames("id", "userId")
+			c.SetParamValues(workspaceID, userID)
+
+			serve(c, handler.SetWorkspaceMember)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedError != "" {
+				var errResp schemas.Problem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveWorkspaceMember(t *testing.T) {
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{}
+
+	mockService := &MockWorkspaceService{}
+	mockService.On("RemoveWorkspaceMember", mock.Anything, workspaceID, userID).Return(nil)
+
+	handler := &WorkspaceHandler{workspaceService: mockService, logger: slog.Default()}
+
+	req := httptest.NewRequest(http.MethodDelete, "/workspaces/"+workspaceID+"/members/"+userID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/workspaces/:id/members/:userId")
+	c.SetParamNames("id", "userId")
+	c.SetParamValues(workspaceID, userID)
+
+	serve(c, handler.RemoveWorkspaceMember)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockService.AssertExpectations(t)
+}
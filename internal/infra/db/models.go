@@ -8,11 +8,143 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type TodoItem struct {
+type ApiKey struct {
+	ID         pgtype.UUID        `json:"id"`
+	OwnerID    pgtype.UUID        `json:"ownerId"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	KeyHash    []byte             `json:"keyHash"`
+	Scopes     []string           `json:"scopes"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+	LastUsedAt pgtype.Timestamptz `json:"lastUsedAt"`
+	RevokedAt  pgtype.Timestamptz `json:"revokedAt"`
+}
+
+type Attachment struct {
 	ID          pgtype.UUID      `json:"id"`
-	Description string           `json:"description"`
-	DueDate     pgtype.Timestamp `json:"dueDate"`
-	FileID      pgtype.Text      `json:"fileId"`
+	TodoID      pgtype.UUID      `json:"todoId"`
+	StorageKey  string           `json:"storageKey"`
+	Filename    string           `json:"filename"`
+	ContentType string           `json:"contentType"`
+	Size        int64            `json:"size"`
+	Checksum    string           `json:"checksum"`
+	UploadedAt  pgtype.Timestamp `json:"uploadedAt"`
+}
+
+type ChecklistItem struct {
+	ID        pgtype.UUID        `json:"id"`
+	TodoID    pgtype.UUID        `json:"todoId"`
+	Text      string             `json:"text"`
+	Done      bool               `json:"done"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type Comment struct {
+	ID        pgtype.UUID        `json:"id"`
+	TodoID    pgtype.UUID        `json:"todoId"`
+	AuthorID  pgtype.UUID        `json:"authorId"`
+	Text      string             `json:"text"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
+type List struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Colour      string             `json:"colour"`
+	Archived    bool               `json:"archived"`
+	DefaultSort string             `json:"defaultSort"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type Outbox struct {
+	ID          int64            `json:"id"`
+	EventType   string           `json:"eventType"`
+	Payload     []byte           `json:"payload"`
+	Attempts    int32            `json:"attempts"`
+	LastError   pgtype.Text      `json:"lastError"`
+	AvailableAt pgtype.Timestamp `json:"availableAt"`
 	CreatedAt   pgtype.Timestamp `json:"createdAt"`
-	UpdatedAt   pgtype.Timestamp `json:"updatedAt"`
+	SentAt      pgtype.Timestamp `json:"sentAt"`
+}
+
+type Share struct {
+	ID               pgtype.UUID        `json:"id"`
+	TodoID           pgtype.UUID        `json:"todoId"`
+	UserID           pgtype.UUID        `json:"userId"`
+	WorkspaceID      pgtype.UUID        `json:"workspaceId"`
+	Level            string             `json:"level"`
+	GrantedBy        pgtype.UUID        `json:"grantedBy"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt        pgtype.Timestamptz `json:"updatedAt"`
+	OwnerWorkspaceID pgtype.UUID        `json:"ownerWorkspaceId"`
+	ListID           pgtype.UUID        `json:"listId"`
+}
+
+type Tag struct {
+	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	Colour      string             `json:"colour"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+}
+
+type TodoDependency struct {
+	TodoID    pgtype.UUID        `json:"todoId"`
+	BlockerID pgtype.UUID        `json:"blockerId"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
+type TodoItem struct {
+	ID           pgtype.UUID        `json:"id"`
+	Description  string             `json:"description"`
+	DueDate      pgtype.Timestamptz `json:"dueDate"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt    pgtype.Timestamptz `json:"updatedAt"`
+	Version      int32              `json:"version"`
+	DeletedAt    pgtype.Timestamptz `json:"deletedAt"`
+	Status       string             `json:"status"`
+	CompletedAt  pgtype.Timestamptz `json:"completedAt"`
+	Timezone     pgtype.Text        `json:"timezone"`
+	SearchVector interface{}        `json:"searchVector"`
+	Priority     string             `json:"priority"`
+	Rank         string             `json:"rank"`
+	Recurrence   pgtype.Text        `json:"recurrence"`
+	RecursFrom   pgtype.UUID        `json:"recursFrom"`
+	ParentID     pgtype.UUID        `json:"parentId"`
+	OwnerID      pgtype.UUID        `json:"ownerId"`
+	WorkspaceID  pgtype.UUID        `json:"workspaceId"`
+	ListID       pgtype.UUID        `json:"listId"`
+}
+
+type TodoTag struct {
+	TodoID pgtype.UUID `json:"todoId"`
+	TagID  pgtype.UUID `json:"tagId"`
+}
+
+type User struct {
+	ID        pgtype.UUID        `json:"id"`
+	Subject   string             `json:"subject"`
+	Email     string             `json:"email"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type Workspace struct {
+	ID        pgtype.UUID        `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+	UserID      pgtype.UUID        `json:"userId"`
+	Role        string             `json:"role"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
 }
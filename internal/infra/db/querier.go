@@ -6,10 +6,133 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddTodoDependency(ctx context.Context, arg AddTodoDependencyParams) (int64, error)
+	AddTodoTag(ctx context.Context, arg AddTodoTagParams) error
+	CheckChecklistItems(ctx context.Context, arg CheckChecklistItemsParams) error
+	// Leases the pending events to the relay until leased_until instead of keeping them locked while they are published,
+	// concurrent relays skip them and they become pending again if the relay stops before marking them.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error)
+	CountChecklistItems(ctx context.Context, todoID pgtype.UUID) (int64, error)
+	CountWorkspaceMemberships(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountWorkspaceOwners(ctx context.Context, workspaceID pgtype.UUID) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) error
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) error
+	CreateChecklistItem(ctx context.Context, arg CreateChecklistItemParams) error
+	CreateComment(ctx context.Context, arg CreateCommentParams) error
+	CreateList(ctx context.Context, arg CreateListParams) (List, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) error
 	CreateTodo(ctx context.Context, arg CreateTodoParams) error
+	CreateWorkspace(ctx context.Context, arg CreateWorkspaceParams) error
+	DeleteAttachment(ctx context.Context, arg DeleteAttachmentParams) (Attachment, error)
+	DeleteChecklistItem(ctx context.Context, arg DeleteChecklistItemParams) (int64, error)
+	// The todos of the list stay, without a list.
+	DeleteList(ctx context.Context, arg DeleteListParams) (int64, error)
+	DeleteListShare(ctx context.Context, arg DeleteListShareParams) (Share, error)
+	// Deletes up to batch_size of the events sent before sent_before, callers repeat it until fewer rows are deleted.
+	DeleteSentOutboxEvents(ctx context.Context, arg DeleteSentOutboxEventsParams) (int64, error)
+	DeleteShare(ctx context.Context, arg DeleteShareParams) (Share, error)
+	DeleteTag(ctx context.Context, arg DeleteTagParams) (int64, error)
+	DeleteTodoTags(ctx context.Context, todoID pgtype.UUID) error
+	DeleteWorkspaceMember(ctx context.Context, arg DeleteWorkspaceMemberParams) (int64, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error)
+	GetAttachment(ctx context.Context, arg GetAttachmentParams) (Attachment, error)
+	GetChecklistItem(ctx context.Context, arg GetChecklistItemParams) (ChecklistItem, error)
+	// The workspace requests go to when they don't name one is the first one the user joined.
+	GetDefaultWorkspaceMember(ctx context.Context, userID pgtype.UUID) (WorkspaceMember, error)
+	GetLastTodoRank(ctx context.Context, workspaceID pgtype.UUID) (string, error)
+	// Counts the todos of the list that are neither done nor cancelled, those of them past their due date and the
+	// done ones, trashed todos don't count.
+	GetList(ctx context.Context, arg GetListParams) (GetListRow, error)
+	// Looks a list up without counting its todos, for moving todos to it and sorting them.
+	GetListState(ctx context.Context, arg GetListStateParams) (GetListStateRow, error)
+	GetNextTodoRank(ctx context.Context, arg GetNextTodoRankParams) (string, error)
+	GetPreviousTodoRank(ctx context.Context, arg GetPreviousTodoRankParams) (string, error)
+	// Counts the levels of the todo and its subtasks, trashed subtasks included since they can be restored.
+	GetSubtreeHeight(ctx context.Context, id pgtype.UUID) (int32, error)
+	GetTag(ctx context.Context, arg GetTagParams) (Tag, error)
+	GetTodo(ctx context.Context, arg GetTodoParams) (TodoItem, error)
+	// Looks up a todo shared with the user, directly or through one of the workspaces they are a member of, together
+	// with the workspace it belongs to and the levels of all shares reaching the user.
+	GetTodoShareLevels(ctx context.Context, arg GetTodoShareLevelsParams) (GetTodoShareLevelsRow, error)
+	GetUserBySubject(ctx context.Context, subject string) (User, error)
+	GetWorkspaceMember(ctx context.Context, arg GetWorkspaceMemberParams) (WorkspaceMember, error)
+	// Trashed occurrences count too, a todo deleted on purpose doesn't come back when its predecessor is completed again.
+	HasNextOccurrence(ctx context.Context, recursFrom pgtype.UUID) (bool, error)
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error
+	// Reports whether the todo waits for the blocker, directly or through other todos.
+	IsTodoBlockedBy(ctx context.Context, arg IsTodoBlockedByParams) (bool, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListAttachments(ctx context.Context, todoID pgtype.UUID) ([]Attachment, error)
+	ListAttachmentsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]Attachment, error)
+	ListChecklistItemsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]ChecklistItem, error)
+	ListComments(ctx context.Context, todoID pgtype.UUID) ([]Comment, error)
+	// Returns the dependencies of the todos and of their blockers at every depth, leaving out trashed blockers.
+	ListDependencyGraph(ctx context.Context, todoIds []pgtype.UUID) ([]ListDependencyGraphRow, error)
+	ListListShares(ctx context.Context, listID pgtype.UUID) ([]Share, error)
+	// Counts the todos of every list in a single pass like GetList, archived lists are left out unless asked for.
+	ListLists(ctx context.Context, arg ListListsParams) ([]ListListsRow, error)
+	// Returns the blockers of the todo that are neither done nor cancelled, trashed blockers don't hold it up.
+	ListOpenBlockerIDs(ctx context.Context, todoID pgtype.UUID) ([]pgtype.UUID, error)
+	// Pages through the trash oldest first, the cursor moves past the todos that failed to purge in the same run.
+	ListPurgeableTodos(ctx context.Context, arg ListPurgeableTodosParams) ([]ListPurgeableTodosRow, error)
+	ListReferencedStorageKeys(ctx context.Context, storageKeys []string) ([]string, error)
+	// Keys that attachments of other todos refer to as well, such as the files carried over to a recurring todo.
+	ListSharedStorageKeys(ctx context.Context, arg ListSharedStorageKeysParams) ([]string, error)
+	ListShares(ctx context.Context, todoID pgtype.UUID) ([]Share, error)
+	// Returns the subtasks of the todo at every depth in the manual order, callers nest them by their parent IDs.
+	ListSubtasks(ctx context.Context, parentID pgtype.UUID) ([]TodoItem, error)
+	ListTags(ctx context.Context, workspaceID pgtype.UUID) ([]Tag, error)
+	ListTagsByTodoIDs(ctx context.Context, todoIds []pgtype.UUID) ([]ListTagsByTodoIDsRow, error)
+	// Returns the todo and the todos above it up to the top-level one.
+	ListTodoAncestorIDs(ctx context.Context, id pgtype.UUID) ([]pgtype.UUID, error)
+	// Counts the direct subtasks and the checklist items of each todo, cancelled subtasks don't count.
+	ListTodoProgress(ctx context.Context, todoIds []pgtype.UUID) ([]ListTodoProgressRow, error)
+	ListTodos(ctx context.Context, arg ListTodosParams) ([]TodoItem, error)
+	ListTodosByDueDate(ctx context.Context, arg ListTodosByDueDateParams) ([]TodoItem, error)
+	ListTodosByIDs(ctx context.Context, arg ListTodosByIDsParams) ([]TodoItem, error)
+	ListTodosByPriority(ctx context.Context, arg ListTodosByPriorityParams) ([]TodoItem, error)
+	ListWorkspaceMembers(ctx context.Context, workspaceID pgtype.UUID) ([]ListWorkspaceMembersRow, error)
+	ListWorkspacesByUser(ctx context.Context, userID pgtype.UUID) ([]ListWorkspacesByUserRow, error)
+	// Serializes the transactions adding dependencies so concurrent ones can't close a cycle together.
+	LockTodoDependencies(ctx context.Context) error
+	// Serializes the transactions changing parents so concurrent moves can't nest todos in a cycle or too deeply.
+	LockTodoHierarchy(ctx context.Context) error
+	// Serializes the transactions handing out ranks in a workspace so no two of its todos get the same one.
+	LockTodoRanks(ctx context.Context, workspaceID pgtype.UUID) error
+	// Serializes the transactions changing the members of the workspace so they can't remove its last owner together.
+	LockWorkspace(ctx context.Context, id pgtype.UUID) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventSent(ctx context.Context, arg MarkOutboxEventSentParams) error
+	// Subtasks at every depth follow the todo to its new list.
+	MoveSubtasksToList(ctx context.Context, arg MoveSubtasksToListParams) error
+	PurgeTodo(ctx context.Context, id pgtype.UUID) error
+	RemoveTodoDependency(ctx context.Context, arg RemoveTodoDependencyParams) (int64, error)
+	// Restores the subtasks that went to the trash together with the todo, those trashed before it stay there.
+	RestoreSubtasks(ctx context.Context, arg RestoreSubtasksParams) error
+	// A subtask restored while its parent is still in the trash becomes a top-level todo.
+	RestoreTodo(ctx context.Context, arg RestoreTodoParams) (TodoItem, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	// Subtasks go to the trash together with the todo, at the same time so RestoreSubtasks can tell them apart.
+	SoftDeleteTodo(ctx context.Context, arg SoftDeleteTodoParams) (int64, error)
+	// Records the use of the key at most once a minute, so busy clients don't turn every request into a write.
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	UpdateChecklistItem(ctx context.Context, arg UpdateChecklistItemParams) (ChecklistItem, error)
+	UpdateList(ctx context.Context, arg UpdateListParams) (List, error)
+	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
+	UpdateTodo(ctx context.Context, arg UpdateTodoParams) (TodoItem, error)
+	UpdateTodoRank(ctx context.Context, arg UpdateTodoRankParams) (TodoItem, error)
+	UpdateTodoStatus(ctx context.Context, arg UpdateTodoStatusParams) (TodoItem, error)
+	// Sharing the todo again with the same grantee changes the level of the existing share.
+	UpsertShare(ctx context.Context, arg UpsertShareParams) (Share, error)
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error)
+	// Creates the user on their first sign-in, afterwards it updates the email and the name the user's tokens carry.
+	UpsertUser(ctx context.Context, arg UpsertUserParams) (User, error)
+	UpsertWorkspaceMember(ctx context.Context, arg UpsertWorkspaceMemberParams) error
 }
 
 var _ Querier = (*Queries)(nil)
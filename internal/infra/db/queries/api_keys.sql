@@ -0,0 +1,28 @@
+-- name: CreateAPIKey :exec
+INSERT INTO api_keys (
+    id, owner_id, name, prefix, key_hash, scopes, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT id, owner_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at
+FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL;
+
+-- name: ListAPIKeys :many
+SELECT id, owner_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at
+FROM api_keys
+ORDER BY created_at DESC, id DESC;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = $2
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING id, owner_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at;
+
+-- name: TouchAPIKey :exec
+-- Records the use of the key at most once a minute, so busy clients don't turn every request into a write.
+UPDATE api_keys
+SET last_used_at = $2
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2::timestamptz - interval '1 minute');
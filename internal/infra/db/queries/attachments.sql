@@ -0,0 +1,39 @@
+-- name: CreateAttachment :exec
+INSERT INTO attachments (
+    id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: GetAttachment :one
+SELECT id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at
+FROM attachments
+WHERE todo_id = $1 AND id = $2;
+
+-- name: ListAttachments :many
+SELECT id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at
+FROM attachments
+WHERE todo_id = $1
+ORDER BY uploaded_at, id;
+
+-- name: ListAttachmentsByTodoIDs :many
+SELECT id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at
+FROM attachments
+WHERE todo_id = ANY(sqlc.arg('todo_ids')::uuid[])
+ORDER BY todo_id, uploaded_at, id;
+
+-- name: DeleteAttachment :one
+DELETE FROM attachments
+WHERE todo_id = $1 AND id = $2
+RETURNING id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at;
+
+-- name: ListReferencedStorageKeys :many
+SELECT storage_key
+FROM attachments
+WHERE storage_key = ANY(sqlc.arg('storage_keys')::text[]);
+
+-- name: ListSharedStorageKeys :many
+-- Keys that attachments of other todos refer to as well, such as the files carried over to a recurring todo.
+SELECT DISTINCT storage_key
+FROM attachments
+WHERE storage_key = ANY(sqlc.arg('storage_keys')::text[]) AND todo_id <> sqlc.arg('todo_id');
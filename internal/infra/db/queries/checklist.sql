@@ -0,0 +1,40 @@
+-- name: CreateChecklistItem :exec
+INSERT INTO checklist_items (
+    id, todo_id, text, created_at, updated_at
+) VALUES (
+    $1, $2, $3, $4, $4
+);
+
+-- name: CountChecklistItems :one
+SELECT count(*)
+FROM checklist_items
+WHERE todo_id = $1;
+
+-- name: GetChecklistItem :one
+SELECT id, todo_id, text, done, created_at, updated_at
+FROM checklist_items
+WHERE todo_id = $1 AND id = $2;
+
+-- name: ListChecklistItemsByTodoIDs :many
+SELECT id, todo_id, text, done, created_at, updated_at
+FROM checklist_items
+WHERE todo_id = ANY(sqlc.arg('todo_ids')::uuid[])
+ORDER BY todo_id, created_at, id;
+
+-- name: UpdateChecklistItem :one
+UPDATE checklist_items
+SET text = $3,
+    done = $4,
+    updated_at = $5
+WHERE todo_id = $1 AND id = $2
+RETURNING id, todo_id, text, done, created_at, updated_at;
+
+-- name: CheckChecklistItems :exec
+UPDATE checklist_items
+SET done = true,
+    updated_at = sqlc.arg('updated_at')
+WHERE todo_id = ANY(sqlc.arg('todo_ids')::uuid[]) AND NOT done;
+
+-- name: DeleteChecklistItem :execrows
+DELETE FROM checklist_items
+WHERE todo_id = $1 AND id = $2;
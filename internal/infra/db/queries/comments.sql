@@ -0,0 +1,12 @@
+-- name: CreateComment :exec
+INSERT INTO comments (
+    id, todo_id, author_id, text, created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListComments :many
+SELECT id, todo_id, author_id, text, created_at
+FROM comments
+WHERE todo_id = $1
+ORDER BY created_at, id;
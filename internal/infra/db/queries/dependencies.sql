@@ -0,0 +1,57 @@
+-- name: AddTodoDependency :execrows
+INSERT INTO todo_dependencies (
+    todo_id, blocker_id, created_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTodoDependency :execrows
+DELETE FROM todo_dependencies
+WHERE todo_id = $1 AND blocker_id = $2;
+
+-- name: IsTodoBlockedBy :one
+-- Reports whether the todo waits for the blocker, directly or through other todos.
+WITH RECURSIVE blockers AS (
+    SELECT todo_dependencies.blocker_id
+    FROM todo_dependencies
+    WHERE todo_dependencies.todo_id = sqlc.arg('todo_id')
+    UNION
+    SELECT todo_dependencies.blocker_id
+    FROM todo_dependencies
+    JOIN blockers ON todo_dependencies.todo_id = blockers.blocker_id
+)
+SELECT EXISTS (
+    SELECT 1 FROM blockers WHERE blockers.blocker_id = sqlc.arg('blocker_id')
+);
+
+-- name: ListOpenBlockerIDs :many
+-- Returns the blockers of the todo that are neither done nor cancelled, trashed blockers don't hold it up.
+SELECT todo_items.id
+FROM todo_dependencies
+JOIN todo_items ON todo_items.id = todo_dependencies.blocker_id
+WHERE todo_dependencies.todo_id = $1
+  AND todo_items.deleted_at IS NULL
+  AND todo_items.status NOT IN ('done', 'cancelled')
+ORDER BY todo_items.rank;
+
+-- name: ListDependencyGraph :many
+-- Returns the dependencies of the todos and of their blockers at every depth, leaving out trashed blockers.
+WITH RECURSIVE graph AS (
+    SELECT todo_dependencies.todo_id, todo_dependencies.blocker_id
+    FROM todo_dependencies
+    JOIN todo_items ON todo_items.id = todo_dependencies.blocker_id AND todo_items.deleted_at IS NULL
+    WHERE todo_dependencies.todo_id = ANY(sqlc.arg('todo_ids')::uuid[])
+    UNION
+    SELECT todo_dependencies.todo_id, todo_dependencies.blocker_id
+    FROM todo_dependencies
+    JOIN graph ON todo_dependencies.todo_id = graph.blocker_id
+    JOIN todo_items ON todo_items.id = todo_dependencies.blocker_id AND todo_items.deleted_at IS NULL
+)
+SELECT todo_id, blocker_id
+FROM graph
+ORDER BY todo_id, blocker_id;
+
+-- name: LockTodoDependencies :exec
+-- Serializes the transactions adding dependencies so concurrent ones can't close a cycle together.
+SELECT pg_advisory_xact_lock(hashtext('todo_dependencies'));
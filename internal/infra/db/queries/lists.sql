@@ -0,0 +1,57 @@
+-- name: CreateList :one
+INSERT INTO lists (
+    id, workspace_id, name, description, colour, archived, default_sort, created_at, updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $8
+)
+RETURNING id, workspace_id, name, description, colour, archived, default_sort, created_at, updated_at;
+
+-- name: GetList :one
+-- Counts the todos of the list that are neither done nor cancelled, those of them past their due date and the
+-- done ones, trashed todos don't count.
+SELECT lists.id, lists.workspace_id, lists.name, lists.description, lists.colour, lists.archived, lists.default_sort,
+       lists.created_at, lists.updated_at,
+       count(todo_items.id) FILTER (WHERE todo_items.status NOT IN ('done', 'cancelled')) AS open_count,
+       count(todo_items.id) FILTER (WHERE todo_items.status NOT IN ('done', 'cancelled')
+                                      AND todo_items.due_date < sqlc.arg('overdue_at')::timestamptz) AS overdue_count,
+       count(todo_items.id) FILTER (WHERE todo_items.status = 'done') AS done_count
+FROM lists
+LEFT JOIN todo_items ON todo_items.list_id = lists.id AND todo_items.deleted_at IS NULL
+WHERE lists.id = $1 AND lists.workspace_id = $2
+GROUP BY lists.id;
+
+-- name: ListLists :many
+-- Counts the todos of every list in a single pass like GetList, archived lists are left out unless asked for.
+SELECT lists.id, lists.workspace_id, lists.name, lists.description, lists.colour, lists.archived, lists.default_sort,
+       lists.created_at, lists.updated_at,
+       count(todo_items.id) FILTER (WHERE todo_items.status NOT IN ('done', 'cancelled')) AS open_count,
+       count(todo_items.id) FILTER (WHERE todo_items.status NOT IN ('done', 'cancelled')
+                                      AND todo_items.due_date < sqlc.arg('overdue_at')::timestamptz) AS overdue_count,
+       count(todo_items.id) FILTER (WHERE todo_items.status = 'done') AS done_count
+FROM lists
+LEFT JOIN todo_items ON todo_items.list_id = lists.id AND todo_items.deleted_at IS NULL
+WHERE lists.workspace_id = $1 AND (sqlc.arg('include_archived')::boolean OR NOT lists.archived)
+GROUP BY lists.id
+ORDER BY lists.name;
+
+-- name: GetListState :one
+-- Looks a list up without counting its todos, for moving todos to it and sorting them.
+SELECT archived, default_sort
+FROM lists
+WHERE id = $1 AND workspace_id = $2;
+
+-- name: UpdateList :one
+UPDATE lists
+SET name = $3,
+    description = $4,
+    colour = $5,
+    archived = $6,
+    default_sort = $7,
+    updated_at = $8
+WHERE id = $1 AND workspace_id = $2
+RETURNING id, workspace_id, name, description, colour, archived, default_sort, created_at, updated_at;
+
+-- name: DeleteList :execrows
+-- The todos of the list stay, without a list.
+DELETE FROM lists
+WHERE id = $1 AND workspace_id = $2;
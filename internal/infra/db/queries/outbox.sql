@@ -0,0 +1,44 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (
+    event_type, payload, created_at, available_at
+) VALUES (
+    $1, $2, $3, $3
+);
+
+-- name: ClaimOutboxEvents :many
+-- Leases the pending events to the relay until leased_until instead of keeping them locked while they are published,
+-- concurrent relays skip them and they become pending again if the relay stops before marking them.
+UPDATE outbox
+SET available_at = sqlc.arg('leased_until')
+WHERE id IN (
+    SELECT pending.id
+    FROM outbox AS pending
+    WHERE pending.sent_at IS NULL AND pending.available_at <= sqlc.arg('available_before')
+    ORDER BY pending.id
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_type, payload, attempts, last_error, available_at, created_at, sent_at;
+
+-- name: DeleteSentOutboxEvents :execrows
+-- Deletes up to batch_size of the events sent before sent_before, callers repeat it until fewer rows are deleted.
+DELETE FROM outbox
+WHERE id IN (
+    SELECT sent.id
+    FROM outbox AS sent
+    WHERE sent.sent_at < sqlc.arg('sent_before')
+    ORDER BY sent.id
+    LIMIT sqlc.arg('batch_size')
+);
+
+-- name: MarkOutboxEventSent :exec
+UPDATE outbox
+SET sent_at = $2
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    last_error = $2,
+    available_at = $3
+WHERE id = $1;
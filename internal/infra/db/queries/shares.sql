@@ -0,0 +1,48 @@
+-- name: UpsertShare :one
+-- Sharing the todo or list again with the same grantee changes the level of the existing share.
+INSERT INTO shares (
+    id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $7, $8, $9
+)
+ON CONFLICT (todo_id, list_id, user_id, workspace_id) DO UPDATE SET
+    level = EXCLUDED.level,
+    granted_by = EXCLUDED.granted_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id;
+
+-- name: ListShares :many
+SELECT id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id
+FROM shares
+WHERE todo_id = $1
+ORDER BY created_at, id;
+
+-- name: DeleteShare :one
+DELETE FROM shares
+WHERE todo_id = $1 AND id = $2
+RETURNING id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id;
+
+-- name: GetTodoShareLevels :one
+-- Looks up a todo shared with the user, on its own or with its list, directly or through one of the workspaces they
+-- are a member of, together with the workspace it belongs to and the levels of all shares reaching the user.
+SELECT todo_items.workspace_id, array_agg(shares.level)::text[] AS levels
+FROM todo_items
+JOIN shares ON shares.todo_id = todo_items.id OR shares.list_id = todo_items.list_id
+WHERE todo_items.id = $1
+  AND todo_items.deleted_at IS NULL
+  AND (
+      shares.user_id = $2
+      OR shares.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE workspace_members.user_id = $2)
+  )
+GROUP BY todo_items.workspace_id;
+
+-- name: ListListShares :many
+SELECT id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id
+FROM shares
+WHERE list_id = $1
+ORDER BY created_at, id;
+
+-- name: DeleteListShare :one
+DELETE FROM shares
+WHERE list_id = $1 AND id = $2
+RETURNING id, todo_id, user_id, workspace_id, level, granted_by, created_at, updated_at, owner_workspace_id, list_id;
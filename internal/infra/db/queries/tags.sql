@@ -0,0 +1,54 @@
+-- name: CreateTag :exec
+INSERT INTO tags (
+    id, name, colour, created_at, updated_at, workspace_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: GetTag :one
+SELECT id, name, colour, created_at, updated_at
+FROM tags
+WHERE id = $1 AND workspace_id = $2;
+
+-- name: ListTags :many
+SELECT id, name, colour, created_at, updated_at
+FROM tags
+WHERE workspace_id = $1
+ORDER BY name;
+
+-- name: UpdateTag :one
+UPDATE tags
+SET name = $2,
+    colour = $3,
+    updated_at = $4
+WHERE id = $1 AND workspace_id = $5
+RETURNING id, name, colour, created_at, updated_at;
+
+-- name: DeleteTag :execrows
+DELETE FROM tags
+WHERE id = $1 AND workspace_id = $2;
+
+-- name: UpsertTag :one
+INSERT INTO tags (
+    id, name, created_at, updated_at, workspace_id
+) VALUES (
+    $1, $2, $3, $3, $4
+)
+ON CONFLICT (workspace_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, name, colour, created_at, updated_at;
+
+-- name: AddTodoTag :exec
+INSERT INTO todo_tags (todo_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTodoTags :exec
+DELETE FROM todo_tags
+WHERE todo_id = $1;
+
+-- name: ListTagsByTodoIDs :many
+SELECT todo_tags.todo_id, tags.id, tags.name, tags.colour, tags.created_at, tags.updated_at
+FROM todo_tags
+JOIN tags ON tags.id = todo_tags.tag_id
+WHERE todo_tags.todo_id = ANY(sqlc.arg('todo_ids')::uuid[])
+ORDER BY todo_tags.todo_id, tags.name;
@@ -1,6 +1,287 @@
 -- name: CreateTodo :exec
 INSERT INTO todo_items (
-    id, description, due_date, file_id, created_at, updated_at
+    id, description, due_date, created_at, updated_at, timezone, priority, rank, recurrence, recurs_from, parent_id, owner_id, workspace_id, list_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 ) RETURNING id;
+
+-- name: GetTodo :one
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL;
+
+-- name: GetSubtreeHeight :one
+-- Counts the levels of the todo and its subtasks, trashed subtasks included since they can be restored.
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id, 1 AS depth
+    FROM todo_items
+    WHERE todo_items.id = $1
+    UNION ALL
+    SELECT todo_items.id, subtree.depth + 1
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+)
+SELECT max(depth)::int AS height
+FROM subtree;
+
+-- name: HasNextOccurrence :one
+-- Trashed occurrences count too, a todo deleted on purpose doesn't come back when its predecessor is completed again.
+SELECT EXISTS (SELECT 1 FROM todo_items WHERE recurs_from = $1);
+
+-- name: ListSubtasks :many
+-- Returns the subtasks of the todo at every depth in the manual order, callers nest them by their parent IDs.
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.parent_id = $1 AND todo_items.deleted_at IS NULL
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at IS NULL
+)
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id IN (SELECT id FROM subtree)
+ORDER BY rank;
+
+-- name: ListTodoAncestorIDs :many
+-- Returns the todo and the todos above it up to the top-level one.
+WITH RECURSIVE ancestors AS (
+    SELECT todo_items.id, todo_items.parent_id
+    FROM todo_items
+    WHERE todo_items.id = $1
+    UNION
+    SELECT todo_items.id, todo_items.parent_id
+    FROM todo_items
+    JOIN ancestors ON todo_items.id = ancestors.parent_id
+)
+SELECT id
+FROM ancestors;
+
+-- name: ListTodoProgress :many
+-- Counts the direct subtasks and the checklist items of each todo, cancelled subtasks don't count.
+SELECT todo_items.id AS todo_id,
+       (SELECT count(*) FROM todo_items AS subtasks
+        WHERE subtasks.parent_id = todo_items.id AND subtasks.deleted_at IS NULL AND subtasks.status = 'done')
+     + (SELECT count(*) FROM checklist_items
+        WHERE checklist_items.todo_id = todo_items.id AND checklist_items.done) AS done,
+       (SELECT count(*) FROM todo_items AS subtasks
+        WHERE subtasks.parent_id = todo_items.id AND subtasks.deleted_at IS NULL AND subtasks.status <> 'cancelled')
+     + (SELECT count(*) FROM checklist_items
+        WHERE checklist_items.todo_id = todo_items.id) AS total
+FROM todo_items
+WHERE todo_items.id = ANY(sqlc.arg('todo_ids')::uuid[]);
+
+-- name: ListTodos :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id') AND deleted_at IS NULL
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY(sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('overdue_at')::timestamptz IS NULL
+   OR (due_date < sqlc.narg('overdue_at')::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND (sqlc.narg('has_attachment')::boolean IS NULL
+   OR sqlc.narg('has_attachment')::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND (sqlc.narg('search')::text IS NULL OR search_vector @@ websearch_to_tsquery('english', sqlc.narg('search')::text))
+  AND (sqlc.narg('list_id')::uuid IS NULL OR list_id = sqlc.narg('list_id')::uuid)
+  AND (sqlc.narg('tags')::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY(sqlc.narg('tags')::text[]))
+      >= CASE WHEN sqlc.arg('match_all_tags')::boolean THEN cardinality(sqlc.narg('tags')::text[]) ELSE 1 END)
+  AND (sqlc.narg('cursor_key')::timestamptz IS NULL
+   OR (created_at, id) < (sqlc.narg('cursor_key')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListTodosByIDs :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id = ANY(sqlc.arg('ids')::uuid[]) AND workspace_id = sqlc.arg('workspace_id') AND deleted_at IS NULL
+ORDER BY rank;
+
+-- name: ListTodosByDueDate :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id') AND deleted_at IS NULL
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY(sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('overdue_at')::timestamptz IS NULL
+   OR (due_date < sqlc.narg('overdue_at')::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND (sqlc.narg('has_attachment')::boolean IS NULL
+   OR sqlc.narg('has_attachment')::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND (sqlc.narg('search')::text IS NULL OR search_vector @@ websearch_to_tsquery('english', sqlc.narg('search')::text))
+  AND (sqlc.narg('list_id')::uuid IS NULL OR list_id = sqlc.narg('list_id')::uuid)
+  AND (sqlc.narg('tags')::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY(sqlc.narg('tags')::text[]))
+      >= CASE WHEN sqlc.arg('match_all_tags')::boolean THEN cardinality(sqlc.narg('tags')::text[]) ELSE 1 END)
+  AND (sqlc.narg('cursor_key')::timestamptz IS NULL
+   OR (due_date, id) > (sqlc.narg('cursor_key')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY due_date, id
+LIMIT sqlc.arg('page_size');
+
+-- name: ListTodosByPriority :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id') AND deleted_at IS NULL
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY(sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('overdue_at')::timestamptz IS NULL
+   OR (due_date < sqlc.narg('overdue_at')::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND (sqlc.narg('has_attachment')::boolean IS NULL
+   OR sqlc.narg('has_attachment')::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND (sqlc.narg('search')::text IS NULL OR search_vector @@ websearch_to_tsquery('english', sqlc.narg('search')::text))
+  AND (sqlc.narg('list_id')::uuid IS NULL OR list_id = sqlc.narg('list_id')::uuid)
+  AND (sqlc.narg('tags')::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY(sqlc.narg('tags')::text[]))
+      >= CASE WHEN sqlc.arg('match_all_tags')::boolean THEN cardinality(sqlc.narg('tags')::text[]) ELSE 1 END)
+  AND (sqlc.narg('cursor_priority')::text IS NULL
+   OR (priority, rank, id) > (sqlc.narg('cursor_priority')::text, sqlc.narg('cursor_rank')::text, sqlc.narg('cursor_id')::uuid))
+ORDER BY priority, rank, id
+LIMIT sqlc.arg('page_size');
+
+-- name: UpdateTodo :one
+UPDATE todo_items
+SET description = $2,
+    due_date = $3,
+    updated_at = $4,
+    timezone = $5,
+    priority = $6,
+    recurrence = $7,
+    parent_id = $8,
+    list_id = $9,
+    version = version + 1
+WHERE id = $1 AND version = sqlc.arg('expected_version') AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id;
+
+-- name: MoveSubtasksToList :exec
+-- Subtasks at every depth follow the todo to its new list.
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.parent_id = $1
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+)
+UPDATE todo_items
+SET list_id = $2,
+    updated_at = $3,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree);
+
+-- name: SoftDeleteTodo :execrows
+-- Subtasks go to the trash together with the todo, at the same time so RestoreSubtasks can tell them apart.
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.id = $1 AND todo_items.workspace_id = sqlc.arg('workspace_id') AND todo_items.deleted_at IS NULL
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at IS NULL
+)
+UPDATE todo_items
+SET deleted_at = $2,
+    updated_at = $2,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree);
+
+-- name: RestoreSubtasks :exec
+-- Restores the subtasks that went to the trash together with the todo, those trashed before it stay there.
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id, todo_items.deleted_at
+    FROM todo_items
+    WHERE todo_items.id = $1 AND todo_items.workspace_id = sqlc.arg('workspace_id') AND todo_items.deleted_at IS NOT NULL
+    UNION
+    SELECT todo_items.id, todo_items.deleted_at
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at = subtree.deleted_at
+)
+UPDATE todo_items
+SET deleted_at = NULL,
+    updated_at = $2,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree) AND id <> $1;
+
+-- name: RestoreTodo :one
+-- A subtask restored while its parent is still in the trash becomes a top-level todo.
+UPDATE todo_items
+SET deleted_at = NULL,
+    parent_id = CASE
+        WHEN EXISTS (SELECT 1 FROM todo_items AS parents WHERE parents.id = todo_items.parent_id AND parents.deleted_at IS NULL)
+        THEN todo_items.parent_id
+    END,
+    updated_at = $2,
+    version = version + 1
+WHERE id = $1 AND workspace_id = sqlc.arg('workspace_id') AND deleted_at IS NOT NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id;
+
+-- name: ListPurgeableTodos :many
+-- Pages through the trash oldest first, the cursor moves past the todos that failed to purge in the same run.
+SELECT id, workspace_id, deleted_at
+FROM todo_items
+WHERE deleted_at < sqlc.arg('deleted_before')
+  AND (sqlc.narg('cursor_deleted_at')::timestamptz IS NULL
+   OR (deleted_at, id) > (sqlc.narg('cursor_deleted_at')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY deleted_at, id
+LIMIT sqlc.arg('batch_size');
+
+-- name: PurgeTodo :exec
+DELETE FROM todo_items
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: UpdateTodoStatus :one
+UPDATE todo_items
+SET status = $2,
+    completed_at = $3,
+    updated_at = $4,
+    version = version + 1
+WHERE id = $1 AND version = sqlc.arg('expected_version') AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id;
+
+
+-- name: UpdateTodoRank :one
+UPDATE todo_items
+SET rank = $2,
+    updated_at = $3,
+    version = version + 1
+WHERE id = $1 AND version = sqlc.arg('expected_version') AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id;
+
+-- name: LockTodoHierarchy :exec
+-- Serializes the transactions changing parents so concurrent moves can't nest todos in a cycle or too deeply.
+SELECT pg_advisory_xact_lock(hashtext('todo_items.parent_id'));
+
+-- name: LockTodoRanks :exec
+-- Serializes the transactions handing out ranks in a workspace so no two of its todos get the same one.
+SELECT pg_advisory_xact_lock(hashtext('todo_items.rank'), hashtext(sqlc.arg('workspace_id')::uuid::text));
+
+-- name: GetLastTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id')
+ORDER BY rank DESC
+LIMIT 1;
+
+-- name: GetPreviousTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id') AND rank < sqlc.arg('rank') AND id <> sqlc.arg('exclude_id')
+ORDER BY rank DESC
+LIMIT 1;
+
+-- name: GetNextTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = sqlc.arg('workspace_id') AND rank > sqlc.arg('rank') AND id <> sqlc.arg('exclude_id')
+ORDER BY rank
+LIMIT 1;
\ No newline at end of file
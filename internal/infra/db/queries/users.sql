@@ -0,0 +1,14 @@
+-- name: GetUserBySubject :one
+SELECT id, subject, email, name, created_at, updated_at
+FROM users
+WHERE subject = $1;
+
+-- name: UpsertUser :one
+-- Creates the user on their first sign-in, afterwards it updates the email and the name the user's tokens carry.
+INSERT INTO users (
+    id, subject, email, name, created_at, updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $5
+)
+ON CONFLICT (subject) DO UPDATE SET email = EXCLUDED.email, name = EXCLUDED.name, updated_at = EXCLUDED.updated_at
+RETURNING id, subject, email, name, created_at, updated_at;
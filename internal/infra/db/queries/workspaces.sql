@@ -0,0 +1,59 @@
+-- name: CreateWorkspace :exec
+INSERT INTO workspaces (
+    id, name, created_at, updated_at
+) VALUES (
+    $1, $2, $3, $3
+);
+
+-- name: ListWorkspacesByUser :many
+SELECT workspaces.id, workspaces.name, workspaces.created_at, workspaces.updated_at, workspace_members.role
+FROM workspaces
+JOIN workspace_members ON workspace_members.workspace_id = workspaces.id
+WHERE workspace_members.user_id = $1
+ORDER BY workspace_members.created_at, workspaces.id;
+
+-- name: LockWorkspace :exec
+-- Serializes the transactions changing the members of the workspace so they can't remove its last owner together.
+SELECT id FROM workspaces WHERE id = $1 FOR UPDATE;
+
+-- name: UpsertWorkspaceMember :exec
+INSERT INTO workspace_members (
+    workspace_id, user_id, role, created_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role;
+
+-- name: GetWorkspaceMember :one
+SELECT workspace_id, user_id, role, created_at
+FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2;
+
+-- name: GetDefaultWorkspaceMember :one
+-- The workspace requests go to when they don't name one is the first one the user joined.
+SELECT workspace_id, user_id, role, created_at
+FROM workspace_members
+WHERE user_id = $1
+ORDER BY created_at, workspace_id
+LIMIT 1;
+
+-- name: ListWorkspaceMembers :many
+SELECT workspace_members.workspace_id, workspace_members.user_id, workspace_members.role, workspace_members.created_at, users.email, users.name
+FROM workspace_members
+JOIN users ON users.id = workspace_members.user_id
+WHERE workspace_members.workspace_id = $1
+ORDER BY workspace_members.created_at, workspace_members.user_id;
+
+-- name: CountWorkspaceOwners :one
+SELECT count(*)
+FROM workspace_members
+WHERE workspace_id = $1 AND role = 'owner';
+
+-- name: CountWorkspaceMemberships :one
+SELECT count(*)
+FROM workspace_members
+WHERE user_id = $1;
+
+-- name: DeleteWorkspaceMember :execrows
+DELETE FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2;
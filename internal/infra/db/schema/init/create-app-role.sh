@@ -0,0 +1,9 @@
+#!/bin/sh
+# Creates the role the application connects as when the database is first initialised. The role is neither a
+# superuser nor allowed to bypass row-level security, so the workspace policies apply to it; the migrations grant it
+# access to the tables.
+set -e
+
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-EOSQL
+	CREATE ROLE todo_app LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE PASSWORD '$APP_DB_PASSWORD';
+EOSQL
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_todo_items_created_at_id;
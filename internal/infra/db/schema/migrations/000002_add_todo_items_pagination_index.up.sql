@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_todo_items_created_at_id ON todo_items (created_at DESC, id DESC); -- Keyset pagination
@@ -0,0 +1 @@
+ALTER TABLE todo_items ADD COLUMN version INTEGER NOT NULL DEFAULT 1; -- Optimistic concurrency token, bumped on every update
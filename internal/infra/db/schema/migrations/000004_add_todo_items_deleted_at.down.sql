@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todo_items_deleted_at;
+
+ALTER TABLE todo_items DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE todo_items ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL; -- Set when the todo is moved to the trash
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_deleted_at ON todo_items (deleted_at) WHERE deleted_at IS NOT NULL; -- Trash purge
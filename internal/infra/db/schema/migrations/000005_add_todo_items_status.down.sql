@@ -0,0 +1,3 @@
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS status;
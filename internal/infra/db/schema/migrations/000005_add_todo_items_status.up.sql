@@ -0,0 +1,4 @@
+ALTER TABLE todo_items
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'in_progress', 'blocked', 'done', 'cancelled')), -- Lifecycle state
+    ADD COLUMN completed_at TIMESTAMP DEFAULT NULL;                              -- Set while the todo is done
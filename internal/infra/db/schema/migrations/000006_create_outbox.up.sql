@@ -0,0 +1,12 @@
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,                     -- Preserves insertion order
+    event_type TEXT NOT NULL,                     -- e.g. todo.created
+    payload JSONB NOT NULL,                       -- Message body published as is
+    attempts INTEGER NOT NULL DEFAULT 0,          -- Failed publish attempts so far
+    last_error TEXT DEFAULT NULL,                 -- Error of the last failed attempt
+    available_at TIMESTAMP NOT NULL DEFAULT now(),-- Not published before this time (backoff)
+    created_at TIMESTAMP NOT NULL DEFAULT now(),  -- Creation timestamp
+    sent_at TIMESTAMP DEFAULT NULL                -- Set once published
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (available_at, id) WHERE sent_at IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_todo_items_file_id;
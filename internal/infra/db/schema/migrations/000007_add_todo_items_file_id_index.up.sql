@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_todo_items_file_id ON todo_items (file_id) WHERE file_id <> ''; -- File reconciliation
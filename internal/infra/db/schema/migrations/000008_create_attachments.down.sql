@@ -0,0 +1,15 @@
+ALTER TABLE todo_items ADD COLUMN IF NOT EXISTS file_id TEXT DEFAULT NULL;
+
+-- Only a single file per todo can be kept, the earliest one wins
+UPDATE todo_items t
+SET file_id = a.storage_key
+FROM (
+    SELECT DISTINCT ON (todo_id) todo_id, storage_key
+    FROM attachments
+    ORDER BY todo_id, uploaded_at, id
+) a
+WHERE a.todo_id = t.id;
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_file_id ON todo_items (file_id) WHERE file_id <> '';
+
+DROP TABLE IF EXISTS attachments;
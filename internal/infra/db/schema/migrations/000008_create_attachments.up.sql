@@ -0,0 +1,21 @@
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY,                          -- Used in attachment URLs
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,             -- Key of the S3 object
+    filename TEXT NOT NULL,                       -- Original name of the uploaded file
+    content_type TEXT NOT NULL,                   -- MIME type of the file
+    size BIGINT NOT NULL,                         -- Size in bytes
+    checksum TEXT NOT NULL,                       -- Hex encoded SHA-256 of the content
+    uploaded_at TIMESTAMP NOT NULL DEFAULT now()  -- Upload timestamp
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_todo_id ON attachments (todo_id, uploaded_at, id);
+
+-- Existing files keep their ID, the last segment of todos/<todo id>/<file id>, so download links stay valid
+INSERT INTO attachments (id, todo_id, storage_key, filename, content_type, size, checksum, uploaded_at)
+SELECT split_part(file_id, '/', 3)::uuid, id, file_id, split_part(file_id, '/', 3), 'application/octet-stream', 0, '', updated_at
+FROM todo_items
+WHERE file_id IS NOT NULL AND file_id <> '';
+
+DROP INDEX IF EXISTS idx_todo_items_file_id;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS file_id;
@@ -0,0 +1,8 @@
+-- Timestamps were written as UTC wall clock time, so they are interpreted as UTC when the zone is added
+ALTER TABLE todo_items
+    ALTER COLUMN due_date TYPE TIMESTAMPTZ USING due_date AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN deleted_at TYPE TIMESTAMPTZ USING deleted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN completed_at TYPE TIMESTAMPTZ USING completed_at AT TIME ZONE 'UTC',
+    ADD COLUMN timezone TEXT;  -- IANA name of the todo's zone, e.g. Europe/Berlin, NULL when it has none
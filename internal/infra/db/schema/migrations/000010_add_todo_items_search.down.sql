@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todo_items_due_date_id;
+DROP INDEX IF EXISTS idx_todo_items_search_vector;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS search_vector;
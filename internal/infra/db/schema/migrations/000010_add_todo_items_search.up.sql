@@ -0,0 +1,6 @@
+ALTER TABLE todo_items
+    ADD COLUMN search_vector TSVECTOR
+        GENERATED ALWAYS AS (to_tsvector('english', description)) STORED; -- Full-text search over the description
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_search_vector ON todo_items USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_todo_items_due_date_id ON todo_items (due_date, id); -- Keyset pagination by due date
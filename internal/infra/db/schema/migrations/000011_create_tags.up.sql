@@ -0,0 +1,15 @@
+CREATE TABLE tags (
+    id UUID PRIMARY KEY,                            -- Used in tag URLs
+    name TEXT NOT NULL UNIQUE,                      -- Lower case label, e.g. work
+    colour TEXT NOT NULL DEFAULT '',                -- Hex colour such as #ff8800, empty for none
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),  -- Creation timestamp
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()   -- Last modification timestamp
+);
+
+CREATE TABLE todo_tags (
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id, todo_id); -- Filtering todos by tag
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todo_items_priority_rank_id;
+DROP INDEX IF EXISTS idx_todo_items_rank;
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS rank,
+    DROP COLUMN IF EXISTS priority;
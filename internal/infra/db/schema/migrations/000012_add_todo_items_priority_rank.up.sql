@@ -0,0 +1,16 @@
+ALTER TABLE todo_items
+    ADD COLUMN priority TEXT NOT NULL DEFAULT 'P2'
+        CHECK (priority IN ('P0', 'P1', 'P2', 'P3')), -- P0 is the most urgent
+    ADD COLUMN rank TEXT COLLATE "C" NOT NULL DEFAULT ''; -- Manual position, compared byte by byte
+
+-- existing todos keep the order they were created in, the ranks follow the format of domain.RankBetween
+UPDATE todo_items
+SET rank = ranked.rank
+FROM (SELECT id, 'h' || lpad((row_number() OVER (ORDER BY created_at, id))::text, 8, '0') AS rank
+      FROM todo_items) AS ranked
+WHERE todo_items.id = ranked.id;
+
+ALTER TABLE todo_items ALTER COLUMN rank DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_rank ON todo_items (rank);
+CREATE INDEX IF NOT EXISTS idx_todo_items_priority_rank_id ON todo_items (priority, rank, id); -- Keyset pagination by priority
@@ -0,0 +1,8 @@
+-- fails while carried over attachments share a file, purge or detach them first
+DROP INDEX IF EXISTS idx_attachments_storage_key;
+ALTER TABLE attachments ADD CONSTRAINT attachments_storage_key_key UNIQUE (storage_key);
+
+DROP INDEX IF EXISTS idx_todo_items_recurs_from;
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS recurs_from,
+    DROP COLUMN IF EXISTS recurrence;
@@ -0,0 +1,10 @@
+ALTER TABLE todo_items
+    ADD COLUMN recurrence TEXT, -- RFC 5545 RRULE, it starts at the due date
+    ADD COLUMN recurs_from UUID REFERENCES todo_items (id) ON DELETE SET NULL; -- The completed todo this one follows
+
+-- a completed todo is followed by at most one occurrence, even when it is reopened and completed again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_items_recurs_from ON todo_items (recurs_from);
+
+-- the attachments carried over to the next occurrence refer to the files of the completed todo
+ALTER TABLE attachments DROP CONSTRAINT IF EXISTS attachments_storage_key_key;
+CREATE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments (storage_key);
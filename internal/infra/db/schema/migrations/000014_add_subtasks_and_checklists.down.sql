@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS checklist_items;
+DROP INDEX IF EXISTS idx_todo_items_parent_id;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS parent_id;
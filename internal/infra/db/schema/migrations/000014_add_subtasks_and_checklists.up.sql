@@ -0,0 +1,15 @@
+ALTER TABLE todo_items
+    ADD COLUMN parent_id UUID REFERENCES todo_items (id) ON DELETE SET NULL; -- The todo a subtask belongs to
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_parent_id ON todo_items (parent_id);
+
+CREATE TABLE checklist_items (
+    id UUID PRIMARY KEY,
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_checklist_items_todo_id ON checklist_items (todo_id, created_at, id);
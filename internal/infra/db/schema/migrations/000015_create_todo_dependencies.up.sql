@@ -0,0 +1,9 @@
+CREATE TABLE todo_dependencies (
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,     -- The todo that is blocked
+    blocker_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,  -- The todo it waits for
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id <> blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocker_id ON todo_dependencies (blocker_id, todo_id); -- Todos a blocker holds up
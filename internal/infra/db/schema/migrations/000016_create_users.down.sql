@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_todo_items_owner_id_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_todo_items_created_at_id ON todo_items (created_at DESC, id DESC);
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_owner_id_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+ALTER TABLE tags DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,26 @@
+CREATE TABLE users (
+    id UUID PRIMARY KEY,                            -- Owner of todos and tags
+    subject TEXT NOT NULL UNIQUE,                   -- The sub claim of the user's tokens
+    email TEXT NOT NULL DEFAULT '',                 -- The email claim, empty when the token has none
+    name TEXT NOT NULL DEFAULT '',                  -- The name claim, empty when the token has none
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),  -- First sign-in
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()   -- Last change of the email or the name
+);
+
+-- todos and tags created before authentication belong to a placeholder user until they are handed over
+INSERT INTO users (id, subject)
+SELECT gen_random_uuid(), 'migration:unowned'
+WHERE EXISTS (SELECT 1 FROM todo_items) OR EXISTS (SELECT 1 FROM tags);
+
+ALTER TABLE todo_items ADD COLUMN owner_id UUID REFERENCES users (id);
+UPDATE todo_items SET owner_id = (SELECT id FROM users WHERE subject = 'migration:unowned');
+ALTER TABLE todo_items ALTER COLUMN owner_id SET NOT NULL;
+
+ALTER TABLE tags ADD COLUMN owner_id UUID REFERENCES users (id);
+UPDATE tags SET owner_id = (SELECT id FROM users WHERE subject = 'migration:unowned');
+ALTER TABLE tags ALTER COLUMN owner_id SET NOT NULL;
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_owner_id_name_key UNIQUE (owner_id, name); -- Every user has their own tag names
+
+DROP INDEX IF EXISTS idx_todo_items_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_todo_items_owner_id_created_at_id ON todo_items (owner_id, created_at DESC, id DESC); -- Listing a user's todos
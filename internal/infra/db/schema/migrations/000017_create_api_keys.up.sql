@@ -0,0 +1,13 @@
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,                                              -- Used in the admin URLs
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,   -- The user requests made with the key act as
+    name TEXT NOT NULL,                                               -- What the key is for, e.g. release bot
+    prefix TEXT NOT NULL,                                             -- Start of the key, tells keys apart without revealing them
+    key_hash BYTEA NOT NULL UNIQUE,                                   -- SHA-256 of the key, the key itself is never stored
+    scopes TEXT[] NOT NULL,                                           -- What the key may do, e.g. todos:read
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),                    -- Creation timestamp
+    last_used_at TIMESTAMPTZ,                                         -- Last authenticated request, to the minute
+    revoked_at TIMESTAMPTZ                                            -- Set once the key no longer authenticates
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_created_at_id ON api_keys (created_at DESC, id DESC); -- Listing keys
@@ -0,0 +1,27 @@
+DROP POLICY IF EXISTS todo_dependencies_workspace_isolation ON todo_dependencies;
+ALTER TABLE todo_dependencies NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE todo_dependencies DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS todo_tags_workspace_isolation ON todo_tags;
+ALTER TABLE todo_tags NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE todo_tags DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS checklist_items_workspace_isolation ON checklist_items;
+ALTER TABLE checklist_items NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE checklist_items DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS attachments_workspace_isolation ON attachments;
+ALTER TABLE attachments NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE attachments DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS todo_items_workspace_isolation ON todo_items;
+ALTER TABLE todo_items NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE todo_items DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_todo_items_workspace_id_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_todo_items_owner_id_created_at_id ON todo_items (owner_id, created_at DESC, id DESC);
+
+ALTER TABLE todo_items DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
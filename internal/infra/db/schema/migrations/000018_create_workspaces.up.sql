@@ -0,0 +1,61 @@
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY,                            -- Tenant the todos belong to
+    name TEXT NOT NULL,                             -- Display name, e.g. the team's name
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),  -- Creation timestamp
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()   -- Last modification timestamp
+);
+
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')), -- Owners manage members, viewers only read
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),                     -- When the user joined
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id, created_at); -- Workspaces of a user
+
+-- every existing user gets a personal workspace holding their todos, it takes the user's ID
+INSERT INTO workspaces (id, name, created_at, updated_at)
+SELECT id, 'Personal', created_at, created_at FROM users;
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT id, id, 'owner', created_at FROM users;
+
+ALTER TABLE todo_items ADD COLUMN workspace_id UUID REFERENCES workspaces (id);
+UPDATE todo_items SET workspace_id = owner_id;
+ALTER TABLE todo_items ALTER COLUMN workspace_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_todo_items_owner_id_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_todo_items_workspace_id_created_at_id ON todo_items (workspace_id, created_at DESC, id DESC); -- Listing a workspace's todos
+
+-- Rows are only visible within the workspace set for the connection by every request, app.workspace_id, unless
+-- background jobs ask for all of them with app.all_workspaces. Superusers and roles with BYPASSRLS skip the
+-- policies, the application has to connect as another role for them to apply.
+ALTER TABLE todo_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY todo_items_workspace_isolation ON todo_items
+    USING (
+        current_setting('app.all_workspaces', true) = 'on'
+        OR workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid
+    );
+
+-- the rows hanging off a todo are visible together with it
+ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachments FORCE ROW LEVEL SECURITY;
+CREATE POLICY attachments_workspace_isolation ON attachments
+    USING (EXISTS (SELECT 1 FROM todo_items WHERE todo_items.id = attachments.todo_id));
+
+ALTER TABLE checklist_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE checklist_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY checklist_items_workspace_isolation ON checklist_items
+    USING (EXISTS (SELECT 1 FROM todo_items WHERE todo_items.id = checklist_items.todo_id));
+
+ALTER TABLE todo_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY todo_tags_workspace_isolation ON todo_tags
+    USING (EXISTS (SELECT 1 FROM todo_items WHERE todo_items.id = todo_tags.todo_id));
+
+ALTER TABLE todo_dependencies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_dependencies FORCE ROW LEVEL SECURITY;
+CREATE POLICY todo_dependencies_workspace_isolation ON todo_dependencies
+    USING (EXISTS (SELECT 1 FROM todo_items WHERE todo_items.id = todo_dependencies.todo_id));
@@ -0,0 +1,4 @@
+DROP POLICY IF EXISTS todo_items_shared_edit ON todo_items;
+DROP POLICY IF EXISTS todo_items_shared_read ON todo_items;
+
+DROP TABLE IF EXISTS shares;
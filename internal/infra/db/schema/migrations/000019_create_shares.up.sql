@@ -0,0 +1,46 @@
+CREATE TABLE shares (
+    id UUID PRIMARY KEY,                                                -- Used in the URL revoking the share
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE, -- The shared todo
+    user_id UUID REFERENCES users (id) ON DELETE CASCADE,               -- Shared with a single user, or
+    workspace_id UUID REFERENCES workspaces (id) ON DELETE CASCADE,     -- shared with every member of a workspace
+    level TEXT NOT NULL CHECK (level IN ('read', 'comment', 'edit')),   -- What the grantee may do with the todo
+    granted_by UUID REFERENCES users (id) ON DELETE SET NULL,           -- The user who last granted the share
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),                      -- Creation timestamp
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),                      -- Last change of the level
+    CHECK ((user_id IS NULL) <> (workspace_id IS NULL)),
+    UNIQUE NULLS NOT DISTINCT (todo_id, user_id, workspace_id)          -- One share per grantee, the unused column is NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_shares_user_id ON shares (user_id) WHERE user_id IS NOT NULL; -- Todos shared with a user
+CREATE INDEX IF NOT EXISTS idx_shares_workspace_id ON shares (workspace_id) WHERE workspace_id IS NOT NULL; -- Todos shared with a workspace
+
+-- A shared todo is visible to the user set for the connection by every request, app.user_id, at any level, and
+-- can be updated by them at the edit level. The rows hanging off the todo follow it through their own policies.
+CREATE POLICY todo_items_shared_read ON todo_items
+    FOR SELECT
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE shares.todo_id = todo_items.id
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
+
+CREATE POLICY todo_items_shared_edit ON todo_items
+    FOR UPDATE
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE shares.todo_id = todo_items.id
+          AND shares.level = 'edit'
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
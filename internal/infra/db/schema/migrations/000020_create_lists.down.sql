@@ -0,0 +1,7 @@
+DROP POLICY IF EXISTS lists_workspace_isolation ON lists;
+
+DROP INDEX IF EXISTS idx_todo_items_list_id_status;
+
+ALTER TABLE todo_items DROP COLUMN IF EXISTS list_id;
+
+DROP TABLE IF EXISTS lists;
@@ -0,0 +1,26 @@
+CREATE TABLE lists (
+    id UUID PRIMARY KEY,                                                     -- Used in list URLs
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE, -- Lists belong to a workspace like its todos
+    name TEXT NOT NULL,                                                      -- Display name, e.g. Home renovation
+    description TEXT NOT NULL DEFAULT '',                                    -- What the list is about, empty for none
+    colour TEXT NOT NULL DEFAULT '',                                         -- Hex colour such as #ff8800, empty for none
+    archived BOOLEAN NOT NULL DEFAULT false,                                 -- Archived lists take no new todos
+    default_sort TEXT NOT NULL DEFAULT 'createdAt',                          -- Order of the list's todos unless another is asked for
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),                           -- Creation timestamp
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),                           -- Last modification timestamp
+    CHECK (default_sort IN ('createdAt', 'dueDate', 'priority')),
+    UNIQUE (workspace_id, name)                                              -- Names are unique within a workspace
+);
+
+-- todos of a deleted list stay, without a list
+ALTER TABLE todo_items ADD COLUMN list_id UUID REFERENCES lists (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_list_id_status ON todo_items (list_id, status, due_date) WHERE deleted_at IS NULL; -- Counting and listing the todos of a list
+
+ALTER TABLE lists ENABLE ROW LEVEL SECURITY;
+ALTER TABLE lists FORCE ROW LEVEL SECURITY;
+CREATE POLICY lists_workspace_isolation ON lists
+    USING (
+        current_setting('app.all_workspaces', true) = 'on'
+        OR workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid
+    );
@@ -0,0 +1,7 @@
+-- the role is kept, it may have been created by the init script rather than by the migration
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM todo_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM todo_app;
+
+REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public FROM todo_app;
+REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public FROM todo_app;
+REVOKE USAGE ON SCHEMA public FROM todo_app;
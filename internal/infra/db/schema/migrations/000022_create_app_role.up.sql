@@ -0,0 +1,20 @@
+-- The application connects as todo_app, which is neither a superuser nor allowed to bypass row-level security, so
+-- the workspace policies apply to every query it runs. Migrations keep running as the owner of the tables. The role
+-- is created by the database's init script with a password; created here it has none and can't log in until one is
+-- set with ALTER ROLE.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'todo_app') THEN
+        CREATE ROLE todo_app LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO todo_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO todo_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO todo_app;
+REVOKE ALL ON schema_migrations FROM todo_app; -- Left to the migrations
+
+-- tables and sequences of later migrations are granted as they are created
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO todo_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO todo_app;
@@ -0,0 +1,21 @@
+DROP POLICY IF EXISTS tags_shared_read ON tags;
+DROP POLICY IF EXISTS tags_workspace_isolation ON tags;
+ALTER TABLE tags NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tags DISABLE ROW LEVEL SECURITY;
+
+-- tags go back to the first owner of their workspace
+ALTER TABLE tags ADD COLUMN owner_id UUID REFERENCES users (id);
+UPDATE tags SET owner_id = (
+    SELECT workspace_members.user_id
+    FROM workspace_members
+    WHERE workspace_members.workspace_id = tags.workspace_id AND workspace_members.role = 'owner'
+    ORDER BY workspace_members.created_at
+    LIMIT 1
+);
+ALTER TABLE tags ALTER COLUMN owner_id SET NOT NULL;
+DELETE FROM tags USING tags AS older -- A user's tags of the same name in several workspaces keep the oldest
+WHERE older.owner_id = tags.owner_id AND older.name = tags.name AND (older.created_at, older.id) < (tags.created_at, tags.id);
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_workspace_id_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_owner_id_name_key UNIQUE (owner_id, name);
+ALTER TABLE tags DROP COLUMN IF EXISTS workspace_id;
@@ -0,0 +1,86 @@
+-- Tags belong to a workspace like its todos, so its members share them. A tag is moved to every workspace it was
+-- used in; tags of the same name in a workspace are merged into the oldest one, which keeps its ID in one of them.
+-- Unused tags go to the first workspace their owner joined.
+SELECT set_config('app.all_workspaces', 'on', false); -- The table owner is subject to the policies as well
+
+ALTER TABLE tags ADD COLUMN workspace_id UUID REFERENCES workspaces (id) ON DELETE CASCADE;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_owner_id_name_key;
+
+CREATE TEMPORARY TABLE tag_workspaces AS
+SELECT DISTINCT todo_tags.tag_id, todo_items.workspace_id
+FROM todo_tags
+JOIN todo_items ON todo_items.id = todo_tags.todo_id;
+
+INSERT INTO tag_workspaces (tag_id, workspace_id)
+SELECT tags.id, (
+    SELECT workspace_members.workspace_id
+    FROM workspace_members
+    WHERE workspace_members.user_id = tags.owner_id
+    ORDER BY workspace_members.created_at
+    LIMIT 1
+)
+FROM tags
+WHERE NOT EXISTS (SELECT 1 FROM tag_workspaces WHERE tag_workspaces.tag_id = tags.id);
+
+CREATE TEMPORARY TABLE tag_merges AS
+SELECT tag_workspaces.tag_id, tag_workspaces.workspace_id,
+       first_value(tags.id) OVER (PARTITION BY tag_workspaces.workspace_id, tags.name ORDER BY tags.created_at, tags.id) AS kept_id
+FROM tag_workspaces
+JOIN tags ON tags.id = tag_workspaces.tag_id
+WHERE tag_workspaces.workspace_id IS NOT NULL;
+
+-- a kept tag used in several workspaces keeps its ID in one of them and is copied to the others
+CREATE TEMPORARY TABLE workspace_tags AS
+SELECT workspace_id, kept_id,
+       CASE WHEN row_number() OVER (PARTITION BY kept_id ORDER BY workspace_id) = 1 THEN kept_id ELSE gen_random_uuid() END AS id
+FROM (SELECT DISTINCT workspace_id, kept_id FROM tag_merges) AS kept;
+
+UPDATE tags
+SET workspace_id = workspace_tags.workspace_id
+FROM workspace_tags
+WHERE tags.id = workspace_tags.id;
+
+INSERT INTO tags (id, name, colour, created_at, updated_at, owner_id, workspace_id)
+SELECT workspace_tags.id, tags.name, tags.colour, tags.created_at, tags.updated_at, tags.owner_id, workspace_tags.workspace_id
+FROM workspace_tags
+JOIN tags ON tags.id = workspace_tags.kept_id
+WHERE workspace_tags.id <> workspace_tags.kept_id;
+
+INSERT INTO todo_tags (todo_id, tag_id)
+SELECT todo_tags.todo_id, workspace_tags.id
+FROM todo_tags
+JOIN todo_items ON todo_items.id = todo_tags.todo_id
+JOIN tag_merges ON tag_merges.tag_id = todo_tags.tag_id AND tag_merges.workspace_id = todo_items.workspace_id
+JOIN workspace_tags ON workspace_tags.workspace_id = tag_merges.workspace_id AND workspace_tags.kept_id = tag_merges.kept_id
+ON CONFLICT DO NOTHING;
+
+-- what is left are the merged tags and the kept ones on the todos of the workspaces they were copied to
+DELETE FROM todo_tags
+USING tags, todo_items
+WHERE tags.id = todo_tags.tag_id
+  AND todo_items.id = todo_tags.todo_id
+  AND tags.workspace_id IS DISTINCT FROM todo_items.workspace_id;
+DELETE FROM tags WHERE workspace_id IS NULL;
+
+DROP TABLE workspace_tags;
+DROP TABLE tag_merges;
+DROP TABLE tag_workspaces;
+
+ALTER TABLE tags ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE tags DROP COLUMN owner_id;
+ALTER TABLE tags ADD CONSTRAINT tags_workspace_id_name_key UNIQUE (workspace_id, name); -- Names are unique within a workspace
+
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY tags_workspace_isolation ON tags
+    USING (
+        current_setting('app.all_workspaces', true) = 'on'
+        OR workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid
+    );
+
+-- the tags of a todo shared with the user are shown with it, but can't be changed
+CREATE POLICY tags_shared_read ON tags
+    FOR SELECT
+    USING (EXISTS (SELECT 1 FROM todo_tags WHERE todo_tags.tag_id = tags.id));
+
+SELECT set_config('app.all_workspaces', '', false);
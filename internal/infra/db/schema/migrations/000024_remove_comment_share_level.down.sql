@@ -0,0 +1,2 @@
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_level_check;
+ALTER TABLE shares ADD CONSTRAINT shares_level_check CHECK (level IN ('read', 'comment', 'edit'));
@@ -0,0 +1,4 @@
+-- Todos have no comments, a comment share only allowed reading them
+UPDATE shares SET level = 'read' WHERE level = 'comment';
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_level_check;
+ALTER TABLE shares ADD CONSTRAINT shares_level_check CHECK (level IN ('read', 'edit'));
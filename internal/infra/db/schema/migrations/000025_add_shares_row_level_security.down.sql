@@ -0,0 +1,6 @@
+DROP POLICY IF EXISTS shares_grantee_read ON shares;
+DROP POLICY IF EXISTS shares_workspace_isolation ON shares;
+ALTER TABLE shares NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE shares DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE shares DROP COLUMN IF EXISTS owner_workspace_id;
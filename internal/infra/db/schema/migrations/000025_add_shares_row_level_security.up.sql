@@ -0,0 +1,27 @@
+SELECT set_config('app.all_workspaces', 'on', false); -- The table owner is subject to the policies as well
+
+-- The policies of todo_items look at shares, so the policies of shares can't look at todo_items
+ALTER TABLE shares ADD COLUMN owner_workspace_id UUID REFERENCES workspaces (id) ON DELETE CASCADE; -- The workspace of the shared todo, whose members manage the share
+UPDATE shares SET owner_workspace_id = todo_items.workspace_id FROM todo_items WHERE todo_items.id = shares.todo_id;
+ALTER TABLE shares ALTER COLUMN owner_workspace_id SET NOT NULL;
+
+-- shares are managed by the members of the shared todo's workspace and seen by their grantees
+ALTER TABLE shares ENABLE ROW LEVEL SECURITY;
+ALTER TABLE shares FORCE ROW LEVEL SECURITY;
+CREATE POLICY shares_workspace_isolation ON shares
+    USING (
+        current_setting('app.all_workspaces', true) = 'on'
+        OR owner_workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid
+    );
+
+CREATE POLICY shares_grantee_read ON shares
+    FOR SELECT
+    USING (
+        user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+        OR workspace_id IN (
+            SELECT workspace_id FROM workspace_members
+            WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+        )
+    );
+
+SELECT set_config('app.all_workspaces', '', false);
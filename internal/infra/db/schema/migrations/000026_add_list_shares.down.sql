@@ -0,0 +1,40 @@
+DROP POLICY IF EXISTS todo_items_shared_edit ON todo_items;
+CREATE POLICY todo_items_shared_edit ON todo_items
+    FOR UPDATE
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE shares.todo_id = todo_items.id
+          AND shares.level = 'edit'
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
+
+DROP POLICY IF EXISTS todo_items_shared_read ON todo_items;
+CREATE POLICY todo_items_shared_read ON todo_items
+    FOR SELECT
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE shares.todo_id = todo_items.id
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
+
+DROP INDEX IF EXISTS idx_shares_list_id;
+SELECT set_config('app.all_workspaces', 'on', false); -- The table owner is subject to the policies as well
+DELETE FROM shares WHERE list_id IS NOT NULL;
+SELECT set_config('app.all_workspaces', '', false);
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_todo_id_list_id_user_id_workspace_id_key;
+ALTER TABLE shares ADD CONSTRAINT shares_todo_id_user_id_workspace_id_key UNIQUE NULLS NOT DISTINCT (todo_id, user_id, workspace_id);
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_target_check;
+ALTER TABLE shares DROP COLUMN IF EXISTS list_id;
+ALTER TABLE shares ALTER COLUMN todo_id SET NOT NULL;
@@ -0,0 +1,41 @@
+-- A share gives access to a single todo or to every todo of a list
+ALTER TABLE shares ALTER COLUMN todo_id DROP NOT NULL;
+ALTER TABLE shares ADD COLUMN list_id UUID REFERENCES lists (id) ON DELETE CASCADE; -- The shared list, when todo_id is NULL
+ALTER TABLE shares ADD CONSTRAINT shares_target_check CHECK ((todo_id IS NULL) <> (list_id IS NULL));
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_todo_id_user_id_workspace_id_key;
+ALTER TABLE shares ADD CONSTRAINT shares_todo_id_list_id_user_id_workspace_id_key
+    UNIQUE NULLS NOT DISTINCT (todo_id, list_id, user_id, workspace_id); -- One share per target and grantee, the unused columns are NULL
+
+CREATE INDEX IF NOT EXISTS idx_shares_list_id ON shares (list_id) WHERE list_id IS NOT NULL; -- Todos reached through a shared list
+
+-- the todos of a shared list are visible and editable as if each of them was shared at the level of the list
+DROP POLICY IF EXISTS todo_items_shared_read ON todo_items;
+CREATE POLICY todo_items_shared_read ON todo_items
+    FOR SELECT
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE (shares.todo_id = todo_items.id OR shares.list_id = todo_items.list_id)
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
+
+DROP POLICY IF EXISTS todo_items_shared_edit ON todo_items;
+CREATE POLICY todo_items_shared_edit ON todo_items
+    FOR UPDATE
+    USING (EXISTS (
+        SELECT 1 FROM shares
+        WHERE (shares.todo_id = todo_items.id OR shares.list_id = todo_items.list_id)
+          AND shares.level = 'edit'
+          AND (
+              shares.user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              OR shares.workspace_id IN (
+                  SELECT workspace_id FROM workspace_members
+                  WHERE user_id = NULLIF(current_setting('app.user_id', true), '')::uuid
+              )
+          )
+    ));
@@ -0,0 +1,8 @@
+SELECT set_config('app.all_workspaces', 'on', false); -- The table owner is subject to the policies as well
+UPDATE shares SET level = 'read' WHERE level = 'comment';
+SELECT set_config('app.all_workspaces', '', false);
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_level_check;
+ALTER TABLE shares ADD CONSTRAINT shares_level_check CHECK (level IN ('read', 'edit'));
+
+DROP POLICY IF EXISTS comments_workspace_isolation ON comments;
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,19 @@
+CREATE TABLE comments (
+    id UUID PRIMARY KEY,                                                -- Identifies the comment
+    todo_id UUID NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE, -- The todo commented on
+    author_id UUID REFERENCES users (id) ON DELETE SET NULL,            -- The user who left the comment
+    text TEXT NOT NULL,                                                 -- The comment itself
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()                       -- When the comment was left
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_todo_id ON comments (todo_id, created_at, id); -- Comments of a todo, oldest first
+
+-- comments are visible together with their todo, shared todos included
+ALTER TABLE comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE comments FORCE ROW LEVEL SECURITY;
+CREATE POLICY comments_workspace_isolation ON comments
+    USING (EXISTS (SELECT 1 FROM todo_items WHERE todo_items.id = comments.todo_id));
+
+-- todos and lists can be shared for commenting again
+ALTER TABLE shares DROP CONSTRAINT IF EXISTS shares_level_check;
+ALTER TABLE shares ADD CONSTRAINT shares_level_check CHECK (level IN ('read', 'comment', 'edit'));
@@ -0,0 +1,2 @@
+-- ranks are handed out per workspace, the neighbours of a rank are looked up within the workspace
+CREATE INDEX IF NOT EXISTS idx_todo_items_workspace_rank ON todo_items (workspace_id, rank);
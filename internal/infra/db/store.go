@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store adds transaction support on top of the generated queries.
+type Store struct {
+	*Queries
+	pool *pgxpool.Pool
+}
+
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{Queries: New(pool), pool: pool}
+}
+
+// ExecTx runs fn inside a transaction. The transaction is committed when fn returns nil and rolled back otherwise.
+func (s *Store) ExecTx(ctx context.Context, fn func(q Querier) error) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// setWorkspace sets the session variables the row-level security policies of the todo tables check. Sessions
+// without a workspace see no todos at all, apart from the ones shared with the user.
+const setWorkspace = `SELECT set_config('app.workspace_id', $1, false), set_config('app.user_id', $2, false), set_config('app.all_workspaces', $3, false)`
+
+type allWorkspacesKey struct{}
+
+// WithAllWorkspaces returns a copy of ctx whose queries see the todos of every workspace, for the background jobs
+// that aren't made on behalf of a user.
+func WithAllWorkspaces(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allWorkspacesKey{}, true)
+}
+
+// ApplyWorkspace is meant as the BeforeAcquire hook of the pool. It limits the connection to the workspace of the
+// principal in ctx before a request gets to use it, so a query missing its workspace filter still can't reach the
+// todos of other workspaces, apart from those shared with the principal's user. The variables are set on every
+// acquire since connections are shared by all requests. A connection the variables can't be set on is destroyed
+// rather than handed out.
+func ApplyWorkspace(ctx context.Context, conn *pgx.Conn) bool {
+	principal, _ := domain.PrincipalFromContext(ctx)
+	allWorkspaces := "off"
+	if all, _ := ctx.Value(allWorkspacesKey{}).(bool); all {
+		allWorkspaces = "on"
+	}
+	_, err := conn.Exec(ctx, setWorkspace, principal.WorkspaceID, principal.UserID, allWorkspaces)
+	return err == nil
+}
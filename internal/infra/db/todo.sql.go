@@ -13,19 +13,27 @@ import (
 
 const createTodo = `-- name: CreateTodo :exec
 INSERT INTO todo_items (
-    id, description, due_date, file_id, created_at, updated_at
+    id, description, due_date, created_at, updated_at, timezone, priority, rank, recurrence, recurs_from, parent_id, owner_id, workspace_id, list_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 ) RETURNING id
 `
 
 type CreateTodoParams struct {
-	ID          pgtype.UUID      `json:"id"`
-	Description string           `json:"description"`
-	DueDate     pgtype.Timestamp `json:"dueDate"`
-	FileID      pgtype.Text      `json:"fileId"`
-	CreatedAt   pgtype.Timestamp `json:"createdAt"`
-	UpdatedAt   pgtype.Timestamp `json:"updatedAt"`
+	ID          pgtype.UUID        `json:"id"`
+	Description string             `json:"description"`
+	DueDate     pgtype.Timestamptz `json:"dueDate"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	Timezone    pgtype.Text        `json:"timezone"`
+	Priority    string             `json:"priority"`
+	Rank        string             `json:"rank"`
+	Recurrence  pgtype.Text        `json:"recurrence"`
+	RecursFrom  pgtype.UUID        `json:"recursFrom"`
+	ParentID    pgtype.UUID        `json:"parentId"`
+	OwnerID     pgtype.UUID        `json:"ownerId"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+	ListID      pgtype.UUID        `json:"listId"`
 }
 
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) error {
@@ -33,9 +41,956 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) error {
 		arg.ID,
 		arg.Description,
 		arg.DueDate,
-		arg.FileID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Timezone,
+		arg.Priority,
+		arg.Rank,
+		arg.Recurrence,
+		arg.RecursFrom,
+		arg.ParentID,
+		arg.OwnerID,
+		arg.WorkspaceID,
+		arg.ListID,
 	)
 	return err
 }
+
+const getLastTodoRank = `-- name: GetLastTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = $1
+ORDER BY rank DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastTodoRank(ctx context.Context, workspaceID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getLastTodoRank, workspaceID)
+	var rank string
+	err := row.Scan(&rank)
+	return rank, err
+}
+
+const getNextTodoRank = `-- name: GetNextTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = $1 AND rank > $2 AND id <> $3
+ORDER BY rank
+LIMIT 1
+`
+
+type GetNextTodoRankParams struct {
+	WorkspaceID pgtype.UUID `json:"workspaceId"`
+	Rank        string      `json:"rank"`
+	ExcludeID   pgtype.UUID `json:"excludeId"`
+}
+
+func (q *Queries) GetNextTodoRank(ctx context.Context, arg GetNextTodoRankParams) (string, error) {
+	row := q.db.QueryRow(ctx, getNextTodoRank, arg.WorkspaceID, arg.Rank, arg.ExcludeID)
+	var rank string
+	err := row.Scan(&rank)
+	return rank, err
+}
+
+const getPreviousTodoRank = `-- name: GetPreviousTodoRank :one
+SELECT rank
+FROM todo_items
+WHERE workspace_id = $1 AND rank < $2 AND id <> $3
+ORDER BY rank DESC
+LIMIT 1
+`
+
+type GetPreviousTodoRankParams struct {
+	WorkspaceID pgtype.UUID `json:"workspaceId"`
+	Rank        string      `json:"rank"`
+	ExcludeID   pgtype.UUID `json:"excludeId"`
+}
+
+func (q *Queries) GetPreviousTodoRank(ctx context.Context, arg GetPreviousTodoRankParams) (string, error) {
+	row := q.db.QueryRow(ctx, getPreviousTodoRank, arg.WorkspaceID, arg.Rank, arg.ExcludeID)
+	var rank string
+	err := row.Scan(&rank)
+	return rank, err
+}
+
+const getSubtreeHeight = `-- name: GetSubtreeHeight :one
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id, 1 AS depth
+    FROM todo_items
+    WHERE todo_items.id = $1
+    UNION ALL
+    SELECT todo_items.id, subtree.depth + 1
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+)
+SELECT max(depth)::int AS height
+FROM subtree
+`
+
+// Counts the levels of the todo and its subtasks, trashed subtasks included since they can be restored.
+func (q *Queries) GetSubtreeHeight(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getSubtreeHeight, id)
+	var height int32
+	err := row.Scan(&height)
+	return height, err
+}
+
+const getTodo = `-- name: GetTodo :one
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL
+`
+
+type GetTodoParams struct {
+	ID          pgtype.UUID `json:"id"`
+	WorkspaceID pgtype.UUID `json:"workspaceId"`
+}
+
+func (q *Queries) GetTodo(ctx context.Context, arg GetTodoParams) (TodoItem, error) {
+	row := q.db.QueryRow(ctx, getTodo, arg.ID, arg.WorkspaceID)
+	var i TodoItem
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Status,
+		&i.CompletedAt,
+		&i.Timezone,
+		&i.Priority,
+		&i.Rank,
+		&i.Recurrence,
+		&i.ParentID,
+		&i.ListID,
+	)
+	return i, err
+}
+
+const listPurgeableTodos = `-- name: ListPurgeableTodos :many
+SELECT id, workspace_id, deleted_at
+FROM todo_items
+WHERE deleted_at < $1
+  AND ($2::timestamptz IS NULL
+   OR (deleted_at, id) > ($2::timestamptz, $3::uuid))
+ORDER BY deleted_at, id
+LIMIT $4
+`
+
+type ListPurgeableTodosParams struct {
+	DeletedBefore   pgtype.Timestamptz `json:"deletedBefore"`
+	CursorDeletedAt pgtype.Timestamptz `json:"cursorDeletedAt"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	BatchSize       int32              `json:"batchSize"`
+}
+
+type ListPurgeableTodosRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
+}
+
+// Pages through the trash oldest first, the cursor moves past the todos that failed to purge in the same run.
+func (q *Queries) ListPurgeableTodos(ctx context.Context, arg ListPurgeableTodosParams) ([]ListPurgeableTodosRow, error) {
+	rows, err := q.db.Query(ctx, listPurgeableTodos,
+		arg.DeletedBefore,
+		arg.CursorDeletedAt,
+		arg.CursorID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPurgeableTodosRow{}
+	for rows.Next() {
+		var i ListPurgeableTodosRow
+		if err := rows.Scan(&i.ID, &i.WorkspaceID, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasNextOccurrence = `-- name: HasNextOccurrence :one
+SELECT EXISTS (SELECT 1 FROM todo_items WHERE recurs_from = $1)
+`
+
+// Trashed occurrences count too, a todo deleted on purpose doesn't come back when its predecessor is completed again.
+func (q *Queries) HasNextOccurrence(ctx context.Context, recursFrom pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasNextOccurrence, recursFrom)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listSubtasks = `-- name: ListSubtasks :many
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.parent_id = $1 AND todo_items.deleted_at IS NULL
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at IS NULL
+)
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id IN (SELECT id FROM subtree)
+ORDER BY rank
+`
+
+// Returns the subtasks of the todo at every depth in the manual order, callers nest them by their parent IDs.
+func (q *Queries) ListSubtasks(ctx context.Context, parentID pgtype.UUID) ([]TodoItem, error) {
+	rows, err := q.db.Query(ctx, listSubtasks, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoItem{}
+	for rows.Next() {
+		var i TodoItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Status,
+			&i.CompletedAt,
+			&i.Timezone,
+			&i.Priority,
+			&i.Rank,
+			&i.Recurrence,
+			&i.ParentID,
+			&i.ListID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoAncestorIDs = `-- name: ListTodoAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+    SELECT todo_items.id, todo_items.parent_id
+    FROM todo_items
+    WHERE todo_items.id = $1
+    UNION
+    SELECT todo_items.id, todo_items.parent_id
+    FROM todo_items
+    JOIN ancestors ON todo_items.id = ancestors.parent_id
+)
+SELECT id
+FROM ancestors
+`
+
+// Returns the todo and the todos above it up to the top-level one.
+func (q *Queries) ListTodoAncestorIDs(ctx context.Context, id pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listTodoAncestorIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoProgress = `-- name: ListTodoProgress :many
+SELECT todo_items.id AS todo_id,
+       (SELECT count(*) FROM todo_items AS subtasks
+        WHERE subtasks.parent_id = todo_items.id AND subtasks.deleted_at IS NULL AND subtasks.status = 'done')
+     + (SELECT count(*) FROM checklist_items
+        WHERE checklist_items.todo_id = todo_items.id AND checklist_items.done) AS done,
+       (SELECT count(*) FROM todo_items AS subtasks
+        WHERE subtasks.parent_id = todo_items.id AND subtasks.deleted_at IS NULL AND subtasks.status <> 'cancelled')
+     + (SELECT count(*) FROM checklist_items
+        WHERE checklist_items.todo_id = todo_items.id) AS total
+FROM todo_items
+WHERE todo_items.id = ANY($1::uuid[])
+`
+
+type ListTodoProgressRow struct {
+	TodoID pgtype.UUID `json:"todoId"`
+	Done   int32       `json:"done"`
+	Total  int32       `json:"total"`
+}
+
+// Counts the direct subtasks and the checklist items of each todo, cancelled subtasks don't count.
+func (q *Queries) ListTodoProgress(ctx context.Context, todoIds []pgtype.UUID) ([]ListTodoProgressRow, error) {
+	rows, err := q.db.Query(ctx, listTodoProgress, todoIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTodoProgressRow{}
+	for rows.Next() {
+		var i ListTodoProgressRow
+		if err := rows.Scan(&i.TodoID, &i.Done, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodos = `-- name: ListTodos :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = $1 AND deleted_at IS NULL
+  AND ($2::text[] IS NULL OR status = ANY($2::text[]))
+  AND ($3::timestamptz IS NULL OR due_date >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR due_date < $4::timestamptz)
+  AND ($5::timestamptz IS NULL
+   OR (due_date < $5::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND ($6::boolean IS NULL
+   OR $6::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND ($7::text IS NULL OR search_vector @@ websearch_to_tsquery('english', $7::text))
+  AND ($8::uuid IS NULL OR list_id = $8::uuid)
+  AND ($9::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY($9::text[]))
+      >= CASE WHEN $10::boolean THEN cardinality($9::text[]) ELSE 1 END)
+  AND ($11::timestamptz IS NULL
+   OR (created_at, id) < ($11::timestamptz, $12::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $13
+`
+
+type ListTodosParams struct {
+	WorkspaceID   pgtype.UUID        `json:"workspaceId"`
+	Statuses      []string           `json:"statuses"`
+	DueAfter      pgtype.Timestamptz `json:"dueAfter"`
+	DueBefore     pgtype.Timestamptz `json:"dueBefore"`
+	OverdueAt     pgtype.Timestamptz `json:"overdueAt"`
+	HasAttachment pgtype.Bool        `json:"hasAttachment"`
+	Search        pgtype.Text        `json:"search"`
+	ListID        pgtype.UUID        `json:"listId"`
+	Tags          []string           `json:"tags"`
+	MatchAllTags  bool               `json:"matchAllTags"`
+	CursorKey     pgtype.Timestamptz `json:"cursorKey"`
+	CursorID      pgtype.UUID        `json:"cursorId"`
+	PageSize      int32              `json:"pageSize"`
+}
+
+func (q *Queries) ListTodos(ctx context.Context, arg ListTodosParams) ([]TodoItem, error) {
+	rows, err := q.db.Query(ctx, listTodos,
+		arg.WorkspaceID,
+		arg.Statuses,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.OverdueAt,
+		arg.HasAttachment,
+		arg.Search,
+		arg.ListID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoItem{}
+	for rows.Next() {
+		var i TodoItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Status,
+			&i.CompletedAt,
+			&i.Timezone,
+			&i.Priority,
+			&i.Rank,
+			&i.Recurrence,
+			&i.ParentID,
+			&i.ListID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByIDs = `-- name: ListTodosByIDs :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE id = ANY($1::uuid[]) AND workspace_id = $2 AND deleted_at IS NULL
+ORDER BY rank
+`
+
+type ListTodosByIDsParams struct {
+	Ids         []pgtype.UUID `json:"ids"`
+	WorkspaceID pgtype.UUID   `json:"workspaceId"`
+}
+
+func (q *Queries) ListTodosByIDs(ctx context.Context, arg ListTodosByIDsParams) ([]TodoItem, error) {
+	rows, err := q.db.Query(ctx, listTodosByIDs, arg.Ids, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoItem{}
+	for rows.Next() {
+		var i TodoItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Status,
+			&i.CompletedAt,
+			&i.Timezone,
+			&i.Priority,
+			&i.Rank,
+			&i.Recurrence,
+			&i.ParentID,
+			&i.ListID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByDueDate = `-- name: ListTodosByDueDate :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = $1 AND deleted_at IS NULL
+  AND ($2::text[] IS NULL OR status = ANY($2::text[]))
+  AND ($3::timestamptz IS NULL OR due_date >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR due_date < $4::timestamptz)
+  AND ($5::timestamptz IS NULL
+   OR (due_date < $5::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND ($6::boolean IS NULL
+   OR $6::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND ($7::text IS NULL OR search_vector @@ websearch_to_tsquery('english', $7::text))
+  AND ($8::uuid IS NULL OR list_id = $8::uuid)
+  AND ($9::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY($9::text[]))
+      >= CASE WHEN $10::boolean THEN cardinality($9::text[]) ELSE 1 END)
+  AND ($11::timestamptz IS NULL
+   OR (due_date, id) > ($11::timestamptz, $12::uuid))
+ORDER BY due_date, id
+LIMIT $13
+`
+
+type ListTodosByDueDateParams struct {
+	WorkspaceID   pgtype.UUID        `json:"workspaceId"`
+	Statuses      []string           `json:"statuses"`
+	DueAfter      pgtype.Timestamptz `json:"dueAfter"`
+	DueBefore     pgtype.Timestamptz `json:"dueBefore"`
+	OverdueAt     pgtype.Timestamptz `json:"overdueAt"`
+	HasAttachment pgtype.Bool        `json:"hasAttachment"`
+	Search        pgtype.Text        `json:"search"`
+	ListID        pgtype.UUID        `json:"listId"`
+	Tags          []string           `json:"tags"`
+	MatchAllTags  bool               `json:"matchAllTags"`
+	CursorKey     pgtype.Timestamptz `json:"cursorKey"`
+	CursorID      pgtype.UUID        `json:"cursorId"`
+	PageSize      int32              `json:"pageSize"`
+}
+
+func (q *Queries) ListTodosByDueDate(ctx context.Context, arg ListTodosByDueDateParams) ([]TodoItem, error) {
+	rows, err := q.db.Query(ctx, listTodosByDueDate,
+		arg.WorkspaceID,
+		arg.Statuses,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.OverdueAt,
+		arg.HasAttachment,
+		arg.Search,
+		arg.ListID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoItem{}
+	for rows.Next() {
+		var i TodoItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Status,
+			&i.CompletedAt,
+			&i.Timezone,
+			&i.Priority,
+			&i.Rank,
+			&i.Recurrence,
+			&i.ParentID,
+			&i.ListID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByPriority = `-- name: ListTodosByPriority :many
+SELECT id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+FROM todo_items
+WHERE workspace_id = $1 AND deleted_at IS NULL
+  AND ($2::text[] IS NULL OR status = ANY($2::text[]))
+  AND ($3::timestamptz IS NULL OR due_date >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR due_date < $4::timestamptz)
+  AND ($5::timestamptz IS NULL
+   OR (due_date < $5::timestamptz AND status NOT IN ('done', 'cancelled')))
+  AND ($6::boolean IS NULL
+   OR $6::boolean = EXISTS (SELECT 1 FROM attachments WHERE attachments.todo_id = todo_items.id))
+  AND ($7::text IS NULL OR search_vector @@ websearch_to_tsquery('english', $7::text))
+  AND ($8::uuid IS NULL OR list_id = $8::uuid)
+  AND ($9::text[] IS NULL
+   OR (SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+       WHERE todo_tags.todo_id = todo_items.id AND tags.name = ANY($9::text[]))
+      >= CASE WHEN $10::boolean THEN cardinality($9::text[]) ELSE 1 END)
+  AND ($11::text IS NULL
+   OR (priority, rank, id) > ($11::text, $12::text, $13::uuid))
+ORDER BY priority, rank, id
+LIMIT $14
+`
+
+type ListTodosByPriorityParams struct {
+	WorkspaceID    pgtype.UUID        `json:"workspaceId"`
+	Statuses       []string           `json:"statuses"`
+	DueAfter       pgtype.Timestamptz `json:"dueAfter"`
+	DueBefore      pgtype.Timestamptz `json:"dueBefore"`
+	OverdueAt      pgtype.Timestamptz `json:"overdueAt"`
+	HasAttachment  pgtype.Bool        `json:"hasAttachment"`
+	Search         pgtype.Text        `json:"search"`
+	ListID         pgtype.UUID        `json:"listId"`
+	Tags           []string           `json:"tags"`
+	MatchAllTags   bool               `json:"matchAllTags"`
+	CursorPriority pgtype.Text        `json:"cursorPriority"`
+	CursorRank     pgtype.Text        `json:"cursorRank"`
+	CursorID       pgtype.UUID        `json:"cursorId"`
+	PageSize       int32              `json:"pageSize"`
+}
+
+func (q *Queries) ListTodosByPriority(ctx context.Context, arg ListTodosByPriorityParams) ([]TodoItem, error) {
+	rows, err := q.db.Query(ctx, listTodosByPriority,
+		arg.WorkspaceID,
+		arg.Statuses,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.OverdueAt,
+		arg.HasAttachment,
+		arg.Search,
+		arg.ListID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CursorPriority,
+		arg.CursorRank,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoItem{}
+	for rows.Next() {
+		var i TodoItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Status,
+			&i.CompletedAt,
+			&i.Timezone,
+			&i.Priority,
+			&i.Rank,
+			&i.Recurrence,
+			&i.ParentID,
+			&i.ListID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockTodoHierarchy = `-- name: LockTodoHierarchy :exec
+SELECT pg_advisory_xact_lock(hashtext('todo_items.parent_id'))
+`
+
+// Serializes the transactions changing parents so concurrent moves can't nest todos in a cycle or too deeply.
+func (q *Queries) LockTodoHierarchy(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockTodoHierarchy)
+	return err
+}
+
+const lockTodoRanks = `-- name: LockTodoRanks :exec
+SELECT pg_advisory_xact_lock(hashtext('todo_items.rank'), hashtext($1::uuid::text))
+`
+
+// Serializes the transactions handing out ranks in a workspace so no two of its todos get the same one.
+func (q *Queries) LockTodoRanks(ctx context.Context, workspaceID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockTodoRanks, workspaceID)
+	return err
+}
+
+const moveSubtasksToList = `-- name: MoveSubtasksToList :exec
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.parent_id = $1
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+)
+UPDATE todo_items
+SET list_id = $2,
+    updated_at = $3,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree)
+`
+
+type MoveSubtasksToListParams struct {
+	ParentID  pgtype.UUID        `json:"parentId"`
+	ListID    pgtype.UUID        `json:"listId"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+// Subtasks at every depth follow the todo to its new list.
+func (q *Queries) MoveSubtasksToList(ctx context.Context, arg MoveSubtasksToListParams) error {
+	_, err := q.db.Exec(ctx, moveSubtasksToList, arg.ParentID, arg.ListID, arg.UpdatedAt)
+	return err
+}
+
+const purgeTodo = `-- name: PurgeTodo :exec
+DELETE FROM todo_items
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeTodo(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, purgeTodo, id)
+	return err
+}
+
+const restoreSubtasks = `-- name: RestoreSubtasks :exec
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id, todo_items.deleted_at
+    FROM todo_items
+    WHERE todo_items.id = $1 AND todo_items.workspace_id = $3 AND todo_items.deleted_at IS NOT NULL
+    UNION
+    SELECT todo_items.id, todo_items.deleted_at
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at = subtree.deleted_at
+)
+UPDATE todo_items
+SET deleted_at = NULL,
+    updated_at = $2,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree) AND id <> $1
+`
+
+type RestoreSubtasksParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+}
+
+// Restores the subtasks that went to the trash together with the todo, those trashed before it stay there.
+func (q *Queries) RestoreSubtasks(ctx context.Context, arg RestoreSubtasksParams) error {
+	_, err := q.db.Exec(ctx, restoreSubtasks, arg.ID, arg.UpdatedAt, arg.WorkspaceID)
+	return err
+}
+
+const restoreTodo = `-- name: RestoreTodo :one
+UPDATE todo_items
+SET deleted_at = NULL,
+    parent_id = CASE
+        WHEN EXISTS (SELECT 1 FROM todo_items AS parents WHERE parents.id = todo_items.parent_id AND parents.deleted_at IS NULL)
+        THEN todo_items.parent_id
+    END,
+    updated_at = $2,
+    version = version + 1
+WHERE id = $1 AND workspace_id = $3 AND deleted_at IS NOT NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+`
+
+type RestoreTodoParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+}
+
+// A subtask restored while its parent is still in the trash becomes a top-level todo.
+func (q *Queries) RestoreTodo(ctx context.Context, arg RestoreTodoParams) (TodoItem, error) {
+	row := q.db.QueryRow(ctx, restoreTodo, arg.ID, arg.UpdatedAt, arg.WorkspaceID)
+	var i TodoItem
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Status,
+		&i.CompletedAt,
+		&i.Timezone,
+		&i.Priority,
+		&i.Rank,
+		&i.Recurrence,
+		&i.ParentID,
+		&i.ListID,
+	)
+	return i, err
+}
+
+const softDeleteTodo = `-- name: SoftDeleteTodo :execrows
+WITH RECURSIVE subtree AS (
+    SELECT todo_items.id
+    FROM todo_items
+    WHERE todo_items.id = $1 AND todo_items.workspace_id = $3 AND todo_items.deleted_at IS NULL
+    UNION
+    SELECT todo_items.id
+    FROM todo_items
+    JOIN subtree ON todo_items.parent_id = subtree.id
+    WHERE todo_items.deleted_at IS NULL
+)
+UPDATE todo_items
+SET deleted_at = $2,
+    updated_at = $2,
+    version = version + 1
+WHERE id IN (SELECT id FROM subtree)
+`
+
+type SoftDeleteTodoParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
+	WorkspaceID pgtype.UUID        `json:"workspaceId"`
+}
+
+// Subtasks go to the trash together with the todo, at the same time so RestoreSubtasks can tell them apart.
+func (q *Queries) SoftDeleteTodo(ctx context.Context, arg SoftDeleteTodoParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteTodo, arg.ID, arg.DeletedAt, arg.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateTodo = `-- name: UpdateTodo :one
+UPDATE todo_items
+SET description = $2,
+    due_date = $3,
+    updated_at = $4,
+    timezone = $5,
+    priority = $6,
+    recurrence = $7,
+    parent_id = $8,
+    list_id = $9,
+    version = version + 1
+WHERE id = $1 AND version = $10 AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+`
+
+type UpdateTodoParams struct {
+	ID              pgtype.UUID        `json:"id"`
+	Description     string             `json:"description"`
+	DueDate         pgtype.Timestamptz `json:"dueDate"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+	Timezone        pgtype.Text        `json:"timezone"`
+	Priority        string             `json:"priority"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	ParentID        pgtype.UUID        `json:"parentId"`
+	ListID          pgtype.UUID        `json:"listId"`
+	ExpectedVersion int32              `json:"expectedVersion"`
+}
+
+func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (TodoItem, error) {
+	row := q.db.QueryRow(ctx, updateTodo,
+		arg.ID,
+		arg.Description,
+		arg.DueDate,
+		arg.UpdatedAt,
+		arg.Timezone,
+		arg.Priority,
+		arg.Recurrence,
+		arg.ParentID,
+		arg.ListID,
+		arg.ExpectedVersion,
+	)
+	var i TodoItem
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Status,
+		&i.CompletedAt,
+		&i.Timezone,
+		&i.Priority,
+		&i.Rank,
+		&i.Recurrence,
+		&i.ParentID,
+		&i.ListID,
+	)
+	return i, err
+}
+
+const updateTodoRank = `-- name: UpdateTodoRank :one
+UPDATE todo_items
+SET rank = $2,
+    updated_at = $3,
+    version = version + 1
+WHERE id = $1 AND version = $4 AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+`
+
+type UpdateTodoRankParams struct {
+	ID              pgtype.UUID        `json:"id"`
+	Rank            string             `json:"rank"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+	ExpectedVersion int32              `json:"expectedVersion"`
+}
+
+func (q *Queries) UpdateTodoRank(ctx context.Context, arg UpdateTodoRankParams) (TodoItem, error) {
+	row := q.db.QueryRow(ctx, updateTodoRank,
+		arg.ID,
+		arg.Rank,
+		arg.UpdatedAt,
+		arg.ExpectedVersion,
+	)
+	var i TodoItem
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Status,
+		&i.CompletedAt,
+		&i.Timezone,
+		&i.Priority,
+		&i.Rank,
+		&i.Recurrence,
+		&i.ParentID,
+		&i.ListID,
+	)
+	return i, err
+}
+
+const updateTodoStatus = `-- name: UpdateTodoStatus :one
+UPDATE todo_items
+SET status = $2,
+    completed_at = $3,
+    updated_at = $4,
+    version = version + 1
+WHERE id = $1 AND version = $5 AND deleted_at IS NULL
+RETURNING id, description, due_date, created_at, updated_at, version, deleted_at, status, completed_at, timezone, priority, rank, recurrence, parent_id, list_id
+`
+
+type UpdateTodoStatusParams struct {
+	ID              pgtype.UUID        `json:"id"`
+	Status          string             `json:"status"`
+	CompletedAt     pgtype.Timestamptz `json:"completedAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+	ExpectedVersion int32              `json:"expectedVersion"`
+}
+
+func (q *Queries) UpdateTodoStatus(ctx context.Context, arg UpdateTodoStatusParams) (TodoItem, error) {
+	row := q.db.QueryRow(ctx, updateTodoStatus,
+		arg.ID,
+		arg.Status,
+		arg.CompletedAt,
+		arg.UpdatedAt,
+		arg.ExpectedVersion,
+	)
+	var i TodoItem
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Status,
+		&i.CompletedAt,
+		&i.Timezone,
+		&i.Priority,
+		&i.Rank,
+		&i.Recurrence,
+		&i.ParentID,
+		&i.ListID,
+	)
+	return i, err
+}
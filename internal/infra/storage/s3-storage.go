@@ -2,21 +2,29 @@
 package storage
 
 import (
-	"bytes"
 	"context"
+	"errors"
+	"io"
+	"time"
 
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 )
 
 type S3FileStorage struct {
-	client s3iface.S3API
-	bucket string
+	client        s3iface.S3API
+	uploader      s3manageriface.UploaderAPI
+	bucket        string
+	presignExpiry time.Duration
 }
 
-func NewS3FileStorage(region, bucket, endpoint string, disableSSL, forcePathStyle bool) *S3FileStorage {
+func NewS3FileStorage(region, bucket, endpoint string, disableSSL, forcePathStyle bool, presignExpiry time.Duration) *S3FileStorage {
 	sess := session.Must(session.NewSession(
 		&aws.Config{
 			Region:           aws.String(region),
@@ -24,20 +32,85 @@ func NewS3FileStorage(region, bucket, endpoint string, disableSSL, forcePathStyl
 			DisableSSL:       aws.Bool(disableSSL),
 			S3ForcePathStyle: aws.Bool(forcePathStyle),
 		}))
+	client := s3.New(sess)
 	return &S3FileStorage{
-		client: s3.New(sess),
-		bucket: bucket,
+		client:        client,
+		uploader:      s3manager.NewUploaderWithClient(client),
+		bucket:        bucket,
+		presignExpiry: presignExpiry,
 	}
 }
 
-func (s *S3FileStorage) Upload(ctx context.Context, key string, data []byte) (string, error) {
-	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(data),
+// Upload streams body to the bucket, bodies larger than a single part are sent as a multipart upload
+// so only a few parts are held in memory at a time. The content type is stored with the object and served on download.
+func (s *S3FileStorage) Upload(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
 	})
 	if err != nil {
 		return "", err
 	}
 	return key, nil
 }
+
+// Open starts reading the object, outbound.ErrFileNotFound is returned when it doesn't exist.
+func (s *S3FileStorage) Open(ctx context.Context, key string) (*outbound.FileContent, error) {
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, outbound.ErrFileNotFound
+		}
+		return nil, err
+	}
+	return &outbound.FileContent{
+		Body:        output.Body,
+		ContentType: aws.StringValue(output.ContentType),
+		Size:        aws.Int64Value(output.ContentLength),
+	}, nil
+}
+
+// PresignDownload returns a URL that allows anyone holding it to download the object until the presign expiry passes.
+func (s *S3FileStorage) PresignDownload(ctx context.Context, key string) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+	return req.Presign(s.presignExpiry)
+}
+
+func (s *S3FileStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// List returns all objects whose key starts with prefix.
+func (s *S3FileStorage) List(ctx context.Context, prefix string) ([]outbound.StoredFile, error) {
+	var files []outbound.StoredFile
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			files = append(files, outbound.StoredFile{
+				Key:          aws.StringValue(object.Key),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -1,26 +1,78 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/a-berahman/todo-list/internal/ports/outbound"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockS3Client struct {
 	s3iface.S3API
-	putObjectErr   error
-	putObjectInput *s3.PutObjectInput
+	deleteObjectErr   error
+	deleteObjectInput *s3.DeleteObjectInput
+	getObjectOutput   *s3.GetObjectOutput
+	getObjectErr      error
+	getObjectInput    *s3.GetObjectInput
+	listPages         []*s3.ListObjectsV2Output
+	listErr           error
+	listInput         *s3.ListObjectsV2Input
 }
 
-func (m *MockS3Client) PutObjectWithContext(ctx context.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
-	m.putObjectInput = input
-	return &s3.PutObjectOutput{}, m.putObjectErr
+func (m *MockS3Client) GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	m.getObjectInput = input
+	return m.getObjectOutput, m.getObjectErr
+}
+
+func (m *MockS3Client) DeleteObjectWithContext(ctx context.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error) {
+	m.deleteObjectInput = input
+	return &s3.DeleteObjectOutput{}, m.deleteObjectErr
+}
+
+func (m *MockS3Client) ListObjectsV2PagesWithContext(ctx context.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	m.listInput = input
+	for i, page := range m.listPages {
+		if !fn(page, i == len(m.listPages)-1) {
+			break
+		}
+	}
+	return m.listErr
+}
+
+type MockS3Uploader struct {
+	s3manageriface.UploaderAPI
+	uploadErr   error
+	uploadInput *s3manager.UploadInput
+	uploaded    []byte
+}
+
+func (m *MockS3Uploader) UploadWithContext(ctx aws.Context, input *s3manager.UploadInput, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	m.uploadInput = input
+	if m.uploadErr != nil {
+		return nil, m.uploadErr
+	}
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.uploaded = data
+	return &s3manager.UploadOutput{}, nil
 }
 
 func TestS3FileStorage_Upload(t *testing.T) {
@@ -55,26 +107,185 @@ func TestS3FileStorage_Upload(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockS3 := &MockS3Client{
-				putObjectErr: tt.mockErr,
+			mockUploader := &MockS3Uploader{
+				uploadErr: tt.mockErr,
 			}
 
 			storage := &S3FileStorage{
-				client: mockS3,
-				bucket: tt.bucket,
+				client:   &MockS3Client{},
+				uploader: mockUploader,
+				bucket:   tt.bucket,
 			}
 
-			got, err := storage.Upload(context.Background(), tt.key, tt.data)
+			got, err := storage.Upload(context.Background(), tt.key, bytes.NewReader(tt.data), "image/png")
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Equal(t, tt.want, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.data, mockUploader.uploaded)
+			}
+
+			assert.Equal(t, aws.StringValue(mockUploader.uploadInput.Bucket), tt.bucket)
+			assert.Equal(t, aws.StringValue(mockUploader.uploadInput.Key), tt.key)
+			assert.Equal(t, "image/png", aws.StringValue(mockUploader.uploadInput.ContentType))
+		})
+	}
+}
+
+func TestS3FileStorage_Open(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  *s3.GetObjectOutput
+		mockErr error
+		wantErr error
+	}{
+		{
+			name: "successful open",
+			output: &s3.GetObjectOutput{
+				Body:          io.NopCloser(strings.NewReader("test data")),
+				ContentType:   aws.String("text/plain"),
+				ContentLength: aws.Int64(9),
+			},
+		},
+		{
+			name:    "missing object",
+			mockErr: awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil),
+			wantErr: outbound.ErrFileNotFound,
+		},
+		{
+			name:    "open fails",
+			mockErr: errors.New("s3 error"),
+			wantErr: errors.New("s3 error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockS3 := &MockS3Client{getObjectOutput: tt.output, getObjectErr: tt.mockErr}
+			storage := &S3FileStorage{client: mockS3, bucket: "test-bucket"}
+
+			got, err := storage.Open(context.Background(), "test-key")
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				data, err := io.ReadAll(got.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "test data", string(data))
+				assert.Equal(t, "text/plain", got.ContentType)
+				assert.Equal(t, int64(9), got.Size)
+			}
+
+			assert.Equal(t, "test-bucket", aws.StringValue(mockS3.getObjectInput.Bucket))
+			assert.Equal(t, "test-key", aws.StringValue(mockS3.getObjectInput.Key))
+		})
+	}
+}
+
+func TestS3FileStorage_PresignDownload(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+	storage := &S3FileStorage{client: s3.New(sess), bucket: "test-bucket", presignExpiry: 15 * time.Minute}
+
+	got, err := storage.PresignDownload(context.Background(), "todos/1/a")
+	assert.NoError(t, err)
+
+	presigned, err := url.Parse(got)
+	assert.NoError(t, err)
+	assert.Contains(t, presigned.Host+presigned.Path, "test-bucket")
+	assert.Contains(t, presigned.Path, "todos/1/a")
+	assert.Equal(t, "900", presigned.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, presigned.Query().Get("X-Amz-Signature"))
+}
+
+func TestS3FileStorage_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockErr error
+		wantErr bool
+	}{
+		{
+			name: "successful delete",
+		},
+		{
+			name:    "delete fails",
+			mockErr: errors.New("s3 error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockS3 := &MockS3Client{deleteObjectErr: tt.mockErr}
+			storage := &S3FileStorage{client: mockS3, bucket: "test-bucket"}
+
+			err := storage.Delete(context.Background(), "test-key")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, "test-bucket", aws.StringValue(mockS3.deleteObjectInput.Bucket))
+			assert.Equal(t, "test-key", aws.StringValue(mockS3.deleteObjectInput.Key))
+		})
+	}
+}
+
+func TestS3FileStorage_List(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		pages   []*s3.ListObjectsV2Output
+		mockErr error
+		want    []outbound.StoredFile
+		wantErr bool
+	}{
+		{
+			name: "objects across pages",
+			pages: []*s3.ListObjectsV2Output{
+				{Contents: []*s3.Object{
+					{Key: aws.String("todos/1/a"), LastModified: aws.Time(modified)},
+					{Key: aws.String("todos/1/b"), LastModified: aws.Time(modified)},
+				}},
+				{Contents: []*s3.Object{{Key: aws.String("todos/1/c")}}},
+			},
+			want: []outbound.StoredFile{
+				{Key: "todos/1/a", LastModified: modified},
+				{Key: "todos/1/b", LastModified: modified},
+				{Key: "todos/1/c"},
+			},
+		},
+		{
+			name:  "no objects",
+			pages: []*s3.ListObjectsV2Output{{}},
+		},
+		{
+			name:    "list fails",
+			mockErr: errors.New("s3 error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockS3 := &MockS3Client{listPages: tt.pages, listErr: tt.mockErr}
+			storage := &S3FileStorage{client: mockS3, bucket: "test-bucket"}
+
+			got, err := storage.List(context.Background(), "todos/1/")
+			if tt.wantErr {
+				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
 			}
 
-			assert.Equal(t, aws.StringValue(mockS3.putObjectInput.Bucket), tt.bucket)
-			assert.Equal(t, aws.StringValue(mockS3.putObjectInput.Key), tt.key)
+			assert.Equal(t, "todos/1/", aws.StringValue(mockS3.listInput.Prefix))
 		})
 	}
 }
@@ -114,11 +325,14 @@ func TestNewS3FileStorage(t *testing.T) {
 				tt.endpoint,
 				tt.disableSSL,
 				tt.forcePathStyle,
+				15*time.Minute,
 			)
 
 			assert.NotNil(t, storage)
 			assert.NotNil(t, storage.client)
+			assert.NotNil(t, storage.uploader)
 			assert.Equal(t, tt.bucket, storage.bucket)
+			assert.Equal(t, 15*time.Minute, storage.presignExpiry)
 		})
 	}
 }
@@ -0,0 +1,15 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+)
+
+type APIKeyService interface {
+	MintAPIKey(ctx context.Context, name string, scopes []domain.Scope) (domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	RotateAPIKey(ctx context.Context, id string) (domain.APIKey, string, error)
+	AuthenticateAPIKey(ctx context.Context, secret string) (domain.APIKey, error)
+}
@@ -3,9 +3,47 @@ package inbound
 import (
 	"context"
 
+	"github.com/a-berahman/todo-list/internal/application"
 	"github.com/a-berahman/todo-list/internal/domain"
 )
 
 type TodoService interface {
-	CreateTodo(ctx context.Context, todo domain.TodoItem, fileData []byte) error
+	CreateTodo(ctx context.Context, todo domain.TodoItem, files []domain.FileUpload) (domain.TodoItem, error)
+	GetTodo(ctx context.Context, id string) (domain.TodoItem, error)
+	GetTodoTree(ctx context.Context, id string) (domain.TodoItem, error)
+	ListTodos(ctx context.Context, query application.TodoQuery) (domain.TodoPage, error)
+	UpdateTodo(ctx context.Context, id string, patch domain.TodoItemPatch, expectedVersion int32) (domain.TodoItem, error)
+	TransitionTodo(ctx context.Context, id string, status domain.TodoStatus, expectedVersion int32) (domain.TodoItem, error)
+	MoveTodo(ctx context.Context, id string, position domain.TodoPosition, expectedVersion int32) (domain.TodoItem, error)
+	DeleteTodo(ctx context.Context, id string) error
+	RestoreTodo(ctx context.Context, id string) (domain.TodoItem, error)
+	AddAttachments(ctx context.Context, todoID string, files []domain.FileUpload) ([]domain.Attachment, error)
+	ListAttachments(ctx context.Context, todoID string) ([]domain.Attachment, error)
+	RemoveAttachment(ctx context.Context, todoID, attachmentID string) error
+	OpenAttachment(ctx context.Context, todoID, attachmentID string) (*domain.FileDownload, error)
+	AttachmentURL(ctx context.Context, todoID, attachmentID string) (string, error)
+	AddChecklistItem(ctx context.Context, todoID string, item domain.ChecklistItem) (domain.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, todoID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error)
+	RemoveChecklistItem(ctx context.Context, todoID, itemID string) error
+	AddDependency(ctx context.Context, todoID, blockerID string) (domain.TodoDependency, error)
+	RemoveDependency(ctx context.Context, todoID, blockerID string) error
+	GetDependencyGraph(ctx context.Context, todoIDs []string) (domain.DependencyGraph, []string, error)
+	AddComment(ctx context.Context, todoID string, comment domain.Comment) (domain.Comment, error)
+	ListComments(ctx context.Context, todoID string) ([]domain.Comment, error)
+	ShareTodo(ctx context.Context, todoID string, share domain.Share) (domain.Share, error)
+	ListShares(ctx context.Context, todoID string) ([]domain.Share, error)
+	RevokeShare(ctx context.Context, todoID, shareID string) error
+	CreateTag(ctx context.Context, tag domain.Tag) (domain.Tag, error)
+	GetTag(ctx context.Context, id string) (domain.Tag, error)
+	ListTags(ctx context.Context) ([]domain.Tag, error)
+	UpdateTag(ctx context.Context, id string, tag domain.Tag) (domain.Tag, error)
+	DeleteTag(ctx context.Context, id string) error
+	CreateList(ctx context.Context, list domain.List) (domain.List, error)
+	GetList(ctx context.Context, id string) (domain.List, error)
+	ListLists(ctx context.Context, includeArchived bool) ([]domain.List, error)
+	UpdateList(ctx context.Context, id string, patch domain.ListPatch) (domain.List, error)
+	DeleteList(ctx context.Context, id string) error
+	ShareList(ctx context.Context, listID string, share domain.Share) (domain.Share, error)
+	ListListShares(ctx context.Context, listID string) ([]domain.Share, error)
+	RevokeListShare(ctx context.Context, listID, shareID string) error
 }
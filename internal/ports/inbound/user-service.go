@@ -0,0 +1,11 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+)
+
+type UserService interface {
+	SignIn(ctx context.Context, subject, email, name string) (domain.User, error)
+}
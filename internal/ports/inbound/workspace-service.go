@@ -0,0 +1,16 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/a-berahman/todo-list/internal/domain"
+)
+
+type WorkspaceService interface {
+	ResolveWorkspace(ctx context.Context, workspaceID string) (domain.WorkspaceMember, error)
+	CreateWorkspace(ctx context.Context, name string) (domain.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]domain.Workspace, error)
+	ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error)
+	SetWorkspaceMember(ctx context.Context, workspaceID, userID string, role domain.Role) error
+	RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+}
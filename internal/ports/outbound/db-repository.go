@@ -7,5 +7,7 @@ import (
 )
 
 type DBRepository interface {
-	CreateTodo(ctx context.Context, arg db.CreateTodoParams) error
+	db.Querier
+	// ExecTx runs fn inside a transaction, committing only when fn returns nil.
+	ExecTx(ctx context.Context, fn func(q db.Querier) error) error
 }
@@ -1,7 +1,31 @@
 package outbound
 
-import "context"
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrFileNotFound = errors.New("file not found")
 
 type FileStorage interface {
-	Upload(ctx context.Context, key string, file []byte) (string, error)
+	Upload(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	Open(ctx context.Context, key string) (*FileContent, error)
+	PresignDownload(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]StoredFile, error)
+}
+
+// StoredFile describes an object kept in the file storage.
+type StoredFile struct {
+	Key          string
+	LastModified time.Time
+}
+
+// FileContent is an opened file, the caller must close Body.
+type FileContent struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
 }